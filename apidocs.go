@@ -0,0 +1,176 @@
+package zentrox
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"sync"
+)
+
+// ServeSwaggerSpec serves an OpenAPI/Swagger document (JSON or YAML) from
+// memory at path, so it ships inside the binary instead of being read from
+// the working directory:
+//
+//	//go:embed docs/swagger.json
+//	var spec []byte
+//
+//	app.ServeSwaggerSpec("/docs/swagger.json", spec)
+//
+// Responses carry a strong ETag and honour If-None-Match.
+func (a *App) ServeSwaggerSpec(path string, spec []byte) *Route {
+	h := specHandler(func() []byte { return spec })
+	a.on(http.MethodHead, path, h)
+	return a.GET(path, h)
+}
+
+// ServeSwaggerSpecFS serves name from fsys (typically an embed.FS) like
+// ServeSwaggerSpec. The file is read once, at registration.
+func (a *App) ServeSwaggerSpecFS(path string, fsys fs.FS, name string) (*Route, error) {
+	spec, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return a.ServeSwaggerSpec(path, spec), nil
+}
+
+// ServeOpenAPI serves the document built by GenerateOpenAPI at path. It is
+// generated on the first request, after all routes are registered.
+func (a *App) ServeOpenAPI(path string, info OpenAPIInfo) *Route {
+	var once sync.Once
+	var spec []byte
+	h := specHandler(func() []byte {
+		once.Do(func() {
+			var err error
+			if spec, err = a.GenerateOpenAPI(info); err != nil {
+				a.Logger().Error("zentrox: generate OpenAPI", "err", err)
+			}
+		})
+		return spec
+	})
+	a.on(http.MethodHead, path, h)
+	return a.GET(path, h)
+}
+
+func specHandler(load func() []byte) Handler {
+	var once sync.Once
+	var etag, ctype string
+	return func(c *Context) {
+		spec := load()
+		if spec == nil {
+			c.Fail(http.StatusInternalServerError, MsgInternalServerError)
+			return
+		}
+		once.Do(func() {
+			sum := sha1.Sum(spec)
+			etag = `"` + hex.EncodeToString(sum[:]) + `"`
+			ctype = ContentTypeYAML
+			if b := bytes.TrimSpace(spec); len(b) > 0 && b[0] == '{' {
+				ctype = ContentTypeJSON
+			}
+		})
+		c.SetHeader(HeaderETag, etag)
+		c.SetHeader(HeaderCacheControl, CacheControlNoCache)
+		if etagMatch(c.GetHeader(HeaderIfNoneMatch), etag) {
+			c.SendStatus(http.StatusNotModified)
+			return
+		}
+		c.Data(http.StatusOK, ctype, spec)
+	}
+}
+
+// DocsUIOptions configures ServeSwagger, ServeRedoc and ServeScalar.
+type DocsUIOptions struct {
+	// Title is the page title (default "API Reference").
+	Title string
+	// SpecURL is where the browser loads the document from (default
+	// "/openapi.json"), e.g. a route registered with ServeOpenAPI.
+	SpecURL string
+	// ScriptURL and StyleURL override the CDN assets, e.g. to self-host
+	// them or pin a version.
+	ScriptURL string
+	StyleURL  string
+}
+
+// Default CDN assets of the documentation UIs.
+const (
+	SwaggerUIScriptURL = "https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui-bundle.js"
+	SwaggerUIStyleURL  = "https://cdn.jsdelivr.net/npm/swagger-ui-dist@5/swagger-ui.css"
+	RedocScriptURL     = "https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"
+	ScalarScriptURL    = "https://cdn.jsdelivr.net/npm/@scalar/api-reference"
+)
+
+var (
+	swaggerUIPage = template.Must(template.New("swagger").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.StyleURL}}"></head>
+<body><div id="swagger-ui"></div>
+<script src="{{.ScriptURL}}"></script>
+<script>window.ui = SwaggerUIBundle({url: {{.SpecURL}}, dom_id: "#swagger-ui"});</script>
+</body></html>
+`))
+	redocPage = template.Must(template.New("redoc").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<meta name="viewport" content="width=device-width, initial-scale=1"></head>
+<body><redoc spec-url="{{.SpecURL}}"></redoc>
+<script src="{{.ScriptURL}}"></script>
+</body></html>
+`))
+	scalarPage = template.Must(template.New("scalar").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<meta name="viewport" content="width=device-width, initial-scale=1"></head>
+<body><script id="api-reference" data-url="{{.SpecURL}}"></script>
+<script src="{{.ScriptURL}}"></script>
+</body></html>
+`))
+)
+
+// ServeSwagger serves a Swagger UI page for the spec at opt.SpecURL:
+//
+//	app.ServeOpenAPI("/openapi.json", info)
+//	app.ServeSwagger("/docs")
+//
+// The UIs load their assets from a CDN; allow it in any Content-Security-Policy
+// or set ScriptURL/StyleURL to self-hosted copies.
+func (a *App) ServeSwagger(path string, opt ...DocsUIOptions) *Route {
+	return a.serveDocsUI(path, swaggerUIPage, SwaggerUIScriptURL, SwaggerUIStyleURL, opt)
+}
+
+// ServeRedoc serves a ReDoc page for the spec at opt.SpecURL.
+func (a *App) ServeRedoc(path string, opt ...DocsUIOptions) *Route {
+	return a.serveDocsUI(path, redocPage, RedocScriptURL, "", opt)
+}
+
+// ServeScalar serves a Scalar API reference page for the spec at opt.SpecURL.
+func (a *App) ServeScalar(path string, opt ...DocsUIOptions) *Route {
+	return a.serveDocsUI(path, scalarPage, ScalarScriptURL, "", opt)
+}
+
+func (a *App) serveDocsUI(path string, page *template.Template, script, style string, opts []DocsUIOptions) *Route {
+	var o DocsUIOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Title == "" {
+		o.Title = "API Reference"
+	}
+	if o.SpecURL == "" {
+		o.SpecURL = "/openapi.json"
+	}
+	if o.ScriptURL == "" {
+		o.ScriptURL = script
+	}
+	if o.StyleURL == "" {
+		o.StyleURL = style
+	}
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, o); err != nil {
+		panic("zentrox: render docs UI: " + err.Error())
+	}
+	body := buf.Bytes()
+	return a.GET(path, func(c *Context) {
+		c.Data(http.StatusOK, ContentTypeHTMLUTF8, body)
+	})
+}
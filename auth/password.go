@@ -0,0 +1,217 @@
+// Package auth provides authentication helpers: password hashing and related
+// primitives used by login flows.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithm selects the password hashing scheme.
+type Algorithm string
+
+const (
+	Argon2id Algorithm = "argon2id"
+	Bcrypt   Algorithm = "bcrypt"
+)
+
+var (
+	ErrInvalidHash         = errors.New("auth: invalid password hash")
+	ErrIncompatibleVersion = errors.New("auth: incompatible argon2 version")
+	ErrInvalidParams       = errors.New("auth: argon2id parameters out of range")
+)
+
+// PasswordParams tunes password hashing. Memory is in KiB.
+type PasswordParams struct {
+	Algorithm   Algorithm
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+	BcryptCost  int
+}
+
+// DefaultPasswordParams follows the RFC 9106 recommendation for memory
+// constrained environments: argon2id, 64 MiB, 3 passes, 4 lanes.
+func DefaultPasswordParams() PasswordParams {
+	return PasswordParams{
+		Algorithm:   Argon2id,
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 4,
+		SaltLength:  16,
+		KeyLength:   32,
+		BcryptCost:  12,
+	}
+}
+
+// HashPassword hashes password with DefaultPasswordParams and returns an
+// encoded string that embeds the algorithm, parameters and salt.
+func HashPassword(password string) (string, error) {
+	return HashPasswordWithParams(password, DefaultPasswordParams())
+}
+
+// HashPasswordWithParams hashes password with p.
+func HashPasswordWithParams(password string, p PasswordParams) (string, error) {
+	p = p.withDefaults()
+	if p.Algorithm == Bcrypt {
+		b, err := bcrypt.GenerateFromPassword([]byte(password), p.BcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+
+	if !p.argon2InRange() {
+		return "", ErrInvalidParams
+	}
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// VerifyPassword reports whether password matches encoded, which may be an
+// argon2id hash produced by HashPassword or a bcrypt hash ($2a$, $2b$, $2y$).
+// A mismatch returns false with a nil error.
+func VerifyPassword(password, encoded string) (bool, error) {
+	if isBcrypt(encoded) {
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash reports whether encoded was produced with weaker or different
+// settings than DefaultPasswordParams. Call it after a successful login and
+// store a fresh hash when it returns true.
+func NeedsRehash(encoded string) bool {
+	return NeedsRehashWithParams(encoded, DefaultPasswordParams())
+}
+
+// NeedsRehashWithParams is like NeedsRehash for custom parameters.
+func NeedsRehashWithParams(encoded string, p PasswordParams) bool {
+	p = p.withDefaults()
+	if isBcrypt(encoded) {
+		if p.Algorithm != Bcrypt {
+			return true
+		}
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost < p.BcryptCost
+	}
+	if p.Algorithm != Argon2id {
+		return true
+	}
+	got, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return got.Memory < p.Memory || got.Iterations < p.Iterations || got.Parallelism < p.Parallelism ||
+		uint32(len(salt)) < p.SaltLength || uint32(len(key)) < p.KeyLength
+}
+
+func (p PasswordParams) withDefaults() PasswordParams {
+	def := DefaultPasswordParams()
+	if p.Algorithm == "" {
+		p.Algorithm = def.Algorithm
+	}
+	if p.Memory == 0 {
+		p.Memory = def.Memory
+	}
+	if p.Iterations == 0 {
+		p.Iterations = def.Iterations
+	}
+	if p.Parallelism == 0 {
+		p.Parallelism = def.Parallelism
+	}
+	if p.SaltLength == 0 {
+		p.SaltLength = def.SaltLength
+	}
+	if p.KeyLength == 0 {
+		p.KeyLength = def.KeyLength
+	}
+	if p.BcryptCost == 0 {
+		p.BcryptCost = def.BcryptCost
+	}
+	return p
+}
+
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// Limits on argon2id parameters, checked when hashing and when decoding
+// stored hashes, so a corrupted or crafted hash cannot exhaust memory or
+// CPU in VerifyPassword.
+const (
+	maxArgon2Memory      = 1 << 20 // KiB (1 GiB)
+	maxArgon2Iterations  = 64
+	maxArgon2Parallelism = 64
+	maxArgon2Length      = 1024 // salt and key bytes
+)
+
+// argon2InRange reports whether p is within the limits above.
+func (p PasswordParams) argon2InRange() bool {
+	return p.Memory <= maxArgon2Memory &&
+		p.Iterations > 0 && p.Iterations <= maxArgon2Iterations &&
+		p.Parallelism > 0 && p.Parallelism <= maxArgon2Parallelism &&
+		p.SaltLength > 0 && p.SaltLength <= maxArgon2Length &&
+		p.KeyLength > 0 && p.KeyLength <= maxArgon2Length
+}
+
+// decodeArgon2id parses $argon2id$v=19$m=65536,t=3,p=4$<salt>$<key>.
+func decodeArgon2id(encoded string) (p PasswordParams, salt, key []byte, err error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, ErrInvalidHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if version != argon2.Version {
+		return p, nil, nil, ErrIncompatibleVersion
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	// Check the encoded lengths before decoding anything large.
+	if len(parts[4]) > base64.RawStdEncoding.EncodedLen(maxArgon2Length) ||
+		len(parts[5]) > base64.RawStdEncoding.EncodedLen(maxArgon2Length) {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+	if !p.argon2InRange() {
+		return p, nil, nil, ErrInvalidHash
+	}
+	p.Algorithm = Argon2id
+	return p, salt, key, nil
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTPOptions tunes TOTP generation and verification (RFC 6238, HMAC-SHA1).
+type TOTPOptions struct {
+	// Digits is the code length, clamped to the 6–8 that RFC 4226 and
+	// authenticator apps support.
+	Digits int
+	// Period is the time step; it is rounded up to whole seconds, as
+	// otpauth URIs and authenticator apps only support those.
+	Period time.Duration
+	// Skew is the number of periods accepted before and after the current
+	// one to tolerate clock drift.
+	Skew int
+}
+
+func DefaultTOTPOptions() TOTPOptions {
+	return TOTPOptions{Digits: 6, Period: 30 * time.Second, Skew: 1}
+}
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random 160-bit secret, base32 encoded as
+// expected by authenticator apps.
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// TOTPURI returns the otpauth:// provisioning URI for secret. Render it as a
+// QR code for authenticator apps to scan.
+func TOTPURI(secret, issuer, account string) string {
+	return TOTPURIWithOptions(secret, issuer, account, DefaultTOTPOptions())
+}
+
+// TOTPURIWithOptions is like TOTPURI with custom digits and period.
+func TOTPURIWithOptions(secret, issuer, account string, o TOTPOptions) string {
+	o = o.withDefaults()
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(o.Digits))
+	q.Set("period", fmt.Sprint(int(o.Period/time.Second)))
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// TOTPCode returns the code for secret at t.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	o := DefaultTOTPOptions()
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, o.step(t), o.Digits), nil
+}
+
+// VerifyTOTP checks code against secret at the current time, allowing the
+// default drift window. lastStep is the time step of the last code accepted
+// for this user (0 if none); codes for that step or earlier are rejected to
+// prevent replay. On success it returns the matched step, which the caller
+// must persist as the new lastStep.
+func VerifyTOTP(secret, code string, lastStep int64) (int64, bool) {
+	return VerifyTOTPWithOptions(secret, code, lastStep, time.Now(), DefaultTOTPOptions())
+}
+
+// VerifyTOTPWithOptions is like VerifyTOTP for a given time and options.
+func VerifyTOTPWithOptions(secret, code string, lastStep int64, now time.Time, o TOTPOptions) (int64, bool) {
+	o = o.withDefaults()
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != o.Digits {
+		return 0, false
+	}
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+	cur := o.step(now)
+	for i := -o.Skew; i <= o.Skew; i++ {
+		step := cur + int64(i)
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(hotp(key, step, o.Digits)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func (o TOTPOptions) withDefaults() TOTPOptions {
+	def := DefaultTOTPOptions()
+	if o.Digits <= 0 {
+		o.Digits = def.Digits
+	}
+	o.Digits = min(max(o.Digits, 6), 8)
+	if o.Period <= 0 {
+		o.Period = def.Period
+	}
+	if o.Period%time.Second != 0 {
+		o.Period = o.Period.Truncate(time.Second) + time.Second
+	}
+	if o.Skew < 0 {
+		o.Skew = 0
+	}
+	return o
+}
+
+func (o TOTPOptions) step(t time.Time) int64 {
+	return t.Unix() / int64(o.Period/time.Second)
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	return totpEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// hotp implements RFC 4226 dynamic truncation.
+func hotp(key []byte, counter int64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	off := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[off:off+4]) & 0x7fffffff
+	mod := uint64(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, uint64(v)%mod)
+}
@@ -0,0 +1,98 @@
+package zentrox
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// BannerInfo describes the server passed to the SetBanner callback.
+type BannerInfo struct {
+	Addr    string
+	Version string
+	Routes  int
+}
+
+// PrintRoutesOptions configures route printing enabled with SetPrintRoutes.
+type PrintRoutesOptions struct {
+	// Format is RoutesTable (default), RoutesGrouped or any format accepted
+	// by ExportRoutes, such as RoutesJSON.
+	Format string
+	// Output defaults to os.Stdout.
+	Output io.Writer
+}
+
+// SetBanner registers fn to run when Run, Start or StartTLS builds the
+// server, e.g. to log the listen address through slog instead of printing
+// text. By default no banner is written; see TextBanner.
+func (a *App) SetBanner(fn func(BannerInfo)) *App {
+	a.banner = fn
+	return a
+}
+
+// SetQuiet suppresses the banner and route printing at startup.
+func (a *App) SetQuiet(v bool) *App {
+	a.quiet = v
+	return a
+}
+
+// TextBanner returns a banner callback writing a single line to w.
+func TextBanner(w io.Writer) func(BannerInfo) {
+	return func(b BannerInfo) {
+		if b.Version != "" {
+			fmt.Fprintf(w, "zentrox: %s listening on %s (%d routes)\n", b.Version, b.Addr, b.Routes)
+			return
+		}
+		fmt.Fprintf(w, "zentrox: listening on %s (%d routes)\n", b.Addr, b.Routes)
+	}
+}
+
+// announce writes the banner and route table configured on a.
+func (a *App) announce(addr string) {
+	if a.quiet {
+		return
+	}
+	if a.banner != nil {
+		a.banner(BannerInfo{Addr: addr, Version: a.version, Routes: len(a.routeIndex)})
+	}
+	if !a.printRoutes {
+		return
+	}
+	out := a.routesOut
+	if out == nil {
+		out = os.Stdout
+	}
+	format := a.routesFormat
+	if format == "" {
+		format = RoutesTable
+	}
+	if err := a.ExportRoutes(out, format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// writeRoutesGrouped prints the route table with one section per Scope.
+func (a *App) writeRoutesGrouped(w io.Writer) error {
+	groups := map[string][]RouteInfo{}
+	for _, r := range a.ListRoutes() {
+		groups[r.Group] = append(groups[r.Group], r)
+	}
+	names := make([]string, 0, len(groups))
+	for g := range groups {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+	for i, g := range names {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		title := g
+		if title == "" {
+			title = "(root)"
+		}
+		fmt.Fprintf(w, "%s\n", title)
+		writeRoutesTable(w, groups[g])
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package binding
 import (
 	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"io"
 	"net/http"
@@ -21,10 +22,13 @@ type Binder interface {
 type jsonBinder struct{}
 type formBinder struct{}
 type queryBinder struct{}
+type xmlBinder struct{}
 
 const (
 	headerContentType         = "Content-Type"
 	contentTypeJSON           = "application/json"
+	contentTypeXML            = "application/xml"
+	contentTypeTextXML        = "text/xml"
 	contentTypeMultipartForm  = "multipart/form-data"
 	contentTypeFormURLEncoded = "application/x-www-form-urlencoded"
 )
@@ -33,6 +37,7 @@ var (
 	JSON  = jsonBinder{}
 	Form  = formBinder{}
 	Query = queryBinder{}
+	XML   = xmlBinder{}
 )
 
 func (jsonBinder) Name() string {
@@ -47,6 +52,10 @@ func (queryBinder) Name() string {
 	return "query"
 }
 
+func (xmlBinder) Name() string {
+	return "xml"
+}
+
 func (jsonBinder) Bind(r *http.Request, dst any) error {
 	if r.Body == nil {
 		return errors.New("empty body")
@@ -54,6 +63,13 @@ func (jsonBinder) Bind(r *http.Request, dst any) error {
 	defer r.Body.Close()
 	return json.NewDecoder(r.Body).Decode(dst)
 }
+func (xmlBinder) Bind(r *http.Request, dst any) error {
+	if r.Body == nil {
+		return errors.New("empty body")
+	}
+	defer r.Body.Close()
+	return xml.NewDecoder(r.Body).Decode(dst)
+}
 func (formBinder) Bind(r *http.Request, dst any) error {
 	if err := r.ParseMultipartForm(32 << 20); err != nil {
 		if err := r.ParseForm(); err != nil {
@@ -66,12 +82,15 @@ func (queryBinder) Bind(r *http.Request, dst any) error {
 	return mapToStruct(r.URL.Query(), dst, "query")
 }
 
-// Auto detect: JSON -> Form -> Query
+// Auto detect: JSON -> XML -> Form -> Query
 func Bind(r *http.Request, dst any) error {
 	ct := r.Header.Get(headerContentType)
 	if strings.HasPrefix(ct, contentTypeJSON) {
 		return JSON.Bind(r, dst)
 	}
+	if strings.HasPrefix(ct, contentTypeXML) || strings.HasPrefix(ct, contentTypeTextXML) {
+		return XML.Bind(r, dst)
+	}
 	if strings.HasPrefix(ct, contentTypeMultipartForm) || strings.HasPrefix(ct, contentTypeFormURLEncoded) {
 		return Form.Bind(r, dst)
 	}
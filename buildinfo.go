@@ -0,0 +1,100 @@
+package zentrox
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// BuildInfoOptions controls MountBuildInfo.
+type BuildInfoOptions struct {
+	// If true, include goroutine, GC and memory statistics in each response.
+	Runtime bool
+}
+
+// BuildInfo is the JSON document served by MountBuildInfo.
+type BuildInfo struct {
+	Module     string        `json:"module,omitempty"`
+	Version    string        `json:"version,omitempty"`
+	AppVersion string        `json:"app_version,omitempty"`
+	GoVersion  string        `json:"go_version"`
+	Revision   string        `json:"revision,omitempty"`
+	BuildTime  string        `json:"build_time,omitempty"`
+	Modified   bool          `json:"modified,omitempty"`
+	Runtime    *RuntimeStats `json:"runtime,omitempty"`
+}
+
+// RuntimeStats is a snapshot of Go runtime metrics.
+type RuntimeStats struct {
+	Uptime       string `json:"uptime"`
+	NumCPU       int    `json:"num_cpu"`
+	Goroutines   int    `json:"goroutines"`
+	NumGC        uint32 `json:"num_gc"`
+	PauseTotalNs uint64 `json:"gc_pause_total_ns"`
+	HeapAlloc    uint64 `json:"heap_alloc_bytes"`
+	HeapInuse    uint64 `json:"heap_inuse_bytes"`
+	Sys          uint64 `json:"sys_bytes"`
+}
+
+var processStart = time.Now()
+
+// MountBuildInfo registers a GET endpoint returning the main module path and
+// version, the VCS revision and commit time stamped by "go build", the Go
+// version and the App version, e.g.
+//
+//	app.MountBuildInfo("/version", zentrox.BuildInfoOptions{Runtime: true})
+//
+// Fields the binary was not built with are omitted.
+func (a *App) MountBuildInfo(path string, opt ...BuildInfoOptions) {
+	var o BuildInfoOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	base := readBuildInfo()
+	a.GET(path, func(c *Context) {
+		info := base
+		info.AppVersion = a.version
+		if o.Runtime {
+			info.Runtime = readRuntimeStats()
+		}
+		c.SetHeader(HeaderCacheControl, "no-store")
+		c.JSON(http.StatusOK, info)
+	})
+}
+
+func readBuildInfo() BuildInfo {
+	info := BuildInfo{GoVersion: runtime.Version()}
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	info.Module = bi.Main.Path
+	info.Version = bi.Main.Version
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.BuildTime = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+func readRuntimeStats() *RuntimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return &RuntimeStats{
+		Uptime:       time.Since(processStart).Round(time.Second).String(),
+		NumCPU:       runtime.NumCPU(),
+		Goroutines:   runtime.NumGoroutine(),
+		NumGC:        m.NumGC,
+		PauseTotalNs: m.PauseTotalNs,
+		HeapAlloc:    m.HeapAlloc,
+		HeapInuse:    m.HeapInuse,
+		Sys:          m.Sys,
+	}
+}
@@ -0,0 +1,432 @@
+package zentrox
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// RoutesGoClient is the ExportRoutes format producing a typed Go client in
+// package "client"; use "go:<pkg>" to pick another package name.
+const RoutesGoClient = "go"
+
+// GenerateClient writes a typed Go client for the registered routes to w as
+// package pkg. Routes are grouped into services by their first path segment,
+// skipping "api" and version segments such as "v1", so that
+//
+//	GET  /api/v1/users/:id  ->  client.Users.Get(ctx, id)
+//	POST /api/v1/users      ->  client.Users.Create(ctx, body)
+//
+// Request and response types come from Route.Body and Route.Returns; their
+// struct definitions are copied into the generated package. Routes without
+// Returns return only an error. Non-2xx responses are returned as *APIError.
+func (a *App) GenerateClient(w io.Writer, pkg string) error {
+	if pkg == "" {
+		pkg = "client"
+	}
+	g := &clientGen{types: map[reflect.Type]string{}, names: map[string]bool{}}
+	services := map[string]*clientService{}
+	var root clientService
+
+	for _, ri := range a.ListRoutes() {
+		if ri.Method == http.MethodHead || ri.Method == http.MethodOptions {
+			continue
+		}
+		resource, rest := splitResource(ri.Path)
+		svc := &root
+		if resource != "" {
+			name := exportedIdent(resource)
+			if services[name] == nil {
+				services[name] = &clientService{name: name, used: map[string]bool{}}
+			}
+			svc = services[name]
+		} else if root.used == nil {
+			root.used = map[string]bool{}
+		}
+		svc.methods = append(svc.methods, g.method(svc, ri, rest))
+	}
+
+	names := make([]string, 0, len(services))
+	for n := range services {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by zentrox GenerateClient. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	b.WriteString("import (\n\t\"bytes\"\n\t\"context\"\n\t\"encoding/json\"\n\t\"fmt\"\n\t\"io\"\n\t\"net/http\"\n")
+	if g.usesURL {
+		b.WriteString("\t\"net/url\"\n")
+	}
+	if g.usesTime {
+		b.WriteString("\t\"time\"\n")
+	}
+	b.WriteString(")\n\n")
+	b.WriteString(clientPrelude)
+
+	b.WriteString("// Client calls the API.\ntype Client struct {\n\tBaseURL string\n\tHTTPClient *http.Client\n\n")
+	for _, n := range names {
+		fmt.Fprintf(&b, "\t%s *%sService\n", n, n)
+	}
+	b.WriteString("}\n\n// New returns a Client for baseURL, e.g. \"http://localhost:8000\".\nfunc New(baseURL string) *Client {\n\tc := &Client{BaseURL: baseURL, HTTPClient: http.DefaultClient}\n")
+	for _, n := range names {
+		fmt.Fprintf(&b, "\tc.%s = &%sService{c: c}\n", n, n)
+	}
+	b.WriteString("\treturn c\n}\n\n")
+	for _, m := range root.methods {
+		b.WriteString(m.render("c *Client", "c"))
+	}
+	for _, n := range names {
+		svc := services[n]
+		fmt.Fprintf(&b, "// %sService groups the %s routes.\ntype %sService struct{ c *Client }\n\n", n, n, n)
+		for _, m := range svc.methods {
+			b.WriteString(m.render("s *"+n+"Service", "s.c"))
+		}
+	}
+	b.WriteString(g.decls.String())
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return fmt.Errorf("zentrox: format generated client: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+const clientPrelude = `// APIError is returned for non-2xx responses.
+type APIError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), bytes.TrimSpace(e.Body))
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return &APIError{StatusCode: resp.StatusCode, Body: b}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+`
+
+type clientService struct {
+	name    string
+	methods []clientMethod
+	used    map[string]bool
+}
+
+type clientMethod struct {
+	name    string
+	summary string
+	verb    string
+	route   string // route pattern
+	path    string // Go expression building the path
+	params  []string
+	body    string // Go type of the request body, "" if none
+	result  string // Go type of the result, "" if none
+	pointer bool   // result is returned as *result
+}
+
+func (m clientMethod) render(recv, client string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// %s calls %s %s.\n", m.name, m.verb, m.route)
+	if m.summary != "" {
+		fmt.Fprintf(&b, "//\n// %s\n", m.summary)
+	}
+	args := []string{"ctx context.Context"}
+	for _, p := range m.params {
+		args = append(args, p+" string")
+	}
+	in := "nil"
+	if m.body != "" {
+		args = append(args, "body "+m.body)
+		in = "body"
+	}
+	switch {
+	case m.result == "":
+		fmt.Fprintf(&b, "func (%s) %s(%s) error {\n", recv, m.name, strings.Join(args, ", "))
+		fmt.Fprintf(&b, "\treturn %s.do(ctx, %q, %s, %s, nil)\n}\n\n", client, m.verb, m.path, in)
+	case m.pointer:
+		fmt.Fprintf(&b, "func (%s) %s(%s) (*%s, error) {\n", recv, m.name, strings.Join(args, ", "), m.result)
+		fmt.Fprintf(&b, "\tout := new(%s)\n\tif err := %s.do(ctx, %q, %s, %s, out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn out, nil\n}\n\n", m.result, client, m.verb, m.path, in)
+	default:
+		fmt.Fprintf(&b, "func (%s) %s(%s) (%s, error) {\n", recv, m.name, strings.Join(args, ", "), m.result)
+		fmt.Fprintf(&b, "\tvar out %s\n\terr := %s.do(ctx, %q, %s, %s, &out)\n\treturn out, err\n}\n\n", m.result, client, m.verb, m.path, in)
+	}
+	return b.String()
+}
+
+var versionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// splitResource returns the first static segment that is not "api" or a
+// version, and the segments after it.
+func splitResource(pattern string) (string, []string) {
+	segs := splitPattern(strings.Trim(pattern, "/"))
+	for i, s := range segs {
+		if s == "" || s == "api" || versionSegment.MatchString(s) {
+			continue
+		}
+		if s[0] == ':' || s[0] == '*' {
+			return "", segs[i:]
+		}
+		return s, segs[i+1:]
+	}
+	return "", nil
+}
+
+func (g *clientGen) method(svc *clientService, ri RouteInfo, rest []string) clientMethod {
+	m := clientMethod{verb: ri.Method, route: ri.Path, summary: ri.Summary}
+
+	// Path expression with params substituted.
+	var parts []string
+	lit := ""
+	for _, seg := range splitPattern(strings.Trim(ri.Path, "/")) {
+		if seg == "" {
+			continue
+		}
+		if seg[0] == ':' || seg[0] == '*' {
+			p := paramIdent(paramName(seg))
+			for _, have := range m.params {
+				if have == p {
+					p += "2"
+				}
+			}
+			m.params = append(m.params, p)
+			parts = append(parts, fmt.Sprintf("%q", lit+"/"))
+			lit = ""
+			if seg[0] == '*' {
+				parts = append(parts, p)
+			} else {
+				g.usesURL = true
+				parts = append(parts, "url.PathEscape("+p+")")
+			}
+			continue
+		}
+		lit += "/" + seg
+	}
+	if lit != "" || len(parts) == 0 {
+		if lit == "" {
+			lit = "/"
+		}
+		parts = append(parts, fmt.Sprintf("%q", lit))
+	}
+	m.path = strings.Join(parts, " + ")
+
+	// Method name.
+	var statics []string
+	endsWithParam := false
+	for _, s := range rest {
+		if s == "" {
+			continue
+		}
+		endsWithParam = s[0] == ':' || s[0] == '*'
+		if !endsWithParam {
+			statics = append(statics, exportedIdent(s))
+		}
+	}
+	var name string
+	switch {
+	case len(rest) == 0:
+		name = map[string]string{http.MethodGet: "List", http.MethodPost: "Create", http.MethodDelete: "DeleteAll"}[ri.Method]
+	case len(rest) == 1 && endsWithParam:
+		name = map[string]string{http.MethodGet: "Get", http.MethodPut: "Update", http.MethodDelete: "Delete"}[ri.Method]
+	}
+	if name == "" {
+		name = exportedIdent(strings.ToLower(ri.Method)) + strings.Join(statics, "")
+	}
+	base := name
+	for i := 2; svc.used[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	svc.used[name] = true
+	m.name = name
+
+	if ri.Body != nil {
+		m.body = g.goType(reflect.TypeOf(ri.Body))
+	}
+	if ri.Response != nil {
+		t := reflect.TypeOf(ri.Response)
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		m.result = g.goType(t)
+		m.pointer = t.Kind() == reflect.Struct
+	}
+	return m
+}
+
+// clientGen converts Go types into source for the generated package.
+type clientGen struct {
+	types    map[reflect.Type]string
+	names    map[string]bool
+	decls    strings.Builder
+	usesTime bool
+	usesURL  bool
+}
+
+var clientTimeType = reflect.TypeOf(time.Time{})
+
+func (g *clientGen) goType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return "*" + g.goType(t.Elem())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			if t.PkgPath() == "encoding/json" && t.Name() == "RawMessage" {
+				return "json.RawMessage"
+			}
+			return "[]byte"
+		}
+		return "[]" + g.goType(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), g.goType(t.Elem()))
+	case reflect.Map:
+		return "map[" + g.goType(t.Key()) + "]" + g.goType(t.Elem())
+	case reflect.Interface:
+		return "any"
+	case reflect.Struct:
+		if t == clientTimeType {
+			g.usesTime = true
+			return "time.Time"
+		}
+		if t.Name() == "" {
+			return g.structBody(t)
+		}
+		if name, ok := g.types[t]; ok {
+			return name
+		}
+		name := exportedIdent(t.Name())
+		base := name
+		for i := 2; g.names[name]; i++ {
+			name = fmt.Sprintf("%s%d", base, i)
+		}
+		g.names[name] = true
+		g.types[t] = name
+		body := g.structBody(t)
+		fmt.Fprintf(&g.decls, "type %s %s\n\n", name, body)
+		return name
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return "any"
+	}
+	// Basic kinds; named basic types (type Status string) collapse to their
+	// underlying type.
+	return t.Kind().String()
+}
+
+func (g *clientGen) structBody(t reflect.Type) string {
+	var b strings.Builder
+	b.WriteString("struct {\n")
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		typ := g.goType(f.Type)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Type.Name() != "" {
+			b.WriteString("\t" + typ)
+		} else {
+			b.WriteString("\t" + f.Name + " " + typ)
+		}
+		if tag := f.Tag.Get("json"); tag != "" {
+			fmt.Fprintf(&b, " `json:%q`", tag)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// identWords splits "order-items" or "order_id" into words.
+func identWords(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+var initialisms = map[string]bool{
+	"api": true, "id": true, "ip": true, "json": true, "http": true,
+	"url": true, "uri": true, "uuid": true, "html": true, "sql": true,
+}
+
+func titleWord(w string) string {
+	if initialisms[strings.ToLower(w)] {
+		return strings.ToUpper(w)
+	}
+	r := []rune(w)
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+// exportedIdent turns "order-items" into "OrderItems" and "user_id" into
+// "UserID".
+func exportedIdent(s string) string {
+	var b strings.Builder
+	for _, w := range identWords(s) {
+		b.WriteString(titleWord(w))
+	}
+	out := b.String()
+	if out == "" || unicode.IsDigit(rune(out[0])) {
+		out = "X" + out
+	}
+	return out
+}
+
+var goKeywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true,
+	"default": true, "defer": true, "else": true, "fallthrough": true, "for": true,
+	"func": true, "go": true, "goto": true, "if": true, "import": true,
+	"interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+	// names used by the generated methods
+	"ctx": true, "body": true, "out": true, "err": true, "url": true,
+}
+
+// paramIdent turns a path parameter name into an unexported identifier.
+func paramIdent(s string) string {
+	words := identWords(s)
+	if len(words) == 0 || unicode.IsDigit(rune(words[0][0])) {
+		return "p" + exportedIdent(s)
+	}
+	id := strings.ToLower(words[0])
+	for _, w := range words[1:] {
+		id += titleWord(w)
+	}
+	if goKeywords[id] {
+		id += "Param"
+	}
+	return id
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// devConfig holds `zentrox dev` options.
+type devConfig struct {
+	proxyAddr string
+	appAddr   string
+	pkg       string
+	exts      []string
+	exclude   []string
+	interval  time.Duration
+	holdFor   time.Duration
+}
+
+func runDev(args []string) error {
+	fs := flag.NewFlagSet("dev", flag.ContinueOnError)
+	proxyAddr := fs.String("addr", ":8000", "address the dev proxy listens on")
+	appAddr := fs.String("app-addr", "127.0.0.1:8001", "address passed to the app via $ADDR")
+	pkg := fs.String("build", ".", "package to build")
+	exts := fs.String("ext", ".go,.html,.tmpl,.gohtml,.tpl", "comma-separated file extensions to watch")
+	exclude := fs.String("exclude", ".git,vendor,node_modules,tmp,bin", "comma-separated directories to skip")
+	interval := fs.Duration("interval", 500*time.Millisecond, "polling interval")
+	hold := fs.Duration("hold", 30*time.Second, "how long the proxy holds requests while the app restarts")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cfg := devConfig{
+		proxyAddr: *proxyAddr,
+		appAddr:   *appAddr,
+		pkg:       *pkg,
+		exts:      splitList(*exts),
+		exclude:   splitList(*exclude),
+		interval:  *interval,
+		holdFor:   *hold,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return newDevServer(cfg).run(ctx)
+}
+
+// devServer rebuilds and restarts the app, fronted by a proxy that waits for
+// the app to become reachable instead of failing requests mid-restart.
+type devServer struct {
+	cfg    devConfig
+	binary string
+
+	mu    sync.Mutex
+	cmd   *exec.Cmd
+	ready chan struct{} // closed when the current app instance accepts connections
+}
+
+func newDevServer(cfg devConfig) *devServer {
+	bin := filepath.Join(os.TempDir(), fmt.Sprintf("zentrox-dev-%d", os.Getpid()))
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	return &devServer{cfg: cfg, binary: bin, ready: make(chan struct{})}
+}
+
+func (d *devServer) run(ctx context.Context) error {
+	target, err := url.Parse("http://" + d.cfg.appAddr)
+	if err != nil {
+		return err
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		http.Error(w, "zentrox dev: app unavailable: "+err.Error(), http.StatusBadGateway)
+	}
+
+	srv := &http.Server{
+		Addr: d.cfg.proxyAddr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !d.waitReady(r.Context()) {
+				http.Error(w, "zentrox dev: app is not running (see build output)", http.StatusServiceUnavailable)
+				return
+			}
+			proxy.ServeHTTP(w, r)
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("dev proxy: %v", err)
+		}
+	}()
+	log.Printf("dev proxy on %s -> app on %s", d.cfg.proxyAddr, d.cfg.appAddr)
+
+	d.rebuild()
+	snap := d.snapshot()
+	ticker := time.NewTicker(d.cfg.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			d.stopApp()
+			_ = os.Remove(d.binary)
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			return srv.Shutdown(shutdownCtx)
+		case <-ticker.C:
+			next := d.snapshot()
+			if changed := diffSnapshot(snap, next); changed != "" {
+				log.Printf("change detected: %s", changed)
+				snap = next
+				d.rebuild()
+			}
+		}
+	}
+}
+
+// rebuild compiles the app and swaps the running process. A failed build keeps
+// the previous instance running.
+func (d *devServer) rebuild() {
+	start := time.Now()
+	build := exec.Command("go", "build", "-o", d.binary, d.cfg.pkg)
+	build.Stdout, build.Stderr = os.Stdout, os.Stderr
+	if err := build.Run(); err != nil {
+		log.Printf("build failed: %v", err)
+		return
+	}
+	log.Printf("built in %s", time.Since(start).Round(time.Millisecond))
+
+	d.stopApp()
+
+	cmd := exec.Command(d.binary)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	cmd.Env = append(os.Environ(), "ADDR="+d.cfg.appAddr)
+	if err := cmd.Start(); err != nil {
+		log.Printf("start failed: %v", err)
+		return
+	}
+
+	ready := make(chan struct{})
+	d.mu.Lock()
+	d.cmd = cmd
+	d.ready = ready
+	d.mu.Unlock()
+
+	go func() {
+		deadline := time.Now().Add(d.cfg.holdFor)
+		for time.Now().Before(deadline) {
+			conn, err := net.DialTimeout("tcp", d.cfg.appAddr, 200*time.Millisecond)
+			if err == nil {
+				_ = conn.Close()
+				close(ready)
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		log.Printf("app did not listen on %s within %s (does it read $ADDR?)", d.cfg.appAddr, d.cfg.holdFor)
+	}()
+}
+
+// stopApp asks the running app to shut down gracefully, then kills it.
+func (d *devServer) stopApp() {
+	d.mu.Lock()
+	cmd := d.cmd
+	d.cmd = nil
+	d.ready = make(chan struct{})
+	d.mu.Unlock()
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	if runtime.GOOS == "windows" {
+		_ = cmd.Process.Kill()
+	} else {
+		_ = cmd.Process.Signal(os.Interrupt)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+		<-done
+	}
+}
+
+// waitReady blocks until the app accepts connections, the hold time elapses,
+// or the client goes away.
+func (d *devServer) waitReady(ctx context.Context) bool {
+	timer := time.NewTimer(d.cfg.holdFor)
+	defer timer.Stop()
+	for {
+		d.mu.Lock()
+		ready := d.ready
+		d.mu.Unlock()
+		select {
+		case <-ready:
+			// A restart may have replaced the channel meanwhile; re-check.
+			d.mu.Lock()
+			same := ready == d.ready
+			d.mu.Unlock()
+			if same {
+				return true
+			}
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// snapshot records modification times of watched files.
+func (d *devServer) snapshot() map[string]time.Time {
+	out := map[string]time.Time{}
+	_ = filepath.WalkDir(".", func(path string, e fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if e.IsDir() {
+			if path != "." && (strings.HasPrefix(e.Name(), ".") || contains(d.cfg.exclude, e.Name())) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !contains(d.cfg.exts, filepath.Ext(path)) {
+			return nil
+		}
+		if info, err := e.Info(); err == nil {
+			out[path] = info.ModTime()
+		}
+		return nil
+	})
+	return out
+}
+
+// diffSnapshot returns the first changed, added or removed path, or "".
+func diffSnapshot(prev, next map[string]time.Time) string {
+	for p, t := range next {
+		if old, ok := prev[p]; !ok || !old.Equal(t) {
+			return p
+		}
+	}
+	for p := range prev {
+		if _, ok := next[p]; !ok {
+			return p
+		}
+	}
+	return ""
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
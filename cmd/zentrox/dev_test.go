@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDevSnapshot(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"views", "vendor/x", ".git"} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"main.go", "views/index.html", "vendor/x/x.go", ".git/HEAD.go", "notes.txt"} {
+		if err := os.WriteFile(f, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := newDevServer(devConfig{exts: splitList(".go, .html"), exclude: []string{"vendor"}})
+	snap := d.snapshot()
+	if len(snap) != 2 {
+		t.Fatalf("expected main.go and views/index.html, got %v", snap)
+	}
+	if got := diffSnapshot(snap, d.snapshot()); got != "" {
+		t.Fatalf("no change expected, got %q", got)
+	}
+
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes("views/index.html", later, later); err != nil {
+		t.Fatal(err)
+	}
+	if got := diffSnapshot(snap, d.snapshot()); got != "views/index.html" {
+		t.Fatalf("expected template change, got %q", got)
+	}
+	if err := os.Remove("main.go"); err != nil {
+		t.Fatal(err)
+	}
+	next := d.snapshot()
+	delete(snap, "views/index.html")
+	delete(next, "views/index.html")
+	if got := diffSnapshot(snap, next); got != "main.go" {
+		t.Fatalf("expected removal, got %q", got)
+	}
+}
+
+func TestDevWaitReady(t *testing.T) {
+	d := newDevServer(devConfig{holdFor: 50 * time.Millisecond})
+	if d.waitReady(context.Background()) {
+		t.Fatal("should time out while the app is not running")
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		d.mu.Lock()
+		close(d.ready)
+		d.mu.Unlock()
+	}()
+	d.cfg.holdFor = time.Second
+	if !d.waitReady(context.Background()) {
+		t.Fatal("should proceed once the app is ready")
+	}
+}
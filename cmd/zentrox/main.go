@@ -0,0 +1,67 @@
+// Command zentrox is the zentrox project tool.
+//
+// Usage:
+//
+//	zentrox new <project> [-module path]
+//	zentrox gen handler <name>
+//	zentrox gen middleware <name>
+//	zentrox gen module <name>
+//	zentrox dev [-addr :8000] [-app-addr 127.0.0.1:8001]
+//	zentrox routes [-format json|markdown|postman|openapi|go] [-pkg client] [-o file]
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// command is a CLI subcommand.
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "new", usage: "new <project> [-module path] [-force]   create a new project", run: runNew},
+		{name: "gen", usage: "gen handler|middleware|module <name>    generate code in the current project", run: runGen},
+		{name: "dev", usage: "dev [-addr :8000] [-app-addr host:port]   rebuild and restart on change", run: runDev},
+		{name: "routes", usage: "routes [-format json|markdown|postman|openapi|go]  export routes or a Go client", run: runRoutes},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	if name == "help" || name == "-h" || name == "--help" {
+		usage()
+		return
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			if err := cmd.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "zentrox %s: %v\n", name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "zentrox: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: zentrox <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %s\n", cmd.usage)
+	}
+}
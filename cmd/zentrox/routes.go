@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// envExportRoutes mirrors zentrox.EnvExportRoutes: the app writes its route
+// table to stdout and exits when it reaches Run/Start with this set, before
+// any scheduled jobs or server hooks start.
+const envExportRoutes = "ZENTROX_EXPORT_ROUTES"
+
+func runRoutes(args []string) error {
+	fs := flag.NewFlagSet("routes", flag.ContinueOnError)
+	format := fs.String("format", "json", "output format: json, markdown, postman, openapi, table, grouped or go (typed client)")
+	clientPkg := fs.String("pkg", "client", "package name of the generated client (-format go)")
+	out := fs.String("o", "", "write to file instead of stdout")
+	pkg := fs.String("build", ".", "package to run")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	switch *format {
+	case "json", "markdown", "md", "postman", "openapi", "table", "grouped":
+	case "go":
+		*format = "go:" + *clientPkg
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	cmd := exec.Command("go", "run", *pkg)
+	cmd.Env = append(os.Environ(), envExportRoutes+"="+*format)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run %s: %w", *pkg, err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// tmplData is passed to every template.
+type tmplData struct {
+	Module  string // Go module path of the project
+	Project string // project directory name
+	Name    string // raw name given on the command line
+	Type    string // exported identifier, e.g. "UserProfile"
+	Var     string // unexported identifier, e.g. "userProfile"
+	Pkg     string // package name, e.g. "userprofile"
+	File    string // file base name, e.g. "user_profile"
+	Route   string // URL segment, e.g. "user-profile"
+}
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+// file is a generated file: path relative to the project root and template name.
+type file struct {
+	path string
+	tmpl string
+}
+
+func runNew(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ContinueOnError)
+	module := fs.String("module", "", "Go module path (default: project name)")
+	force := fs.Bool("force", false, "overwrite existing files")
+	name, err := parseWithName(fs, args)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Clean(name)
+	project := filepath.Base(dir)
+	if *module == "" {
+		*module = project
+	}
+	data := newData(*module, project, "ping")
+
+	files := []file{
+		{"go.mod", "go.mod.tmpl"},
+		{".gitignore", "gitignore.tmpl"},
+		{"README.md", "readme.md.tmpl"},
+		{"main.go", "main.go.tmpl"},
+		{"internal/config/config.go", "config.go.tmpl"},
+		{"internal/handler/ping.go", "ping.go.tmpl"},
+		{"internal/handler/ping_test.go", "ping_test.go.tmpl"},
+	}
+	if err := writeFiles(dir, files, data, *force); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created %s\n\nNext steps:\n  cd %s\n  go mod tidy\n  go run .\n", dir, dir)
+	return nil
+}
+
+func runGen(args []string) error {
+	if len(args) == 0 {
+		return errors.New("missing kind (handler, middleware or module)")
+	}
+	kind := args[0]
+
+	fs := flag.NewFlagSet("gen "+kind, flag.ContinueOnError)
+	force := fs.Bool("force", false, "overwrite existing files")
+	name, err := parseWithName(fs, args[1:])
+	if err != nil {
+		return err
+	}
+
+	module, err := readModulePath("go.mod")
+	if err != nil {
+		return fmt.Errorf("run inside a project root: %w", err)
+	}
+	data := newData(module, filepath.Base(mustGetwd()), name)
+
+	var files []file
+	switch kind {
+	case "handler":
+		files = []file{
+			{"internal/handler/" + data.File + ".go", "handler.go.tmpl"},
+			{"internal/handler/" + data.File + "_test.go", "handler_test.go.tmpl"},
+		}
+	case "middleware":
+		files = []file{
+			{"internal/middleware/" + data.File + ".go", "middleware.go.tmpl"},
+			{"internal/middleware/" + data.File + "_test.go", "middleware_test.go.tmpl"},
+		}
+	case "module":
+		files = []file{
+			{"internal/" + data.Pkg + "/model.go", "module_model.go.tmpl"},
+			{"internal/" + data.Pkg + "/service.go", "module_service.go.tmpl"},
+			{"internal/" + data.Pkg + "/handler.go", "module_handler.go.tmpl"},
+			{"internal/" + data.Pkg + "/handler_test.go", "module_handler_test.go.tmpl"},
+		}
+	default:
+		return fmt.Errorf("unknown kind %q (want handler, middleware or module)", kind)
+	}
+
+	if err := writeFiles(".", files, data, *force); err != nil {
+		return err
+	}
+	fmt.Print(mustRender("hint_"+kind+".tmpl", data))
+	return nil
+}
+
+// parseWithName accepts the positional name before or after flags.
+func parseWithName(fs *flag.FlagSet, args []string) (string, error) {
+	var name string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if name == "" && fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	if name == "" {
+		return "", errors.New("missing name")
+	}
+	return name, nil
+}
+
+func newData(module, project, name string) tmplData {
+	words := splitWords(name)
+	var typ strings.Builder
+	for _, w := range words {
+		typ.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	t := typ.String()
+	return tmplData{
+		Module:  module,
+		Project: project,
+		Name:    name,
+		Type:    t,
+		Var:     strings.ToLower(t[:1]) + t[1:],
+		Pkg:     strings.Join(words, ""),
+		File:    strings.Join(words, "_"),
+		Route:   strings.Join(words, "-"),
+	}
+}
+
+// splitWords splits "user-profile", "user_profile" and "UserProfile" into lower-case words.
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = cur[:0]
+		}
+	}
+	for i, r := range s {
+		switch {
+		case r == '-' || r == '_' || r == ' ' || r == '.' || r == '/':
+			flush()
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			// drop
+		case unicode.IsUpper(r) && i > 0 && len(cur) > 0 && !unicode.IsUpper(cur[len(cur)-1]):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+	if len(words) == 0 {
+		words = []string{"app"}
+	}
+	return words
+}
+
+func writeFiles(root string, files []file, data tmplData, force bool) error {
+	if !force {
+		for _, f := range files {
+			p := filepath.Join(root, filepath.FromSlash(f.path))
+			if _, err := os.Stat(p); err == nil {
+				return fmt.Errorf("%s already exists (use -force to overwrite)", p)
+			}
+		}
+	}
+	for _, f := range files {
+		p := filepath.Join(root, filepath.FromSlash(f.path))
+		out := []byte(mustRender(f.tmpl, data))
+		if strings.HasSuffix(p, ".go") {
+			formatted, err := format.Source(out)
+			if err != nil {
+				return fmt.Errorf("format %s: %w", p, err)
+			}
+			out = formatted
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(p, out, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("  create %s\n", p)
+	}
+	return nil
+}
+
+func mustRender(name string, data tmplData) string {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		panic(err)
+	}
+	return buf.String()
+}
+
+func readModulePath(gomod string) (string, error) {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`), nil
+		}
+	}
+	return "", errors.New("no module directive in " + gomod)
+}
+
+func mustGetwd() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "app"
+	}
+	return wd
+}
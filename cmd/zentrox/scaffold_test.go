@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewData(t *testing.T) {
+	for in, want := range map[string]tmplData{
+		"user-profile": {Type: "UserProfile", Var: "userProfile", Pkg: "userprofile", File: "user_profile", Route: "user-profile"},
+		"UserProfile":  {Type: "UserProfile", Var: "userProfile", Pkg: "userprofile", File: "user_profile", Route: "user-profile"},
+		"orders":       {Type: "Orders", Var: "orders", Pkg: "orders", File: "orders", Route: "orders"},
+	} {
+		got := newData("m", "p", in)
+		if got.Type != want.Type || got.Var != want.Var || got.Pkg != want.Pkg || got.File != want.File || got.Route != want.Route {
+			t.Fatalf("%s: unexpected %+v", in, got)
+		}
+	}
+}
+
+func TestRunNew(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "svc")
+	if err := runNew([]string{dir, "-module", "example.com/svc"}); err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatalf("read main.go: %v", err)
+	}
+	if !strings.Contains(string(b), `"example.com/svc/internal/handler"`) {
+		t.Fatalf("main.go should import the project handler package:\n%s", b)
+	}
+	if err := runNew([]string{dir}); err == nil {
+		t.Fatal("second run should refuse to overwrite")
+	}
+}
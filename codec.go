@@ -0,0 +1,210 @@
+package zentrox
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+)
+
+// Codec marshals response bodies and unmarshals request bodies for one media
+// type. See App.SetCodec.
+type Codec struct {
+	Marshal   func(v any) ([]byte, error)
+	Unmarshal func(data []byte, v any) error
+	// MaxBodyBytes bounds the request body read for Unmarshal (default
+	// 10 MiB); larger bodies fail with *http.MaxBytesError.
+	MaxBodyBytes int64
+}
+
+// defaultCodecMaxBody is the MaxBodyBytes used when a Codec sets none.
+const defaultCodecMaxBody = 10 << 20
+
+// builtinCodecs serve these media types unless App.SetCodec replaces them.
+var builtinCodecs = map[string]Codec{
+	ContentTypeYAML:     {Marshal: yaml.Marshal, Unmarshal: yaml.Unmarshal},
+	ContentTypeTOML:     {Marshal: toml.Marshal, Unmarshal: toml.Unmarshal},
+	ContentTypeProtobuf: {Marshal: marshalProto, Unmarshal: unmarshalProto},
+	ContentTypeMsgPack:  {Marshal: msgpack.Marshal, Unmarshal: msgpack.Unmarshal},
+}
+
+// errNotProto is returned by the protobuf codec for other values.
+var errNotProto = errors.New("zentrox: protobuf codec needs a proto.Message")
+
+func marshalProto(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w, got %T", errNotProto, v)
+	}
+	return proto.Marshal(m)
+}
+
+func unmarshalProto(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w, got %T", errNotProto, v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// codecAliases maps other names in use for a media type to the canonical one.
+var codecAliases = map[string]string{
+	"application/x-yaml":      ContentTypeYAML,
+	"text/yaml":               ContentTypeYAML,
+	"text/x-yaml":             ContentTypeYAML,
+	"application/x-toml":      ContentTypeTOML,
+	"application/protobuf":    ContentTypeProtobuf,
+	"application/x-msgpack":   ContentTypeMsgPack,
+	"application/vnd.msgpack": ContentTypeMsgPack,
+}
+
+// ErrNoCodec is returned when no codec is registered for a media type.
+var ErrNoCodec = errors.New("zentrox: no codec registered")
+
+// SetCodec makes a media type available to Render, the matching Bind*Into
+// methods, BindInto and Negotiate for this App, or replaces a built-in
+// codec. YAML (gopkg.in/yaml.v3), TOML (github.com/BurntSushi/toml),
+// Protocol Buffers (google.golang.org/protobuf, for proto.Message values)
+// and MessagePack (github.com/vmihailenco/msgpack/v5) are built in; other
+// formats are added the same way:
+//
+//	app.SetCodec("application/cbor", zentrox.Codec{
+//		Marshal:   cbor.Marshal,
+//		Unmarshal: cbor.Unmarshal,
+//	})
+//
+// Aliases such as application/x-yaml, text/yaml and application/x-msgpack
+// resolve to the canonical type. Call it before serving requests.
+func (a *App) SetCodec(mediaType string, codec Codec) *App {
+	if a.codecs == nil {
+		a.codecs = map[string]Codec{}
+	}
+	a.codecs[canonicalMediaType(mediaType)] = codec
+	return a
+}
+
+// lookupCodec returns the App's codec for mediaType, or the built-in one.
+func (c *Context) lookupCodec(mediaType string) (Codec, bool) {
+	mt := canonicalMediaType(mediaType)
+	if c.app != nil {
+		if codec, ok := c.app.codecs[mt]; ok {
+			return codec, true
+		}
+	}
+	codec, ok := builtinCodecs[mt]
+	return codec, ok
+}
+
+// canonicalMediaType strips parameters and resolves aliases.
+func canonicalMediaType(v string) string {
+	if mt, _, err := mime.ParseMediaType(v); err == nil {
+		v = mt
+	} else {
+		v = strings.ToLower(strings.TrimSpace(v))
+	}
+	if a, ok := codecAliases[v]; ok {
+		return a
+	}
+	return v
+}
+
+// Render marshals v with the codec for contentType and sends it.
+// Without a codec, or when marshaling fails, the response is a 500 and the
+// error is recorded on the context.
+func (c *Context) Render(code int, contentType string, v any) {
+	codec, ok := c.lookupCodec(contentType)
+	var b []byte
+	err := fmt.Errorf("%w for %s", ErrNoCodec, contentType)
+	if ok && codec.Marshal != nil {
+		b, err = codec.Marshal(v)
+	}
+	if err != nil {
+		c.err = err
+		c.setContentType(textContentType)
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = io.WriteString(c.Writer, MsgEncodeFailed)
+		return
+	}
+	c.Writer.Header().Set(HeaderContentType, contentType)
+	c.Writer.WriteHeader(code)
+	_, _ = c.Writer.Write(b)
+}
+
+// YAML sends v as YAML.
+func (c *Context) YAML(code int, v any) {
+	c.Render(code, ContentTypeYAML, v)
+}
+
+// TOML sends v as TOML.
+func (c *Context) TOML(code int, v any) {
+	c.Render(code, ContentTypeTOML, v)
+}
+
+// ProtoBuf sends msg, a proto.Message, as Protocol Buffers.
+func (c *Context) ProtoBuf(code int, msg any) {
+	c.Render(code, ContentTypeProtobuf, msg)
+}
+
+// MsgPack sends v as MessagePack.
+func (c *Context) MsgPack(code int, v any) {
+	c.Render(code, ContentTypeMsgPack, v)
+}
+
+// BindProtoInto decodes a Protocol Buffers body into msg, a proto.Message,
+// and validates it.
+func (c *Context) BindProtoInto(msg any) error {
+	return c.BindCodecInto(ContentTypeProtobuf, msg)
+}
+
+// BindMsgPackInto decodes a MessagePack body into dst and validates tags.
+func (c *Context) BindMsgPackInto(dst any) error {
+	return c.BindCodecInto(ContentTypeMsgPack, dst)
+}
+
+// BindYAMLInto binds a YAML body into dst and validates tags.
+func (c *Context) BindYAMLInto(dst any) error {
+	return c.BindCodecInto(ContentTypeYAML, dst)
+}
+
+// BindTOMLInto binds a TOML body into dst and validates tags.
+func (c *Context) BindTOMLInto(dst any) error {
+	return c.BindCodecInto(ContentTypeTOML, dst)
+}
+
+// BindCodecInto decodes the body with the codec for mediaType and
+// validates dst.
+func (c *Context) BindCodecInto(mediaType string, dst any) error {
+	if err := c.decodeCodec(mediaType, dst); err != nil {
+		return err
+	}
+	return c.Validate(dst)
+}
+
+// decodeCodec decodes the body, at most codec.MaxBodyBytes of it, with the
+// codec for mediaType.
+func (c *Context) decodeCodec(mediaType string, dst any) error {
+	codec, ok := c.lookupCodec(mediaType)
+	if !ok || codec.Unmarshal == nil {
+		return fmt.Errorf("%w for %s", ErrNoCodec, mediaType)
+	}
+	if c.Request.Body == nil {
+		return errors.New("empty body")
+	}
+	defer c.Request.Body.Close()
+	limit := codec.MaxBodyBytes
+	if limit <= 0 {
+		limit = defaultCodecMaxBody
+	}
+	b, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, limit))
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(b, dst)
+}
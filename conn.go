@@ -0,0 +1,209 @@
+package zentrox
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ConnStats is a snapshot of connection counters for servers built by Run,
+// Start or StartTLS. Counters are cumulative across servers of an App.
+type ConnStats struct {
+	Accepted uint64 `json:"accepted"`
+	Active   int64  `json:"active"`
+	Idle     int64  `json:"idle"`
+	Hijacked uint64 `json:"hijacked"`
+	Rejected uint64 `json:"rejected"`
+	Evicted  uint64 `json:"evicted"`
+}
+
+// connTracker feeds ConnStats from http.Server.ConnState and enforces
+// ServerConfig.MaxIdleConns.
+type connTracker struct {
+	accepted atomic.Uint64
+	active   atomic.Int64
+	hijacked atomic.Uint64
+	rejected atomic.Uint64
+	evicted  atomic.Uint64
+
+	mu   sync.Mutex
+	idle map[net.Conn]time.Time
+}
+
+// ConnStats returns the current connection counters.
+func (a *App) ConnStats() ConnStats {
+	t := &a.conns
+	t.mu.Lock()
+	idle := int64(len(t.idle))
+	t.mu.Unlock()
+	return ConnStats{
+		Accepted: t.accepted.Load(),
+		Active:   t.active.Load(),
+		Idle:     idle,
+		Hijacked: t.hijacked.Load(),
+		Rejected: t.rejected.Load(),
+		Evicted:  t.evicted.Load(),
+	}
+}
+
+// SetDraining makes every response carry "Connection: close" (a GOAWAY on
+// HTTP/2) so clients move to other instances before Shutdown. Flip it when
+// the instance is taken out of the load balancer.
+func (a *App) SetDraining(v bool) *App {
+	a.draining.Store(v)
+	return a
+}
+
+// Draining reports whether SetDraining(true) is in effect.
+func (a *App) Draining() bool {
+	return a.draining.Load()
+}
+
+// connState returns the http.Server.ConnState hook for the tracker.
+// maxIdle > 0 closes the longest-idle keep-alive connection once more than
+// maxIdle are idle.
+func (t *connTracker) connState(maxIdle int, next func(net.Conn, http.ConnState)) func(net.Conn, http.ConnState) {
+	return func(c net.Conn, st http.ConnState) {
+		switch st {
+		case http.StateNew:
+			t.accepted.Add(1)
+			t.active.Add(1)
+		case http.StateActive:
+			t.setIdle(c, false)
+		case http.StateIdle:
+			t.setIdle(c, true)
+			if maxIdle > 0 {
+				t.evictIdle(maxIdle)
+			}
+		case http.StateHijacked:
+			t.setIdle(c, false)
+			t.hijacked.Add(1)
+			t.active.Add(-1)
+		case http.StateClosed:
+			t.setIdle(c, false)
+			t.active.Add(-1)
+		}
+		if next != nil {
+			next(c, st)
+		}
+	}
+}
+
+func (t *connTracker) setIdle(c net.Conn, idle bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !idle {
+		delete(t.idle, c)
+		return
+	}
+	if t.idle == nil {
+		t.idle = make(map[net.Conn]time.Time)
+	}
+	t.idle[c] = time.Now()
+}
+
+func (t *connTracker) evictIdle(maxIdle int) {
+	t.mu.Lock()
+	var victims []net.Conn
+	for len(t.idle) > maxIdle {
+		var oldest net.Conn
+		var since time.Time
+		for c, ts := range t.idle {
+			if oldest == nil || ts.Before(since) {
+				oldest, since = c, ts
+			}
+		}
+		delete(t.idle, oldest)
+		victims = append(victims, oldest)
+	}
+	t.mu.Unlock()
+	for _, c := range victims {
+		t.evicted.Add(1)
+		_ = c.Close()
+	}
+}
+
+// limitListener caps concurrent connections in total (blocking Accept, like
+// x/net/netutil.LimitListener) and per remote IP (closing extra connections).
+type limitListener struct {
+	net.Listener
+	sem     chan struct{}
+	perHost int
+	t       *connTracker
+
+	mu    sync.Mutex
+	hosts map[string]int
+}
+
+func newLimitListener(ln net.Listener, maxConns, perHost int, t *connTracker) net.Listener {
+	l := &limitListener{Listener: ln, perHost: perHost, t: t}
+	if maxConns > 0 {
+		l.sem = make(chan struct{}, maxConns)
+	}
+	if perHost > 0 {
+		l.hosts = make(map[string]int)
+	}
+	return l
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		if l.sem != nil {
+			l.sem <- struct{}{}
+		}
+		c, err := l.Listener.Accept()
+		if err != nil {
+			l.releaseSlot()
+			return nil, err
+		}
+		host := ""
+		if l.perHost > 0 {
+			host, _, _ = net.SplitHostPort(c.RemoteAddr().String())
+			l.mu.Lock()
+			over := l.hosts[host] >= l.perHost
+			if !over {
+				l.hosts[host]++
+			}
+			l.mu.Unlock()
+			if over {
+				l.t.rejected.Add(1)
+				_ = c.Close()
+				l.releaseSlot()
+				continue
+			}
+		}
+		return &limitConn{Conn: c, l: l, host: host}, nil
+	}
+}
+
+func (l *limitListener) releaseSlot() {
+	if l.sem != nil {
+		<-l.sem
+	}
+}
+
+func (l *limitListener) release(host string) {
+	if l.perHost > 0 {
+		l.mu.Lock()
+		if l.hosts[host]--; l.hosts[host] <= 0 {
+			delete(l.hosts, host)
+		}
+		l.mu.Unlock()
+	}
+	l.releaseSlot()
+}
+
+type limitConn struct {
+	net.Conn
+	l    *limitListener
+	host string
+	once sync.Once
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(func() { c.l.release(c.host) })
+	return err
+}
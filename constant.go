@@ -7,6 +7,8 @@ const (
 	TraceParent = "traceparent"
 	TraceID     = "trace_id"
 	SpanID      = "span_id"
+	CSRFToken   = "csrf_token"
+	SessionKey  = "session"
 )
 
 const (
@@ -27,9 +29,22 @@ const (
 	HeaderAcceptEncoding      = "Accept-Encoding"
 	HeaderXForwardedFor       = "X-Forwarded-For"
 	HeaderXRealIP             = "X-Real-IP"
+	HeaderForwarded           = "Forwarded"
 	HeaderXContentTypeOptions = "X-Content-Type-Options"
 	HeaderXFrameOptions       = "X-Frame-Options"
 	HeaderReferrerPolicy      = "Referrer-Policy"
+	HeaderLink                = "Link"
+	HeaderXTotalCount         = "X-Total-Count"
+	HeaderSetCookie           = "Set-Cookie"
+	HeaderCookie              = "Cookie"
+	HeaderUpgrade             = "Upgrade"
+	HeaderRetryAfter          = "Retry-After"
+	HeaderXNonce              = "X-Nonce"
+	HeaderXTimestamp          = "X-Timestamp"
+	HeaderXSignature          = "X-Signature"
+	HeaderWWWAuthenticate     = "WWW-Authenticate"
+	HeaderXHTTPMethodOverride = "X-HTTP-Method-Override"
+	HeaderXCSRFToken          = "X-CSRF-Token"
 )
 
 const (
@@ -39,6 +54,8 @@ const (
 	HeaderAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
 	HeaderAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
 	HeaderAccessControlMaxAge           = "Access-Control-Max-Age"
+	HeaderAccessControlRequestMethod    = "Access-Control-Request-Method"
+	HeaderAccessControlRequestHeaders   = "Access-Control-Request-Headers"
 	HeaderVary                          = "Vary"
 )
 
@@ -54,15 +71,29 @@ const (
 	ContentTypeFormURLEncoded  = "application/x-www-form-urlencoded"
 	ContentTypeMultipartForm   = "multipart/form-data"
 	ContentTypeJSON            = "application/json"
+	ContentTypeJSONAPI         = "application/vnd.api+json"
+	ContentTypeYAML            = "application/yaml"
+	ContentTypeTOML            = "application/toml"
+	ContentTypeProtobuf        = "application/x-protobuf"
+	ContentTypeMsgPack         = "application/msgpack"
+	ContentTypeJavaScriptUTF8  = "application/javascript; charset=utf-8"
 )
 
+// SecureJSONPrefix is the default prefix written by Context.SecureJSON.
+const SecureJSONPrefix = "while(1);"
+
 const (
 	BearerPrefix = "Bearer "
 )
 
+// ProblemTypeBlank is the RFC 9457 problem type for problems that need no
+// more than their status code's meaning.
+const ProblemTypeBlank = "about:blank"
+
 const (
-	CacheControlNoCache = "no-cache"
-	ConnectionKeepAlive = "keep-alive"
+	CacheControlNoCache   = "no-cache"
+	CacheControlImmutable = "public, max-age=31536000, immutable"
+	ConnectionKeepAlive   = "keep-alive"
 )
 
 const (
@@ -71,16 +102,43 @@ const (
 	MsgInvalidToken        = "invalid token"
 	MsgUnsupportedAlg      = "unsupported algorithm"
 	MsgInvalidSignature    = "invalid signature"
+	MsgTokenExpired        = "token expired"
+	MsgMissingExpiry       = "token has no expiry"
+	MsgTokenNotYetValid    = "token not yet valid"
+	MsgTokenIssuedAt       = "token issued in the future"
+	MsgInvalidIssuer       = "invalid issuer"
+	MsgInvalidAudience     = "invalid audience"
+	MsgTokenRevoked        = "token revoked"
+	MsgRevocationFailed    = "revocation check failed"
+	MsgUnknownKeyID        = "unknown key id"
+	MsgKeySetFailed        = "key set unavailable"
 	MsgTooManyRequests     = "too many requests"
 	MsgRequestTimeout      = "request timeout"
 	MsgNotFound            = "not found"
 	MsgForbidden           = "forbidden"
+	MsgUnauthorized        = "unauthorized"
+	MsgTwoFactorRequired   = "two-factor authentication required"
+	MsgLinkExpired         = "link expired"
+	MsgMissingNonce        = "missing nonce or timestamp"
+	MsgStaleRequest        = "stale request"
+	MsgReplayedRequest     = "replayed request"
+	MsgChallengeRequired   = "challenge required"
 	MsgMethodNotAllowed    = "method not allowed"
 	MsgURITooLong          = "uri too long"
 	MsgPayloadTooLarge     = "payload too large"
 	MsgServerBusy          = "server busy"
+	MsgBadGateway          = "bad gateway"
+	MsgGatewayTimeout      = "gateway timeout"
+	MsgServiceUnavailable  = "service unavailable"
 	MsgStatError           = "stat error"
 	MsgOpenError           = "open error"
 	MsgFileNotFound        = "file not found"
 	MsgJSONEncodeFailed    = "json encode failed"
+	MsgXMLEncodeFailed     = "xml encode failed"
+	MsgEncodeFailed        = "encode failed"
+	MsgInvalidCSRFToken    = "invalid csrf token"
+	MsgInvalidRequest      = "request does not match the api spec"
+	MsgInvalidResponse     = "response does not match the api spec"
+	MsgValidationFailed    = "validation failed"
+	MsgInvalidRequestBody  = "invalid request body"
 )
@@ -3,17 +3,16 @@ package zentrox
 import (
 	"encoding/json"
 	"encoding/xml"
-	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/textproto"
+	"net/url"
 	"os"
 	"path/filepath"
 	"reflect"
-	"regexp"
 	"sort"
 
 	"strconv"
@@ -28,11 +27,26 @@ import (
 type Context struct {
 	Writer  http.ResponseWriter
 	Request *http.Request
-	params  map[string]string
+	app     *App
+	entry   *routeEntry
+	params  []param // path params in route order; reused across requests
 	index   int
 	stack   []Handler
-	store   map[string]any
-	realIP  func(*http.Request) string
+	store   map[string]any // allocated on first Set
+
+	// query caches Request.URL.Query() for rawQuery.
+	query    url.Values
+	rawQuery string
+
+	// rec wraps Writer for the lifetime of the request; kept inline to avoid
+	// a per-request allocation.
+	rec respRecorder
+	// head discards the body when a GET route answers HEAD; inline for the
+	// same reason.
+	head headWriter
+
+	// logAttrs are added with LogAttr and emitted by request loggers.
+	logAttrs []slog.Attr
 
 	aborted bool
 	err     error
@@ -60,13 +74,23 @@ func (c *Context) Aborted() bool {
 	return c.aborted
 }
 
-// Fail sends a standardized HTTPError JSON and stops the chain.
+// Fail sends a standardized HTTPError JSON, or problem+json when
+// App.SetProblemJSON is on, and stops the chain.
 func (c *Context) Fail(code int, message string, detail ...any) {
 	c.err = NewHTTPError(code, message, detail...)
-	c.JSON(code, c.err)
+	c.sendError(c.err.(HTTPError))
 	c.Abort()
 }
 
+// sendError writes e in the App's error format.
+func (c *Context) sendError(e HTTPError) {
+	if c.app != nil && c.app.problemJSON {
+		c.Problem(e.ProblemDetails(c.Request.URL.Path))
+		return
+	}
+	c.JSON(e.Code, e)
+}
+
 // Error returns the last recorded error, if any.
 func (c *Context) Error() error {
 	return c.err
@@ -82,14 +106,143 @@ func (c *Context) ClearError() {
 	c.err = nil
 }
 
+// param is a matched path parameter.
+type param struct {
+	key, value string
+}
+
 // Param returns a path parameter value.
 func (c *Context) Param(key string) string {
-	return c.params[key]
+	v, _ := c.lookupParam(key)
+	return v
+}
+
+func (c *Context) lookupParam(key string) (string, bool) {
+	for i := range c.params {
+		if c.params[i].key == key {
+			return c.params[i].value, true
+		}
+	}
+	return "", false
+}
+
+// Params returns a copy of all path parameters.
+func (c *Context) Params() map[string]string {
+	out := make(map[string]string, len(c.params))
+	for _, p := range c.params {
+		out[p.key] = p.value
+	}
+	return out
+}
+
+// App returns the App serving the request.
+func (c *Context) App() *App {
+	return c.app
+}
+
+// Logger returns the app logger (see App.SetLogger) with request attributes
+// attached: request_id, method, route, and user and tenant when upstream
+// middleware provided them. user is the "sub" claim of the JWT claims under
+// "user" or a string stored under "user_id"; tenant is a string stored under
+// "tenant" or the "tenant" claim.
+func (c *Context) Logger() *slog.Logger {
+	base := slog.Default()
+	if c.app != nil {
+		base = c.app.Logger()
+	}
+	attrs := make([]any, 0, 10)
+	if id := c.RequestID(); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if c.Request != nil {
+		attrs = append(attrs, slog.String("method", c.Request.Method))
+	}
+	if route := c.RoutePath(); route != "" {
+		attrs = append(attrs, slog.String("route", route))
+	}
+	claims, _ := Get[map[string]any](c, "user")
+	user := c.GetString("user_id")
+	if sub, _ := claims["sub"].(string); user == "" && sub != "" {
+		user = sub
+	}
+	if user != "" {
+		attrs = append(attrs, slog.String("user", user))
+	}
+	tenant := c.GetString("tenant")
+	if t, _ := claims["tenant"].(string); tenant == "" && t != "" {
+		tenant = t
+	}
+	if tenant != "" {
+		attrs = append(attrs, slog.String("tenant", tenant))
+	}
+	for _, a := range c.logAttrs {
+		attrs = append(attrs, a)
+	}
+	return base.With(attrs...)
+}
+
+// LogAttr attaches a field to this request's log output: the access log
+// written by middleware.StructuredLogger and loggers later obtained from
+// c.Logger().
+func (c *Context) LogAttr(key string, val any) {
+	c.logAttrs = append(c.logAttrs, slog.Any(key, val))
+}
+
+// LogAttrs returns the fields added with LogAttr. The slice is reused after
+// the request; copy it to keep it.
+func (c *Context) LogAttrs() []slog.Attr {
+	return c.logAttrs
+}
+
+// RoutePath returns the matched route template (e.g. "/users/:id"), or ""
+// when no route matched.
+func (c *Context) RoutePath() string {
+	if c.entry == nil {
+		return ""
+	}
+	return c.entry.pattern
+}
+
+// FullPath is RoutePath, under the name used by other routers. Use it for
+// metric labels, span names and rate-limit keys instead of the raw path.
+func (c *Context) FullPath() string {
+	return c.RoutePath()
+}
+
+// RouteTimeout returns the timeout declared for the matched route with
+// Route.Timeout or Scope.Timeout, or 0 when none is set.
+func (c *Context) RouteTimeout() time.Duration {
+	if c.entry == nil {
+		return 0
+	}
+	return c.entry.timeout
+}
+
+// RouteMeta returns the annotations attached with Route.Meta, or nil when
+// there are none. The map is shared by all requests; do not modify it.
+//
+//	if audit, _ := c.RouteMeta()["audit"].(bool); audit { ... }
+func (c *Context) RouteMeta() map[string]any {
+	if c.entry == nil {
+		return nil
+	}
+	return c.entry.meta
 }
 
 // Query returns a query parameter value.
 func (c *Context) Query(key string) string {
-	return c.Request.URL.Query().Get(key)
+	return c.QueryValues().Get(key)
+}
+
+// QueryValues returns the parsed query string. It is parsed on first use and
+// cached until Request.URL.RawQuery changes; treat the result as read-only.
+func (c *Context) QueryValues() url.Values {
+	raw := c.Request.URL.RawQuery
+	if c.query == nil || raw != c.rawQuery {
+		c.query, _ = url.ParseQuery(raw)
+		c.rawQuery = raw
+	}
+	return c.query
 }
 
 // SetHeader sets a response header.
@@ -103,6 +256,9 @@ func (c *Context) GetHeader(key string) string {
 
 // Set stores an arbitrary value for the lifetime of the request.
 func (c *Context) Set(key string, v any) {
+	if c.store == nil {
+		c.store = make(map[string]any)
+	}
 	c.store[key] = v
 }
 
@@ -112,21 +268,125 @@ func (c *Context) Get(key string) (any, bool) {
 	return v, ok
 }
 
+// MustGet returns the value stored under key and panics when it is missing.
+// Use it for values a required upstream middleware always sets.
+func (c *Context) MustGet(key string) any {
+	v, ok := c.store[key]
+	if !ok {
+		panic(fmt.Sprintf("zentrox: context key %q not set (is the middleware that sets it installed?)", key))
+	}
+	return v
+}
+
+// GetString returns the string stored under key, or "" when it is missing
+// or not a string.
+func (c *Context) GetString(key string) string {
+	v, _ := Get[string](c, key)
+	return v
+}
+
+// GetInt returns the int stored under key, or 0.
+func (c *Context) GetInt(key string) int {
+	v, _ := Get[int](c, key)
+	return v
+}
+
+// GetBool returns the bool stored under key, or false.
+func (c *Context) GetBool(key string) bool {
+	v, _ := Get[bool](c, key)
+	return v
+}
+
+// Keys returns the keys set on the context, sorted.
+func (c *Context) Keys() []string {
+	keys := make([]string, 0, len(c.store))
+	for k := range c.store {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get retrieves the value stored under key as a T. It reports false when the
+// key is missing or holds a value of another type, instead of panicking like
+// a bare type assertion.
+func Get[T any](c *Context, key string) (T, bool) {
+	v, ok := c.store[key].(T)
+	return v, ok
+}
+
+// CtxValue is a typed context key. Declare one per value shared between
+// middleware and handlers:
+//
+//	var CurrentUser = zentrox.NewCtxValue[*User]("user")
+//
+//	CurrentUser.Set(c, u)
+//	u, ok := CurrentUser.Get(c)
+type CtxValue[T any] struct {
+	key string
+}
+
+// NewCtxValue returns a typed key stored under key, so it interoperates with
+// c.Set and c.Get.
+func NewCtxValue[T any](key string) CtxValue[T] {
+	return CtxValue[T]{key: key}
+}
+
+// Key returns the underlying string key.
+func (v CtxValue[T]) Key() string { return v.key }
+
+// Set stores val for the lifetime of the request.
+func (v CtxValue[T]) Set(c *Context, val T) { c.Set(v.key, val) }
+
+// Get returns the stored value, or the zero T and false.
+func (v CtxValue[T]) Get(c *Context) (T, bool) { return Get[T](c, v.key) }
+
+// Validate checks dst with the App's validator (see App.SetValidator).
+// Failed rules are reported as validation.Errors by the default engine.
+func (c *Context) Validate(dst any) error {
+	if c.app != nil && c.app.validator != nil {
+		return c.app.validator.ValidateStruct(dst)
+	}
+	return validation.ValidateStruct(dst)
+}
+
 // Binding & Validation
-// BindInto auto-detects the binder (JSON/Form/Query), binds into dst, then validates tags.
+// BindInto auto-detects the binder (JSON/XML/Form/Query, or a codec from
+// App.SetCodec) from the Content-Type, binds into dst, then validates tags.
 func (c *Context) BindInto(dst any) error {
-	if err := binding.Bind(c.Request, dst); err != nil {
+	if err := c.bindBody(dst); err != nil {
 		return err
 	}
-	return validation.ValidateStruct(dst)
+	return c.Validate(dst)
+}
+
+// bindBody is BindInto without validation.
+func (c *Context) bindBody(dst any) error {
+	ct := c.Request.Header.Get(HeaderContentType)
+	if strings.HasPrefix(ct, ContentTypeJSON) {
+		return c.decodeJSON(dst)
+	}
+	if _, ok := c.lookupCodec(ct); ok && ct != "" {
+		return c.decodeCodec(ct, dst)
+	}
+	return binding.Bind(c.Request, dst)
 }
 
 // BindJSONInto binds JSON into dst and validates tags.
 func (c *Context) BindJSONInto(dst any) error {
-	if err := binding.JSON.Bind(c.Request, dst); err != nil {
+	if err := c.decodeJSON(dst); err != nil {
 		return err
 	}
-	return validation.ValidateStruct(dst)
+	return c.Validate(dst)
+}
+
+// BindXMLInto binds an XML body into dst and validates tags. Use `xml`
+// struct tags for element and attribute names.
+func (c *Context) BindXMLInto(dst any) error {
+	if err := binding.XML.Bind(c.Request, dst); err != nil {
+		return err
+	}
+	return c.Validate(dst)
 }
 
 // BindFormInto binds form data into dst and validates tags.
@@ -134,7 +394,7 @@ func (c *Context) BindFormInto(dst any) error {
 	if err := binding.Form.Bind(c.Request, dst); err != nil {
 		return err
 	}
-	return validation.ValidateStruct(dst)
+	return c.Validate(dst)
 }
 
 // BindQueryInto binds query params into dst and validates tags.
@@ -142,7 +402,7 @@ func (c *Context) BindQueryInto(dst any) error {
 	if err := binding.Query.Bind(c.Request, dst); err != nil {
 		return err
 	}
-	return validation.ValidateStruct(dst)
+	return c.Validate(dst)
 }
 
 // BindHeaderInto maps request headers into a struct.
@@ -218,7 +478,7 @@ func (c *Context) BindPathInto(dst any) error {
 		}
 		tag := sf.Tag.Get("path")
 		name, required := parseTagNameRequired(tag, lowerCamel(sf.Name))
-		raw, ok := c.params[name]
+		raw, ok := c.lookupParam(name)
 		if !ok || raw == "" {
 			if required {
 				return fmt.Errorf("BindPathInto: missing required path param %q", name)
@@ -311,15 +571,25 @@ func parseHeaderTag(tag, fallback string) (name string, required bool) {
 	return
 }
 
+// Preallocated Content-Type values; assigning them directly avoids the
+// slice allocation done by Header().Set on every response.
+var (
+	jsonContentType = []string{ContentTypeJSONUTF8}
+	textContentType = []string{ContentTypeTextUTF8}
+	htmlContentType = []string{ContentTypeHTMLUTF8}
+	xmlContentType  = []string{ContentTypeXMLUTF8}
+)
+
+func (c *Context) setContentType(v []string) {
+	c.Writer.Header()[HeaderContentType] = v
+}
+
 // JSON sends a JSON response
 func (c *Context) JSON(code int, v any) {
-	c.Writer.Header().Set(HeaderContentType, ContentTypeJSONUTF8)
+	c.setContentType(jsonContentType)
 	c.Writer.WriteHeader(code)
 
-	enc := json.NewEncoder(c.Writer)
-	enc.SetEscapeHTML(false) // do not escape < > & by default; safer for API payloads
-
-	if err := enc.Encode(v); err != nil {
+	if err := c.encodeJSON(v); err != nil {
 		// Fallback to a minimal error envelope if marshaling fails
 		_, _ = c.Writer.Write([]byte(`{"code":500,"message":"` + MsgJSONEncodeFailed + `"}`))
 	}
@@ -327,31 +597,35 @@ func (c *Context) JSON(code int, v any) {
 
 // String sends a plain text response
 func (c *Context) String(code int, format string, values ...any) {
-	c.Writer.Header().Set(HeaderContentType, ContentTypeTextUTF8)
+	c.setContentType(textContentType)
 	c.Writer.WriteHeader(code)
 	if len(values) > 0 {
 		_, _ = fmt.Fprintf(c.Writer, format, values...)
 	} else {
-		_, _ = c.Writer.Write([]byte(format))
+		_, _ = io.WriteString(c.Writer, format)
 	}
 }
 
 // HTML sends an HTML response
 func (c *Context) HTML(code int, html string) {
-	c.Writer.Header().Set(HeaderContentType, ContentTypeHTMLUTF8)
+	c.setContentType(htmlContentType)
 	c.Writer.WriteHeader(code)
-	_, _ = c.Writer.Write([]byte(html))
+	_, _ = io.WriteString(c.Writer, html)
 }
 
-// XML sends an XML response
+// XML sends v as an XML document with an <?xml?> declaration. When v cannot
+// be marshaled the response is a 500 instead.
 func (c *Context) XML(code int, v any) {
-	c.Writer.Header().Set(HeaderContentType, ContentTypeXMLUTF8)
-	c.Writer.WriteHeader(code)
 	b, err := xml.Marshal(v)
+	c.setContentType(xmlContentType)
 	if err != nil {
-		_, _ = c.Writer.Write([]byte("<error>xml marshal failed</error>"))
+		c.err = err
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = c.Writer.Write([]byte(xml.Header + "<error>" + MsgXMLEncodeFailed + "</error>"))
 		return
 	}
+	c.Writer.WriteHeader(code)
+	_, _ = io.WriteString(c.Writer, xml.Header)
 	_, _ = c.Writer.Write(b)
 }
 
@@ -391,16 +665,73 @@ func (c *Context) SendAttachment(path, filename string) {
 }
 
 func (c *Context) SendBytes(code int, b []byte) {
-	c.Writer.Header().Set(HeaderContentType, ContentTypeTextUTF8)
+	c.setContentType(textContentType)
 	c.Writer.WriteHeader(code)
 	_, _ = c.Writer.Write(b)
 }
 
 func (c *Context) SendStatus(code int) {
-	c.Writer.Header().Set(HeaderContentType, ContentTypeTextUTF8)
+	c.setContentType(textContentType)
 	c.Writer.WriteHeader(code)
 }
 
+// Redirect replies with a redirect to location, which may be relative to the
+// request path. code must be a 3xx status (or 201 Created). Never pass
+// unchecked user input as location: that creates an open redirect.
+func (c *Context) Redirect(code int, location string) {
+	if (code < http.StatusMultipleChoices || code > http.StatusPermanentRedirect) && code != http.StatusCreated {
+		panic(fmt.Sprintf("zentrox: cannot redirect with status %d", code))
+	}
+	http.Redirect(c.Writer, c.Request, location, code)
+}
+
+// Stream calls step until it returns false, flushing after every call, so
+// large payloads (CSV exports, backups) go out as they are produced. It stops
+// early and returns true when the client disconnects. Set the Content-Type
+// before the first write; the status is 200 unless WriteHeader was called.
+//
+//	c.SetHeader(zentrox.HeaderContentType, "text/csv")
+//	c.Stream(func(w io.Writer) bool {
+//		row, ok := rows.Next()
+//		if ok {
+//			fmt.Fprintln(w, row)
+//		}
+//		return ok
+//	})
+func (c *Context) Stream(step func(w io.Writer) bool) (clientGone bool) {
+	done := c.Request.Context().Done()
+	rc := http.NewResponseController(c.Writer)
+	for {
+		select {
+		case <-done:
+			return true
+		default:
+		}
+		more := step(c.Writer)
+		_ = rc.Flush()
+		if !more {
+			return false
+		}
+	}
+}
+
+// DataFromReader copies r to the response. length sets Content-Length; pass
+// -1 when unknown and the response is sent chunked. r is not closed.
+func (c *Context) DataFromReader(code int, length int64, contentType string, r io.Reader) {
+	h := c.Writer.Header()
+	if contentType == "" {
+		contentType = ContentTypeOctetStream
+	}
+	h.Set(HeaderContentType, contentType)
+	if length >= 0 {
+		h.Set(HeaderContentLength, strconv.FormatInt(length, 10))
+	}
+	c.Writer.WriteHeader(code)
+	if _, err := io.Copy(c.Writer, r); err != nil && c.err == nil {
+		c.err = err
+	}
+}
+
 func (c *Context) PushStream(fn func(w io.Writer, flush func())) {
 	c.Writer.Header().Set(HeaderContentType, ContentTypeOctetStream)
 	c.Writer.WriteHeader(http.StatusOK)
@@ -440,6 +771,17 @@ func (c *Context) RequestID() string {
 	return ""
 }
 
+// CSRFToken returns the token issued by the CSRF middleware, for embedding in
+// forms or meta tags. It is masked afresh per request; empty without the
+// middleware.
+func (c *Context) CSRFToken() string {
+	if v, ok := c.Get(CSRFToken); ok {
+		s, _ := v.(string)
+		return s
+	}
+	return ""
+}
+
 // Deadline returns the time when work done on behalf of this request
 // should be canceled. It proxies http.Request.Context().
 func (c *Context) Deadline() (time.Time, bool) {
@@ -476,14 +818,23 @@ func (c *Context) Value(key any) any {
 	return c.Request.Context().Value(key)
 }
 
+// ClientIP returns the address of the client. Proxy headers are only
+// honoured when the peer is a trusted proxy (see App.SetTrustedProxies and
+// App.SetClientIPHeaders); otherwise it is the peer address, so clients
+// cannot spoof their IP for rate limiting or audit logs.
+func (c *Context) ClientIP() string {
+	return c.RealIP()
+}
+
 // RealIP returns the client IP considering common reverse proxy headers.
-// Order: X-Forwarded-For (first), X-Real-IP, then RemoteAddr fallback.
+// Outside an App (no trusted proxy configuration) the order is
+// X-Forwarded-For (first), X-Real-IP, then RemoteAddr.
 func (c *Context) RealIP() string {
 	if c.Request == nil {
 		return ""
 	}
-	if c.realIP != nil {
-		return c.realIP(c.Request)
+	if c.app != nil {
+		return c.app.clientIP(c.Request)
 	}
 	r := c.Request
 	// X-Forwarded-For could be "client, proxy1, proxy2"
@@ -500,125 +851,6 @@ func (c *Context) RealIP() string {
 	return ip
 }
 
-// UploadOptions controls how files are accepted and saved.
-type UploadOptions struct {
-	// Maximum memory used by ParseMultipartForm; files larger than this are stored in temporary files.
-	MaxMemory int64 // default 10 << 20 (10 MiB)
-	// Allowed file extensions (lowercase, with dot). Empty means allow all.
-	AllowedExt []string
-	// If true, sanitize the base filename (only [a-zA-Z0-9._-]) to avoid weird characters.
-	Sanitize bool
-	// If true, always generate a unique filename (timestamp + random suffix).
-	GenerateUniqueName bool
-	// If false and file exists, returns error. If true, overwrite existing file.
-	Overwrite bool
-}
-
-// SaveUploadedFile reads file from multipart form by field name and writes it into dstDir.
-// It validates extension (if provided), prevents path traversal, and can sanitize/generate names.
-// Returns the full path saved to.
-func (c *Context) SaveUploadedFile(field, dstDir string, opt UploadOptions) (string, error) {
-	if dstDir == "" {
-		return "", errors.New("upload: destination directory required")
-	}
-	if opt.MaxMemory <= 0 {
-		opt.MaxMemory = 10 << 20 // 10 MiB
-	}
-	if err := c.Request.ParseMultipartForm(opt.MaxMemory); err != nil {
-		return "", err
-	}
-	file, hdr, err := c.Request.FormFile(field)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-
-	// Decide target filename
-	name := hdr.Filename
-	if opt.Sanitize {
-		name = sanitizeFilename(name)
-	}
-	if opt.GenerateUniqueName {
-		ext := strings.ToLower(filepath.Ext(name))
-		base := strings.TrimSuffix(name, ext)
-		name = base + "-" + time.Now().UTC().Format("20060102T150405") + ext
-	}
-	if name == "" {
-		return "", errors.New("upload: empty filename")
-	}
-
-	// Extension allow-list
-	if len(opt.AllowedExt) > 0 {
-		ext := strings.ToLower(filepath.Ext(name))
-		allowed := false
-		for _, e := range opt.AllowedExt {
-			if strings.ToLower(e) == ext {
-				allowed = true
-				break
-			}
-		}
-		if !allowed {
-			return "", errors.New("upload: disallowed file extension")
-		}
-	}
-
-	// Prevent path traversal
-	target := filepath.Join(dstDir, filepath.Base(name))
-	if ok := isWithinBase(dstDir, target); !ok { // reuse helper from zentrox.go
-		return "", errors.New("upload: invalid path")
-	}
-
-	// Create directory tree if needed
-	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
-		return "", err
-	}
-
-	// Deny overwrite unless allowed
-	if !opt.Overwrite {
-		if _, err := os.Stat(target); err == nil {
-			return "", errors.New("upload: file exists")
-		}
-	}
-
-	// Copy stream to disk (0600 for privacy by default)
-	dst, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
-	if err != nil {
-		return "", err
-	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, file); err != nil {
-		return "", err
-	}
-
-	return target, nil
-}
-
-// UploadedFile returns the multipart file and header for advanced use.
-// Caller must close the returned multipart.File.
-func (c *Context) UploadedFile(field string, maxMemory int64) (multipart.File, *multipart.FileHeader, error) {
-	if maxMemory <= 0 {
-		maxMemory = 10 << 20
-	}
-	if err := c.Request.ParseMultipartForm(maxMemory); err != nil {
-		return nil, nil, err
-	}
-	return c.Request.FormFile(field)
-}
-
-var sanitizeFilenameRe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
-
-// sanitizeFilename strips unsupported characters from a file name.
-func sanitizeFilename(name string) string {
-	name = filepath.Base(name)
-	name = sanitizeFilenameRe.ReplaceAllString(name, "_")
-	// Avoid empty name
-	if name == "" || name == "." || name == ".." {
-		name = "file"
-	}
-	return name
-}
-
 // Accepts returns the preferred type among provided candidates according to the
 // request's "Accept" header. It returns the first element of candidates if the header
 // is empty or no match is found.
@@ -698,6 +930,7 @@ func matchesMedia(acceptVal, candidate string) bool {
 //   - "text/plain": payload must be string
 //   - "text/html": payload must be string (HTML)
 //   - "application/xml": payload marshaled as XML (via SendXML)
+//   - any media type with a codec (see App.SetCodec), e.g. "application/yaml"
 //
 // Example:
 //
@@ -738,6 +971,10 @@ func (c *Context) Negotiate(code int, candidates map[string]any) {
 	case "application/xml", "text/xml":
 		c.XML(code, payload)
 	default:
+		if _, ok := c.lookupCodec(ct); ok && ct != "" {
+			c.Render(code, ct, payload)
+			return
+		}
 		// Fallback to JSON if provided, else first candidate as text
 		if v, ok := candidates[ContentTypeJSON]; ok {
 			c.JSON(code, v)
@@ -754,9 +991,10 @@ func (c *Context) Negotiate(code int, candidates map[string]any) {
 	}
 }
 
-// Problem is a serializable RFC 9457 error object. Extension members are
-// included when marshaled by merging Ext into the base object.
-type Problem struct {
+// ProblemDetails is a serializable RFC 9457 (formerly RFC 7807) error
+// object, sent with Context.Problem. Extension members are included
+// when marshaled by merging Ext into the base object.
+type ProblemDetails struct {
 	Type     string         `json:"type,omitempty"`     // A URI reference that identifies the problem type
 	Title    string         `json:"title,omitempty"`    // A short, human-readable summary of the problem type
 	Status   int            `json:"status,omitempty"`   // HTTP status code generated by the origin server
@@ -766,7 +1004,7 @@ type Problem struct {
 }
 
 // MarshalJSON merges extension members into the base JSON.
-func (p Problem) MarshalJSON() ([]byte, error) {
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
 	base := map[string]any{}
 	if p.Type != "" {
 		base["type"] = p.Type
@@ -792,31 +1030,39 @@ func (p Problem) MarshalJSON() ([]byte, error) {
 	return json.Marshal(base)
 }
 
-// Problem writes an application/problem+json response using the provided fields.
-// The Content-Type is set to "application/problem+json".
-func (c *Context) Problem(status int, typeURI, title, detail, instance string, ext map[string]any) {
-	if ext == nil {
-		ext = map[string]any{}
+// Problem writes p as an application/problem+json response. Status
+// defaults to 500, Type to "about:blank" and Title to the status text.
+func (c *Context) Problem(p ProblemDetails) {
+	if p.Status == 0 {
+		p.Status = http.StatusInternalServerError
+	}
+	if p.Type == "" {
+		p.Type = ProblemTypeBlank
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
 	}
-	p := Problem{
+	// Explicit content-type per RFC
+	c.Writer.Header().Set(HeaderContentType, ContentTypeProblemJSONUTF8)
+	c.Writer.WriteHeader(p.Status)
+	if err := c.encodeJSON(p); err != nil {
+		_, _ = c.Writer.Write([]byte(`{"type":"about:blank","title":"Internal Server Error","status":500}`))
+	}
+}
+
+// ProblemFields is Problem with the members passed positionally.
+func (c *Context) ProblemFields(status int, typeURI, title, detail, instance string, ext map[string]any) {
+	c.Problem(ProblemDetails{
 		Type:     typeURI,
 		Title:    title,
 		Status:   status,
 		Detail:   detail,
 		Instance: instance,
 		Ext:      ext,
-	}
-	// Explicit content-type per RFC
-	c.Writer.Header().Set(HeaderContentType, ContentTypeProblemJSONUTF8)
-	c.Writer.WriteHeader(status)
-	enc := json.NewEncoder(c.Writer)
-	enc.SetEscapeHTML(false)
-	if err := enc.Encode(p); err != nil {
-		_, _ = c.Writer.Write([]byte(`{"type":"about:blank","title":"Internal Server Error","status":500}`))
-	}
+	})
 }
 
 // Problemf is a convenience helper to write a simple problem without instance/ext.
 func (c *Context) Problemf(status int, title string, detail string) {
-	c.Problem(status, "about:blank", title, detail, "", nil)
+	c.Problem(ProblemDetails{Status: status, Type: ProblemTypeBlank, Title: title, Detail: detail})
 }
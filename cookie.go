@@ -0,0 +1,176 @@
+package zentrox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrInvalidCookie   = errors.New("invalid cookie value")
+	ErrCookieExpired   = errors.New("cookie expired")
+	ErrNoSecureCookies = errors.New("secure cookies not configured")
+)
+
+// cookieTimeLen is the size of the issue timestamp prefixed to cookie values.
+const cookieTimeLen = 8
+
+// SetCookie adds a Set-Cookie header to the response.
+func (c *Context) SetCookie(ck *http.Cookie) {
+	http.SetCookie(c.Writer, ck)
+}
+
+// Cookie returns the value of the named request cookie, or http.ErrNoCookie.
+func (c *Context) Cookie(name string) (string, error) {
+	ck, err := c.Request.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return ck.Value, nil
+}
+
+// SecureCookieOptions configures NewSecureCookies.
+type SecureCookieOptions struct {
+	// Encrypt hides the value from the client (AES-256-GCM) in addition to
+	// authenticating it.
+	Encrypt bool
+	// MaxAge rejects values encoded longer ago than this, independent of the
+	// cookie's own expiry, which the client controls. 0 disables the check.
+	MaxAge time.Duration
+}
+
+// SecureCookies signs, and optionally encrypts, cookie values so clients
+// cannot read or alter them. Values are bound to the cookie name, so a value
+// cannot be moved to another cookie. Signing and encryption keys are derived
+// from one secret of at least 32 random bytes.
+type SecureCookies struct {
+	hashKey []byte
+	aead    cipher.AEAD
+	maxAge  time.Duration
+}
+
+func NewSecureCookies(secret []byte, opt ...SecureCookieOptions) *SecureCookies {
+	var o SecureCookieOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	s := &SecureCookies{hashKey: deriveKey(secret, "zentrox cookie sign"), maxAge: o.MaxAge}
+	if o.Encrypt {
+		block, _ := aes.NewCipher(deriveKey(secret, "zentrox cookie encrypt")) // 32-byte key never fails
+		s.aead, _ = cipher.NewGCM(block)
+	}
+	return s
+}
+
+func deriveKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// Encode returns the protected form of value for the cookie name.
+func (s *SecureCookies) Encode(name, value string) (string, error) {
+	payload := make([]byte, cookieTimeLen, cookieTimeLen+len(value))
+	binary.BigEndian.PutUint64(payload, uint64(time.Now().Unix()))
+	payload = append(payload, value...)
+
+	var out []byte
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(payload)+s.aead.Overhead())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		out = s.aead.Seal(nonce, nonce, payload, []byte(name))
+	} else {
+		out = append(payload, s.mac(name, payload)...)
+	}
+	return base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// Decode verifies encoded for the cookie name and returns the original
+// value, or ErrInvalidCookie / ErrCookieExpired.
+func (s *SecureCookies) Decode(name, encoded string) (string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+	var payload []byte
+	if s.aead != nil {
+		ns := s.aead.NonceSize()
+		if len(raw) < ns {
+			return "", ErrInvalidCookie
+		}
+		if payload, err = s.aead.Open(nil, raw[:ns], raw[ns:], []byte(name)); err != nil {
+			return "", ErrInvalidCookie
+		}
+	} else {
+		if len(raw) < sha256.Size {
+			return "", ErrInvalidCookie
+		}
+		payload = raw[:len(raw)-sha256.Size]
+		if !hmac.Equal(raw[len(payload):], s.mac(name, payload)) {
+			return "", ErrInvalidCookie
+		}
+	}
+	if len(payload) < cookieTimeLen {
+		return "", ErrInvalidCookie
+	}
+	if s.maxAge > 0 {
+		issued := time.Unix(int64(binary.BigEndian.Uint64(payload)), 0)
+		if time.Since(issued) > s.maxAge {
+			return "", ErrCookieExpired
+		}
+	}
+	return string(payload[cookieTimeLen:]), nil
+}
+
+func (s *SecureCookies) mac(name string, payload []byte) []byte {
+	m := hmac.New(sha256.New, s.hashKey)
+	m.Write([]byte(name))
+	m.Write([]byte{0})
+	m.Write(payload)
+	return m.Sum(nil)
+}
+
+// SetSecureCookies sets the codec used by Context.SetSecureCookie and
+// Context.SecureCookie.
+func (a *App) SetSecureCookies(s *SecureCookies) *App {
+	a.secureCookies = s
+	return a
+}
+
+// SetSecureCookie protects ck.Value with the app's SecureCookies and adds the
+// cookie to the response.
+func (c *Context) SetSecureCookie(ck *http.Cookie) error {
+	if c.app == nil || c.app.secureCookies == nil {
+		return ErrNoSecureCookies
+	}
+	v, err := c.app.secureCookies.Encode(ck.Name, ck.Value)
+	if err != nil {
+		return err
+	}
+	out := *ck
+	out.Value = v
+	http.SetCookie(c.Writer, &out)
+	return nil
+}
+
+// SecureCookie returns the verified (and decrypted) value of a cookie set
+// with SetSecureCookie.
+func (c *Context) SecureCookie(name string) (string, error) {
+	if c.app == nil || c.app.secureCookies == nil {
+		return "", ErrNoSecureCookies
+	}
+	v, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return c.app.secureCookies.Decode(name, v)
+}
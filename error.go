@@ -1,13 +1,26 @@
 package zentrox
 
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
 // HTTPError is the canonical error payload returned by the framework.
 type HTTPError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Detail  any    `json:"detail,omitempty"`
+
+	cause error
 }
 
-func (e HTTPError) Error() string { return e.Message }
+func (e HTTPError) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
 
 // NewHTTPError constructs a new HTTPError as a Go error.
 func NewHTTPError(code int, message string, detail ...any) error {
@@ -17,3 +30,113 @@ func NewHTTPError(code int, message string, detail ...any) error {
 	}
 	return HTTPError{Code: code, Message: message, Detail: d}
 }
+
+// NewError returns an HTTPError to build on with WithDetails and Wrap. It
+// suits package-level sentinels that handlers return and callers test with
+// errors.Is:
+//
+//	var ErrOrderLocked = zentrox.NewError(409, "order is locked")
+//
+//	return ErrOrderLocked.WithDetails(map[string]any{"order_id": id})
+func NewError(code int, message string) HTTPError {
+	return HTTPError{Code: code, Message: message}
+}
+
+// WithDetails returns a copy of e with Detail set to detail.
+func (e HTTPError) WithDetails(detail any) HTTPError {
+	e.Detail = detail
+	return e
+}
+
+// Wrap returns a copy of e carrying cause, reachable with errors.Is and
+// errors.As but never sent to the client.
+func (e HTTPError) Wrap(cause error) HTTPError {
+	e.cause = cause
+	return e
+}
+
+// Unwrap returns the error passed to Wrap.
+func (e HTTPError) Unwrap() error { return e.cause }
+
+// Is reports whether target is an HTTPError with the same Code and Message,
+// so errors.Is matches a sentinel whatever its Detail or cause.
+func (e HTTPError) Is(target error) bool {
+	t, ok := target.(HTTPError)
+	return ok && t.Code == e.Code && t.Message == e.Message
+}
+
+// ProblemDetails converts e for Context.Problem: Message becomes the
+// title, a string Detail the detail, and any other Detail, such as
+// validation.Errors, the "errors" extension member.
+func (e HTTPError) ProblemDetails(instance string) ProblemDetails {
+	p := ProblemDetails{Status: e.Code, Title: e.Message, Instance: instance}
+	switch d := e.Detail.(type) {
+	case nil:
+	case string:
+		p.Detail = d
+	default:
+		p.Ext = map[string]any{"errors": d}
+	}
+	return p
+}
+
+// errorMap holds the mappings registered with App.MapError.
+type errorMap struct {
+	mu       sync.RWMutex
+	mappings []errorMapping
+}
+
+type errorMapping struct {
+	target  error
+	code    int
+	message string
+}
+
+// MapError makes errors matching target (by errors.Is) answer with status
+// wherever the App turns an error into a response: middleware.ErrorHandler
+// for c.Error(), and handlers built with H. The message defaults to
+// target's text; the matched error is kept as the cause, not sent:
+//
+//	app.MapError(sql.ErrNoRows, 404, "not found")
+//	app.MapError(store.ErrConflict, 409)
+//
+// Mappings are checked in registration order.
+func (a *App) MapError(target error, status int, message ...string) *App {
+	m := errorMapping{target: target, code: status, message: target.Error()}
+	if len(message) > 0 {
+		m.message = message[0]
+	}
+	a.errMap.mu.Lock()
+	a.errMap.mappings = append(a.errMap.mappings, m)
+	a.errMap.mu.Unlock()
+	return a
+}
+
+// ResolveError converts err into the HTTPError to send: err itself if it
+// is or wraps an HTTPError, otherwise the first MapError match. It returns
+// false for unknown errors, which should answer 500.
+func (a *App) ResolveError(err error) (HTTPError, bool) {
+	if err == nil {
+		return HTTPError{}, false
+	}
+	var he HTTPError
+	if errors.As(err, &he) {
+		return he, true
+	}
+	if a == nil {
+		return HTTPError{}, false
+	}
+	a.errMap.mu.RLock()
+	defer a.errMap.mu.RUnlock()
+	for _, m := range a.errMap.mappings {
+		if errors.Is(err, m.target) {
+			return HTTPError{Code: m.code, Message: m.message, cause: err}, true
+		}
+	}
+	return HTTPError{}, false
+}
+
+// internalError is the body sent for errors ResolveError does not know.
+func internalError() HTTPError {
+	return HTTPError{Code: http.StatusInternalServerError, Message: MsgInternalServerError}
+}
@@ -0,0 +1,155 @@
+package zentrox
+
+import (
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Request lifecycle events, see App.On.
+const (
+	// EventRequestStart is emitted before the middleware chain runs.
+	EventRequestStart = "request.start"
+	// EventRequestFinish is emitted after the response, with Status and
+	// Duration set.
+	EventRequestFinish = "request.finish"
+	// EventRequestPanic is emitted when a handler panics, with the panic
+	// value as Data; by middleware.Recovery or ErrorHandler if installed.
+	EventRequestPanic = "request.panic"
+)
+
+// Event is delivered to subscribers registered with App.On and App.OnAsync.
+type Event struct {
+	Name string
+	Data any
+	Time time.Time
+	// Method, Path, Route, RequestID and ClientIP describe the request the
+	// event was emitted in; they are empty for App.Emit. Route is empty
+	// for EventRequestStart, which fires before routing.
+	Method, Path, Route, RequestID, ClientIP string
+	// Status and Duration are set for EventRequestFinish.
+	Status   int
+	Duration time.Duration
+	// Context is the request, for synchronous subscribers only: it is nil
+	// for asynchronous ones, which may run after the Context is reused.
+	Context *Context
+}
+
+type subscriber struct {
+	fn    func(Event)
+	async bool
+}
+
+// eventBus holds the subscribers by event name, copied on write so
+// emitting needs no lock.
+type eventBus struct {
+	mu   sync.Mutex
+	subs atomic.Pointer[map[string][]subscriber]
+}
+
+// On subscribes fn to the event name, or to every event with "*". fn runs
+// synchronously in the emitting goroutine, in subscription order, so it
+// can read the request Context and adds to the request latency:
+//
+//	app.On(zentrox.EventRequestFinish, func(e zentrox.Event) {
+//		audit.Record(e.Method, e.Route, e.Status, e.Context.GetString("user_id"))
+//	})
+func (a *App) On(name string, fn func(Event)) *App {
+	a.events.subscribe(name, subscriber{fn: fn})
+	return a
+}
+
+// OnAsync subscribes fn to the event name, or to every event with "*"; each
+// call runs in its own goroutine, for analytics or notifications that should
+// not slow down requests. Event.Context is nil. Panics are logged.
+func (a *App) OnAsync(name string, fn func(Event)) *App {
+	a.events.subscribe(name, subscriber{fn: fn, async: true})
+	return a
+}
+
+func (b *eventBus) subscribe(name string, s subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	next := map[string][]subscriber{}
+	if cur := b.subs.Load(); cur != nil {
+		for k, v := range *cur {
+			next[k] = v
+		}
+	}
+	next[name] = append(append([]subscriber(nil), next[name]...), s)
+	b.subs.Store(&next)
+}
+
+// has reports whether anything subscribed to name.
+func (b *eventBus) has(name string) bool {
+	subs := b.subs.Load()
+	return subs != nil && (len((*subs)[name]) > 0 || len((*subs)["*"]) > 0)
+}
+
+// Emit publishes a custom event from outside a request, e.g. a scheduled
+// job.
+func (a *App) Emit(name string, data any) {
+	if a.events.has(name) {
+		a.publish(Event{Name: name, Data: data, Time: time.Now()})
+	}
+}
+
+// Emit publishes a custom event, such as "order.created", to the App's
+// subscribers, with the request's details filled in.
+func (c *Context) Emit(name string, data any) {
+	if c.app == nil || !c.app.events.has(name) {
+		return
+	}
+	c.app.publish(c.requestEvent(name, data))
+}
+
+func (c *Context) requestEvent(name string, data any) Event {
+	return Event{
+		Name:      name,
+		Data:      data,
+		Time:      time.Now(),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Route:     c.RoutePath(),
+		RequestID: c.RequestID(),
+		ClientIP:  c.ClientIP(),
+		Context:   c,
+	}
+}
+
+func (a *App) publish(e Event) {
+	subs := *a.events.subs.Load()
+	for _, key := range [2]string{e.Name, "*"} {
+		for _, s := range subs[key] {
+			if !s.async {
+				s.fn(e)
+				continue
+			}
+			ae := e
+			ae.Context = nil
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						a.Logger().Error("zentrox: event subscriber panic", "event", ae.Name, "panic", r, "stack", string(debug.Stack()))
+					}
+				}()
+				s.fn(ae)
+			}()
+		}
+	}
+}
+
+// emitFinish publishes EventRequestFinish for the request.
+func (a *App) emitFinish(c *Context, status int, d time.Duration) {
+	if !a.events.has(EventRequestFinish) {
+		return
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+	e := c.requestEvent(EventRequestFinish, nil)
+	e.Status, e.Duration = status, d
+	a.publish(e)
+}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"errors"
 	"log"
 	"time"
 
@@ -46,27 +45,21 @@ func main() {
 		c.JSON(200, map[string]string{"token": token})
 	})
 
-	// Protected scope: validates exp, iss, and role
+	// Protected scope: exp/nbf/iat are checked automatically; iss via Issuer; role via RequireRoles
 	api := app.Scope("/api", middleware.JWT(middleware.JWTConfig{
 		Secret:     secret,
 		ContextKey: "user",
-		ValidateFunc: func(claims map[string]any) error {
-			if exp, ok := claims["exp"].(float64); ok && time.Now().Unix() > int64(exp) {
-				return errors.New("token expired")
-			}
-			if iss, _ := claims["iss"].(string); iss != "myapp" {
-				return errors.New("invalid issuer")
-			}
-			if role, _ := claims["role"].(string); role != "admin" {
-				return errors.New("admin role required")
-			}
-			return nil
-		},
-	}))
+		Issuer:     "myapp",
+		Leeway:     30 * time.Second,
+	}), middleware.RequireRoles("admin"))
 
 	api.GET("/me", func(c *zentrox.Context) {
-		user, _ := c.Get("user")
-		c.JSON(200, user)
+		claims, ok := zentrox.Get[map[string]any](c, "user")
+		if !ok {
+			c.Fail(401, "unauthorized")
+			return
+		}
+		c.JSON(200, claims)
 	})
 
 	log.Println("listening on :8000")
@@ -0,0 +1,98 @@
+package zentrox
+
+import (
+	"strings"
+)
+
+// hostRouter holds the routes of one Host pattern.
+type hostRouter struct {
+	pattern string   // as registered, e.g. "*.example.com"
+	labels  []string // lower-cased labels; "*" matches any one label
+	suffix  bool     // labels is a prefix: any further labels match (Subdomain without Host)
+	rt      *router
+}
+
+func (h *hostRouter) matches(host string) bool {
+	n := 0
+	for len(host) > 0 {
+		label, rest, _ := strings.Cut(host, ".")
+		if n == len(h.labels) {
+			return h.suffix
+		}
+		if h.labels[n] != "*" && !strings.EqualFold(h.labels[n], label) {
+			return false
+		}
+		n++
+		host = rest
+	}
+	return n == len(h.labels) && !h.suffix
+}
+
+// Host returns a scope whose routes only match requests for host, ignoring
+// the port and case. A "*" label matches any one label, e.g. for tenants:
+//
+//	api := app.Host("api.example.com")
+//	api.GET("/users", listUsers)
+//	tenants := app.Host("*.example.com")
+//	tenants.GET("/", tenantHome) // c.Request.Host names the tenant
+//
+// Requests for other hosts, or paths a host does not register, fall back to
+// the routes registered directly on the App. Exact hosts are tried before
+// patterns with "*".
+func (a *App) Host(host string, mws ...Handler) *Scope {
+	s := a.Scope("", mws...)
+	s.host = a.hostRouter(strings.ToLower(strings.TrimSuffix(host, ".")), false)
+	return s
+}
+
+// Subdomain returns a scope for the subdomain name of the scope's host, so
+// app.Host("example.com").Subdomain("admin") matches admin.example.com. On a
+// scope without a host it matches any host starting with name, such as
+// admin.example.com and admin.localhost. Prefix and middleware are kept.
+func (s *Scope) Subdomain(name string) *Scope {
+	sub := s.Scope("")
+	name = strings.ToLower(strings.Trim(name, "."))
+	if s.host != nil {
+		sub.host = s.app.hostRouter(name+"."+s.host.pattern, s.host.suffix)
+	} else {
+		sub.host = s.app.hostRouter(name, true)
+	}
+	return sub
+}
+
+func (a *App) hostRouter(pattern string, suffix bool) *hostRouter {
+	for _, h := range a.hosts {
+		if h.pattern == pattern && h.suffix == suffix {
+			return h
+		}
+	}
+	h := &hostRouter{pattern: pattern, labels: strings.Split(pattern, "."), suffix: suffix, rt: newRouter()}
+	a.hosts = append(a.hosts, h)
+	return h
+}
+
+// routerFor returns the router for the request host and path, falling back
+// to the App's own routes.
+func (a *App) routerFor(host, path string) *router {
+	host = strings.TrimSuffix(stripPort(host), ".")
+	for pass := 0; pass < 2; pass++ {
+		for _, h := range a.hosts {
+			wild := h.suffix || strings.Contains(h.pattern, "*")
+			if wild != (pass == 1) || !h.matches(host) {
+				continue
+			}
+			if n := h.rt.findNode(path); n != nil && n.handlers != nil {
+				return h.rt
+			}
+		}
+	}
+	return a.rt
+}
+
+// stripPort removes the port from a Host header, keeping IPv6 brackets.
+func stripPort(host string) string {
+	if i := strings.LastIndexByte(host, ':'); i > strings.LastIndexByte(host, ']') {
+		return host[:i]
+	}
+	return host
+}
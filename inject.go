@@ -0,0 +1,112 @@
+package zentrox
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// container holds the values registered with App.Provide.
+type container struct {
+	mu     sync.RWMutex
+	keys   []string // registration order
+	values map[string]any
+}
+
+// Provide registers a dependency under key, replacing any previous value,
+// so handlers and middleware can fetch it with Inject or InjectKey instead
+// of closing over it:
+//
+//	app.Provide("db", db)
+//	app.Provide("mailer", mailer) // a Mailer interface implementation
+//
+//	app.GET("/users/:id", func(c *zentrox.Context) {
+//		db := zentrox.Inject[*sql.DB](c)
+//		// ...
+//	})
+func (a *App) Provide(key string, value any) *App {
+	a.deps.mu.Lock()
+	defer a.deps.mu.Unlock()
+	if a.deps.values == nil {
+		a.deps.values = map[string]any{}
+	}
+	if _, ok := a.deps.values[key]; !ok {
+		a.deps.keys = append(a.deps.keys, key)
+	}
+	a.deps.values[key] = value
+	return a
+}
+
+// Provided returns the value registered under key.
+func (a *App) Provided(key string) (any, bool) {
+	a.deps.mu.RLock()
+	defer a.deps.mu.RUnlock()
+	v, ok := a.deps.values[key]
+	return v, ok
+}
+
+// byType returns the first value, in registration order, of type t or,
+// when t is an interface, implementing it. Exact matches win.
+func (a *App) byType(t reflect.Type) (any, bool) {
+	a.deps.mu.RLock()
+	defer a.deps.mu.RUnlock()
+	var assignable any
+	found := false
+	for _, k := range a.deps.keys {
+		v := a.deps.values[k]
+		if v == nil {
+			continue
+		}
+		vt := reflect.TypeOf(v)
+		if vt == t {
+			return v, true
+		}
+		if !found && t.Kind() == reflect.Interface && vt.Implements(t) {
+			assignable, found = v, true
+		}
+	}
+	return assignable, found
+}
+
+// TryInject returns the dependency of type T registered with App.Provide: a
+// value of exactly that type or, for an interface T, the first value
+// implementing it.
+func TryInject[T any](c *Context) (T, bool) {
+	var zero T
+	if c.app == nil {
+		return zero, false
+	}
+	v, ok := c.app.byType(reflect.TypeFor[T]())
+	if !ok {
+		return zero, false
+	}
+	return v.(T), true
+}
+
+// Inject is TryInject that panics when nothing of type T was provided, a
+// wiring mistake Recovery turns into a 500.
+func Inject[T any](c *Context) T {
+	v, ok := TryInject[T](c)
+	if !ok {
+		panic(fmt.Sprintf("zentrox: no dependency of type %s provided", reflect.TypeFor[T]()))
+	}
+	return v
+}
+
+// InjectKey returns the dependency registered under key, for several values
+// of the same type. It panics if key is missing or holds another type.
+func InjectKey[T any](c *Context, key string) T {
+	var v any
+	var ok bool
+	if c.app != nil {
+		v, ok = c.app.Provided(key)
+	}
+	if !ok {
+		panic(fmt.Sprintf("zentrox: no dependency %q provided", key))
+	}
+	t, ok := v.(T)
+	if !ok {
+		panic(fmt.Sprintf("zentrox: dependency %q is %T, not %s", key, v, reflect.TypeFor[T]()))
+	}
+	return t
+}
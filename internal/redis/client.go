@@ -0,0 +1,240 @@
+// Package redis is a minimal RESP2 client used by the Redis-backed stores.
+// It supports plain request/response commands over a small connection pool.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Config describes how to reach a Redis server.
+type Config struct {
+	Addr        string
+	Password    string
+	DB          int
+	DialTimeout time.Duration
+	PoolSize    int
+}
+
+// Error is an error reply from the server.
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// ErrNil is returned by String for nil replies.
+var ErrNil = errors.New("redis: nil")
+
+type conn struct {
+	c  net.Conn
+	br *bufio.Reader
+}
+
+// Client is safe for concurrent use.
+type Client struct {
+	cfg  Config
+	idle chan *conn
+}
+
+func New(cfg Config) *Client {
+	if cfg.Addr == "" {
+		cfg.Addr = "127.0.0.1:6379"
+	}
+	if cfg.DialTimeout <= 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 8
+	}
+	return &Client{cfg: cfg, idle: make(chan *conn, cfg.PoolSize)}
+}
+
+// Do sends a command and returns the reply: string, int64, nil, []any or an
+// Error. Array elements may themselves be Error values.
+func (cl *Client) Do(ctx context.Context, args ...string) (any, error) {
+	cn, err := cl.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = cn.c.SetDeadline(dl)
+	} else {
+		_ = cn.c.SetDeadline(time.Time{})
+	}
+	if err := writeCommand(cn.c, args); err != nil {
+		cn.c.Close()
+		return nil, err
+	}
+	reply, err := readReply(cn.br)
+	if err != nil {
+		var re Error
+		if !errors.As(err, &re) {
+			cn.c.Close()
+			return nil, err
+		}
+	}
+	cl.put(cn)
+	return reply, err
+}
+
+// String runs a command expecting a bulk or simple string reply.
+func (cl *Client) String(ctx context.Context, args ...string) (string, error) {
+	v, err := cl.Do(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case nil:
+		return "", ErrNil
+	}
+	return "", fmt.Errorf("redis: unexpected reply %T", v)
+}
+
+// Int runs a command expecting an integer reply.
+func (cl *Client) Int(ctx context.Context, args ...string) (int64, error) {
+	v, err := cl.Do(ctx, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(int64)
+	if !ok {
+		return 0, fmt.Errorf("redis: unexpected reply %T", v)
+	}
+	return n, nil
+}
+
+// Close closes idle connections.
+func (cl *Client) Close() error {
+	for {
+		select {
+		case cn := <-cl.idle:
+			cn.c.Close()
+		default:
+			return nil
+		}
+	}
+}
+
+func (cl *Client) get(ctx context.Context) (*conn, error) {
+	select {
+	case cn := <-cl.idle:
+		return cn, nil
+	default:
+	}
+	d := net.Dialer{Timeout: cl.cfg.DialTimeout}
+	c, err := d.DialContext(ctx, "tcp", cl.cfg.Addr)
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{c: c, br: bufio.NewReader(c)}
+	if cl.cfg.Password != "" {
+		if err := cn.handshake("AUTH", cl.cfg.Password); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if cl.cfg.DB != 0 {
+		if err := cn.handshake("SELECT", strconv.Itoa(cl.cfg.DB)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+func (cn *conn) handshake(args ...string) error {
+	if err := writeCommand(cn.c, args); err != nil {
+		return err
+	}
+	_, err := readReply(cn.br)
+	return err
+}
+
+func (cl *Client) put(cn *conn) {
+	select {
+	case cl.idle <- cn:
+	default:
+		cn.c.Close()
+	}
+}
+
+func writeCommand(w io.Writer, args []string) error {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, a := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(a)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, a...)
+		buf = append(buf, '\r', '\n')
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func readReply(br *bufio.Reader) (any, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, errors.New("redis: short reply")
+	}
+	body := line[1 : len(line)-2]
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, Error(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		b := make([]byte, n+2)
+		if _, err := io.ReadFull(br, b); err != nil {
+			return nil, err
+		}
+		return string(b[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		// Error elements (e.g. a failed command inside EXEC) are kept as
+		// values so the rest of the array is still consumed and the
+		// connection stays in sync.
+		out := make([]any, n)
+		for i := range out {
+			v, err := readReply(br)
+			var re Error
+			if errors.As(err, &re) {
+				out[i] = re
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", line[0])
+}
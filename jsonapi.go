@@ -0,0 +1,495 @@
+package zentrox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// JSON:API (https://jsonapi.org) support.
+//
+// Structs opt in with `jsonapi` tags:
+//
+//	type Article struct {
+//	    ID     string  `jsonapi:"primary,articles"`
+//	    Title  string  `jsonapi:"attr,title"`
+//	    Author *Person `jsonapi:"relation,author"`
+//	}
+//
+// c.JSONAPI renders such values (or slices of them) as a document with related
+// resources in "included"; c.BindJSONAPIInto does the reverse for request bodies.
+
+// JSONAPIDocument is a top-level JSON:API document.
+type JSONAPIDocument struct {
+	Data     any               `json:"data,omitempty"` // *JSONAPIResource, []JSONAPIResource or nil
+	Included []JSONAPIResource `json:"included,omitempty"`
+	Errors   []JSONAPIError    `json:"errors,omitempty"`
+	Meta     map[string]any    `json:"meta,omitempty"`
+	Links    map[string]string `json:"links,omitempty"`
+}
+
+// MarshalJSON always writes "data", as null for an empty primary resource
+// (e.g. an unset to-one relationship), except in error documents, which
+// must not contain it.
+func (d JSONAPIDocument) MarshalJSON() ([]byte, error) {
+	type plain JSONAPIDocument
+	if len(d.Errors) > 0 {
+		return json.Marshal(plain(d))
+	}
+	return json.Marshal(struct {
+		Data any `json:"data"`
+		plain
+	}{d.Data, plain(d)})
+}
+
+// JSONAPIResource is a resource object.
+type JSONAPIResource struct {
+	Type          string                         `json:"type"`
+	ID            string                         `json:"id,omitempty"`
+	Attributes    map[string]any                 `json:"attributes,omitempty"`
+	Relationships map[string]JSONAPIRelationship `json:"relationships,omitempty"`
+	Links         map[string]string              `json:"links,omitempty"`
+	Meta          map[string]any                 `json:"meta,omitempty"`
+}
+
+// JSONAPIRelationship is a relationship object. Data is a *JSONAPIIdentifier,
+// a []JSONAPIIdentifier, or nil for an empty to-one relationship.
+type JSONAPIRelationship struct {
+	Data  any               `json:"data"`
+	Links map[string]string `json:"links,omitempty"`
+	Meta  map[string]any    `json:"meta,omitempty"`
+}
+
+// JSONAPIIdentifier is a resource identifier object.
+type JSONAPIIdentifier struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// JSONAPIError is an error object.
+type JSONAPIError struct {
+	ID     string              `json:"id,omitempty"`
+	Status string              `json:"status,omitempty"`
+	Code   string              `json:"code,omitempty"`
+	Title  string              `json:"title,omitempty"`
+	Detail string              `json:"detail,omitempty"`
+	Source *JSONAPIErrorSource `json:"source,omitempty"`
+	Meta   map[string]any      `json:"meta,omitempty"`
+}
+
+// JSONAPIErrorSource points at the part of the request that caused the error.
+type JSONAPIErrorSource struct {
+	Pointer   string `json:"pointer,omitempty"`
+	Parameter string `json:"parameter,omitempty"`
+	Header    string `json:"header,omitempty"`
+}
+
+// JSONAPI writes v as an application/vnd.api+json document.
+// v may be a JSONAPIDocument (written as-is), a tagged struct, a pointer to one,
+// or a slice of them.
+func (c *Context) JSONAPI(code int, v any) {
+	var doc JSONAPIDocument
+	switch d := v.(type) {
+	case JSONAPIDocument:
+		doc = d
+	case *JSONAPIDocument:
+		doc = *d
+	default:
+		var err error
+		doc, err = MarshalJSONAPI(v)
+		if err != nil {
+			c.JSONAPIErrors(http.StatusInternalServerError, JSONAPIError{
+				Status: strconv.Itoa(http.StatusInternalServerError),
+				Title:  MsgInternalServerError,
+			})
+			return
+		}
+	}
+	c.writeJSONAPI(code, doc)
+}
+
+// JSONAPIErrors writes an errors document with the given status code.
+func (c *Context) JSONAPIErrors(code int, errs ...JSONAPIError) {
+	for i := range errs {
+		if errs[i].Status == "" {
+			errs[i].Status = strconv.Itoa(code)
+		}
+	}
+	c.writeJSONAPI(code, JSONAPIDocument{Errors: errs})
+}
+
+func (c *Context) writeJSONAPI(code int, doc JSONAPIDocument) {
+	c.Writer.Header().Set(HeaderContentType, ContentTypeJSONAPI)
+	c.Writer.WriteHeader(code)
+	_ = c.encodeJSON(doc)
+}
+
+// BindJSONAPIInto decodes a JSON:API request document with a single primary
+// resource into a tagged struct, then validates it.
+// Relationship fields receive only the related resource IDs.
+func (c *Context) BindJSONAPIInto(dst any) error {
+	if c.Request.Body == nil {
+		return errors.New("jsonapi: empty body")
+	}
+	defer c.Request.Body.Close()
+	if err := UnmarshalJSONAPI(c.Request.Body, dst); err != nil {
+		return err
+	}
+	return c.Validate(dst)
+}
+
+// MarshalJSONAPI converts a tagged struct (or slice of structs) into a document.
+func MarshalJSONAPI(v any) (JSONAPIDocument, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return JSONAPIDocument{}, nil
+		}
+		rv = rv.Elem()
+	}
+
+	inc := &jsonapiIncluded{seen: map[string]struct{}{}}
+	var doc JSONAPIDocument
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		list := make([]JSONAPIResource, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			res, err := marshalJSONAPIResource(rv.Index(i), inc)
+			if err != nil {
+				return JSONAPIDocument{}, err
+			}
+			list = append(list, res)
+		}
+		doc.Data = list
+	case reflect.Struct:
+		res, err := marshalJSONAPIResource(rv, inc)
+		if err != nil {
+			return JSONAPIDocument{}, err
+		}
+		doc.Data = &res
+	default:
+		return JSONAPIDocument{}, fmt.Errorf("jsonapi: unsupported kind %s", rv.Kind())
+	}
+
+	doc.Included = inc.filter(doc.Data)
+	return doc, nil
+}
+
+type jsonapiIncluded struct {
+	seen  map[string]struct{}
+	list  []JSONAPIResource
+	depth int
+}
+
+func (inc *jsonapiIncluded) add(r JSONAPIResource) {
+	key := r.Type + "\x00" + r.ID
+	if _, ok := inc.seen[key]; ok {
+		return
+	}
+	inc.seen[key] = struct{}{}
+	inc.list = append(inc.list, r)
+}
+
+// filter drops included resources that duplicate primary data.
+func (inc *jsonapiIncluded) filter(primary any) []JSONAPIResource {
+	if len(inc.list) == 0 {
+		return nil
+	}
+	prim := map[string]struct{}{}
+	switch p := primary.(type) {
+	case *JSONAPIResource:
+		prim[p.Type+"\x00"+p.ID] = struct{}{}
+	case []JSONAPIResource:
+		for _, r := range p {
+			prim[r.Type+"\x00"+r.ID] = struct{}{}
+		}
+	}
+	out := make([]JSONAPIResource, 0, len(inc.list))
+	for _, r := range inc.list {
+		if _, dup := prim[r.Type+"\x00"+r.ID]; !dup {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+type jsonapiTag struct {
+	kind      string // primary | attr | relation
+	name      string
+	omitEmpty bool
+}
+
+func parseJSONAPITag(tag string) (jsonapiTag, bool) {
+	if tag == "" || tag == "-" {
+		return jsonapiTag{}, false
+	}
+	parts := strings.Split(tag, ",")
+	t := jsonapiTag{kind: strings.TrimSpace(parts[0])}
+	if len(parts) > 1 {
+		t.name = strings.TrimSpace(parts[1])
+	}
+	for _, p := range parts[2:] {
+		if strings.TrimSpace(p) == "omitempty" {
+			t.omitEmpty = true
+		}
+	}
+	return t, t.kind != ""
+}
+
+func marshalJSONAPIResource(rv reflect.Value, inc *jsonapiIncluded) (JSONAPIResource, error) {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return JSONAPIResource{}, errors.New("jsonapi: nil resource")
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return JSONAPIResource{}, fmt.Errorf("jsonapi: resource must be a struct, got %s", rv.Kind())
+	}
+
+	var res JSONAPIResource
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := parseJSONAPITag(sf.Tag.Get("jsonapi"))
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		switch tag.kind {
+		case "primary":
+			res.Type = tag.name
+			res.ID = jsonapiID(fv)
+		case "attr":
+			if tag.omitEmpty && fv.IsZero() {
+				continue
+			}
+			if res.Attributes == nil {
+				res.Attributes = map[string]any{}
+			}
+			res.Attributes[tag.name] = fv.Interface()
+		case "relation":
+			rel, err := marshalJSONAPIRelation(fv, inc)
+			if err != nil {
+				return JSONAPIResource{}, fmt.Errorf("jsonapi: relation %s: %w", tag.name, err)
+			}
+			if tag.omitEmpty && rel.Data == nil {
+				continue
+			}
+			if res.Relationships == nil {
+				res.Relationships = map[string]JSONAPIRelationship{}
+			}
+			res.Relationships[tag.name] = rel
+		}
+	}
+	if res.Type == "" {
+		return JSONAPIResource{}, fmt.Errorf("jsonapi: %s has no primary tag", rt.Name())
+	}
+	return res, nil
+}
+
+func marshalJSONAPIRelation(fv reflect.Value, inc *jsonapiIncluded) (JSONAPIRelationship, error) {
+	switch fv.Kind() {
+	case reflect.Slice, reflect.Array:
+		ids := make([]JSONAPIIdentifier, 0, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			el := fv.Index(i)
+			if el.Kind() == reflect.Pointer && el.IsNil() {
+				continue
+			}
+			id, err := includeJSONAPI(el, inc)
+			if err != nil {
+				return JSONAPIRelationship{}, err
+			}
+			ids = append(ids, id)
+		}
+		return JSONAPIRelationship{Data: ids}, nil
+	case reflect.Pointer:
+		if fv.IsNil() {
+			return JSONAPIRelationship{Data: nil}, nil
+		}
+	}
+	id, err := includeJSONAPI(fv, inc)
+	if err != nil {
+		return JSONAPIRelationship{}, err
+	}
+	return JSONAPIRelationship{Data: &id}, nil
+}
+
+// jsonapiMaxDepth bounds relation nesting so cyclic object graphs terminate.
+const jsonapiMaxDepth = 4
+
+func includeJSONAPI(rv reflect.Value, inc *jsonapiIncluded) (JSONAPIIdentifier, error) {
+	if inc.depth >= jsonapiMaxDepth {
+		return jsonapiIdentifierOf(rv)
+	}
+	inc.depth++
+	res, err := marshalJSONAPIResource(rv, inc)
+	inc.depth--
+	if err != nil {
+		return JSONAPIIdentifier{}, err
+	}
+	inc.add(res)
+	return JSONAPIIdentifier{Type: res.Type, ID: res.ID}, nil
+}
+
+func jsonapiIdentifierOf(rv reflect.Value) (JSONAPIIdentifier, error) {
+	for rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() == reflect.Struct {
+		for i := 0; i < rv.NumField(); i++ {
+			tag, ok := parseJSONAPITag(rv.Type().Field(i).Tag.Get("jsonapi"))
+			if ok && tag.kind == "primary" {
+				return JSONAPIIdentifier{Type: tag.name, ID: jsonapiID(rv.Field(i))}, nil
+			}
+		}
+	}
+	return JSONAPIIdentifier{}, errors.New("jsonapi: related value has no primary tag")
+}
+
+func jsonapiID(fv reflect.Value) string {
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fv.Int() == 0 {
+			return ""
+		}
+		return strconv.FormatInt(fv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if fv.Uint() == 0 {
+			return ""
+		}
+		return strconv.FormatUint(fv.Uint(), 10)
+	}
+	return fmt.Sprint(fv.Interface())
+}
+
+// UnmarshalJSONAPI decodes a single-resource document from r into a tagged struct.
+func UnmarshalJSONAPI(r io.Reader, dst any) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("jsonapi: dst must be non-nil pointer")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return errors.New("jsonapi: dst must point to a struct")
+	}
+
+	var doc struct {
+		Data *struct {
+			Type          string                     `json:"type"`
+			ID            string                     `json:"id"`
+			Attributes    map[string]json.RawMessage `json:"attributes"`
+			Relationships map[string]struct {
+				Data json.RawMessage `json:"data"`
+			} `json:"relationships"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return err
+	}
+	if doc.Data == nil {
+		return errors.New("jsonapi: missing primary data")
+	}
+	data := doc.Data
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := parseJSONAPITag(sf.Tag.Get("jsonapi"))
+		if !ok {
+			continue
+		}
+		fv := rv.Field(i)
+		switch tag.kind {
+		case "primary":
+			if data.Type != tag.name {
+				return fmt.Errorf("jsonapi: type %q does not match %q", data.Type, tag.name)
+			}
+			if data.ID != "" {
+				if err := setField(fv, data.ID); err != nil {
+					return fmt.Errorf("jsonapi: id: %w", err)
+				}
+			}
+		case "attr":
+			raw, ok := data.Attributes[tag.name]
+			if !ok {
+				continue
+			}
+			if err := json.Unmarshal(raw, fv.Addr().Interface()); err != nil {
+				return fmt.Errorf("jsonapi: attribute %s: %w", tag.name, err)
+			}
+		case "relation":
+			rel, ok := data.Relationships[tag.name]
+			if !ok || len(rel.Data) == 0 || string(rel.Data) == "null" {
+				continue
+			}
+			if err := unmarshalJSONAPIRelation(fv, rel.Data); err != nil {
+				return fmt.Errorf("jsonapi: relation %s: %w", tag.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func unmarshalJSONAPIRelation(fv reflect.Value, raw json.RawMessage) error {
+	if fv.Kind() == reflect.Slice {
+		var ids []JSONAPIIdentifier
+		if err := json.Unmarshal(raw, &ids); err != nil {
+			return err
+		}
+		out := reflect.MakeSlice(fv.Type(), 0, len(ids))
+		for _, id := range ids {
+			el := reflect.New(fv.Type().Elem()).Elem()
+			if err := setJSONAPIIdentifier(el, id); err != nil {
+				return err
+			}
+			out = reflect.Append(out, el)
+		}
+		fv.Set(out)
+		return nil
+	}
+	var id JSONAPIIdentifier
+	if err := json.Unmarshal(raw, &id); err != nil {
+		return err
+	}
+	return setJSONAPIIdentifier(fv, id)
+}
+
+// setJSONAPIIdentifier sets the primary ID field of a (pointer to) tagged struct.
+func setJSONAPIIdentifier(fv reflect.Value, id JSONAPIIdentifier) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv = fv.Elem()
+	}
+	if fv.Kind() != reflect.Struct {
+		return fmt.Errorf("unsupported kind %s", fv.Kind())
+	}
+	for i := 0; i < fv.NumField(); i++ {
+		tag, ok := parseJSONAPITag(fv.Type().Field(i).Tag.Get("jsonapi"))
+		if !ok || tag.kind != "primary" {
+			continue
+		}
+		if tag.name != id.Type {
+			return fmt.Errorf("type %q does not match %q", id.Type, tag.name)
+		}
+		return setField(fv.Field(i), id.ID)
+	}
+	return errors.New("related struct has no primary tag")
+}
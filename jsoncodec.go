@@ -0,0 +1,133 @@
+package zentrox
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// JSONEncoder writes JSON values to a stream, like *json.Encoder.
+type JSONEncoder interface {
+	Encode(v any) error
+}
+
+// JSONDecoder reads JSON values from a stream, like *json.Decoder.
+type JSONDecoder interface {
+	Decode(v any) error
+}
+
+// JSONCodec swaps the JSON implementation used by Context.JSON, Problem,
+// JSON:API responses, BindJSONInto and BindInto. Nil fields fall back to
+// encoding/json. Encoders with a SetEscapeHTML(bool) method get HTML
+// escaping disabled, matching the default.
+type JSONCodec struct {
+	Marshal    func(v any) ([]byte, error)
+	Unmarshal  func(data []byte, v any) error
+	NewEncoder func(w io.Writer) JSONEncoder
+	NewDecoder func(r io.Reader) JSONDecoder
+}
+
+// stdJSON is the encoding/json codec.
+var stdJSON = JSONCodec{
+	Marshal:    json.Marshal,
+	Unmarshal:  json.Unmarshal,
+	NewEncoder: func(w io.Writer) JSONEncoder { return json.NewEncoder(w) },
+	NewDecoder: func(r io.Reader) JSONDecoder { return json.NewDecoder(r) },
+}
+
+// SetJSONCodec replaces encoding/json for this App, e.g. with sonic:
+//
+//	app.SetJSONCodec(zentrox.JSONCodec{
+//		Marshal:    sonic.Marshal,
+//		Unmarshal:  sonic.Unmarshal,
+//		NewEncoder: func(w io.Writer) zentrox.JSONEncoder { return sonic.ConfigStd.NewEncoder(w) },
+//		NewDecoder: func(r io.Reader) zentrox.JSONDecoder { return sonic.ConfigStd.NewDecoder(r) },
+//	})
+//
+// Without NewEncoder, responses are written with Marshal; without
+// NewDecoder, request bodies are read in full and passed to Unmarshal.
+func (a *App) SetJSONCodec(codec JSONCodec) *App {
+	if codec.Marshal == nil {
+		codec.Marshal = stdJSON.Marshal
+	}
+	if codec.Unmarshal == nil {
+		codec.Unmarshal = stdJSON.Unmarshal
+	}
+	if codec.NewEncoder == nil {
+		marshal := codec.Marshal
+		codec.NewEncoder = func(w io.Writer) JSONEncoder { return marshalEncoder{w, marshal} }
+	}
+	if codec.NewDecoder == nil {
+		unmarshal := codec.Unmarshal
+		codec.NewDecoder = func(r io.Reader) JSONDecoder { return unmarshalDecoder{r, unmarshal} }
+	}
+	a.json = &codec
+	return a
+}
+
+// JSONCodec returns the codec in use, so middleware can encode like
+// Context.JSON does.
+func (a *App) JSONCodec() JSONCodec {
+	if a.json == nil {
+		return stdJSON
+	}
+	return *a.json
+}
+
+// jsonCodec returns the codec of the App serving the request.
+func (c *Context) jsonCodec() *JSONCodec {
+	if c.app == nil || c.app.json == nil {
+		return &stdJSON
+	}
+	return c.app.json
+}
+
+// encodeJSON writes v followed by a newline, without HTML escaping.
+func (c *Context) encodeJSON(v any) error {
+	enc := c.jsonCodec().NewEncoder(c.Writer)
+	if e, ok := enc.(interface{ SetEscapeHTML(bool) }); ok {
+		e.SetEscapeHTML(false) // do not escape < > & by default; safer for API payloads
+	}
+	return enc.Encode(v)
+}
+
+// decodeJSON decodes the request body into dst.
+func (c *Context) decodeJSON(dst any) error {
+	if c.Request.Body == nil {
+		return errors.New("empty body")
+	}
+	defer c.Request.Body.Close()
+	return c.jsonCodec().NewDecoder(c.Request.Body).Decode(dst)
+}
+
+// marshalEncoder adapts a Marshal function to JSONEncoder.
+type marshalEncoder struct {
+	w       io.Writer
+	marshal func(any) ([]byte, error)
+}
+
+func (e marshalEncoder) Encode(v any) error {
+	b, err := e.marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(b, '\n'))
+	return err
+}
+
+// unmarshalDecoder adapts an Unmarshal function to JSONDecoder.
+type unmarshalDecoder struct {
+	r         io.Reader
+	unmarshal func([]byte, any) error
+}
+
+func (d unmarshalDecoder) Decode(v any) error {
+	b, err := io.ReadAll(d.r)
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return io.EOF
+	}
+	return d.unmarshal(b, v)
+}
@@ -0,0 +1,194 @@
+package zentrox
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Link is a single hypermedia link (HAL style).
+type Link struct {
+	Href   string `json:"href"`
+	Method string `json:"method,omitempty"`
+	Title  string `json:"title,omitempty"`
+}
+
+// Links is a `_links` object keyed by relation name. Embed it in responses:
+//
+//	type UserResponse struct {
+//	    ID    int           `json:"id"`
+//	    Links zentrox.Links `json:"_links"`
+//	}
+type Links map[string]Link
+
+// LinkBuilder assembles Links from registered route patterns so hrefs stay in
+// sync with the router. Unknown patterns or missing params are recorded and
+// reported by Err.
+type LinkBuilder struct {
+	c     *Context
+	links Links
+	err   error
+}
+
+// Links returns a builder bound to the current request.
+func (c *Context) Links() *LinkBuilder {
+	return &LinkBuilder{c: c, links: Links{}}
+}
+
+// Self adds a "self" link pointing at the current request path and query.
+func (b *LinkBuilder) Self() *LinkBuilder {
+	return b.Href("self", b.c.Request.URL.RequestURI())
+}
+
+// Href adds a link with a literal href.
+func (b *LinkBuilder) Href(rel, href string) *LinkBuilder {
+	b.links[rel] = Link{Href: href}
+	return b
+}
+
+// Add adds a link built from a route pattern, e.g.
+// Add("author", "/users/:id", "id", 42). Pairs that do not match a pattern
+// parameter are appended as query parameters.
+// The pattern must be registered on the App.
+func (b *LinkBuilder) Add(rel, pattern string, pairs ...any) *LinkBuilder {
+	if b.c.app != nil && !b.c.app.hasPattern(pattern) {
+		b.setErr(fmt.Errorf("links: route %q is not registered", pattern))
+		return b
+	}
+	href, err := BuildPath(pattern, pairs...)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	b.links[rel] = Link{Href: href}
+	return b
+}
+
+// Method sets the HTTP method hint on an existing relation.
+func (b *LinkBuilder) Method(rel, method string) *LinkBuilder {
+	if l, ok := b.links[rel]; ok {
+		l.Method = strings.ToUpper(method)
+		b.links[rel] = l
+	}
+	return b
+}
+
+// Build returns the collected links.
+func (b *LinkBuilder) Build() Links {
+	return b.links
+}
+
+// Err returns the first error encountered while adding links.
+func (b *LinkBuilder) Err() error {
+	return b.err
+}
+
+func (b *LinkBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// URLFor builds the path of the route registered with Route.Name, e.g.
+// URLFor("user.show", "id", 42) -> "/users/42". Pairs are handled as in
+// BuildPath; extra pairs become the query string.
+func (a *App) URLFor(name string, pairs ...any) (string, error) {
+	pattern, ok := a.routeNames[name]
+	if !ok {
+		return "", fmt.Errorf("zentrox: no route named %q", name)
+	}
+	return BuildPath(pattern, pairs...)
+}
+
+// URLFor is App.URLFor for the App serving the request.
+func (c *Context) URLFor(name string, pairs ...any) (string, error) {
+	if c.app == nil {
+		return "", fmt.Errorf("zentrox: no route named %q", name)
+	}
+	return c.app.URLFor(name, pairs...)
+}
+
+// RedirectToRoute redirects to the route registered under name, filling its
+// parameters from pairs as in URLFor.
+//
+//	c.RedirectToRoute(http.StatusSeeOther, "order", "id", order.ID)
+func (c *Context) RedirectToRoute(code int, name string, pairs ...any) error {
+	loc, err := c.URLFor(name, pairs...)
+	if err != nil {
+		return err
+	}
+	c.Redirect(code, loc)
+	return nil
+}
+
+// Route adds a link to the route registered under name (see Route.Name).
+func (b *LinkBuilder) Route(rel, name string, pairs ...any) *LinkBuilder {
+	href, err := b.c.URLFor(name, pairs...)
+	if err != nil {
+		b.setErr(err)
+		return b
+	}
+	b.links[rel] = Link{Href: href}
+	return b
+}
+
+// BuildPath fills a route pattern with values from key/value pairs.
+// ":name" segments are path-escaped; "*name" keeps slashes. Pairs whose keys
+// are not pattern parameters become the query string.
+func BuildPath(pattern string, pairs ...any) (string, error) {
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("build path %q: odd number of key/value arguments", pattern)
+	}
+	values := make(map[string]string, len(pairs)/2)
+	order := make([]string, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		k, ok := pairs[i].(string)
+		if !ok {
+			return "", fmt.Errorf("build path %q: key %v is not a string", pattern, pairs[i])
+		}
+		if _, dup := values[k]; !dup {
+			order = append(order, k)
+		}
+		values[k] = fmt.Sprint(pairs[i+1])
+	}
+
+	used := map[string]struct{}{}
+	var sb strings.Builder
+	for _, seg := range compilePattern(pattern) {
+		sb.WriteByte('/')
+		switch {
+		case seg.isParam:
+			v, ok := values[seg.name]
+			if !ok || v == "" {
+				return "", fmt.Errorf("build path %q: missing param %q", pattern, seg.name)
+			}
+			used[seg.name] = struct{}{}
+			sb.WriteString(url.PathEscape(v))
+		case seg.isWildcard:
+			v := strings.TrimPrefix(values[seg.name], "/")
+			used[seg.name] = struct{}{}
+			parts := strings.Split(v, "/")
+			for i, p := range parts {
+				parts[i] = url.PathEscape(p)
+			}
+			sb.WriteString(strings.Join(parts, "/"))
+		default:
+			sb.WriteString(seg.literal)
+		}
+	}
+	out := sb.String()
+	if out == "" {
+		out = "/"
+	}
+
+	q := url.Values{}
+	for _, k := range order {
+		if _, ok := used[k]; !ok {
+			q.Set(k, values[k])
+		}
+	}
+	if len(q) > 0 {
+		out += "?" + q.Encode()
+	}
+	return out, nil
+}
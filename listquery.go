@@ -0,0 +1,233 @@
+package zentrox
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterOp is a comparison operator accepted in filter[field]=op:value.
+type FilterOp string
+
+const (
+	OpEq       FilterOp = "eq"
+	OpNe       FilterOp = "ne"
+	OpGt       FilterOp = "gt"
+	OpGte      FilterOp = "gte"
+	OpLt       FilterOp = "lt"
+	OpLte      FilterOp = "lte"
+	OpIn       FilterOp = "in"
+	OpContains FilterOp = "contains"
+)
+
+var knownFilterOps = map[FilterOp]struct{}{
+	OpEq: {}, OpNe: {}, OpGt: {}, OpGte: {}, OpLt: {}, OpLte: {}, OpIn: {}, OpContains: {},
+}
+
+// FieldType describes how a filter value is parsed.
+type FieldType int
+
+const (
+	FieldString FieldType = iota
+	FieldInt
+	FieldFloat
+	FieldBool
+	FieldTime // RFC 3339
+)
+
+// FilterField declares a filterable field in the allowlist.
+type FilterField struct {
+	Type FieldType
+	// Ops restricts the accepted operators. Empty allows all operators.
+	Ops []FilterOp
+}
+
+// ListQueryConfig is the allowlist used by ParseListQuery. Fields that are not
+// listed are rejected, so values can be mapped to columns without injection risk.
+type ListQueryConfig struct {
+	SortParam   string // default "sort"
+	FilterParam string // default "filter"
+
+	// SortFields lists fields that may appear in the sort parameter.
+	SortFields []string
+	// FilterFields maps field name to its type and accepted operators.
+	FilterFields map[string]FilterField
+	// DefaultSort is used when the request has no sort parameter.
+	DefaultSort []SortField
+	// MaxSort limits the number of sort keys (default 3).
+	MaxSort int
+}
+
+// SortField is a single sort key. Desc is true for "-field".
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Filter is a single parsed predicate. Value holds the typed value
+// (string, int64, float64, bool or time.Time); for OpIn it holds []any.
+type Filter struct {
+	Field string
+	Op    FilterOp
+	Value any
+	Raw   string
+}
+
+// ListQuery is the parsed result of sort and filter parameters.
+type ListQuery struct {
+	Sort    []SortField
+	Filters []Filter
+}
+
+// ParseListQuery parses `?sort=-created_at,name&filter[status]=eq:open&filter[price]=gte:100`
+// against the allowlist in cfg. Unknown fields, operators or malformed values
+// return an HTTPError with status 400.
+func ParseListQuery(c *Context, cfg ListQueryConfig) (ListQuery, error) {
+	return parseListQuery(c.Request.URL.Query(), cfg)
+}
+
+func parseListQuery(q url.Values, cfg ListQueryConfig) (ListQuery, error) {
+	if cfg.SortParam == "" {
+		cfg.SortParam = "sort"
+	}
+	if cfg.FilterParam == "" {
+		cfg.FilterParam = "filter"
+	}
+	if cfg.MaxSort <= 0 {
+		cfg.MaxSort = 3
+	}
+
+	var out ListQuery
+
+	sortAllow := make(map[string]struct{}, len(cfg.SortFields))
+	for _, f := range cfg.SortFields {
+		sortAllow[f] = struct{}{}
+	}
+
+	if raw := strings.TrimSpace(q.Get(cfg.SortParam)); raw != "" {
+		seen := map[string]struct{}{}
+		for _, part := range strings.Split(raw, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			sf := SortField{Field: part}
+			if part[0] == '-' || part[0] == '+' {
+				sf.Desc = part[0] == '-'
+				sf.Field = part[1:]
+			}
+			if _, ok := sortAllow[sf.Field]; !ok {
+				return ListQuery{}, listQueryError("unknown sort field %q", sf.Field)
+			}
+			if _, dup := seen[sf.Field]; dup {
+				continue
+			}
+			seen[sf.Field] = struct{}{}
+			out.Sort = append(out.Sort, sf)
+		}
+		if len(out.Sort) > cfg.MaxSort {
+			return ListQuery{}, listQueryError("too many sort fields (max %d)", cfg.MaxSort)
+		}
+	}
+	if len(out.Sort) == 0 && len(cfg.DefaultSort) > 0 {
+		out.Sort = append(out.Sort, cfg.DefaultSort...)
+	}
+
+	prefix := cfg.FilterParam + "["
+	for key, vals := range q {
+		if !strings.HasPrefix(key, prefix) || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		field := key[len(prefix) : len(key)-1]
+		def, ok := cfg.FilterFields[field]
+		if !ok {
+			return ListQuery{}, listQueryError("unknown filter field %q", field)
+		}
+		for _, raw := range vals {
+			f, err := parseFilter(field, raw, def)
+			if err != nil {
+				return ListQuery{}, err
+			}
+			out.Filters = append(out.Filters, f)
+		}
+	}
+	// Map iteration order is random; keep output deterministic.
+	sort.SliceStable(out.Filters, func(i, j int) bool {
+		return out.Filters[i].Field < out.Filters[j].Field
+	})
+
+	return out, nil
+}
+
+func parseFilter(field, raw string, def FilterField) (Filter, error) {
+	op, val := OpEq, raw
+	if i := strings.IndexByte(raw, ':'); i > 0 {
+		if cand := FilterOp(strings.ToLower(raw[:i])); isKnownOp(cand) {
+			op, val = cand, raw[i+1:]
+		}
+	}
+	if len(def.Ops) > 0 {
+		allowed := false
+		for _, o := range def.Ops {
+			if o == op {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return Filter{}, listQueryError("operator %q not allowed for %q", op, field)
+		}
+	}
+
+	f := Filter{Field: field, Op: op, Raw: val}
+	if op == OpIn {
+		parts := strings.Split(val, ",")
+		list := make([]any, 0, len(parts))
+		for _, p := range parts {
+			v, err := parseFilterValue(strings.TrimSpace(p), def.Type)
+			if err != nil {
+				return Filter{}, listQueryError("invalid value for %q: %v", field, err)
+			}
+			list = append(list, v)
+		}
+		f.Value = list
+		return f, nil
+	}
+	if op == OpContains && def.Type != FieldString {
+		return Filter{}, listQueryError("operator %q requires a string field", op)
+	}
+	v, err := parseFilterValue(val, def.Type)
+	if err != nil {
+		return Filter{}, listQueryError("invalid value for %q: %v", field, err)
+	}
+	f.Value = v
+	return f, nil
+}
+
+func parseFilterValue(s string, t FieldType) (any, error) {
+	switch t {
+	case FieldInt:
+		return strconv.ParseInt(s, 10, 64)
+	case FieldFloat:
+		return strconv.ParseFloat(s, 64)
+	case FieldBool:
+		return strconv.ParseBool(s)
+	case FieldTime:
+		return time.Parse(time.RFC3339, s)
+	default:
+		return s, nil
+	}
+}
+
+func isKnownOp(op FilterOp) bool {
+	_, ok := knownFilterOps[op]
+	return ok
+}
+
+func listQueryError(format string, args ...any) error {
+	return NewHTTPError(http.StatusBadRequest, "invalid query", fmt.Sprintf(format, args...))
+}
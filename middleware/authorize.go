@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// Enforcer decides whether a request is allowed. Its signature matches
+// Casbin's (*casbin.Enforcer).Enforce, so a Casbin enforcer can be passed
+// directly; Authorize calls it with subject, object and action.
+type Enforcer interface {
+	Enforce(rvals ...any) (bool, error)
+}
+
+// EnforcerFunc adapts a function to Enforcer for custom policy engines:
+//
+//	middleware.EnforcerFunc(func(rvals ...any) (bool, error) {
+//		sub, obj, act := rvals[0].(string), rvals[1].(string), rvals[2].(string)
+//		return policy.Allowed(sub, obj, act), nil
+//	})
+type EnforcerFunc func(rvals ...any) (bool, error)
+
+func (f EnforcerFunc) Enforce(rvals ...any) (bool, error) { return f(rvals...) }
+
+// AuthorizeConfig controls Authorize.
+type AuthorizeConfig struct {
+	// ContextKey and SubjectClaim locate the subject in the JWT claims
+	// (defaults "user" and "sub").
+	ContextKey   string
+	SubjectClaim string
+	// Subject, Object and Action override how the request maps to the
+	// policy. Defaults: the subject claim, the matched route pattern (e.g.
+	// "/users/:id", or the path when no route matched) and the HTTP method.
+	// An empty subject is rejected as unauthenticated.
+	Subject func(c *zentrox.Context) string
+	Object  func(c *zentrox.Context) string
+	Action  func(c *zentrox.Context) string
+	// OnUnauthorized (default 401), OnForbidden (default 403) and OnError
+	// (default 500) write the response; the chain is aborted afterwards.
+	OnUnauthorized func(c *zentrox.Context)
+	OnForbidden    func(c *zentrox.Context)
+	OnError        func(c *zentrox.Context, err error)
+}
+
+// DefaultAuthorize returns the default AuthorizeConfig.
+func DefaultAuthorize() AuthorizeConfig {
+	def := DefaultRBAC()
+	return AuthorizeConfig{
+		ContextKey:     "user",
+		SubjectClaim:   "sub",
+		Object:         routeObject,
+		Action:         func(c *zentrox.Context) string { return c.Request.Method },
+		OnUnauthorized: def.OnUnauthorized,
+		OnForbidden:    def.OnForbidden,
+		OnError: func(c *zentrox.Context, err error) {
+			c.Logger().Error("authorize: enforcer failed", "err", err)
+			c.Fail(http.StatusInternalServerError, zentrox.MsgInternalServerError)
+		},
+	}
+}
+
+// Authorize asks e whether subject may perform action on object, centralising
+// RBAC/ABAC decisions in one policy instead of per-route role checks:
+//
+//	e, _ := casbin.NewEnforcer("model.conf", "policy.csv")
+//	api := app.Scope("/api", middleware.JWT(jwtCfg), middleware.Authorize(e))
+//
+// with a policy line such as "p, alice, /api/users/:id, GET".
+func Authorize(e Enforcer, cfg ...AuthorizeConfig) zentrox.Handler {
+	def := DefaultAuthorize()
+	conf := def
+	if len(cfg) > 0 {
+		conf = cfg[0]
+		if conf.ContextKey == "" {
+			conf.ContextKey = def.ContextKey
+		}
+		if conf.SubjectClaim == "" {
+			conf.SubjectClaim = def.SubjectClaim
+		}
+		if conf.Object == nil {
+			conf.Object = def.Object
+		}
+		if conf.Action == nil {
+			conf.Action = def.Action
+		}
+		if conf.OnUnauthorized == nil {
+			conf.OnUnauthorized = def.OnUnauthorized
+		}
+		if conf.OnForbidden == nil {
+			conf.OnForbidden = def.OnForbidden
+		}
+		if conf.OnError == nil {
+			conf.OnError = def.OnError
+		}
+	}
+	if conf.Subject == nil {
+		conf.Subject = func(c *zentrox.Context) string {
+			claims, _ := zentrox.Get[map[string]any](c, conf.ContextKey)
+			switch v := claims[conf.SubjectClaim].(type) {
+			case string:
+				return v
+			case nil:
+				return ""
+			default:
+				return fmt.Sprint(v)
+			}
+		}
+	}
+
+	return func(c *zentrox.Context) {
+		sub := conf.Subject(c)
+		if sub == "" {
+			conf.OnUnauthorized(c)
+			c.Abort()
+			return
+		}
+		ok, err := e.Enforce(sub, conf.Object(c), conf.Action(c))
+		if err != nil {
+			conf.OnError(c, err)
+			c.Abort()
+			return
+		}
+		if !ok {
+			conf.OnForbidden(c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func routeObject(c *zentrox.Context) string {
+	if p := c.RoutePath(); p != "" {
+		return p
+	}
+	return c.Request.URL.Path
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+type BulkheadConfig struct {
+	// Max is the number of requests allowed to run at the same time.
+	Max int
+	// PerRoute gives every method and route template under the bulkhead
+	// its own Max slots instead of one pool shared by all of them.
+	PerRoute bool
+	OnFull   func(*zentrox.Context)
+}
+
+func DefaultBulkhead(max int) BulkheadConfig {
+	return BulkheadConfig{
+		Max: max,
+		OnFull: func(c *zentrox.Context) {
+			c.Fail(http.StatusTooManyRequests, zentrox.MsgTooManyRequests)
+		},
+	}
+}
+
+// Bulkhead isolates an expensive route or scope by capping its concurrent
+// executions, independently of any global ConcurrencyLimit. Requests beyond
+// Max are rejected immediately with 429. Each Bulkhead call owns its own
+// slots, so attach one instance to a scope to share the cap across its
+// routes:
+//
+//	reports := api.Scope("/admin/reports", middleware.Bulkhead(middleware.DefaultBulkhead(2)))
+//
+// It is ConcurrencyLimit without queueing and with a 429 instead of a 503.
+func Bulkhead(cfg BulkheadConfig) zentrox.Handler {
+	if cfg.OnFull == nil {
+		cfg.OnFull = DefaultBulkhead(cfg.Max).OnFull
+	}
+	return ConcurrencyLimit(ConcurrencyLimitConfig{
+		MaxConcurrent: cfg.Max,
+		PerRoute:      cfg.PerRoute,
+		OnLimit:       cfg.OnFull,
+	})
+}
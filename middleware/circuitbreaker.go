@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// CircuitState is the state of one circuit.
+type CircuitState int
+
+const (
+	// CircuitClosed lets requests through and counts failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects requests until OpenTimeout has passed.
+	CircuitOpen
+	// CircuitHalfOpen lets HalfOpenRequests probes through to decide
+	// whether to close or reopen.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig configures CircuitBreaker.
+type CircuitBreakerConfig struct {
+	// KeyFunc picks the circuit for a request; default one per method and
+	// route template.
+	KeyFunc func(*zentrox.Context) string
+	// FailureRatio is the share of failed requests in Window that opens the
+	// circuit (default 0.5).
+	FailureRatio float64
+	// MinRequests is how many requests Window must hold before FailureRatio
+	// is applied (default 20).
+	MinRequests int
+	// Window is the rolling period failures are counted over (default 10s).
+	Window time.Duration
+	// OpenTimeout is how long the circuit stays open before probing
+	// (default 30s).
+	OpenTimeout time.Duration
+	// HalfOpenRequests probes must all succeed to close the circuit
+	// (default 1); one failure reopens it.
+	HalfOpenRequests int
+	// IsFailure classifies a finished request; default status >= 500.
+	// Panics always count as failures.
+	IsFailure func(*zentrox.Context) bool
+	// OnStateChange is called after each transition.
+	OnStateChange func(key string, from, to CircuitState)
+	// OnOpen renders rejected requests; the default sends 503 with
+	// Retry-After.
+	OnOpen func(c *zentrox.Context, retryAfter time.Duration)
+}
+
+// DefaultCircuitBreaker returns the default CircuitBreakerConfig.
+func DefaultCircuitBreaker() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		KeyFunc:          func(c *zentrox.Context) string { return c.Request.Method + " " + RouteLabel(c) },
+		FailureRatio:     0.5,
+		MinRequests:      20,
+		Window:           10 * time.Second,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenRequests: 1,
+		IsFailure:        func(c *zentrox.Context) bool { return responseStatus(c) >= 500 },
+		OnOpen: func(c *zentrox.Context, retryAfter time.Duration) {
+			c.SetHeader(zentrox.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.Fail(http.StatusServiceUnavailable, zentrox.MsgServiceUnavailable)
+		},
+	}
+}
+
+// CircuitBreaker fast-fails requests to a route whose recent requests mostly
+// failed, giving a struggling dependency room to recover instead of piling
+// on. Failures are counted per circuit (per route by default) over a
+// rolling Window; past FailureRatio the circuit opens and requests get 503
+// with Retry-After for OpenTimeout, after which probes decide whether it
+// closes again:
+//
+//	app.Plug(middleware.CircuitBreaker(middleware.CircuitBreakerConfig{
+//		OnStateChange: func(key string, from, to middleware.CircuitState) {
+//			slog.Warn("circuit", "route", key, "from", from, "to", to)
+//		},
+//	}))
+func CircuitBreaker(cfg CircuitBreakerConfig) zentrox.Handler {
+	def := DefaultCircuitBreaker()
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = def.KeyFunc
+	}
+	if cfg.FailureRatio <= 0 {
+		cfg.FailureRatio = def.FailureRatio
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = def.MinRequests
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = def.OpenTimeout
+	}
+	if cfg.HalfOpenRequests <= 0 {
+		cfg.HalfOpenRequests = def.HalfOpenRequests
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = def.IsFailure
+	}
+	if cfg.OnOpen == nil {
+		cfg.OnOpen = def.OnOpen
+	}
+
+	var circuits sync.Map // key -> *circuit
+
+	return func(c *zentrox.Context) {
+		key := cfg.KeyFunc(c)
+		v, ok := circuits.Load(key)
+		if !ok {
+			v, _ = circuits.LoadOrStore(key, &circuit{})
+		}
+		cb := v.(*circuit)
+
+		gen, retryAfter, ok := cb.allow(&cfg, key, time.Now())
+		if !ok {
+			cfg.OnOpen(c, retryAfter)
+			c.Abort()
+			return
+		}
+		panicked := true
+		defer func() {
+			if panicked {
+				cb.record(&cfg, key, gen, true, time.Now())
+			}
+		}()
+		c.Next()
+		panicked = false
+		cb.record(&cfg, key, gen, cfg.IsFailure(c), time.Now())
+	}
+}
+
+// circuitBuckets is the number of slices Window is counted in.
+const circuitBuckets = 10
+
+type circuit struct {
+	mu       sync.Mutex
+	state    CircuitState
+	gen      uint64 // bumped on each transition; stale outcomes are ignored
+	openedAt time.Time
+	buckets  [circuitBuckets]struct {
+		slot          int64
+		total, failed int
+	}
+	probes, passed int
+}
+
+// allow reports whether a request may proceed, returning the generation to
+// record its outcome against.
+func (cb *circuit) allow(cfg *CircuitBreakerConfig, key string, now time.Time) (uint64, time.Duration, bool) {
+	cb.mu.Lock()
+	from := cb.state
+	changed := false
+	if cb.state == CircuitOpen {
+		if wait := cb.openedAt.Add(cfg.OpenTimeout).Sub(now); wait > 0 {
+			cb.mu.Unlock()
+			return 0, wait, false
+		}
+		cb.transition(CircuitHalfOpen, now)
+		changed = true
+	}
+	to := cb.state
+	if cb.state == CircuitHalfOpen {
+		if cb.probes >= cfg.HalfOpenRequests {
+			cb.mu.Unlock()
+			notifyCircuit(cfg, key, from, to, changed)
+			return 0, time.Second, false
+		}
+		cb.probes++
+	}
+	gen := cb.gen
+	cb.mu.Unlock()
+	notifyCircuit(cfg, key, from, to, changed)
+	return gen, 0, true
+}
+
+// record counts the outcome of a request admitted in generation gen.
+func (cb *circuit) record(cfg *CircuitBreakerConfig, key string, gen uint64, failed bool, now time.Time) {
+	cb.mu.Lock()
+	if gen != cb.gen {
+		cb.mu.Unlock()
+		return
+	}
+	from := cb.state
+	changed := false
+	switch cb.state {
+	case CircuitHalfOpen:
+		if failed {
+			cb.transition(CircuitOpen, now)
+			changed = true
+		} else if cb.passed++; cb.passed >= cfg.HalfOpenRequests {
+			cb.transition(CircuitClosed, now)
+			changed = true
+		}
+	case CircuitClosed:
+		width := cfg.Window / circuitBuckets
+		slot := now.UnixNano() / int64(max(width, 1))
+		b := &cb.buckets[slot%circuitBuckets]
+		if b.slot != slot {
+			b.slot, b.total, b.failed = slot, 0, 0
+		}
+		b.total++
+		if failed {
+			b.failed++
+		}
+		total, fails := 0, 0
+		for _, b := range cb.buckets {
+			if slot-b.slot < circuitBuckets {
+				total += b.total
+				fails += b.failed
+			}
+		}
+		if total >= cfg.MinRequests && float64(fails) >= cfg.FailureRatio*float64(total) {
+			cb.transition(CircuitOpen, now)
+			changed = true
+		}
+	}
+	to := cb.state
+	cb.mu.Unlock()
+	notifyCircuit(cfg, key, from, to, changed)
+}
+
+// transition moves to state; cb.mu must be held.
+func (cb *circuit) transition(state CircuitState, now time.Time) {
+	cb.state = state
+	cb.gen++
+	cb.probes, cb.passed = 0, 0
+	switch state {
+	case CircuitOpen:
+		cb.openedAt = now
+	case CircuitClosed:
+		for i := range cb.buckets {
+			cb.buckets[i].total, cb.buckets[i].failed = 0, 0
+		}
+	}
+}
+
+// notifyCircuit calls OnStateChange, outside the circuit lock.
+func notifyCircuit(cfg *CircuitBreakerConfig, key string, from, to CircuitState, changed bool) {
+	if changed && cfg.OnStateChange != nil {
+		cfg.OnStateChange(key, from, to)
+	}
+}
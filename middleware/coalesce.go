@@ -0,0 +1,198 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// CoalesceConfig configures Coalesce.
+type CoalesceConfig struct {
+	// KeyFunc identifies identical requests; the default combines the host,
+	// the path, the query with its parameters sorted, and the VaryHeaders.
+	KeyFunc func(*zentrox.Context) string
+	// VaryHeaders are request headers that must also match for two
+	// requests to share a response (default Authorization, Cookie, Accept
+	// and Accept-Encoding), so users never receive each other's data.
+	VaryHeaders []string
+	// MaxSize is the largest response shared with waiting requests (default
+	// 1 MiB); past it they run the handler themselves.
+	MaxSize int
+}
+
+// DefaultCoalesce returns the default CoalesceConfig.
+func DefaultCoalesce() CoalesceConfig {
+	return CoalesceConfig{
+		VaryHeaders: []string{
+			zentrox.HeaderAuthorization,
+			zentrox.HeaderCookie,
+			zentrox.HeaderAccept,
+			zentrox.HeaderAcceptEncoding,
+		},
+		MaxSize: 1 << 20,
+	}
+}
+
+// Coalesce collapses concurrent identical GET requests into one handler
+// execution: the first runs, the others wait for it and receive a copy of
+// its response. It keeps a burst of cache misses on a hot endpoint from
+// all hitting the database:
+//
+//	app.GET("/products/:id", middleware.Coalesce(), getProduct)
+//
+// Only requests in flight at the same time are merged; nothing is cached
+// afterwards. Responses that set cookies, stream (Flush) or exceed MaxSize
+// are not shared, and waiting requests then run the handler themselves.
+func Coalesce(cfg ...CoalesceConfig) zentrox.Handler {
+	def := DefaultCoalesce()
+	conf := def
+	if len(cfg) > 0 {
+		conf = cfg[0]
+		if conf.VaryHeaders == nil {
+			conf.VaryHeaders = def.VaryHeaders
+		}
+		if conf.MaxSize <= 0 {
+			conf.MaxSize = def.MaxSize
+		}
+	}
+	if conf.KeyFunc == nil {
+		conf.KeyFunc = func(c *zentrox.Context) string { return coalesceKey(c, conf.VaryHeaders) }
+	}
+
+	var (
+		mu      sync.Mutex
+		flights = map[string]*flight{}
+	)
+
+	return func(c *zentrox.Context) {
+		if c.Request.Method != http.MethodGet || c.GetHeader(zentrox.HeaderUpgrade) != "" {
+			c.Next()
+			return
+		}
+		key := conf.KeyFunc(c)
+		mu.Lock()
+		f, waiting := flights[key]
+		if !waiting {
+			f = &flight{done: make(chan struct{})}
+			flights[key] = f
+		}
+		mu.Unlock()
+
+		if waiting {
+			select {
+			case <-f.done:
+			case <-c.Done():
+				c.Abort()
+				return
+			}
+			if !f.shared {
+				c.Next()
+				return
+			}
+			h := c.Writer.Header()
+			for k, v := range f.header {
+				h[k] = append([]string(nil), v...)
+			}
+			c.Writer.WriteHeader(f.status)
+			_, _ = c.Writer.Write(f.body)
+			c.Abort()
+			return
+		}
+
+		orig := c.Writer
+		rw := &coalesceRW{ResponseWriter: orig, max: conf.MaxSize}
+		c.Writer = rw
+		finished := false
+		defer func() {
+			c.Writer = orig
+			mu.Lock()
+			delete(flights, key)
+			mu.Unlock()
+			// A panicking handler shares nothing; waiters retry on their own.
+			if finished && rw.shareable() {
+				f.status, f.header, f.body, f.shared = rw.status, rw.header, rw.buf.Bytes(), true
+			}
+			close(f.done)
+		}()
+		c.Next()
+		finished = true
+	}
+}
+
+// flight is one handler execution shared by identical requests.
+type flight struct {
+	done   chan struct{}
+	shared bool
+	status int
+	header http.Header
+	body   []byte
+}
+
+// coalesceKey builds the default key: host, path, sorted query and vary
+// headers. The host keeps host-routed apps from sharing one response across
+// tenants that serve the same path.
+func coalesceKey(c *zentrox.Context, vary []string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(c.Request.Host))
+	b.WriteString(c.Request.URL.Path)
+	b.WriteByte('?')
+	b.WriteString(c.Request.URL.Query().Encode())
+	for _, h := range vary {
+		b.WriteByte(0)
+		b.WriteString(strings.Join(c.Request.Header.Values(h), ","))
+	}
+	return b.String()
+}
+
+// coalesceRW passes the response through while keeping a copy for waiters.
+type coalesceRW struct {
+	http.ResponseWriter
+	max int
+
+	status   int
+	header   http.Header
+	buf      bytes.Buffer
+	overflow bool
+	streamed bool
+}
+
+func (w *coalesceRW) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+		w.header = w.Header().Clone()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *coalesceRW) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.overflow {
+		if w.buf.Len()+len(p) > w.max {
+			w.overflow = true
+			w.buf = bytes.Buffer{}
+		} else {
+			w.buf.Write(p)
+		}
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *coalesceRW) Flush() {
+	w.streamed = true
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *coalesceRW) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *coalesceRW) shareable() bool {
+	return w.status >= 200 && !w.overflow && !w.streamed && len(w.header.Values(zentrox.HeaderSetCookie)) == 0
+}
@@ -0,0 +1,385 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// DefaultLevel asks an encoder for its library's default compression level.
+const DefaultLevel = -1
+
+// CompressWriter is a resettable compressing writer. *gzip.Writer,
+// *flate.Writer, *brotli.Writer and *zstd.Encoder all satisfy it.
+type CompressWriter interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// EncoderFunc creates a writer for one content coding. level is the value
+// from CompressConfig.Levels, or DefaultLevel when none is configured.
+type EncoderFunc func(level int) (CompressWriter, error)
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]EncoderFunc{
+		"gzip": func(level int) (CompressWriter, error) {
+			return gzip.NewWriterLevel(io.Discard, level)
+		},
+		"deflate": func(level int) (CompressWriter, error) {
+			return flate.NewWriter(io.Discard, level)
+		},
+		"br": func(level int) (CompressWriter, error) {
+			if level == DefaultLevel {
+				level = brotli.DefaultCompression
+			}
+			return brotli.NewWriterLevel(io.Discard, level), nil
+		},
+		"zstd": func(level int) (CompressWriter, error) {
+			opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+			if level != DefaultLevel {
+				opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+			}
+			return zstd.NewWriter(io.Discard, opts...)
+		},
+	}
+)
+
+// RegisterEncoder makes a content coding available to Compress, or
+// replaces a built-in one. zstd, br, gzip and deflate are built in; Levels
+// use each library's scale (zstd levels go through
+// zstd.EncoderLevelFromZstd).
+func RegisterEncoder(name string, fn EncoderFunc) {
+	encodersMu.Lock()
+	encoders[strings.ToLower(name)] = fn
+	encodersMu.Unlock()
+}
+
+func lookupEncoder(name string) EncoderFunc {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+	return encoders[name]
+}
+
+// CompressConfig configures Compress.
+type CompressConfig struct {
+	// Encodings lists the content codings to offer in server preference
+	// order, used to break ties between equally weighted client choices.
+	// Compress panics on codings with no registered encoder.
+	Encodings []string
+	// Levels sets the compression level per coding, e.g. {"gzip": 6}.
+	Levels map[string]int
+	// MinSize is the minimum response size to compress (default 512).
+	MinSize int
+	// IncludeTypes, when set, restricts compression to Content-Types with
+	// one of these prefixes.
+	IncludeTypes []string
+	// ExcludeTypes skips Content-Types with one of these prefixes.
+	ExcludeTypes []string
+	// SkipIf skips compression when it returns true. It runs once the
+	// response headers are known.
+	SkipIf func(*zentrox.Context) bool
+}
+
+func DefaultCompress() CompressConfig {
+	return CompressConfig{
+		Encodings:    []string{"zstd", "br", "gzip", "deflate"},
+		MinSize:      512,
+		ExcludeTypes: []string{"image/", "video/", "audio/", "application/zip", "application/gzip"},
+		SkipIf:       skipStreams,
+	}
+}
+
+// skipStreams skips SSE and websocket upgrades.
+func skipStreams(c *zentrox.Context) bool {
+	ct := c.Writer.Header().Get(zentrox.HeaderContentType)
+	if strings.HasPrefix(ct, zentrox.ContentTypeEventStream) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(c.GetHeader(zentrox.HeaderConnection)), "upgrade")
+}
+
+// Compress negotiates a content coding from Accept-Encoding (honouring
+// q-values) among the configured Encodings and compresses responses of at
+// least MinSize bytes. Writers are pooled per coding and level. It panics if
+// an entry in Encodings has no registered encoder.
+func Compress(cfg CompressConfig) zentrox.Handler {
+	def := DefaultCompress()
+	if cfg.Encodings == nil {
+		cfg.Encodings = def.Encodings
+	}
+	if cfg.MinSize <= 0 {
+		cfg.MinSize = def.MinSize
+	}
+	if cfg.ExcludeTypes == nil {
+		cfg.ExcludeTypes = def.ExcludeTypes
+	}
+	if cfg.SkipIf == nil {
+		cfg.SkipIf = def.SkipIf
+	}
+
+	var offers []string
+	pools := make(map[string]*sync.Pool)
+	for _, name := range cfg.Encodings {
+		name = strings.ToLower(name)
+		fn := lookupEncoder(name)
+		if fn == nil {
+			panic("middleware: Compress: no encoder registered for " + strconv.Quote(name))
+		}
+		if pools[name] != nil {
+			continue
+		}
+		level, ok := cfg.Levels[name]
+		if !ok {
+			level = DefaultLevel
+		}
+		offers = append(offers, name)
+		pools[name] = writerPool(name, level, fn)
+	}
+
+	return func(c *zentrox.Context) {
+		if c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		enc := negotiateEncoding(c.GetHeader(zentrox.HeaderAcceptEncoding), offers)
+		if enc == "" || strings.Contains(strings.ToLower(c.GetHeader(zentrox.HeaderConnection)), "upgrade") {
+			c.Next()
+			return
+		}
+
+		orig := c.Writer
+		rw := compressRWPool.Get().(*compressRW)
+		rw.ResponseWriter = orig
+		rw.ctx = c
+		rw.cfg = &cfg
+		rw.encoding = enc
+		rw.pool = pools[enc]
+		c.Writer = rw
+
+		c.Next()
+
+		rw.finish()
+		// Restore the original writer so upstream middleware sees its
+		// status, and recycle the wrapper.
+		c.Writer = orig
+		rw.reset()
+		compressRWPool.Put(rw)
+	}
+}
+
+// writerPools shares pools between middleware instances with the same
+// coding and level.
+var writerPools sync.Map // map[string]*sync.Pool
+
+func writerPool(name string, level int, fn EncoderFunc) *sync.Pool {
+	key := name + ":" + strconv.Itoa(level)
+	if p, ok := writerPools.Load(key); ok {
+		return p.(*sync.Pool)
+	}
+	p, _ := writerPools.LoadOrStore(key, &sync.Pool{
+		New: func() any {
+			w, err := fn(level)
+			if err != nil {
+				return nil
+			}
+			return w
+		},
+	})
+	return p.(*sync.Pool)
+}
+
+// negotiateEncoding picks the offer with the highest q-value; ties go to the
+// earlier offer. "*" covers offers not listed explicitly.
+func negotiateEncoding(header string, offers []string) string {
+	if header == "" || len(offers) == 0 {
+		return ""
+	}
+	qs := make(map[string]float64, 4)
+	star := -1.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if name == "*" {
+			star = q
+		} else if name != "" {
+			qs[name] = q
+		}
+	}
+	best, bestQ := "", 0.0
+	for _, o := range offers {
+		q, ok := qs[o]
+		if !ok {
+			q = star
+		}
+		if q > bestQ {
+			best, bestQ = o, q
+		}
+	}
+	return best
+}
+
+// compressRWPool recycles response wrappers together with their buffers.
+var compressRWPool = sync.Pool{
+	New: func() any { return new(compressRW) },
+}
+
+// maxPooledCompressBuf caps the buffer capacity kept in compressRWPool.
+const maxPooledCompressBuf = 64 << 10
+
+// compressRW buffers until either MinSize is reached or finish() is called.
+// Then it decides whether to compress and writes headers/body appropriately.
+type compressRW struct {
+	http.ResponseWriter
+	ctx      *zentrox.Context
+	cfg      *CompressConfig
+	encoding string
+	pool     *sync.Pool
+
+	buf         bytes.Buffer
+	decided     bool
+	cw          CompressWriter
+	status      int
+	wroteHeader bool
+}
+
+func (g *compressRW) WriteHeader(code int) {
+	g.status = code
+	g.wroteHeader = true
+	// Defer actually writing until we decide (to be able to set/remove headers properly).
+}
+
+func (g *compressRW) shouldCompress() bool {
+	if g.cfg.SkipIf != nil && g.cfg.SkipIf(g.ctx) {
+		return false
+	}
+	if g.status == http.StatusNoContent || g.status == http.StatusNotModified {
+		return false
+	}
+	h := g.Header()
+	if h.Get(zentrox.HeaderContentEncoding) != "" {
+		return false
+	}
+	ct := h.Get(zentrox.HeaderContentType)
+	hasPrefix := func(pre string) bool { return pre != "" && strings.HasPrefix(ct, pre) }
+	if slices.ContainsFunc(g.cfg.ExcludeTypes, hasPrefix) {
+		return false
+	}
+	if len(g.cfg.IncludeTypes) > 0 && !slices.ContainsFunc(g.cfg.IncludeTypes, hasPrefix) {
+		return false
+	}
+	return true
+}
+
+func (g *compressRW) maybeDecide(start bool) {
+	if g.decided {
+		return
+	}
+	g.decided = true
+
+	if start && g.shouldCompress() {
+		if cw, _ := g.pool.Get().(CompressWriter); cw != nil {
+			cw.Reset(g.ResponseWriter)
+			g.cw = cw
+			h := g.Header()
+			h.Del(zentrox.HeaderContentLength)
+			h.Set(zentrox.HeaderContentEncoding, g.encoding)
+			h.Add(zentrox.HeaderVary, zentrox.HeaderAcceptEncoding)
+		}
+	}
+
+	if !g.wroteHeader {
+		g.status = http.StatusOK
+		g.wroteHeader = true
+	}
+	g.ResponseWriter.WriteHeader(g.status)
+	if g.buf.Len() > 0 {
+		if g.cw != nil {
+			_, _ = g.cw.Write(g.buf.Bytes())
+		} else {
+			_, _ = g.ResponseWriter.Write(g.buf.Bytes())
+		}
+		g.buf.Reset()
+	}
+}
+
+func (g *compressRW) Write(p []byte) (int, error) {
+	// Buffer until threshold; decide thereafter. The write that crosses the
+	// threshold goes straight to the destination, so the buffer never holds
+	// more than MinSize bytes.
+	if !g.decided {
+		if g.buf.Len()+len(p) < g.cfg.MinSize {
+			g.buf.Write(p)
+			return len(p), nil
+		}
+		g.maybeDecide(true)
+	}
+	if g.cw != nil {
+		return g.cw.Write(p)
+	}
+	return g.ResponseWriter.Write(p)
+}
+
+func (g *compressRW) Flush() {
+	if !g.decided {
+		g.maybeDecide(false)
+	}
+	if g.cw != nil {
+		_ = g.cw.Flush()
+	}
+	if f, ok := g.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (g *compressRW) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+// finish is called by the middleware after the downstream handlers completed.
+func (g *compressRW) finish() {
+	if !g.decided {
+		// If nobody wrote enough, don't compress and flush the buffer plain.
+		g.maybeDecide(false)
+	}
+	if g.cw != nil {
+		_ = g.cw.Close()
+		g.pool.Put(g.cw)
+		g.cw = nil
+	}
+}
+
+// reset clears g for reuse from compressRWPool.
+func (g *compressRW) reset() {
+	g.ResponseWriter = nil
+	g.ctx = nil
+	g.cfg = nil
+	g.encoding = ""
+	g.pool = nil
+	if g.buf.Cap() > maxPooledCompressBuf {
+		g.buf = bytes.Buffer{}
+	}
+	g.buf.Reset()
+	g.decided = false
+	g.cw = nil
+	g.status = 0
+	g.wroteHeader = false
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 	"runtime"
+	"sync"
 	"time"
 
 	"github.com/aminofox/zentrox/v2"
@@ -11,7 +12,10 @@ import (
 type ConcurrencyLimitConfig struct {
 	MaxConcurrent int
 	QueueTimeout  time.Duration
-	OnLimit       func(*zentrox.Context)
+	// PerRoute gives every method and route template its own MaxConcurrent
+	// slots instead of one pool shared by all requests.
+	PerRoute bool
+	OnLimit  func(*zentrox.Context)
 }
 
 func DefaultConcurrencyLimit() ConcurrencyLimitConfig {
@@ -28,6 +32,19 @@ func DefaultConcurrencyLimit() ConcurrencyLimitConfig {
 	}
 }
 
+// MaxConcurrent sheds load once n requests are in flight: further requests
+// wait up to queueTimeout for a slot, then get 503. Plug it globally, or
+// attach it to a route or scope to bound only those:
+//
+//	app.Plug(middleware.MaxConcurrent(512, 50*time.Millisecond))
+//	app.GET("/search", middleware.MaxConcurrent(8, 0), search)
+//
+// It is shorthand for ConcurrencyLimit; use PerRoute there for one limit per
+// route from a single global middleware.
+func MaxConcurrent(n int, queueTimeout time.Duration) zentrox.Handler {
+	return ConcurrencyLimit(ConcurrencyLimitConfig{MaxConcurrent: n, QueueTimeout: queueTimeout})
+}
+
 func ConcurrencyLimit(cfg ConcurrencyLimitConfig) zentrox.Handler {
 	if cfg.MaxConcurrent <= 0 {
 		return func(c *zentrox.Context) { c.Next() }
@@ -39,8 +56,19 @@ func ConcurrencyLimit(cfg ConcurrencyLimitConfig) zentrox.Handler {
 	}
 
 	sem := make(chan struct{}, cfg.MaxConcurrent)
+	var routes sync.Map // method + route -> chan struct{}
 
 	return func(c *zentrox.Context) {
+		sem := sem
+		if cfg.PerRoute {
+			key := c.Request.Method + " " + RouteLabel(c)
+			v, ok := routes.Load(key)
+			if !ok {
+				v, _ = routes.LoadOrStore(key, make(chan struct{}, cfg.MaxConcurrent))
+			}
+			sem = v.(chan struct{})
+		}
+
 		if cfg.QueueTimeout <= 0 {
 			select {
 			case sem <- struct{}{}:
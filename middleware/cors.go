@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -9,8 +10,19 @@ import (
 )
 
 type CORSConfig struct {
-	AllowOrigins     []string
-	AllowMethods     []string
+	// AllowOrigins lists allowed origins: "*" for any, exact origins such as
+	// "https://app.example.com", or subdomain patterns such as
+	// "https://*.example.com" (which does not match the bare domain).
+	AllowOrigins []string
+	// AllowOriginRegexps allows origins matching any of these expressions.
+	AllowOriginRegexps []*regexp.Regexp
+	// AllowOriginFunc allows an origin when it returns true, in addition to
+	// the lists above, e.g. to look tenants up in a database.
+	AllowOriginFunc func(origin string) bool
+	AllowMethods    []string
+	// AllowHeaders lists headers allowed in requests; "*" allows any. With
+	// credentials, browsers treat "*" literally, so the preflight's
+	// requested headers (and method) are echoed instead.
 	AllowHeaders     []string
 	ExposeHeaders    []string
 	AllowCredentials bool
@@ -33,61 +45,111 @@ func CORS(cfg CORSConfig) zentrox.Handler {
 	allowHeaders := strings.Join(cfg.AllowHeaders, ", ")
 	exposeHeaders := strings.Join(cfg.ExposeHeaders, ", ")
 	maxAge := strconv.Itoa(cfg.MaxAge)
+	echoHeaders := cfg.AllowCredentials && containsStar(cfg.AllowHeaders)
+	echoMethods := cfg.AllowCredentials && containsStar(cfg.AllowMethods)
 
 	allowMap := make(map[string]bool)
 	hasWildcard := false
+	var patterns [][2]string // scheme://, .domain suffix
 	for _, o := range cfg.AllowOrigins {
 		if o == "*" {
 			hasWildcard = true
+			continue
 		}
-		allowMap[o] = true
+		if scheme, rest, ok := strings.Cut(o, "://*."); ok {
+			patterns = append(patterns, [2]string{strings.ToLower(scheme) + "://", "." + strings.ToLower(rest)})
+			continue
+		}
+		allowMap[strings.ToLower(o)] = true
+	}
+	allowed := func(origin string) bool {
+		if allowMap[strings.ToLower(origin)] {
+			return true
+		}
+		lower := strings.ToLower(origin)
+		for _, p := range patterns {
+			if host, ok := strings.CutPrefix(lower, p[0]); ok && strings.HasSuffix(host, p[1]) &&
+				len(host) > len(p[1]) && !strings.ContainsAny(host, "/@") {
+				return true
+			}
+		}
+		for _, re := range cfg.AllowOriginRegexps {
+			if re.MatchString(origin) {
+				return true
+			}
+		}
+		return cfg.AllowOriginFunc != nil && cfg.AllowOriginFunc(origin)
 	}
 
 	return func(c *zentrox.Context) {
 		origin := c.GetHeader(zentrox.HeaderOrigin)
 		h := c.Writer.Header()
-
-		if origin == "" {
-			origin = "*"
-		}
+		h.Add(zentrox.HeaderVary, zentrox.HeaderOrigin)
+		preflight := c.Request.Method == http.MethodOptions
 
 		acao := ""
-		if hasWildcard && !cfg.AllowCredentials {
+		switch {
+		case hasWildcard && !cfg.AllowCredentials:
 			acao = "*"
-		} else if allowMap[origin] {
-			acao = origin
-		} else if hasWildcard {
+		case origin == "":
+		case hasWildcard || allowed(origin):
 			acao = origin
 		}
-
-		if acao != "" {
-			h.Set(zentrox.HeaderAccessControlAllowOrigin, acao)
+		if acao == "" {
+			// Not a CORS request, or a disallowed origin: no CORS headers,
+			// so the browser blocks the response.
+			if preflight {
+				c.SendStatus(http.StatusNoContent)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
 		}
 
-		if allowMethods != "" {
-			h.Set(zentrox.HeaderAccessControlAllowMethods, allowMethods)
-		}
-		if allowHeaders != "" {
-			h.Set(zentrox.HeaderAccessControlAllowHeaders, allowHeaders)
-		}
+		h.Set(zentrox.HeaderAccessControlAllowOrigin, acao)
 		if exposeHeaders != "" {
 			h.Set(zentrox.HeaderAccessControlExposeHeaders, exposeHeaders)
 		}
 		if cfg.AllowCredentials {
 			h.Set(zentrox.HeaderAccessControlAllowCredentials, "true")
 		}
+
+		if !preflight {
+			c.Next()
+			return
+		}
+
+		methods, headers := allowMethods, allowHeaders
+		if echoMethods {
+			h.Add(zentrox.HeaderVary, zentrox.HeaderAccessControlRequestMethod)
+			if m := c.GetHeader(zentrox.HeaderAccessControlRequestMethod); m != "" {
+				methods = m
+			}
+		}
+		if echoHeaders {
+			h.Add(zentrox.HeaderVary, zentrox.HeaderAccessControlRequestHeaders)
+			headers = c.GetHeader(zentrox.HeaderAccessControlRequestHeaders)
+		}
+		if methods != "" {
+			h.Set(zentrox.HeaderAccessControlAllowMethods, methods)
+		}
+		if headers != "" {
+			h.Set(zentrox.HeaderAccessControlAllowHeaders, headers)
+		}
 		if cfg.MaxAge > 0 {
 			h.Set(zentrox.HeaderAccessControlMaxAge, maxAge)
 		}
+		c.SendStatus(http.StatusNoContent)
+		c.Abort()
+	}
+}
 
-		h.Add(zentrox.HeaderVary, zentrox.HeaderOrigin)
-
-		if c.Request.Method == http.MethodOptions {
-			c.SendStatus(http.StatusNoContent)
-			c.Abort()
-			return
+func containsStar(list []string) bool {
+	for _, s := range list {
+		if s == "*" {
+			return true
 		}
-
-		c.Next()
 	}
+	return false
 }
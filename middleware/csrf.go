@@ -0,0 +1,220 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+const csrfTokenLen = 32
+
+// CSRFStorage keeps the expected token for a client. The default stores it
+// in a cookie (double-submit cookie); session.CSRFStorage keeps it in the
+// server-side session (synchronizer token).
+type CSRFStorage interface {
+	Get(c *zentrox.Context) string
+	Set(c *zentrox.Context, token string)
+}
+
+// CSRFConfig configures CSRF.
+type CSRFConfig struct {
+	// Storage holds the expected token (default: a cookie, see Cookie*).
+	Storage CSRFStorage
+
+	// CookieName defaults to "__Host-csrf", or "_csrf" when CookieDomain
+	// or a CookiePath other than "/" is set. A "__Host-" cookie is always
+	// Secure, host-only and scoped to "/", so sibling subdomains and
+	// plain-HTTP responses cannot plant it.
+	CookieName   string
+	CookiePath   string // default "/"
+	CookieDomain string
+	CookieMaxAge time.Duration // default 12h
+	Secure       bool
+	// CookieHTTPOnly hides the cookie from scripts. Leave it false when a
+	// SPA reads the cookie to echo it in HeaderName.
+	CookieHTTPOnly bool
+	SameSite       http.SameSite // default Lax
+
+	// HeaderName and FormField are where unsafe requests carry the token.
+	HeaderName string // default X-CSRF-Token
+	FormField  string // default "_csrf"
+	ContextKey string // default zentrox.CSRFToken, read by c.CSRFToken()
+
+	// ExemptPaths skips validation for paths with these prefixes, e.g.
+	// "/api/" for token-authenticated endpoints that browsers never call
+	// with ambient cookies.
+	ExemptPaths []string
+	// Skip exempts requests for which it returns true.
+	Skip     func(*zentrox.Context) bool
+	OnReject func(c *zentrox.Context, reason string)
+}
+
+func DefaultCSRF() CSRFConfig {
+	return CSRFConfig{
+		CookieName:   csrfHostCookie,
+		CookiePath:   "/",
+		CookieMaxAge: 12 * time.Hour,
+		Secure:       true,
+		SameSite:     http.SameSiteLaxMode,
+		HeaderName:   zentrox.HeaderXCSRFToken,
+		FormField:    "_csrf",
+		ContextKey:   zentrox.CSRFToken,
+		OnReject: func(c *zentrox.Context, reason string) {
+			c.Fail(http.StatusForbidden, reason)
+		},
+	}
+}
+
+// CSRF issues a per-client token and requires it on unsafe requests (POST,
+// PUT, PATCH, DELETE, ...) in HeaderName or the FormField form value. Safe
+// methods (GET, HEAD, OPTIONS, TRACE) only receive the token. Handlers and
+// templates read it with c.CSRFToken(); the value is masked per request so
+// it does not leak through compressed responses (BREACH).
+func CSRF(cfg CSRFConfig) zentrox.Handler {
+	def := DefaultCSRF()
+	if cfg.CookieName == "" {
+		cfg.CookieName = def.CookieName
+		if cfg.CookieDomain != "" || (cfg.CookiePath != "" && cfg.CookiePath != "/") {
+			cfg.CookieName = "_csrf"
+		}
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = def.CookiePath
+	}
+	if strings.HasPrefix(cfg.CookieName, "__Host-") {
+		if cfg.CookieDomain != "" || cfg.CookiePath != "/" {
+			panic("middleware: CSRF: a __Host- cookie cannot set CookieDomain or a CookiePath other than /")
+		}
+		cfg.Secure = true
+	}
+	if cfg.CookieMaxAge <= 0 {
+		cfg.CookieMaxAge = def.CookieMaxAge
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = def.SameSite
+	}
+	if cfg.HeaderName == "" {
+		cfg.HeaderName = def.HeaderName
+	}
+	if cfg.FormField == "" {
+		cfg.FormField = def.FormField
+	}
+	if cfg.ContextKey == "" {
+		cfg.ContextKey = def.ContextKey
+	}
+	if cfg.OnReject == nil {
+		cfg.OnReject = def.OnReject
+	}
+	if cfg.Storage == nil {
+		cfg.Storage = csrfCookie{cfg: &cfg}
+	}
+
+	return func(c *zentrox.Context) {
+		if cfg.Skip != nil && cfg.Skip(c) {
+			c.Next()
+			return
+		}
+		path := c.Request.URL.Path
+		for _, p := range cfg.ExemptPaths {
+			if strings.HasPrefix(path, p) {
+				c.Next()
+				return
+			}
+		}
+
+		token := decodeCSRF(cfg.Storage.Get(c))
+		if len(token) != csrfTokenLen {
+			token = make([]byte, csrfTokenLen)
+			_, _ = rand.Read(token)
+			cfg.Storage.Set(c, base64.RawURLEncoding.EncodeToString(token))
+		}
+		c.Set(cfg.ContextKey, maskCSRF(token))
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			c.Next()
+			return
+		}
+		sent := c.GetHeader(cfg.HeaderName)
+		if sent == "" {
+			sent = c.Request.PostFormValue(cfg.FormField)
+		}
+		if !csrfMatches(token, sent) {
+			cfg.OnReject(c, zentrox.MsgInvalidCSRFToken)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// csrfHostCookie is the default cookie name; see CSRFConfig.CookieName.
+const csrfHostCookie = "__Host-csrf"
+
+// csrfCookie is the double-submit storage. A cross-site page cannot read the
+// cookie, but a sibling subdomain or a plain-HTTP response can set it unless
+// it carries the "__Host-" prefix, which is why that is the default; use
+// session.CSRFStorage where that is not possible.
+type csrfCookie struct{ cfg *CSRFConfig }
+
+func (s csrfCookie) Get(c *zentrox.Context) string {
+	ck, err := c.Request.Cookie(s.cfg.CookieName)
+	if err != nil {
+		return ""
+	}
+	return ck.Value
+}
+
+func (s csrfCookie) Set(c *zentrox.Context, token string) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     s.cfg.CookieName,
+		Value:    token,
+		Path:     s.cfg.CookiePath,
+		Domain:   s.cfg.CookieDomain,
+		MaxAge:   int(s.cfg.CookieMaxAge / time.Second),
+		Secure:   s.cfg.Secure,
+		HttpOnly: s.cfg.CookieHTTPOnly,
+		SameSite: s.cfg.SameSite,
+	})
+}
+
+func decodeCSRF(s string) []byte {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+	return b
+}
+
+// maskCSRF returns base64(pad || pad^token) with a fresh random pad.
+func maskCSRF(token []byte) string {
+	out := make([]byte, 2*len(token))
+	_, _ = rand.Read(out[:len(token)])
+	for i, b := range token {
+		out[len(token)+i] = out[i] ^ b
+	}
+	return base64.RawURLEncoding.EncodeToString(out)
+}
+
+// csrfMatches accepts the masked form from c.CSRFToken() as well as the raw
+// cookie value echoed by scripts.
+func csrfMatches(token []byte, sent string) bool {
+	b := decodeCSRF(sent)
+	switch len(b) {
+	case 2 * csrfTokenLen:
+		pad, masked := b[:csrfTokenLen], b[csrfTokenLen:]
+		for i := range masked {
+			masked[i] ^= pad[i]
+		}
+		b = masked
+	case csrfTokenLen:
+	default:
+		return false
+	}
+	return subtle.ConstantTimeCompare(b, token) == 1
+}
@@ -15,6 +15,12 @@ type ErrorHandlerConfig struct {
 
 	// Default message for 500 if none provided.
 	DefaultMessage string
+
+	// ProblemJSON renders errors recorded with c.SetError and panics as
+	// application/problem+json, not only for clients whose Accept header
+	// asks for it. App.SetProblemJSON turns this on too and also covers
+	// c.Fail and the router's 404 and 405 responses.
+	ProblemJSON bool
 }
 
 // DefaultErrorHandler returns a sensible default configuration.
@@ -30,8 +36,10 @@ func DefaultErrorHandler() ErrorHandlerConfig {
 //
 // Behavior:
 //   - Panic: recovers, optionally logs (cfg.LogPanic), and writes 500 response
-//     as problem+json if client accepts it, otherwise JSON {code,message}.
-//   - c.Error() set by handlers: writes that error as-is (zentrox.HTTPError),
+//     as problem+json if client accepts it (or cfg.ProblemJSON / App.SetProblemJSON
+//     is on), otherwise JSON {code,message}.
+//   - c.Error() set by handlers: writes that error as-is (zentrox.HTTPError,
+//     also when wrapped) or with the status registered by App.MapError,
 //     honoring problem+json when requested.
 //   - For unknown errors: maps to 500 with cfg.DefaultMessage and includes detail
 //     text in a safe envelope.
@@ -52,10 +60,10 @@ func ErrorHandler(cfg ErrorHandlerConfig) zentrox.Handler {
 				if cfg.LogPanic {
 					log.Printf("panic: %v", r)
 				}
+				c.Emit(zentrox.EventRequestPanic, r)
 				// Respect content negotiation for problem+json.
-				wantsProblem := strings.Contains(strings.ToLower(c.GetHeader(zentrox.HeaderAccept)), zentrox.ContentTypeProblemJSON)
-				if wantsProblem {
-					c.Problem(http.StatusInternalServerError, "about:blank", cfg.DefaultMessage, "", c.Request.URL.Path, nil)
+				if wantsProblem(c, cfg) {
+					c.Problem(zentrox.ProblemDetails{Status: http.StatusInternalServerError, Title: cfg.DefaultMessage, Instance: c.Request.URL.Path})
 				} else {
 					c.JSON(http.StatusInternalServerError, zentrox.HTTPError{
 						Code:    http.StatusInternalServerError,
@@ -74,31 +82,13 @@ func ErrorHandler(cfg ErrorHandlerConfig) zentrox.Handler {
 
 		// If a handler recorded an error, render it now.
 		if err := c.Error(); err != nil {
-			wantsProblem := strings.Contains(strings.ToLower(c.GetHeader(zentrox.HeaderAccept)), zentrox.ContentTypeProblemJSON)
-
-			switch e := err.(type) {
-			case zentrox.HTTPError:
-				// Application-level error with explicit status code.
-				if wantsProblem {
-					// Map to RFC 9457 problem+json; use Message as title and include detail when present.
-					var detail string
-					if e.Detail != nil {
-						// avoid leaking sensitive detail to end users; use as-is if you intend to expose
-						if s, ok := e.Detail.(string); ok {
-							detail = s
-						}
-					}
-					c.Problem(e.Code, "about:blank", e.Message, detail, c.Request.URL.Path, nil)
-				} else {
-					c.JSON(e.Code, e)
-				}
-				c.Abort()
-				return
+			problem := wantsProblem(c, cfg)
 
-			default:
+			e, ok := c.App().ResolveError(err)
+			if !ok {
 				// Unknown error type → map to 500.
-				if wantsProblem {
-					c.Problem(http.StatusInternalServerError, "about:blank", cfg.DefaultMessage, "", c.Request.URL.Path, nil)
+				if problem {
+					c.Problem(zentrox.ProblemDetails{Status: http.StatusInternalServerError, Title: cfg.DefaultMessage, Instance: c.Request.URL.Path})
 				} else {
 					c.JSON(http.StatusInternalServerError, zentrox.HTTPError{
 						Code:    http.StatusInternalServerError,
@@ -109,6 +99,24 @@ func ErrorHandler(cfg ErrorHandlerConfig) zentrox.Handler {
 				c.Abort()
 				return
 			}
+
+			// Application-level error with explicit status code.
+			if problem {
+				// Map to RFC 9457 problem+json; Message becomes the title, Detail the detail or "errors" member.
+				c.Problem(e.ProblemDetails(c.Request.URL.Path))
+			} else {
+				c.JSON(e.Code, e)
+			}
+			c.Abort()
 		}
 	}
 }
+
+// wantsProblem reports whether to answer with problem+json: when configured
+// on the handler or the App, or when the client asks for it.
+func wantsProblem(c *zentrox.Context, cfg ErrorHandlerConfig) bool {
+	if cfg.ProblemJSON || (c.App() != nil && c.App().ProblemJSON()) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(c.GetHeader(zentrox.HeaderAccept)), zentrox.ContentTypeProblemJSON)
+}
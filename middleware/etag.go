@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// ETagConfig configures ETag.
+type ETagConfig struct {
+	// Strong sends strong ETags ("...") instead of weak ones (W/"...").
+	// Both hash the body; use strong only if responses are byte-identical
+	// across encodings.
+	Strong bool
+	// MaxSize is the largest body buffered to compute an ETag (default
+	// 1 MiB). Larger responses are streamed without one.
+	MaxSize int
+	// IncludeTypes, when set, restricts ETags to Content-Types with one of
+	// these prefixes, e.g. "application/json".
+	IncludeTypes []string
+	// ExcludeTypes skips Content-Types with one of these prefixes.
+	ExcludeTypes []string
+}
+
+// DefaultETag returns the default ETagConfig.
+func DefaultETag() ETagConfig {
+	return ETagConfig{
+		MaxSize:      1 << 20,
+		ExcludeTypes: []string{zentrox.ContentTypeEventStream},
+	}
+}
+
+// ETag buffers successful GET responses, tags them with a hash of the body
+// and answers 304 Not Modified when If-None-Match matches, so clients polling
+// a JSON API skip unchanged payloads:
+//
+//	api := app.Scope("/api", middleware.ETag())
+//
+// Responses that set their own ETag, stream (Flush), exceed MaxSize or are
+// not 200 pass through untouched. The handler still runs; ETag saves
+// bandwidth, not work.
+func ETag(cfg ...ETagConfig) zentrox.Handler {
+	def := DefaultETag()
+	conf := def
+	if len(cfg) > 0 {
+		conf = cfg[0]
+		if conf.MaxSize <= 0 {
+			conf.MaxSize = def.MaxSize
+		}
+		if conf.ExcludeTypes == nil {
+			conf.ExcludeTypes = def.ExcludeTypes
+		}
+	}
+
+	return func(c *zentrox.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+		orig := c.Writer
+		ew := &etagRW{ResponseWriter: orig, cfg: &conf}
+		c.Writer = ew
+		c.Next()
+		c.Writer = orig
+		ew.finish(c)
+	}
+}
+
+// etagRW buffers the response until it is complete or too large.
+type etagRW struct {
+	http.ResponseWriter
+	cfg *ETagConfig
+
+	buf         bytes.Buffer
+	status      int
+	passthrough bool
+}
+
+func (w *etagRW) WriteHeader(code int) {
+	if w.passthrough {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *etagRW) Write(p []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(p)
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.buf.Len()+len(p) > w.cfg.MaxSize {
+		w.release()
+		return w.ResponseWriter.Write(p)
+	}
+	return w.buf.Write(p)
+}
+
+// release stops buffering and sends what has been held back.
+func (w *etagRW) release() {
+	if w.passthrough {
+		return
+	}
+	w.passthrough = true
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buf.Len() > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+		w.buf.Reset()
+	}
+}
+
+func (w *etagRW) Flush() {
+	w.release()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *etagRW) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish tags the buffered response, or answers 304, and writes it.
+func (w *etagRW) finish(c *zentrox.Context) {
+	if w.passthrough {
+		return
+	}
+	if w.status == 0 {
+		return // nothing was written
+	}
+	h := w.Header()
+	if w.status != http.StatusOK || h.Get(zentrox.HeaderETag) != "" || !w.tagType(h.Get(zentrox.HeaderContentType)) {
+		w.release()
+		return
+	}
+
+	sum := sha1.Sum(w.buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	if !w.cfg.Strong {
+		etag = "W/" + etag
+	}
+	h.Set(zentrox.HeaderETag, etag)
+	if etagMatches(c.GetHeader(zentrox.HeaderIfNoneMatch), etag) {
+		h.Del(zentrox.HeaderContentLength)
+		h.Del(zentrox.HeaderContentType)
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if h.Get(zentrox.HeaderContentLength) == "" {
+		h.Set(zentrox.HeaderContentLength, strconv.Itoa(w.buf.Len()))
+	}
+	w.release()
+}
+
+func (w *etagRW) tagType(ct string) bool {
+	hasPrefix := func(pre string) bool { return pre != "" && strings.HasPrefix(ct, pre) }
+	if slices.ContainsFunc(w.cfg.ExcludeTypes, hasPrefix) {
+		return false
+	}
+	return len(w.cfg.IncludeTypes) == 0 || slices.ContainsFunc(w.cfg.IncludeTypes, hasPrefix)
+}
+
+// etagMatches applies the weak comparison If-None-Match uses.
+func etagMatches(header, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, v := range strings.Split(header, ",") {
+		v = strings.TrimSpace(v)
+		if v == "*" || strings.TrimPrefix(v, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -1,117 +1,501 @@
 package middleware
 
 import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
 	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/aminofox/zentrox/v2"
 )
 
 type JWTConfig struct {
-	Secret        []byte
+	Secret []byte
+	// Keys holds HS256 secrets by key ID for rotation. Tokens carrying a
+	// "kid" header are verified with the matching key; tokens without one
+	// use Secret, or every key in turn when Secret is empty.
+	Keys map[string][]byte
+	// SigningKeyID selects the entry of Keys used by Sign.
+	SigningKeyID string
+	// PublicKey verifies asymmetric tokens: an *rsa.PublicKey enables RS256,
+	// an *ecdsa.PublicKey (P-256) ES256 and an ed25519.PublicKey EdDSA.
+	PublicKey crypto.PublicKey
+	// JWKS (or JWKSURL, a shorthand for NewJWKS(JWKSURL)) resolves public
+	// keys by the token's "kid" from an identity provider's key set.
+	// When PublicKey or a key set is configured without Secret or Keys,
+	// HS256 tokens are refused.
+	JWKS          *JWKS
+	JWKSURL       string
 	ContextKey    string
 	SkipIfMissing bool
-	ValidateFunc  func(claims map[string]any) error
+	// TokenLookup lists where to find the token, tried in order, as
+	// comma-separated "source:name" pairs with source header, cookie or
+	// query, e.g. "header:Authorization,cookie:token,query:access_token".
+	// The Authorization header must use the Bearer scheme; other sources
+	// carry the bare token. Default "header:Authorization".
+	TokenLookup string
+	// Issuer, when set, must equal the "iss" claim.
+	Issuer string
+	// Audience, when set, requires the "aud" claim to contain at least one
+	// of these values.
+	Audience []string
+	// Leeway tolerates clock skew in the exp, nbf and iat checks, which run
+	// automatically whenever those claims are present.
+	Leeway time.Duration
+	// RequireExp rejects tokens without an "exp" claim, which would
+	// otherwise never expire.
+	RequireExp   bool
+	ValidateFunc func(claims map[string]any) error
+	// RevocationChecker, when set, rejects tokens revoked with Revoke.
+	RevocationChecker RevocationChecker
+	// ErrorHandler writes the response for a rejected request; the chain is
+	// aborted afterwards. err is one of the Err* values below or the error
+	// returned by ValidateFunc. Defaults to {"error": msg} with 401.
+	ErrorHandler func(c *zentrox.Context, err error)
 }
 
+// Errors passed to JWTConfig.ErrorHandler. Errors returned by ValidateFunc
+// are passed through unchanged.
+var (
+	ErrMissingToken     = errors.New(zentrox.MsgMissingToken)
+	ErrInvalidToken     = errors.New(zentrox.MsgInvalidToken)
+	ErrUnsupportedAlg   = errors.New(zentrox.MsgUnsupportedAlg)
+	ErrInvalidSignature = errors.New(zentrox.MsgInvalidSignature)
+	ErrTokenExpired     = errors.New(zentrox.MsgTokenExpired)
+	ErrMissingExpiry    = errors.New(zentrox.MsgMissingExpiry)
+	ErrTokenNotYetValid = errors.New(zentrox.MsgTokenNotYetValid)
+	ErrTokenIssuedAt    = errors.New(zentrox.MsgTokenIssuedAt)
+	ErrInvalidIssuer    = errors.New(zentrox.MsgInvalidIssuer)
+	ErrInvalidAudience  = errors.New(zentrox.MsgInvalidAudience)
+	ErrUnknownKeyID     = errors.New(zentrox.MsgUnknownKeyID)
+	ErrTokenRevoked     = errors.New(zentrox.MsgTokenRevoked)
+	ErrRevocationFailed = errors.New(zentrox.MsgRevocationFailed)
+	ErrKeySetFailed     = errors.New(zentrox.MsgKeySetFailed)
+)
+
 func JWT(cfg JWTConfig) zentrox.Handler {
 	if cfg.ContextKey == "" {
 		cfg.ContextKey = "user"
 	}
+	if cfg.ErrorHandler == nil {
+		cfg.ErrorHandler = defaultJWTErrorHandler
+	}
+	if cfg.JWKS == nil && cfg.JWKSURL != "" {
+		cfg.JWKS = NewJWKS(cfg.JWKSURL)
+	}
+	lookup := parseTokenLookup(cfg.TokenLookup)
+	fail := func(c *zentrox.Context, err error) {
+		cfg.ErrorHandler(c, err)
+		c.Abort()
+	}
 
 	return func(c *zentrox.Context) {
-		auth := c.GetHeader(zentrox.HeaderAuthorization)
-		if !strings.HasPrefix(auth, zentrox.BearerPrefix) {
+		token := lookup(c)
+		if token == "" {
 			if cfg.SkipIfMissing {
 				c.Next()
 				return
 			}
-			c.JSON(http.StatusUnauthorized, map[string]string{"error": zentrox.MsgMissingToken})
-			c.Abort()
+			fail(c, ErrMissingToken)
 			return
 		}
 
-		token := strings.TrimPrefix(auth, zentrox.BearerPrefix)
-		parts := strings.Split(token, ".")
-		if len(parts) != 3 {
-			c.JSON(http.StatusUnauthorized, map[string]string{"error": zentrox.MsgInvalidToken})
-			c.Abort()
+		claims, err := cfg.Verify(c.Request.Context(), token)
+		if err != nil {
+			fail(c, err)
 			return
 		}
 
-		hb, err := base64.RawURLEncoding.DecodeString(parts[0])
+		c.Set(cfg.ContextKey, claims)
+		c.Next()
+	}
+}
+
+// Verify checks a raw token as the JWT middleware does (signature, standard
+// claims, ValidateFunc and revocation) and returns its claims. Errors are
+// the Err* values above or the one returned by ValidateFunc. Outside the
+// middleware, set JWKS rather than JWKSURL so the key set is cached.
+func (cfg JWTConfig) Verify(ctx context.Context, token string) (map[string]any, error) {
+	if cfg.JWKS == nil && cfg.JWKSURL != "" {
+		cfg.JWKS = NewJWKS(cfg.JWKSURL)
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+	hb, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var hdr struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := json.Unmarshal(hb, &hdr); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := verifySignature(ctx, parts, hdr.Alg, hdr.Kid, cfg); err != nil {
+		return nil, err
+	}
+	pb, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(pb, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := validateStandardClaims(claims, cfg, time.Now()); err != nil {
+		return nil, err
+	}
+	if cfg.ValidateFunc != nil {
+		if err := cfg.ValidateFunc(claims); err != nil {
+			return nil, err
+		}
+	}
+	if key := RevocationKey(claims); cfg.RevocationChecker != nil && key != "" {
+		revoked, err := cfg.RevocationChecker.IsRevoked(ctx, key)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, map[string]string{"error": zentrox.MsgInvalidToken})
-			c.Abort()
-			return
+			return nil, ErrRevocationFailed
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
 		}
+	}
+	return claims, nil
+}
 
-		var hdr struct {
-			Alg string `json:"alg"`
+// parseTokenLookup compiles JWTConfig.TokenLookup into a function returning
+// the first token found, or "". Unknown sources are ignored.
+func parseTokenLookup(spec string) func(*zentrox.Context) string {
+	if spec == "" {
+		spec = "header:" + zentrox.HeaderAuthorization
+	}
+	var sources []func(*zentrox.Context) string
+	for _, part := range strings.Split(spec, ",") {
+		src, name, ok := strings.Cut(strings.TrimSpace(part), ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			continue
 		}
-		if err := json.Unmarshal(hb, &hdr); err != nil {
-			c.JSON(http.StatusUnauthorized, map[string]string{"error": zentrox.MsgInvalidToken})
-			c.Abort()
-			return
+		switch strings.ToLower(strings.TrimSpace(src)) {
+		case "header":
+			if strings.EqualFold(name, zentrox.HeaderAuthorization) {
+				sources = append(sources, func(c *zentrox.Context) string {
+					auth := c.GetHeader(zentrox.HeaderAuthorization)
+					if !strings.HasPrefix(auth, zentrox.BearerPrefix) {
+						return ""
+					}
+					return strings.TrimPrefix(auth, zentrox.BearerPrefix)
+				})
+			} else {
+				sources = append(sources, func(c *zentrox.Context) string { return c.GetHeader(name) })
+			}
+		case "cookie":
+			sources = append(sources, func(c *zentrox.Context) string {
+				if ck, err := c.Request.Cookie(name); err == nil {
+					return ck.Value
+				}
+				return ""
+			})
+		case "query":
+			sources = append(sources, func(c *zentrox.Context) string { return c.Query(name) })
 		}
+	}
+	return func(c *zentrox.Context) string {
+		for _, src := range sources {
+			if tok := src(c); tok != "" {
+				return tok
+			}
+		}
+		return ""
+	}
+}
 
-		if hdr.Alg != "HS256" {
-			c.JSON(http.StatusUnauthorized, map[string]string{"error": zentrox.MsgUnsupportedAlg})
-			c.Abort()
-			return
+// defaultJWTErrorHandler writes {"error": msg} with 401, or 503 when the
+// revocation check could not run or the key set could not be fetched.
+func defaultJWTErrorHandler(c *zentrox.Context, err error) {
+	status := http.StatusUnauthorized
+	if errors.Is(err, ErrRevocationFailed) || errors.Is(err, ErrKeySetFailed) {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, map[string]string{"error": err.Error()})
+}
+
+// verifySignature dispatches on the "alg" header. HS256 is accepted unless
+// only asymmetric keys are configured, and RS256, ES256 and EdDSA require a
+// public key of the matching type, so a token cannot pick an algorithm the
+// application did not opt into.
+func verifySignature(ctx context.Context, parts []string, alg, kid string, cfg JWTConfig) error {
+	if alg == "HS256" {
+		if (cfg.PublicKey != nil || cfg.JWKS != nil) && len(cfg.Secret) == 0 && len(cfg.Keys) == 0 {
+			return ErrUnsupportedAlg
+		}
+		return verifyHS256(parts, kid, cfg)
+	}
+	if alg != "RS256" && alg != "ES256" && alg != "EdDSA" {
+		return ErrUnsupportedAlg
+	}
+	key := cfg.PublicKey
+	if cfg.JWKS != nil {
+		k, err := cfg.JWKS.Key(ctx, kid)
+		if errors.Is(err, ErrUnknownKeyID) {
+			return err
+		}
+		if err != nil {
+			return ErrKeySetFailed
+		}
+		key = k
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	signing := []byte(parts[0] + "." + parts[1])
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrUnsupportedAlg
+		}
+		h := sha256.Sum256(signing)
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, h[:], sig) != nil {
+			return ErrInvalidSignature
 		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok || pub.Curve != elliptic.P256() {
+			return ErrUnsupportedAlg
+		}
+		if len(sig) != 64 {
+			return ErrInvalidSignature
+		}
+		h := sha256.Sum256(signing)
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, h[:], r, s) {
+			return ErrInvalidSignature
+		}
+	case "EdDSA":
+		pub, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return ErrUnsupportedAlg
+		}
+		if !ed25519.Verify(pub, signing, sig) {
+			return ErrInvalidSignature
+		}
+	}
+	return nil
+}
 
-		signing := parts[0] + "." + parts[1]
-		mac := hmac.New(sha256.New, cfg.Secret)
+// verifyHS256 checks the token signature against the key selected by kid.
+func verifyHS256(parts []string, kid string, cfg JWTConfig) error {
+	got, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	var keys [][]byte
+	switch {
+	case kid != "" && cfg.Keys != nil:
+		key, ok := cfg.Keys[kid]
+		if !ok {
+			return ErrUnknownKeyID
+		}
+		keys = [][]byte{key}
+	case len(cfg.Secret) > 0 || len(cfg.Keys) == 0:
+		keys = [][]byte{cfg.Secret}
+	default:
+		for _, key := range cfg.Keys {
+			keys = append(keys, key)
+		}
+	}
+	signing := parts[0] + "." + parts[1]
+	for _, key := range keys {
+		mac := hmac.New(sha256.New, key)
 		mac.Write([]byte(signing))
-		want := mac.Sum(nil)
-		got, err := base64.RawURLEncoding.DecodeString(parts[2])
-		if err != nil || !hmac.Equal(got, want) {
-			c.JSON(http.StatusUnauthorized, map[string]string{"error": zentrox.MsgInvalidSignature})
-			c.Abort()
-			return
+		if hmac.Equal(got, mac.Sum(nil)) {
+			return nil
 		}
+	}
+	return ErrInvalidSignature
+}
 
-		pb, err := base64.RawURLEncoding.DecodeString(parts[1])
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, map[string]string{"error": zentrox.MsgInvalidToken})
-			c.Abort()
-			return
+// validateStandardClaims checks the registered claims exp, nbf, iat, iss and
+// aud against cfg. Time claims must be NumericDate values when present.
+func validateStandardClaims(claims map[string]any, cfg JWTConfig, now time.Time) error {
+	leeway := cfg.Leeway
+	for _, name := range []string{"exp", "nbf", "iat"} {
+		if _, present := claims[name]; present {
+			if _, ok := numericClaim(claims, name); !ok {
+				return ErrInvalidToken
+			}
 		}
+	}
+	if _, ok := claims["exp"]; cfg.RequireExp && !ok {
+		return ErrMissingExpiry
+	}
+	if exp, ok := numericClaim(claims, "exp"); ok && !now.Before(exp.Add(leeway)) {
+		return ErrTokenExpired
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(leeway).Before(nbf) {
+		return ErrTokenNotYetValid
+	}
+	if iat, ok := numericClaim(claims, "iat"); ok && now.Add(leeway).Before(iat) {
+		return ErrTokenIssuedAt
+	}
+	if cfg.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != cfg.Issuer {
+			return ErrInvalidIssuer
+		}
+	}
+	if len(cfg.Audience) > 0 && !audienceMatches(claims["aud"], cfg.Audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
 
-		var claims map[string]any
-		if err := json.Unmarshal(pb, &claims); err != nil {
-			c.JSON(http.StatusUnauthorized, map[string]string{"error": zentrox.MsgInvalidToken})
-			c.Abort()
-			return
+func numericClaim(claims map[string]any, name string) (time.Time, bool) {
+	switch v := claims[name].(type) {
+	case float64:
+		sec, frac := math.Modf(v)
+		return time.Unix(int64(sec), int64(frac*1e9)), true
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, false
 		}
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)), true
+	}
+	return time.Time{}, false
+}
 
-		if cfg.ValidateFunc != nil {
-			if err := cfg.ValidateFunc(claims); err != nil {
-				c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
-				c.Abort()
-				return
+// audienceMatches accepts "aud" as a string or an array of strings.
+func audienceMatches(aud any, want []string) bool {
+	var got []string
+	switch v := aud.(type) {
+	case string:
+		got = []string{v}
+	case []any:
+		for _, a := range v {
+			if s, ok := a.(string); ok {
+				got = append(got, s)
+			}
+		}
+	}
+	for _, g := range got {
+		for _, w := range want {
+			if g == w {
+				return true
 			}
 		}
-
-		c.Set(cfg.ContextKey, claims)
-		c.Next()
 	}
+	return false
 }
 
 func SignHS256(claims map[string]any, secret []byte) (string, error) {
-	header := map[string]any{"alg": "HS256", "typ": "JWT"}
+	return SignHS256WithKeyID(claims, "", secret)
+}
+
+// SignHS256WithKeyID is like SignHS256 and sets the "kid" header when kid is
+// not empty.
+func SignHS256WithKeyID(claims map[string]any, kid string, secret []byte) (string, error) {
+	return signToken("HS256", kid, claims, func(signing []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signing)
+		return mac.Sum(nil)
+	})
+}
+
+// SignEdDSA issues an EdDSA (Ed25519) token. Verify it with
+// JWTConfig.PublicKey set to the matching ed25519.PublicKey.
+func SignEdDSA(claims map[string]any, key ed25519.PrivateKey) (string, error) {
+	if len(key) != ed25519.PrivateKeySize {
+		return "", errors.New("jwt: invalid ed25519 private key")
+	}
+	return signToken("EdDSA", "", claims, func(signing []byte) []byte {
+		return ed25519.Sign(key, signing)
+	})
+}
+
+// SignRS256 issues an RS256 token, setting the "kid" header when kid is not
+// empty so verifiers using a JWKS can find the key.
+func SignRS256(claims map[string]any, kid string, key *rsa.PrivateKey) (string, error) {
+	var signErr error
+	tok, err := signToken("RS256", kid, claims, func(signing []byte) []byte {
+		h := sha256.Sum256(signing)
+		sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+		signErr = err
+		return sig
+	})
+	if signErr != nil {
+		return "", signErr
+	}
+	return tok, err
+}
+
+// SignES256 issues an ES256 token with a P-256 key; kid is as for SignRS256.
+func SignES256(claims map[string]any, kid string, key *ecdsa.PrivateKey) (string, error) {
+	if key.Curve != elliptic.P256() {
+		return "", errors.New("jwt: ES256 requires a P-256 key")
+	}
+	var signErr error
+	tok, err := signToken("ES256", kid, claims, func(signing []byte) []byte {
+		h := sha256.Sum256(signing)
+		r, s, err := ecdsa.Sign(rand.Reader, key, h[:])
+		signErr = err
+		if err != nil {
+			return nil
+		}
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig
+	})
+	if signErr != nil {
+		return "", signErr
+	}
+	return tok, err
+}
+
+func signToken(alg, kid string, claims map[string]any, sign func([]byte) []byte) (string, error) {
+	header := map[string]any{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
 	hb, _ := json.Marshal(header)
-	pb, _ := json.Marshal(claims)
+	pb, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
 	h64 := base64.RawURLEncoding.EncodeToString(hb)
 	p64 := base64.RawURLEncoding.EncodeToString(pb)
 	signing := h64 + "." + p64
-	mac := hmac.New(sha256.New, secret)
-	mac.Write([]byte(signing))
-	sig := mac.Sum(nil)
-	return signing + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(signing))), nil
+}
+
+// Sign issues an HS256 token with the key named by SigningKeyID, or with
+// Secret when SigningKeyID is empty. Rotate by adding a new entry to Keys and
+// pointing SigningKeyID at it; remove the old entry once its tokens expire.
+func (cfg JWTConfig) Sign(claims map[string]any) (string, error) {
+	if cfg.SigningKeyID == "" {
+		return SignHS256(claims, cfg.Secret)
+	}
+	key, ok := cfg.Keys[cfg.SigningKeyID]
+	if !ok {
+		return "", ErrUnknownKeyID
+	}
+	return SignHS256WithKeyID(claims, cfg.SigningKeyID, key)
 }
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aminofox/zentrox/v2/internal/redis"
+)
+
+// RevocationChecker reports whether a token has been revoked. key is
+// RevocationKey(claims).
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, key string) (bool, error)
+}
+
+// Revoker is a RevocationChecker that can also record revocations.
+type Revoker interface {
+	RevocationChecker
+	RevokeKey(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// DefaultRevocationTTL is used for tokens without an "exp" claim.
+const DefaultRevocationTTL = 24 * time.Hour
+
+// RevocationKey identifies a token by its "jti" claim, or by "sub" and "iat"
+// when it has none. It returns "" when neither is present.
+func RevocationKey(claims map[string]any) string {
+	if jti, _ := claims["jti"].(string); jti != "" {
+		return "jti:" + jti
+	}
+	sub, _ := claims["sub"].(string)
+	iat, ok := numericClaim(claims, "iat")
+	if sub == "" || !ok {
+		return ""
+	}
+	return fmt.Sprintf("sub:%s:%d", sub, iat.Unix())
+}
+
+// Revoke blacklists token until it expires, e.g. on logout or when a token is
+// compromised. The token is decoded but not verified; revoking a forged token
+// has no effect beyond an unused store entry.
+func Revoke(ctx context.Context, r Revoker, token string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrInvalidToken
+	}
+	pb, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return ErrInvalidToken
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(pb, &claims); err != nil {
+		return ErrInvalidToken
+	}
+	return RevokeClaims(ctx, r, claims)
+}
+
+// RevokeClaims is like Revoke for already verified claims, e.g. the ones the
+// JWT middleware stored in the context.
+func RevokeClaims(ctx context.Context, r Revoker, claims map[string]any) error {
+	key := RevocationKey(claims)
+	if key == "" {
+		return errors.New("jwt: token has neither jti nor sub+iat")
+	}
+	ttl := DefaultRevocationTTL
+	if exp, ok := numericClaim(claims, "exp"); ok {
+		ttl = time.Until(exp)
+		if ttl <= 0 {
+			return nil // already expired
+		}
+	}
+	return r.RevokeKey(ctx, key, ttl)
+}
+
+// storeRevoker keeps revocations in a Store.
+type storeRevoker struct {
+	store Store
+}
+
+// NewStoreRevoker returns a Revoker backed by s (e.g. NewMemoryStore() for a
+// single instance).
+func NewStoreRevoker(s Store) Revoker {
+	return storeRevoker{store: s}
+}
+
+func (r storeRevoker) IsRevoked(_ context.Context, key string) (bool, error) {
+	n, _, err := r.store.Get("jwt:revoked:" + key)
+	return n > 0, err
+}
+
+func (r storeRevoker) RevokeKey(_ context.Context, key string, ttl time.Duration) error {
+	return r.store.Set("jwt:revoked:"+key, 1, ttl)
+}
+
+// RedisConfig configures Redis-backed stores.
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	// Prefix is prepended to every key.
+	Prefix string
+}
+
+// RedisRevoker shares revocations across instances through Redis.
+type RedisRevoker struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisRevoker(cfg RedisConfig) *RedisRevoker {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "zentrox:jwt:revoked:"
+	}
+	return &RedisRevoker{
+		client: redis.New(redis.Config{Addr: cfg.Addr, Password: cfg.Password, DB: cfg.DB}),
+		prefix: cfg.Prefix,
+	}
+}
+
+func (r *RedisRevoker) IsRevoked(ctx context.Context, key string) (bool, error) {
+	n, err := r.client.Int(ctx, "EXISTS", r.prefix+key)
+	return n > 0, err
+}
+
+func (r *RedisRevoker) RevokeKey(ctx context.Context, key string, ttl time.Duration) error {
+	ms := ttl.Milliseconds()
+	if ms <= 0 {
+		ms = 1
+	}
+	_, err := r.client.String(ctx, "SET", r.prefix+key, "1", "PX", fmt.Sprint(ms))
+	return err
+}
+
+// Close releases pooled connections.
+func (r *RedisRevoker) Close() error {
+	return r.client.Close()
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"log"
+	"log/slog"
 	"time"
 
 	"github.com/aminofox/zentrox/v2"
@@ -34,3 +35,59 @@ func LoggerWithFunc(fn LogFunc) zentrox.Handler {
 		fn(c.Request.Method, c.Request.URL.Path, status, time.Since(start), c.Error())
 	}
 }
+
+// StructuredLogger writes one access log record per request to h (nil means
+// slog.Default's handler) with method, path, route, status, bytes, latency,
+// client_ip, user_agent, request_id and error, followed by any fields the
+// handlers added with c.LogAttr. 5xx responses log at ERROR, 4xx at WARN and
+// everything else at INFO.
+func StructuredLogger(h slog.Handler) zentrox.Handler {
+	if h == nil {
+		h = slog.Default().Handler()
+	}
+	logger := slog.New(h)
+
+	return func(c *zentrox.Context) {
+		start := time.Now()
+		c.Next()
+		latency := time.Since(start)
+
+		status := responseStatus(c)
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		ctx := c.Request.Context()
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+
+		bytes := 0
+		if rw, ok := c.Writer.(interface{ BytesWritten() int }); ok {
+			bytes = rw.BytesWritten()
+		}
+		extra := c.LogAttrs()
+		attrs := make([]slog.Attr, 0, 11+len(extra))
+		attrs = append(attrs,
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", RouteLabel(c)),
+			slog.Int("status", status),
+			slog.Int("bytes", bytes),
+			slog.Duration("latency", latency),
+			slog.String("client_ip", c.RealIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		)
+		if id := c.RequestID(); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		if err := c.Error(); err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+		}
+		attrs = append(attrs, extra...)
+		logger.LogAttrs(ctx, level, "request", attrs...)
+	}
+}
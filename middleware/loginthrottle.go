@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// LoginThrottleConfig configures LoginThrottle. Failures are counted per
+// account and per client IP; each failure beyond FreeAttempts blocks the key
+// for BaseDelay doubled per failure, and reaching the Max*Failures limit locks
+// it for LockoutDuration.
+type LoginThrottleConfig struct {
+	Store Store
+	// AccountFunc extracts the account being logged into. Nil or "" throttles
+	// by IP only. See AccountFromField.
+	AccountFunc func(*zentrox.Context) string
+	IPFunc      func(*zentrox.Context) string
+	// IsFailure reports whether the handler rejected the credentials.
+	// Defaults to a 401 response status.
+	IsFailure          func(*zentrox.Context) bool
+	FreeAttempts       int
+	MaxAccountFailures int
+	MaxIPFailures      int
+	BaseDelay          time.Duration
+	LockoutDuration    time.Duration
+	// Window is how long failures are remembered.
+	Window    time.Duration
+	OnBlocked func(c *zentrox.Context, retryAfter time.Duration)
+}
+
+func DefaultLoginThrottle() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		Store:              NewMemoryStore(),
+		IPFunc:             func(c *zentrox.Context) string { return c.RealIP() },
+		IsFailure:          isUnauthorized,
+		FreeAttempts:       3,
+		MaxAccountFailures: 10,
+		MaxIPFailures:      50,
+		BaseDelay:          time.Second,
+		LockoutDuration:    15 * time.Minute,
+		Window:             15 * time.Minute,
+		OnBlocked:          loginBlocked,
+	}
+}
+
+// LoginThrottle protects login style endpoints from brute force and
+// credential stuffing. Blocked requests are rejected with 429 and Retry-After
+// before the handler runs; a successful (2xx) login clears the account's
+// failure count.
+func LoginThrottle(cfg LoginThrottleConfig) zentrox.Handler {
+	def := DefaultLoginThrottle()
+	if cfg.Store == nil {
+		cfg.Store = def.Store
+	}
+	if cfg.IPFunc == nil {
+		cfg.IPFunc = def.IPFunc
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = def.IsFailure
+	}
+	if cfg.FreeAttempts < 0 {
+		cfg.FreeAttempts = 0
+	}
+	if cfg.MaxAccountFailures <= 0 {
+		cfg.MaxAccountFailures = def.MaxAccountFailures
+	}
+	if cfg.MaxIPFailures <= 0 {
+		cfg.MaxIPFailures = def.MaxIPFailures
+	}
+	if cfg.BaseDelay <= 0 {
+		cfg.BaseDelay = def.BaseDelay
+	}
+	if cfg.LockoutDuration <= 0 {
+		cfg.LockoutDuration = def.LockoutDuration
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = def.Window
+	}
+	if cfg.OnBlocked == nil {
+		cfg.OnBlocked = def.OnBlocked
+	}
+
+	// delay returns how long a key stays blocked after its n-th failure.
+	delay := func(n int64, max int) time.Duration {
+		if n >= int64(max) {
+			return cfg.LockoutDuration
+		}
+		over := n - int64(cfg.FreeAttempts)
+		if over <= 0 {
+			return 0
+		}
+		d := float64(cfg.BaseDelay) * math.Pow(2, float64(over-1))
+		if d >= float64(cfg.LockoutDuration) {
+			return cfg.LockoutDuration
+		}
+		return time.Duration(d)
+	}
+
+	type key struct {
+		name string
+		max  int
+	}
+
+	return func(c *zentrox.Context) {
+		keys := []key{{"ip:" + cfg.IPFunc(c), cfg.MaxIPFailures}}
+		var account string
+		if cfg.AccountFunc != nil {
+			account = strings.ToLower(strings.TrimSpace(cfg.AccountFunc(c)))
+		}
+		if account != "" {
+			keys = append(keys, key{"account:" + account, cfg.MaxAccountFailures})
+		}
+
+		var retry time.Duration
+		for _, k := range keys {
+			if n, ttl, err := cfg.Store.Get("login:block:" + k.name); err == nil && n > 0 && ttl > retry {
+				retry = ttl
+			}
+		}
+		if retry > 0 {
+			cfg.OnBlocked(c, retry)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+
+		if cfg.IsFailure(c) {
+			for _, k := range keys {
+				n, err := cfg.Store.Incr("login:fail:"+k.name, cfg.Window)
+				if err != nil {
+					continue
+				}
+				if d := delay(n, k.max); d > 0 {
+					_ = cfg.Store.Set("login:block:"+k.name, n, d)
+				}
+			}
+			return
+		}
+		if account != "" && responseStatus(c) < http.StatusMultipleChoices {
+			_ = cfg.Store.Delete("login:fail:account:" + account)
+			_ = cfg.Store.Delete("login:block:account:" + account)
+		}
+	}
+}
+
+// AccountFromField returns an AccountFunc reading field from a JSON or form
+// request body. The body is restored for the handler.
+func AccountFromField(field string) func(*zentrox.Context) string {
+	return func(c *zentrox.Context) string {
+		r := c.Request
+		if r.Body == nil {
+			return ""
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if err != nil {
+			return ""
+		}
+
+		ct := r.Header.Get(zentrox.HeaderContentType)
+		if strings.HasPrefix(ct, zentrox.ContentTypeJSON) {
+			var m map[string]any
+			if json.Unmarshal(body, &m) != nil {
+				return ""
+			}
+			s, _ := m[field].(string)
+			return s
+		}
+		if strings.HasPrefix(ct, zentrox.ContentTypeFormURLEncoded) {
+			vals, _ := url.ParseQuery(string(body))
+			return vals.Get(field)
+		}
+		return ""
+	}
+}
+
+func isUnauthorized(c *zentrox.Context) bool {
+	return responseStatus(c) == http.StatusUnauthorized
+}
+
+func responseStatus(c *zentrox.Context) int {
+	if rw, ok := c.Writer.(interface{ Status() int }); ok {
+		if st := rw.Status(); st != 0 {
+			return st
+		}
+	}
+	return http.StatusOK
+}
+
+func loginBlocked(c *zentrox.Context, retryAfter time.Duration) {
+	secs := int(math.Ceil(retryAfter.Seconds()))
+	c.SetHeader(zentrox.HeaderRetryAfter, strconv.Itoa(secs))
+	c.Fail(http.StatusTooManyRequests, zentrox.MsgTooManyRequests)
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// MethodOverrideConfig configures MethodOverride.
+type MethodOverrideConfig struct {
+	// Header carrying the override. Empty disables the header.
+	Header string
+	// FormField read from urlencoded or multipart POST bodies. Empty
+	// disables the form field.
+	FormField string
+	// Methods a POST may be turned into.
+	Methods []string
+}
+
+func DefaultMethodOverride() MethodOverrideConfig {
+	return MethodOverrideConfig{
+		Header:    zentrox.HeaderXHTTPMethodOverride,
+		FormField: "_method",
+		Methods:   []string{http.MethodPut, http.MethodPatch, http.MethodDelete},
+	}
+}
+
+// MethodOverride lets POST requests invoke PUT, PATCH or DELETE routes, for
+// HTML forms and proxies that only pass GET and POST. It must run before
+// routing, so install it with app.Pre:
+//
+//	app.Pre(middleware.MethodOverride(middleware.DefaultMethodOverride()))
+//
+// The header takes precedence over the form field; unknown methods are ignored.
+func MethodOverride(cfg MethodOverrideConfig) zentrox.Handler {
+	if cfg.Header == "" && cfg.FormField == "" {
+		def := DefaultMethodOverride()
+		cfg.Header, cfg.FormField = def.Header, def.FormField
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = DefaultMethodOverride().Methods
+	}
+	allowed := make(map[string]bool, len(cfg.Methods))
+	for _, m := range cfg.Methods {
+		allowed[strings.ToUpper(m)] = true
+	}
+
+	return func(c *zentrox.Context) {
+		r := c.Request
+		if r.Method != http.MethodPost {
+			return
+		}
+		var m string
+		if cfg.Header != "" {
+			m = r.Header.Get(cfg.Header)
+		}
+		if m == "" && cfg.FormField != "" {
+			ct := r.Header.Get(zentrox.HeaderContentType)
+			if strings.HasPrefix(ct, zentrox.ContentTypeFormURLEncoded) || strings.HasPrefix(ct, zentrox.ContentTypeMultipartForm) {
+				m = r.PostFormValue(cfg.FormField)
+			}
+		}
+		if m = strings.ToUpper(strings.TrimSpace(m)); allowed[m] {
+			r.Method = m
+		}
+	}
+}
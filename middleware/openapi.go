@@ -0,0 +1,858 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// OpenAPISpec is a parsed OpenAPI 3.0/3.1 document prepared for
+// OpenAPIValidator.
+type OpenAPISpec struct {
+	doc      map[string]any
+	basePath string
+	paths    []*openAPIPath
+
+	reMu sync.Mutex
+	re   map[string]*regexp.Regexp
+}
+
+type openAPIPath struct {
+	segs   []string // literal segments, or "{name}"
+	params int
+	ops    map[string]*openAPIOp // upper-case method
+}
+
+type openAPIOp struct {
+	params    []openAPIParam
+	body      map[string]any // media type -> schema (nil schema allowed)
+	bodyReq   bool
+	responses map[string]map[string]any // "200", "2XX", "default" -> media type -> schema
+}
+
+type openAPIParam struct {
+	name     string
+	in       string
+	required bool
+	schema   map[string]any
+}
+
+// OpenAPIViolation describes one mismatch between a request or response and
+// the spec. In is "path", "query", "header", "body" or "response"; Field is
+// the parameter name or a JSON path such as "items[2].name".
+type OpenAPIViolation struct {
+	In      string `json:"in"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// LoadOpenAPI parses a JSON OpenAPI 3.x document, such as the output of
+// App.GenerateOpenAPI or an embedded swagger.json. The path of the first
+// server URL, if any, is treated as a prefix of every route.
+func LoadOpenAPI(spec []byte) (*OpenAPISpec, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	if v, _ := doc["openapi"].(string); !strings.HasPrefix(v, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported version %q", v)
+	}
+	s := &OpenAPISpec{doc: doc, re: map[string]*regexp.Regexp{}}
+	if servers, _ := doc["servers"].([]any); len(servers) > 0 {
+		if srv, _ := servers[0].(map[string]any); srv != nil {
+			if raw, _ := srv["url"].(string); raw != "" {
+				if u, err := url.Parse(raw); err == nil {
+					s.basePath = strings.TrimSuffix(u.Path, "/")
+				}
+			}
+		}
+	}
+	paths, _ := doc["paths"].(map[string]any)
+	for tmpl, v := range paths {
+		item, _ := s.resolve(v).(map[string]any)
+		if item == nil {
+			continue
+		}
+		p := &openAPIPath{ops: map[string]*openAPIOp{}}
+		for _, seg := range strings.Split(strings.Trim(tmpl, "/"), "/") {
+			if seg == "" {
+				continue
+			}
+			if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+				p.params++
+			}
+			p.segs = append(p.segs, seg)
+		}
+		shared := s.params(item["parameters"], nil)
+		for _, m := range []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"} {
+			op, _ := item[m].(map[string]any)
+			if op == nil {
+				continue
+			}
+			p.ops[strings.ToUpper(m)] = s.operation(op, shared)
+		}
+		s.paths = append(s.paths, p)
+	}
+	// Prefer templates with fewer parameters: /users/me before /users/{id}.
+	sort.SliceStable(s.paths, func(i, j int) bool {
+		if s.paths[i].params != s.paths[j].params {
+			return s.paths[i].params < s.paths[j].params
+		}
+		return strings.Join(s.paths[i].segs, "/") < strings.Join(s.paths[j].segs, "/")
+	})
+	return s, nil
+}
+
+func (s *OpenAPISpec) operation(op map[string]any, shared []openAPIParam) *openAPIOp {
+	o := &openAPIOp{params: s.params(op["parameters"], shared), responses: map[string]map[string]any{}}
+	if rb, _ := s.resolve(op["requestBody"]).(map[string]any); rb != nil {
+		o.bodyReq, _ = rb["required"].(bool)
+		o.body = s.content(rb["content"])
+	}
+	if resps, _ := op["responses"].(map[string]any); resps != nil {
+		for code, v := range resps {
+			if r, _ := s.resolve(v).(map[string]any); r != nil {
+				o.responses[strings.ToUpper(code)] = s.content(r["content"])
+			}
+		}
+	}
+	return o
+}
+
+// params merges operation parameters over path-level ones by name and location.
+func (s *OpenAPISpec) params(v any, shared []openAPIParam) []openAPIParam {
+	out := append([]openAPIParam(nil), shared...)
+	list, _ := v.([]any)
+	for _, raw := range list {
+		m, _ := s.resolve(raw).(map[string]any)
+		if m == nil {
+			continue
+		}
+		p := openAPIParam{}
+		p.name, _ = m["name"].(string)
+		p.in, _ = m["in"].(string)
+		p.required, _ = m["required"].(bool)
+		p.schema, _ = m["schema"].(map[string]any)
+		if p.in == "header" {
+			p.name = http.CanonicalHeaderKey(p.name)
+		}
+		replaced := false
+		for i := range out {
+			if out[i].name == p.name && out[i].in == p.in {
+				out[i], replaced = p, true
+			}
+		}
+		if !replaced {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// content maps media types to their schemas.
+func (s *OpenAPISpec) content(v any) map[string]any {
+	c, _ := v.(map[string]any)
+	if len(c) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(c))
+	for mt, raw := range c {
+		m, _ := raw.(map[string]any)
+		out[strings.ToLower(mt)] = m["schema"]
+	}
+	return out
+}
+
+// resolve follows local "$ref" pointers such as "#/components/schemas/User".
+func (s *OpenAPISpec) resolve(v any) any {
+	for i := 0; i < 32; i++ {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return v
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok || !strings.HasPrefix(ref, "#/") {
+			return v
+		}
+		var cur any = s.doc
+		for _, tok := range strings.Split(ref[2:], "/") {
+			tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+			obj, _ := cur.(map[string]any)
+			cur = obj[tok]
+		}
+		v = cur
+	}
+	return v
+}
+
+// find returns the operation for method and path with its path parameters.
+// pathFound reports whether any template matched the path.
+func (s *OpenAPISpec) find(method, path string) (op *openAPIOp, params map[string]string, pathFound bool) {
+	if s.basePath != "" {
+		rest, ok := strings.CutPrefix(path, s.basePath)
+		if !ok {
+			return nil, nil, false
+		}
+		path = rest
+	}
+	var segs []string
+	for _, seg := range strings.Split(strings.Trim(path, "/"), "/") {
+		if seg != "" {
+			segs = append(segs, seg)
+		}
+	}
+	for _, p := range s.paths {
+		if len(p.segs) != len(segs) {
+			continue
+		}
+		vals := map[string]string{}
+		ok := true
+		for i, t := range p.segs {
+			if strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}") {
+				v, err := url.PathUnescape(segs[i])
+				if err != nil {
+					v = segs[i]
+				}
+				vals[t[1:len(t)-1]] = v
+			} else if t != segs[i] {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		pathFound = true
+		if op := p.ops[method]; op != nil {
+			return op, vals, true
+		}
+		if method == http.MethodHead {
+			if op := p.ops[http.MethodGet]; op != nil {
+				return op, vals, true
+			}
+		}
+	}
+	return nil, nil, pathFound
+}
+
+// OpenAPIValidatorConfig controls OpenAPIValidator.
+type OpenAPIValidatorConfig struct {
+	// ValidateResponses buffers each response and checks JSON bodies against
+	// the documented schema for its status; a mismatch is replaced by a 500.
+	// Streaming responses are held until the handler returns, so enable it
+	// in staging and tests rather than production.
+	ValidateResponses bool
+	// RejectUnknown answers requests for undocumented paths with 404 and
+	// undocumented methods with 405. By default they pass through.
+	RejectUnknown bool
+	// MaxBodyBytes bounds the request body read for validation (default 10 MiB).
+	MaxBodyBytes int64
+	// OnViolation writes the error response (default: c.Fail with the
+	// violations as detail). It must abort the chain.
+	OnViolation func(c *zentrox.Context, status int, violations []OpenAPIViolation)
+}
+
+// DefaultOpenAPIValidator returns request-only validation.
+func DefaultOpenAPIValidator() OpenAPIValidatorConfig {
+	return OpenAPIValidatorConfig{
+		MaxBodyBytes: 10 << 20,
+		OnViolation:  failViolations,
+	}
+}
+
+func failViolations(c *zentrox.Context, status int, v []OpenAPIViolation) {
+	msg := zentrox.MsgInvalidRequest
+	if status >= http.StatusInternalServerError {
+		msg = zentrox.MsgInvalidResponse
+	}
+	c.Fail(status, msg, v)
+}
+
+// OpenAPIValidator checks requests against spec: path, query and header
+// parameters (presence and type) and JSON request bodies against their
+// schemas, answering 400 (415 for an undocumented content type) with the
+// list of violations:
+//
+//	spec, err := middleware.LoadOpenAPI(specJSON)
+//	app.Plug(middleware.OpenAPIValidator(spec, middleware.OpenAPIValidatorConfig{ValidateResponses: true}))
+//
+// Schemas support the JSON Schema keywords generators emit: type, enum,
+// const, properties, required, additionalProperties, items, length, range,
+// pattern, common formats, allOf, anyOf, oneOf and not.
+func OpenAPIValidator(spec *OpenAPISpec, cfg ...OpenAPIValidatorConfig) zentrox.Handler {
+	def := DefaultOpenAPIValidator()
+	conf := def
+	if len(cfg) > 0 {
+		conf = cfg[0]
+		if conf.MaxBodyBytes <= 0 {
+			conf.MaxBodyBytes = def.MaxBodyBytes
+		}
+		if conf.OnViolation == nil {
+			conf.OnViolation = def.OnViolation
+		}
+	}
+
+	return func(c *zentrox.Context) {
+		op, pathVals, pathFound := spec.find(c.Request.Method, c.Request.URL.Path)
+		if op == nil {
+			if conf.RejectUnknown && c.Request.Method != http.MethodOptions {
+				status := http.StatusNotFound
+				msg := "path is not documented"
+				if pathFound {
+					status, msg = http.StatusMethodNotAllowed, "method is not documented"
+				}
+				conf.OnViolation(c, status, []OpenAPIViolation{{In: "path", Message: msg}})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		status, violations := spec.checkRequest(c.Request, op, pathVals, conf.MaxBodyBytes)
+		if len(violations) > 0 {
+			conf.OnViolation(c, status, violations)
+			return
+		}
+		if !conf.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		orig := c.Writer
+		bw := &bufferedWriter{ResponseWriter: orig}
+		c.Writer = bw
+		defer func() { c.Writer = orig }()
+		c.Next()
+		c.Writer = orig
+
+		code := bw.code
+		if code == 0 {
+			code = http.StatusOK
+		}
+		if v := spec.checkResponse(op, code, orig.Header().Get(zentrox.HeaderContentType), bw.buf.Bytes()); len(v) > 0 {
+			orig.Header().Del(zentrox.HeaderContentLength)
+			conf.OnViolation(c, http.StatusInternalServerError, v)
+			return
+		}
+		if bw.code != 0 || bw.buf.Len() > 0 {
+			orig.WriteHeader(code)
+			_, _ = orig.Write(bw.buf.Bytes())
+		}
+	}
+}
+
+// bufferedWriter holds the status and body until the response is validated.
+type bufferedWriter struct {
+	http.ResponseWriter
+	code int
+	buf  bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+	return w.buf.Write(b)
+}
+
+// Flush is a no-op: the body is released only after validation.
+func (w *bufferedWriter) Flush() {}
+
+func (w *bufferedWriter) Status() int { return w.code }
+
+func (w *bufferedWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (s *OpenAPISpec) checkRequest(r *http.Request, op *openAPIOp, pathVals map[string]string, maxBody int64) (int, []OpenAPIViolation) {
+	var out []OpenAPIViolation
+	query := r.URL.Query()
+	for _, p := range op.params {
+		var vals []string
+		switch p.in {
+		case "path":
+			if v, ok := pathVals[p.name]; ok {
+				vals = []string{v}
+			}
+		case "query":
+			vals = query[p.name]
+		case "header":
+			vals = r.Header.Values(p.name)
+		case "cookie":
+			if ck, err := r.Cookie(p.name); err == nil {
+				vals = []string{ck.Value}
+			}
+		default:
+			continue
+		}
+		if len(vals) == 0 {
+			if p.required || p.in == "path" {
+				out = append(out, OpenAPIViolation{In: p.in, Field: p.name, Message: "is required"})
+			}
+			continue
+		}
+		if p.schema == nil {
+			continue
+		}
+		v, err := s.coerce(p.schema, vals)
+		if err != nil {
+			out = append(out, OpenAPIViolation{In: p.in, Field: p.name, Message: err.Error()})
+			continue
+		}
+		for _, e := range s.validate(p.schema, v, p.name) {
+			out = append(out, OpenAPIViolation{In: p.in, Field: e.path, Message: e.msg})
+		}
+	}
+
+	if op.body == nil {
+		return http.StatusBadRequest, out
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody+1))
+	if err != nil {
+		return http.StatusBadRequest, append(out, OpenAPIViolation{In: "body", Message: "unreadable body"})
+	}
+	if int64(len(body)) > maxBody {
+		return http.StatusRequestEntityTooLarge, append(out, OpenAPIViolation{In: "body", Message: "body too large to validate"})
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) == 0 {
+		if op.bodyReq {
+			out = append(out, OpenAPIViolation{In: "body", Message: "is required"})
+		}
+		return http.StatusBadRequest, out
+	}
+	ct := r.Header.Get(zentrox.HeaderContentType)
+	schema, ok := mediaSchema(op.body, ct)
+	if !ok {
+		return http.StatusUnsupportedMediaType, append(out, OpenAPIViolation{In: "body", Message: fmt.Sprintf("content type %q is not documented", ct)})
+	}
+	if !isJSONType(ct) || schema == nil {
+		return http.StatusBadRequest, out
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return http.StatusBadRequest, append(out, OpenAPIViolation{In: "body", Message: "invalid JSON"})
+	}
+	for _, e := range s.validate(schema, v, "") {
+		out = append(out, OpenAPIViolation{In: "body", Field: e.path, Message: e.msg})
+	}
+	return http.StatusBadRequest, out
+}
+
+func (s *OpenAPISpec) checkResponse(op *openAPIOp, code int, ct string, body []byte) []OpenAPIViolation {
+	content, ok := op.responses[strconv.Itoa(code)]
+	if !ok {
+		content, ok = op.responses[strconv.Itoa(code/100)+"XX"]
+	}
+	if !ok {
+		content, ok = op.responses["DEFAULT"]
+	}
+	if !ok {
+		return []OpenAPIViolation{{In: "response", Message: fmt.Sprintf("status %d is not documented", code)}}
+	}
+	if len(body) == 0 || content == nil {
+		return nil
+	}
+	schema, ok := mediaSchema(content, ct)
+	if !ok {
+		return []OpenAPIViolation{{In: "response", Message: fmt.Sprintf("content type %q is not documented", ct)}}
+	}
+	if !isJSONType(ct) || schema == nil {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return []OpenAPIViolation{{In: "response", Message: "invalid JSON"}}
+	}
+	var out []OpenAPIViolation
+	for _, e := range s.validate(schema, v, "") {
+		out = append(out, OpenAPIViolation{In: "response", Field: e.path, Message: e.msg})
+	}
+	return out
+}
+
+// mediaSchema picks the schema for content type ct, honouring "type/*" and
+// "*/*" entries.
+func mediaSchema(content map[string]any, ct string) (any, bool) {
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		mt = strings.ToLower(strings.TrimSpace(ct))
+	}
+	if s, ok := content[mt]; ok {
+		return s, true
+	}
+	if major, _, ok := strings.Cut(mt, "/"); ok {
+		if s, ok := content[major+"/*"]; ok {
+			return s, true
+		}
+	}
+	s, ok := content["*/*"]
+	return s, ok
+}
+
+func isJSONType(ct string) bool {
+	mt, _, _ := mime.ParseMediaType(ct)
+	return mt == zentrox.ContentTypeJSON || strings.HasSuffix(mt, "+json")
+}
+
+// coerce converts parameter strings into the JSON value the schema expects.
+func (s *OpenAPISpec) coerce(schema map[string]any, vals []string) (any, error) {
+	schema, _ = s.resolve(schema).(map[string]any)
+	switch schemaType(schema) {
+	case "array":
+		if len(vals) == 1 {
+			vals = strings.Split(vals[0], ",")
+		}
+		items, _ := schema["items"].(map[string]any)
+		out := make([]any, 0, len(vals))
+		for _, v := range vals {
+			iv, err := s.coerce(items, []string{v})
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, iv)
+		}
+		return out, nil
+	case "integer", "number":
+		f, err := strconv.ParseFloat(vals[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be a number")
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(vals[0])
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean")
+		}
+		return b, nil
+	}
+	return vals[0], nil
+}
+
+// schemaType returns the first non-null type of a schema, or "".
+func schemaType(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		for _, v := range t {
+			if s, _ := v.(string); s != "null" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+type schemaError struct {
+	path, msg string
+}
+
+// validate checks v against schema, returning errors with JSON paths under at.
+func (s *OpenAPISpec) validate(raw any, v any, at string) []schemaError {
+	schema, _ := s.resolve(raw).(map[string]any)
+	if schema == nil {
+		if b, ok := raw.(bool); ok && !b {
+			return []schemaError{{at, "is not allowed"}}
+		}
+		return nil
+	}
+	var errs []schemaError
+	fail := func(format string, args ...any) {
+		errs = append(errs, schemaError{at, fmt.Sprintf(format, args...)})
+	}
+
+	if v == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || typeAllows(schema, "null") || schema["type"] == nil {
+			return nil
+		}
+	}
+	if t := schema["type"]; t != nil && !typeMatches(t, v) {
+		fail("must be of type %s", typeString(t))
+		return errs
+	}
+	if enum, ok := schema["enum"].([]any); ok && !containsValue(enum, v) {
+		fail("must be one of %s", compactJSON(enum))
+	}
+	if c, ok := schema["const"]; ok && !jsonEqual(c, v) {
+		fail("must be %s", compactJSON(c))
+	}
+
+	switch x := v.(type) {
+	case string:
+		n := float64(len([]rune(x)))
+		if m, ok := number(schema["minLength"]); ok && n < m {
+			fail("must be at least %v characters", m)
+		}
+		if m, ok := number(schema["maxLength"]); ok && n > m {
+			fail("must be at most %v characters", m)
+		}
+		if p, ok := schema["pattern"].(string); ok {
+			if re := s.regexp(p); re != nil && !re.MatchString(x) {
+				fail("must match pattern %s", p)
+			}
+		}
+		if f, ok := schema["format"].(string); ok {
+			if msg := checkFormat(f, x); msg != "" {
+				fail("%s", msg)
+			}
+		}
+	case float64:
+		if m, ok := number(schema["minimum"]); ok && x < m {
+			fail("must be >= %v", m)
+		}
+		if m, ok := number(schema["maximum"]); ok && x > m {
+			fail("must be <= %v", m)
+		}
+		// 3.1 uses numbers, 3.0 booleans modifying minimum/maximum.
+		if m, ok := number(schema["exclusiveMinimum"]); ok && x <= m {
+			fail("must be > %v", m)
+		} else if b, _ := schema["exclusiveMinimum"].(bool); b {
+			if m, ok := number(schema["minimum"]); ok && x == m {
+				fail("must be > %v", m)
+			}
+		}
+		if m, ok := number(schema["exclusiveMaximum"]); ok && x >= m {
+			fail("must be < %v", m)
+		} else if b, _ := schema["exclusiveMaximum"].(bool); b {
+			if m, ok := number(schema["maximum"]); ok && x == m {
+				fail("must be < %v", m)
+			}
+		}
+		if m, ok := number(schema["multipleOf"]); ok && m > 0 {
+			if q := x / m; math.Abs(q-math.Round(q)) > 1e-9 {
+				fail("must be a multiple of %v", m)
+			}
+		}
+	case []any:
+		n := float64(len(x))
+		if m, ok := number(schema["minItems"]); ok && n < m {
+			fail("must have at least %v items", m)
+		}
+		if m, ok := number(schema["maxItems"]); ok && n > m {
+			fail("must have at most %v items", m)
+		}
+		if items, ok := schema["items"]; ok {
+			for i, item := range x {
+				errs = append(errs, s.validate(items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	case map[string]any:
+		n := float64(len(x))
+		if m, ok := number(schema["minProperties"]); ok && n < m {
+			fail("must have at least %v properties", m)
+		}
+		if m, ok := number(schema["maxProperties"]); ok && n > m {
+			fail("must have at most %v properties", m)
+		}
+		if req, ok := schema["required"].([]any); ok {
+			for _, r := range req {
+				name, _ := r.(string)
+				if _, ok := x[name]; !ok {
+					errs = append(errs, schemaError{joinPath(at, name), "is required"})
+				}
+			}
+		}
+		props, _ := schema["properties"].(map[string]any)
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := props[k]; ok {
+				errs = append(errs, s.validate(ps, x[k], joinPath(at, k))...)
+				continue
+			}
+			switch ap := schema["additionalProperties"].(type) {
+			case bool:
+				if !ap {
+					errs = append(errs, schemaError{joinPath(at, k), "is not allowed"})
+				}
+			case map[string]any:
+				errs = append(errs, s.validate(ap, x[k], joinPath(at, k))...)
+			}
+		}
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, sub := range all {
+			errs = append(errs, s.validate(sub, v, at)...)
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if len(s.validate(sub, v, at)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("must match at least one schema in anyOf")
+		}
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		n := 0
+		for _, sub := range oneOf {
+			if len(s.validate(sub, v, at)) == 0 {
+				n++
+			}
+		}
+		if n != 1 {
+			fail("must match exactly one schema in oneOf")
+		}
+	}
+	if not, ok := schema["not"]; ok && len(s.validate(not, v, at)) == 0 {
+		fail("must not match the schema in not")
+	}
+	return errs
+}
+
+func (s *OpenAPISpec) regexp(p string) *regexp.Regexp {
+	s.reMu.Lock()
+	defer s.reMu.Unlock()
+	re, ok := s.re[p]
+	if !ok {
+		re, _ = regexp.Compile(p) // invalid patterns are ignored
+		s.re[p] = re
+	}
+	return re
+}
+
+var (
+	reUUID  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	reEmail = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	reDate  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	reDT    = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})$`)
+)
+
+func checkFormat(format, v string) string {
+	switch format {
+	case "uuid":
+		if !reUUID.MatchString(v) {
+			return "must be a UUID"
+		}
+	case "email":
+		if !reEmail.MatchString(v) {
+			return "must be an email address"
+		}
+	case "date":
+		if !reDate.MatchString(v) {
+			return "must be a date (YYYY-MM-DD)"
+		}
+	case "date-time":
+		if !reDT.MatchString(v) {
+			return "must be an RFC 3339 date-time"
+		}
+	case "uri":
+		if u, err := url.Parse(v); err != nil || u.Scheme == "" {
+			return "must be an absolute URI"
+		}
+	}
+	return ""
+}
+
+func typeMatches(t any, v any) bool {
+	switch t := t.(type) {
+	case string:
+		return valueIs(t, v)
+	case []any:
+		for _, x := range t {
+			if s, _ := x.(string); valueIs(s, v) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func typeAllows(schema map[string]any, name string) bool {
+	list, _ := schema["type"].([]any)
+	for _, x := range list {
+		if x == name {
+			return true
+		}
+	}
+	return false
+}
+
+func valueIs(t string, v any) bool {
+	switch t {
+	case "null":
+		return v == nil
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	}
+	return true
+}
+
+func typeString(t any) string {
+	if s, ok := t.(string); ok {
+		return s
+	}
+	return compactJSON(t)
+}
+
+func number(v any) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func containsValue(list []any, v any) bool {
+	for _, x := range list {
+		if jsonEqual(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonEqual(a, b any) bool {
+	return compactJSON(a) == compactJSON(b)
+}
+
+func compactJSON(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func joinPath(at, name string) string {
+	if at == "" {
+		return name
+	}
+	return at + "." + name
+}
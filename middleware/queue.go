@@ -0,0 +1,203 @@
+package middleware
+
+import (
+	"container/list"
+	"expvar"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// Gauge receives the current queue depth. *expvar.Int satisfies it.
+type Gauge interface {
+	Set(int64)
+}
+
+type QueueConfig struct {
+	// MaxConcurrent requests run at once; the rest wait in FIFO order.
+	MaxConcurrent int
+	// MaxQueue bounds the number of waiting requests (default 256). A
+	// negative value disables queueing: requests beyond MaxConcurrent are
+	// rejected at once.
+	MaxQueue int
+	// MaxWait is how long a request may wait for a slot.
+	MaxWait time.Duration
+	// RetryAfter is advertised to rejected clients.
+	RetryAfter time.Duration
+	// Depth reports the number of waiting requests. Defaults to an expvar
+	// entry named Name in the "zentrox_queue_depth" map.
+	Depth Gauge
+	// Name keys the default Depth gauge and must be unique among queues
+	// using it; when empty a name "queue-N" is derived in creation order.
+	Name     string
+	OnReject func(c *zentrox.Context, retryAfter time.Duration)
+}
+
+func DefaultQueue() QueueConfig {
+	return QueueConfig{
+		MaxConcurrent: 64,
+		MaxQueue:      256,
+		MaxWait:       2 * time.Second,
+		RetryAfter:    time.Second,
+		OnReject: func(c *zentrox.Context, retryAfter time.Duration) {
+			c.SetHeader(zentrox.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.Fail(http.StatusTooManyRequests, zentrox.MsgTooManyRequests)
+		},
+	}
+}
+
+var (
+	queueDepthOnce sync.Once
+	queueDepth     *expvar.Map
+	queueNamesMu   sync.Mutex
+	queueSeq       int
+)
+
+// QueueDepthVar returns the expvar map holding per-queue depth gauges.
+func QueueDepthVar() *expvar.Map {
+	queueDepthOnce.Do(func() {
+		queueDepth = expvar.NewMap("zentrox_queue_depth")
+	})
+	return queueDepth
+}
+
+// queueGauge registers the default depth gauge of a Queue under name, or
+// under a derived "queue-N" when name is empty. It panics if name is taken,
+// since two queues would otherwise report into the same gauge.
+func queueGauge(name string) *expvar.Int {
+	queueNamesMu.Lock()
+	defer queueNamesMu.Unlock()
+	m := QueueDepthVar()
+	if name == "" {
+		for name == "" || m.Get(name) != nil {
+			queueSeq++
+			name = "queue-" + strconv.Itoa(queueSeq)
+		}
+	} else if m.Get(name) != nil {
+		panic("middleware: Queue name " + strconv.Quote(name) + " already used")
+	}
+	v := new(expvar.Int)
+	m.Set(name, v)
+	return v
+}
+
+// Queue runs at most MaxConcurrent requests and holds excess ones in a
+// bounded FIFO for up to MaxWait, for bursty workloads where brief queueing
+// beats shedding load immediately. When the queue is full or the wait expires
+// the request is rejected with 429 and Retry-After.
+func Queue(cfg QueueConfig) zentrox.Handler {
+	def := DefaultQueue()
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = def.MaxConcurrent
+	}
+	if cfg.MaxQueue == 0 {
+		cfg.MaxQueue = def.MaxQueue
+	} else if cfg.MaxQueue < 0 {
+		cfg.MaxQueue = 0
+	}
+	if cfg.MaxWait <= 0 {
+		cfg.MaxWait = def.MaxWait
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = def.RetryAfter
+	}
+	if cfg.Depth == nil {
+		cfg.Depth = queueGauge(cfg.Name)
+	}
+	if cfg.OnReject == nil {
+		cfg.OnReject = def.OnReject
+	}
+
+	q := &fifoQueue{max: cfg.MaxConcurrent, maxWaiting: cfg.MaxQueue, depth: cfg.Depth}
+
+	return func(c *zentrox.Context) {
+		switch q.acquire(c.Done(), cfg.MaxWait) {
+		case queueAcquired:
+			defer q.release()
+			c.Next()
+		case queueCanceled:
+			c.Abort()
+		default:
+			cfg.OnReject(c, cfg.RetryAfter)
+			c.Abort()
+		}
+	}
+}
+
+type queueResult int
+
+const (
+	queueAcquired queueResult = iota
+	queueRejected
+	queueCanceled
+)
+
+// fifoQueue is a counting semaphore that hands freed slots to waiters in
+// arrival order.
+type fifoQueue struct {
+	mu         sync.Mutex
+	active     int
+	max        int
+	maxWaiting int
+	waiters    list.List // of chan struct{}
+	depth      Gauge
+}
+
+func (q *fifoQueue) acquire(done <-chan struct{}, maxWait time.Duration) queueResult {
+	q.mu.Lock()
+	if q.active < q.max && q.waiters.Len() == 0 {
+		q.active++
+		q.mu.Unlock()
+		return queueAcquired
+	}
+	if q.waiters.Len() >= q.maxWaiting {
+		q.mu.Unlock()
+		return queueRejected
+	}
+	ready := make(chan struct{})
+	el := q.waiters.PushBack(ready)
+	q.depth.Set(int64(q.waiters.Len()))
+	q.mu.Unlock()
+
+	timer := time.NewTimer(maxWait)
+	defer timer.Stop()
+
+	result := queueRejected
+	select {
+	case <-ready:
+		return queueAcquired
+	case <-timer.C:
+	case <-done:
+		result = queueCanceled
+	}
+
+	q.mu.Lock()
+	select {
+	case <-ready:
+		// A slot was handed over while giving up; pass it on.
+		q.mu.Unlock()
+		q.release()
+		return result
+	default:
+	}
+	q.waiters.Remove(el)
+	q.depth.Set(int64(q.waiters.Len()))
+	q.mu.Unlock()
+	return result
+}
+
+func (q *fifoQueue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if front := q.waiters.Front(); front != nil {
+		q.waiters.Remove(front)
+		q.depth.Set(int64(q.waiters.Len()))
+		close(front.Value.(chan struct{}))
+		return
+	}
+	q.active--
+}
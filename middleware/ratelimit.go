@@ -14,6 +14,10 @@ type RateLimitConfig struct {
 	KeyFunc    func(*zentrox.Context) string
 	OnLimit    func(*zentrox.Context)
 	StaleAfter time.Duration
+	// Store, when set, counts requests in a shared store using fixed windows of
+	// Burst/Rate seconds (Burst requests per window) instead of the in-memory
+	// token bucket. Store errors let the request through.
+	Store Store
 }
 
 type bucket struct {
@@ -58,6 +62,23 @@ func RateLimit(cfg RateLimitConfig) zentrox.Handler {
 		cfg.StaleAfter = 10 * time.Minute
 	}
 
+	if cfg.Store != nil {
+		window := time.Duration(cfg.Burst / cfg.Rate * float64(time.Second))
+		return func(c *zentrox.Context) {
+			key := cfg.KeyFunc(c)
+			if key == "" {
+				key = "global"
+			}
+			n, err := cfg.Store.Incr("ratelimit:"+key, window)
+			if err == nil && float64(n) > cfg.Burst {
+				cfg.OnLimit(c)
+				c.Abort()
+				return
+			}
+			c.Next()
+		}
+	}
+
 	var mu sync.Mutex
 	buckets := make(map[string]*bucket)
 	lastCleanup := time.Now()
@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// RBACConfig controls RequireRoles and RequirePermissions.
+type RBACConfig struct {
+	// ContextKey is where JWT stored the claims (default "user").
+	ContextKey string
+	// RolesClaim names the roles claim (default "roles"; a singular "role"
+	// claim is used when it is absent). PermissionsClaim names the
+	// permissions claim (default "permissions"; set "scope" for OAuth
+	// scopes). Claims may be arrays or space/comma separated strings.
+	RolesClaim       string
+	PermissionsClaim string
+	// Policy, when set, must also allow the request, e.g. to let users
+	// reach only their own resources.
+	Policy func(c *zentrox.Context, claims map[string]any) bool
+	// OnUnauthorized handles requests without claims (default 401);
+	// OnForbidden handles denied requests (default 403). The chain is
+	// aborted afterwards.
+	OnUnauthorized func(c *zentrox.Context)
+	OnForbidden    func(c *zentrox.Context)
+}
+
+// DefaultRBAC returns the configuration used by RequireRoles and
+// RequirePermissions.
+func DefaultRBAC() RBACConfig {
+	return RBACConfig{
+		ContextKey:       "user",
+		RolesClaim:       "roles",
+		PermissionsClaim: "permissions",
+		OnUnauthorized: func(c *zentrox.Context) {
+			c.Fail(http.StatusUnauthorized, zentrox.MsgUnauthorized)
+		},
+		OnForbidden: func(c *zentrox.Context) {
+			c.Fail(http.StatusForbidden, zentrox.MsgForbidden)
+		},
+	}
+}
+
+// RequireRoles allows requests whose JWT claims hold at least one of roles:
+//
+//	admin := app.Scope("/admin", middleware.JWT(jwtCfg), middleware.RequireRoles("admin", "owner"))
+func RequireRoles(roles ...string) zentrox.Handler {
+	return DefaultRBAC().RequireRoles(roles...)
+}
+
+// RequirePermissions allows requests whose JWT claims hold every one of perms.
+func RequirePermissions(perms ...string) zentrox.Handler {
+	return DefaultRBAC().RequirePermissions(perms...)
+}
+
+// RequireRoles is the package-level RequireRoles with cfg.
+func (cfg RBACConfig) RequireRoles(roles ...string) zentrox.Handler {
+	cfg = cfg.withDefaults()
+	return cfg.require(func(claims map[string]any) bool {
+		v, ok := claims[cfg.RolesClaim]
+		if !ok && cfg.RolesClaim == "roles" {
+			v = claims["role"]
+		}
+		have := claimSet(v)
+		for _, r := range roles {
+			if have[r] {
+				return true
+			}
+		}
+		return len(roles) == 0
+	})
+}
+
+// RequirePermissions is the package-level RequirePermissions with cfg.
+func (cfg RBACConfig) RequirePermissions(perms ...string) zentrox.Handler {
+	cfg = cfg.withDefaults()
+	return cfg.require(func(claims map[string]any) bool {
+		have := claimSet(claims[cfg.PermissionsClaim])
+		for _, p := range perms {
+			if !have[p] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (cfg RBACConfig) withDefaults() RBACConfig {
+	def := DefaultRBAC()
+	if cfg.ContextKey == "" {
+		cfg.ContextKey = def.ContextKey
+	}
+	if cfg.RolesClaim == "" {
+		cfg.RolesClaim = def.RolesClaim
+	}
+	if cfg.PermissionsClaim == "" {
+		cfg.PermissionsClaim = def.PermissionsClaim
+	}
+	if cfg.OnUnauthorized == nil {
+		cfg.OnUnauthorized = def.OnUnauthorized
+	}
+	if cfg.OnForbidden == nil {
+		cfg.OnForbidden = def.OnForbidden
+	}
+	return cfg
+}
+
+func (cfg RBACConfig) require(allowed func(map[string]any) bool) zentrox.Handler {
+	return func(c *zentrox.Context) {
+		claims, ok := zentrox.Get[map[string]any](c, cfg.ContextKey)
+		if !ok {
+			cfg.OnUnauthorized(c)
+			c.Abort()
+			return
+		}
+		if !allowed(claims) || (cfg.Policy != nil && !cfg.Policy(c, claims)) {
+			cfg.OnForbidden(c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// claimSet reads a claim holding a string array or a space/comma separated
+// string.
+func claimSet(v any) map[string]bool {
+	out := map[string]bool{}
+	switch x := v.(type) {
+	case string:
+		for _, s := range strings.FieldsFunc(x, func(r rune) bool { return r == ' ' || r == ',' }) {
+			out[s] = true
+		}
+	case []any:
+		for _, e := range x {
+			if s, ok := e.(string); ok {
+				out[s] = true
+			}
+		}
+	case []string:
+		for _, s := range x {
+			out[s] = true
+		}
+	}
+	return out
+}
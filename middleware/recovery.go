@@ -12,6 +12,12 @@ func Recovery() zentrox.Handler {
 		defer func() {
 			if r := recover(); r != nil {
 				log.Printf("panic: %v", r)
+				c.Emit(zentrox.EventRequestPanic, r)
+				if c.App() != nil && c.App().ProblemJSON() {
+					c.Problem(zentrox.ProblemDetails{Status: http.StatusInternalServerError, Title: zentrox.MsgInternalServerError, Instance: c.Request.URL.Path})
+					c.Abort()
+					return
+				}
 				c.JSON(http.StatusInternalServerError, zentrox.HTTPError{
 					Code:    http.StatusInternalServerError,
 					Message: zentrox.MsgInternalServerError,
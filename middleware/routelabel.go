@@ -0,0 +1,14 @@
+package middleware
+
+import "github.com/aminofox/zentrox/v2"
+
+// RouteLabel returns the metric/tracing label for the request: the matched
+// route template, or "unmatched" for requests no route handled. Raw paths
+// would put IDs into labels; SlowRequest, StructuredLogger, ConcurrencyLimit
+// and CircuitBreaker all key by it.
+func RouteLabel(c *zentrox.Context) string {
+	if p := c.RoutePath(); p != "" {
+		return p
+	}
+	return "unmatched"
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/session"
+)
+
+// Session keeps server-side sessions in store, identified by an HttpOnly
+// cookie, and exposes them to handlers as c.Session():
+//
+//	app.Plug(middleware.Session(session.NewMemoryStore()))
+//	app.Plug(middleware.Session(session.NewRedisStore(session.RedisConfig{Addr: "redis:6379"})))
+//
+// cfg sets cookie attributes and timeouts; zero fields (and a nil store)
+// take session.DefaultConfig values. See session.Middleware for details.
+func Session(store session.Store, cfg ...session.Config) zentrox.Handler {
+	var conf session.Config
+	if len(cfg) > 0 {
+		conf = cfg[0]
+	}
+	if store != nil {
+		conf.Store = store
+	}
+	return session.Middleware(conf)
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// SignatureConfig configures VerifySignature.
+type SignatureConfig struct {
+	// Secret is the shared HMAC key.
+	Secret []byte
+	// KeyFunc, if set, picks the key per request instead of Secret, e.g.
+	// by an X-API-Key header for several partners. Returning nil rejects
+	// the request.
+	KeyFunc func(*zentrox.Context) []byte
+	// MaxSkew is how far X-Timestamp may be from the server clock (default
+	// 5m). Pair with Nonce to reject replays inside the window.
+	MaxSkew time.Duration
+	// MaxBody is the largest body read to verify the signature (default
+	// 10 MiB); larger requests get 413.
+	MaxBody int64
+	// OnReject answers unsigned, invalid or stale requests (default 401
+	// via c.Fail); reason is a zentrox.Msg* value.
+	OnReject func(c *zentrox.Context, reason string)
+}
+
+// DefaultSignature returns the default SignatureConfig for secret.
+func DefaultSignature(secret []byte) SignatureConfig {
+	return SignatureConfig{
+		Secret:  secret,
+		MaxSkew: 5 * time.Minute,
+		MaxBody: 10 << 20,
+		OnReject: func(c *zentrox.Context, reason string) {
+			c.Fail(http.StatusUnauthorized, reason)
+		},
+	}
+}
+
+// VerifySignature checks the X-Signature header of requests signed with
+// zentrox.SignRequest: an HMAC-SHA256 over the timestamp, nonce, method, path
+// with query and body. Requests with a missing or wrong signature, or a
+// timestamp outside MaxSkew, are rejected with 401. The body is restored for
+// the handler.
+//
+//	partner := app.Scope("/partner",
+//		middleware.VerifySignature(middleware.DefaultSignature(secret)),
+//		middleware.Nonce(middleware.DefaultNonce()),
+//	)
+func VerifySignature(cfg SignatureConfig) zentrox.Handler {
+	def := DefaultSignature(nil)
+	if cfg.MaxSkew <= 0 {
+		cfg.MaxSkew = def.MaxSkew
+	}
+	if cfg.MaxBody <= 0 {
+		cfg.MaxBody = def.MaxBody
+	}
+	if cfg.OnReject == nil {
+		cfg.OnReject = def.OnReject
+	}
+	if cfg.KeyFunc == nil {
+		if len(cfg.Secret) == 0 {
+			panic("VerifySignature: Secret or KeyFunc is required")
+		}
+		cfg.KeyFunc = func(*zentrox.Context) []byte { return cfg.Secret }
+	}
+
+	return func(c *zentrox.Context) {
+		sig := c.GetHeader(zentrox.HeaderXSignature)
+		ts := c.GetHeader(zentrox.HeaderXTimestamp)
+		if sig == "" || ts == "" {
+			cfg.OnReject(c, zentrox.MsgInvalidSignature)
+			c.Abort()
+			return
+		}
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			cfg.OnReject(c, zentrox.MsgStaleRequest)
+			c.Abort()
+			return
+		}
+		skew := time.Since(time.Unix(sec, 0))
+		if skew < 0 {
+			skew = -skew
+		}
+		if skew > cfg.MaxSkew {
+			cfg.OnReject(c, zentrox.MsgStaleRequest)
+			c.Abort()
+			return
+		}
+		key := cfg.KeyFunc(c)
+		if len(key) == 0 {
+			cfg.OnReject(c, zentrox.MsgInvalidSignature)
+			c.Abort()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, cfg.MaxBody))
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					c.Fail(http.StatusRequestEntityTooLarge, zentrox.MsgPayloadTooLarge)
+					return
+				}
+				c.Fail(http.StatusBadRequest, zentrox.MsgInvalidSignature)
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		want := zentrox.RequestSignature(key, ts, c.GetHeader(zentrox.HeaderXNonce), c.Request.Method, c.Request.URL.RequestURI(), body)
+		if !hmac.Equal([]byte(sig), []byte(want)) {
+			cfg.OnReject(c, zentrox.MsgInvalidSignature)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
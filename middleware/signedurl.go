@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// VerifySignedURL rejects requests whose URL was not produced by
+// app.SignURL with secret, or whose link has expired, with 403.
+func VerifySignedURL(secret []byte) zentrox.Handler {
+	return func(c *zentrox.Context) {
+		err := zentrox.VerifyURLSignature(secret, c.Request.URL, time.Now())
+		if errors.Is(err, zentrox.ErrSignedURLExpired) {
+			c.Fail(http.StatusForbidden, zentrox.MsgLinkExpired)
+			return
+		}
+		if err != nil {
+			c.Fail(http.StatusForbidden, zentrox.MsgInvalidSignature)
+			return
+		}
+		c.Next()
+	}
+}
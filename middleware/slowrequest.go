@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"expvar"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// Counter is incremented for every slow request. *expvar.Int satisfies it;
+// wrap a metrics client counter to export elsewhere.
+type Counter interface {
+	Add(int64)
+}
+
+type SlowRequestConfig struct {
+	Threshold time.Duration
+	Logger    *slog.Logger
+	Counter   Counter
+	// OnSlow, if set, runs after the request is logged.
+	OnSlow func(c *zentrox.Context, elapsed time.Duration)
+}
+
+var (
+	slowRequestsOnce    sync.Once
+	slowRequests        *expvar.Int
+	slowRequestsByRoute *expvar.Map
+)
+
+func publishSlowRequestVars() {
+	slowRequestsOnce.Do(func() {
+		slowRequests = expvar.NewInt("zentrox_slow_requests_total")
+		slowRequestsByRoute = expvar.NewMap("zentrox_slow_requests_by_route")
+	})
+}
+
+// SlowRequestsVar returns the default counter, published through expvar as
+// "zentrox_slow_requests_total".
+func SlowRequestsVar() *expvar.Int {
+	publishSlowRequestVars()
+	return slowRequests
+}
+
+// SlowRequestsByRouteVar returns the expvar map "zentrox_slow_requests_by_route",
+// keyed by route template (see RouteLabel) so IDs in URLs never become keys.
+func SlowRequestsByRouteVar() *expvar.Map {
+	publishSlowRequestVars()
+	return slowRequestsByRoute
+}
+
+func DefaultSlowRequest() SlowRequestConfig {
+	return SlowRequestConfig{
+		Threshold: time.Second,
+		Counter:   SlowRequestsVar(),
+	}
+}
+
+// SlowRequest logs requests that take longer than Threshold at WARN level,
+// with the route template, path params and request id, and increments Counter.
+func SlowRequest(cfg SlowRequestConfig) zentrox.Handler {
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = time.Second
+	}
+	if cfg.Counter == nil {
+		cfg.Counter = SlowRequestsVar()
+	}
+
+	return func(c *zentrox.Context) {
+		start := time.Now()
+		c.Next()
+
+		elapsed := time.Since(start)
+		if elapsed < cfg.Threshold {
+			return
+		}
+
+		cfg.Counter.Add(1)
+		route := RouteLabel(c)
+		SlowRequestsByRouteVar().Add(c.Request.Method+" "+route, 1)
+
+		logger := cfg.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		attrs := []any{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", responseStatus(c)),
+			slog.Duration("duration", elapsed),
+			slog.Duration("threshold", cfg.Threshold),
+			slog.Any("params", c.Params()),
+		}
+		if id := c.RequestID(); id != "" {
+			attrs = append(attrs, slog.String("request_id", id))
+		}
+		logger.WarnContext(c.Request.Context(), "slow request", attrs...)
+
+		if cfg.OnSlow != nil {
+			cfg.OnSlow(c, elapsed)
+		}
+	}
+}
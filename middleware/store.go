@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// Store is a counter store with per-key expiry, shared by RateLimit and
+// LoginThrottle. Implement it on top of Redis or similar to share limits
+// across instances.
+type Store interface {
+	// Incr adds one to key and returns the new value. ttl applies when the
+	// key is created; later increments do not extend it.
+	Incr(key string, ttl time.Duration) (int64, error)
+	// Get returns the value of key and its remaining lifetime, or 0, 0 when
+	// the key does not exist.
+	Get(key string) (int64, time.Duration, error)
+	// Set stores n under key for ttl, replacing any previous value.
+	Set(key string, n int64, ttl time.Duration) error
+	Delete(key string) error
+}
+
+type storeEntry struct {
+	n       int64
+	expires time.Time
+}
+
+// MemoryStore is an in-process Store.
+type MemoryStore struct {
+	mu          sync.Mutex
+	entries     map[string]storeEntry
+	lastCleanup time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]storeEntry), lastCleanup: time.Now()}
+}
+
+func (s *MemoryStore) Incr(key string, ttl time.Duration) (int64, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cleanup(now)
+	e, ok := s.entries[key]
+	if !ok || !now.Before(e.expires) {
+		e = storeEntry{expires: now.Add(ttl)}
+	}
+	e.n++
+	s.entries[key] = e
+	return e.n, nil
+}
+
+func (s *MemoryStore) Get(key string) (int64, time.Duration, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[key]
+	if !ok || !now.Before(e.expires) {
+		return 0, 0, nil
+	}
+	return e.n, e.expires.Sub(now), nil
+}
+
+func (s *MemoryStore) Set(key string, n int64, ttl time.Duration) error {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cleanup(now)
+	s.entries[key] = storeEntry{n: n, expires: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	delete(s.entries, key)
+	s.mu.Unlock()
+	return nil
+}
+
+// cleanup drops expired entries at most once a minute. Callers hold s.mu.
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < time.Minute {
+		return
+	}
+	for k, e := range s.entries {
+		if !now.Before(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+	s.lastCleanup = now
+}
@@ -4,47 +4,128 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/aminofox/zentrox/v2"
 )
 
 type TimeoutConfig struct {
-	Duration  time.Duration
-	OnTimeout func(*zentrox.Context)
+	Duration time.Duration
+	// StatusCode is sent by the default OnTimeout: 504 (default) or 503.
+	StatusCode int
+	OnTimeout  func(*zentrox.Context)
 }
 
 func Timeout(d time.Duration) zentrox.Handler {
 	return TimeoutWithConfig(TimeoutConfig{Duration: d})
 }
 
+// TimeoutWithConfig applies cfg.Duration to every request, unless the matched
+// route declares its own with Route.Timeout or Scope.Timeout.
+//
+// The deadline is set on the request context; handlers should watch c.Done().
+// Once it passes, anything the handler (or a goroutine it started) writes is
+// discarded with http.ErrHandlerTimeout and OnTimeout renders the response
+// after the chain returns. A response that was already started before the
+// deadline is left alone.
 func TimeoutWithConfig(cfg TimeoutConfig) zentrox.Handler {
-	if cfg.Duration <= 0 {
-		return func(c *zentrox.Context) { c.Next() }
+	if cfg.StatusCode == 0 {
+		cfg.StatusCode = http.StatusGatewayTimeout
 	}
 	if cfg.OnTimeout == nil {
 		cfg.OnTimeout = func(c *zentrox.Context) {
-			c.Fail(http.StatusGatewayTimeout, zentrox.MsgRequestTimeout)
+			c.Fail(cfg.StatusCode, zentrox.MsgRequestTimeout)
 		}
 	}
 
 	return func(c *zentrox.Context) {
-		ctx, cancel := context.WithTimeout(c.Request.Context(), cfg.Duration)
+		d := cfg.Duration
+		if rd := c.RouteTimeout(); rd > 0 {
+			d = rd
+		}
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
 		defer cancel()
 
+		orig := c.Writer
+		tw := &timeoutWriter{ResponseWriter: orig, ctx: ctx}
 		c.Request = c.Request.WithContext(ctx)
+		c.Writer = tw
 		c.Next()
+		c.Writer = orig
 
-		if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			return
-		}
+		tw.mu.Lock()
+		timedOut := !tw.wrote && errors.Is(ctx.Err(), context.DeadlineExceeded)
+		tw.closed = true
+		tw.mu.Unlock()
 
-		status := 0
-		if rw, ok := c.Writer.(interface{ Status() int }); ok {
-			status = rw.Status()
-		}
-		if status == 0 && !c.Aborted() {
+		if timedOut {
 			cfg.OnTimeout(c)
 		}
 	}
 }
+
+// timeoutWriter drops writes once the deadline has passed without a response
+// having been started, and all writes after the middleware returned.
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx context.Context
+
+	mu     sync.Mutex
+	wrote  bool
+	closed bool
+}
+
+// allow reports whether a write may go through; w.mu must be held.
+func (w *timeoutWriter) allow() bool {
+	if w.closed {
+		return false
+	}
+	if !w.wrote && w.ctx.Err() != nil {
+		w.closed = true
+		return false
+	}
+	w.wrote = true
+	return true
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.allow() {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.allow() {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.wrote && !w.closed {
+		_ = http.NewResponseController(w.ResponseWriter).Flush()
+	}
+}
+
+func (w *timeoutWriter) Status() int {
+	if rw, ok := w.ResponseWriter.(interface{ Status() int }); ok {
+		return rw.Status()
+	}
+	return 0
+}
+
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"maps"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// Token types stored in the "typ" claim of issued tokens.
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// ErrWrongTokenType is returned when an access token is used to refresh, or
+// a refresh token to authenticate.
+var ErrWrongTokenType = errors.New("wrong token type")
+
+// TokenServiceConfig controls NewTokenService.
+type TokenServiceConfig struct {
+	// JWT signs (with JWTConfig.Sign) and verifies tokens; its Issuer and
+	// Audience are written into issued tokens.
+	JWT JWTConfig
+	// Signer overrides JWT.Sign, e.g. to issue RS256 tokens with SignRS256.
+	Signer func(claims map[string]any) (string, error)
+	// AccessTTL and RefreshTTL default to 15 minutes and 7 days.
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	// Revoker records rotated and revoked tokens. Without it refresh tokens
+	// stay valid until they expire and Revoke fails.
+	Revoker Revoker
+	// Claims reloads the access token claims for subject on Refresh, e.g.
+	// current roles from the database. Without it refreshed access tokens
+	// carry only the standard claims.
+	Claims func(ctx context.Context, subject string) (map[string]any, error)
+	// OnReuse is called when an already rotated refresh token is presented,
+	// a sign that it was stolen; revoke the subject's sessions there.
+	OnReuse func(ctx context.Context, claims map[string]any)
+}
+
+// TokenPair is the usual OAuth-style token response.
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// TokenService issues, rotates and revokes access and refresh tokens:
+//
+//	tokens := middleware.NewTokenService(middleware.TokenServiceConfig{
+//		JWT:     middleware.JWTConfig{Secret: secret, Issuer: "myapp"},
+//		Revoker: middleware.NewStoreRevoker(middleware.NewMemoryStore()),
+//	})
+//	pair, err := tokens.IssuePair(user.ID, map[string]any{"roles": user.Roles})
+//	api := app.Scope("/api", tokens.Middleware())
+//
+// Every token gets a random "jti", so rotation and revocation work per token.
+type TokenService struct {
+	cfg TokenServiceConfig
+}
+
+// NewTokenService returns a TokenService with defaults applied.
+func NewTokenService(cfg TokenServiceConfig) *TokenService {
+	if cfg.AccessTTL <= 0 {
+		cfg.AccessTTL = 15 * time.Minute
+	}
+	if cfg.RefreshTTL <= 0 {
+		cfg.RefreshTTL = 7 * 24 * time.Hour
+	}
+	if cfg.Signer == nil {
+		cfg.Signer = cfg.JWT.Sign
+	}
+	if cfg.JWT.RevocationChecker == nil && cfg.Revoker != nil {
+		cfg.JWT.RevocationChecker = cfg.Revoker
+	}
+	return &TokenService{cfg: cfg}
+}
+
+// IssueAccessToken signs an access token for subject with extra claims.
+func (s *TokenService) IssueAccessToken(subject string, extra map[string]any) (string, error) {
+	return s.issue(subject, TokenTypeAccess, s.cfg.AccessTTL, extra)
+}
+
+// IssueRefreshToken signs a refresh token for subject.
+func (s *TokenService) IssueRefreshToken(subject string) (string, error) {
+	return s.issue(subject, TokenTypeRefresh, s.cfg.RefreshTTL, nil)
+}
+
+// IssuePair issues an access and a refresh token.
+func (s *TokenService) IssuePair(subject string, extra map[string]any) (TokenPair, error) {
+	access, err := s.IssueAccessToken(subject, extra)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	refresh, err := s.IssueRefreshToken(subject)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.cfg.AccessTTL / time.Second),
+	}, nil
+}
+
+// Refresh verifies a refresh token and returns a new pair. With a Revoker the
+// presented token is revoked (rotation); presenting it again returns
+// ErrTokenRevoked and triggers OnReuse.
+func (s *TokenService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	claims, err := s.cfg.JWT.Verify(ctx, refreshToken)
+	if errors.Is(err, ErrTokenRevoked) && s.cfg.OnReuse != nil {
+		if c, perr := s.peek(ctx, refreshToken); perr == nil {
+			s.cfg.OnReuse(ctx, c)
+		}
+	}
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if claims["typ"] != TokenTypeRefresh {
+		return TokenPair{}, ErrWrongTokenType
+	}
+	sub, _ := claims["sub"].(string)
+	var extra map[string]any
+	if s.cfg.Claims != nil {
+		if extra, err = s.cfg.Claims(ctx, sub); err != nil {
+			return TokenPair{}, err
+		}
+	}
+	if s.cfg.Revoker != nil {
+		if err := RevokeClaims(ctx, s.cfg.Revoker, claims); err != nil {
+			return TokenPair{}, err
+		}
+	}
+	return s.IssuePair(sub, extra)
+}
+
+// Revoke blacklists token (access or refresh) until it expires, e.g. on
+// logout.
+func (s *TokenService) Revoke(ctx context.Context, token string) error {
+	if s.cfg.Revoker == nil {
+		return errors.New("jwt: TokenService has no Revoker")
+	}
+	return Revoke(ctx, s.cfg.Revoker, token)
+}
+
+// Middleware authenticates requests with access tokens from this service,
+// rejecting refresh tokens and revoked tokens.
+func (s *TokenService) Middleware() zentrox.Handler {
+	cfg := s.cfg.JWT
+	validate := cfg.ValidateFunc
+	cfg.ValidateFunc = func(claims map[string]any) error {
+		if typ, ok := claims["typ"]; ok && typ != TokenTypeAccess {
+			return ErrWrongTokenType
+		}
+		if validate != nil {
+			return validate(claims)
+		}
+		return nil
+	}
+	return JWT(cfg)
+}
+
+func (s *TokenService) issue(subject, typ string, ttl time.Duration, extra map[string]any) (string, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := make(map[string]any, len(extra)+7)
+	maps.Copy(claims, extra)
+	claims["sub"] = subject
+	claims["typ"] = typ
+	claims["jti"] = hex.EncodeToString(id[:])
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(ttl).Unix()
+	if s.cfg.JWT.Issuer != "" {
+		claims["iss"] = s.cfg.JWT.Issuer
+	}
+	switch len(s.cfg.JWT.Audience) {
+	case 0:
+	case 1:
+		claims["aud"] = s.cfg.JWT.Audience[0]
+	default:
+		claims["aud"] = s.cfg.JWT.Audience
+	}
+	return s.cfg.Signer(claims)
+}
+
+// peek verifies token ignoring revocation, to hand a reused token's claims
+// to OnReuse.
+func (s *TokenService) peek(ctx context.Context, token string) (map[string]any, error) {
+	cfg := s.cfg.JWT
+	cfg.RevocationChecker = nil
+	return cfg.Verify(ctx, token)
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/session"
+)
+
+// TwoFactorConfig configures RequireTwoFactor. A request passes when the JWT
+// claims stored under ClaimsKey have Flag set to true, or the session stored
+// under SessionKey holds Flag = true.
+type TwoFactorConfig struct {
+	ClaimsKey  string
+	SessionKey string
+	Flag       string
+	OnDenied   func(*zentrox.Context)
+}
+
+func DefaultTwoFactor() TwoFactorConfig {
+	return TwoFactorConfig{
+		ClaimsKey:  "user",
+		SessionKey: "session",
+		Flag:       "2fa_verified",
+		OnDenied: func(c *zentrox.Context) {
+			c.Fail(http.StatusForbidden, zentrox.MsgTwoFactorRequired)
+		},
+	}
+}
+
+// RequireTwoFactor guards sensitive scopes so that only requests from users
+// who completed a second factor reach the handlers. Install it after JWT or
+// session.Middleware.
+func RequireTwoFactor(cfg TwoFactorConfig) zentrox.Handler {
+	def := DefaultTwoFactor()
+	if cfg.ClaimsKey == "" {
+		cfg.ClaimsKey = def.ClaimsKey
+	}
+	if cfg.SessionKey == "" {
+		cfg.SessionKey = def.SessionKey
+	}
+	if cfg.Flag == "" {
+		cfg.Flag = def.Flag
+	}
+	if cfg.OnDenied == nil {
+		cfg.OnDenied = def.OnDenied
+	}
+
+	return func(c *zentrox.Context) {
+		if claims, ok := zentrox.Get[map[string]any](c, cfg.ClaimsKey); ok && claims[cfg.Flag] == true {
+			c.Next()
+			return
+		}
+		if s := session.FromKey(c, cfg.SessionKey); s != nil {
+			if v, _ := s.Get(cfg.Flag); v == true {
+				c.Next()
+				return
+			}
+		}
+		cfg.OnDenied(c)
+		c.Abort()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// Unless wraps mw so it is bypassed for requests where skip returns true,
+// letting a globally plugged middleware ignore some endpoints:
+//
+//	app.Plug(middleware.Unless(middleware.PathIs("/healthz", "/metrics"), middleware.Logger()))
+func Unless(skip func(*zentrox.Context) bool, mw zentrox.Handler) zentrox.Handler {
+	return func(c *zentrox.Context) {
+		if skip(c) {
+			c.Next()
+			return
+		}
+		mw(c)
+	}
+}
+
+// PathIs matches requests whose path equals one of paths. A path ending in
+// "*" matches by prefix, e.g. "/internal/*".
+func PathIs(paths ...string) func(*zentrox.Context) bool {
+	return func(c *zentrox.Context) bool {
+		p := c.Request.URL.Path
+		for _, s := range paths {
+			if prefix, ok := strings.CutSuffix(s, "*"); ok {
+				if strings.HasPrefix(p, prefix) {
+					return true
+				}
+			} else if p == s {
+				return true
+			}
+		}
+		return false
+	}
+}
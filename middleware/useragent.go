@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// UserAgentAction is what UserAgentFilter does with a matching request.
+type UserAgentAction int
+
+const (
+	// UAAllow lets the request through untouched.
+	UAAllow UserAgentAction = iota
+	// UATag lets the request through and stores the rule tag in the context.
+	UATag
+	// UAChallenge hands the request to OnChallenge (e.g. a CAPTCHA page).
+	UAChallenge
+	// UABlock rejects the request with OnBlock.
+	UABlock
+)
+
+// UserAgentRule applies Action to user agents matching Pattern
+// (a case-insensitive regular expression).
+type UserAgentRule struct {
+	Pattern string
+	Action  UserAgentAction
+	Tag     string
+}
+
+type UserAgentFilterConfig struct {
+	// Allow patterns pass before any other rule is evaluated.
+	Allow []string
+	// Deny patterns are blocked; shorthand for rules with UABlock.
+	Deny []string
+	// Rules are evaluated in order after Deny; the first match wins.
+	Rules []UserAgentRule
+	// KnownBotAction applies to crawlers and HTTP libraries detected by IsBot
+	// when no rule matched. Tagged bots get the tag "bot".
+	KnownBotAction UserAgentAction
+	// EmptyAction applies to requests without a User-Agent.
+	EmptyAction UserAgentAction
+	// ContextKey stores the tag of UATag matches.
+	ContextKey  string
+	OnBlock     func(*zentrox.Context)
+	OnChallenge func(*zentrox.Context)
+}
+
+func DefaultUserAgentFilter() UserAgentFilterConfig {
+	return UserAgentFilterConfig{
+		KnownBotAction: UATag,
+		EmptyAction:    UATag,
+		ContextKey:     "ua_tag",
+		OnBlock: func(c *zentrox.Context) {
+			c.Fail(http.StatusForbidden, zentrox.MsgForbidden)
+		},
+		OnChallenge: func(c *zentrox.Context) {
+			c.Fail(http.StatusForbidden, zentrox.MsgChallengeRequired)
+		},
+	}
+}
+
+var knownBotPattern = regexp.MustCompile(`(?i)bot\b|bot/|crawl|spider|slurp|mediapartners|facebookexternalhit|` +
+	`headlesschrome|phantomjs|curl/|wget/|python-requests|python-urllib|aiohttp|go-http-client|` +
+	`java/|okhttp|libwww-perl|scrapy|httpclient|axios/|node-fetch`)
+
+// IsBot reports whether ua looks like a crawler, headless browser or HTTP
+// library rather than an interactive browser.
+func IsBot(ua string) bool {
+	return knownBotPattern.MatchString(ua)
+}
+
+type compiledUARule struct {
+	re     *regexp.Regexp
+	action UserAgentAction
+	tag    string
+}
+
+// UserAgentFilter allows, tags, challenges or blocks requests based on their
+// User-Agent, so scrapers can be throttled or excluded from analytics.
+// Invalid patterns panic at startup.
+func UserAgentFilter(cfg UserAgentFilterConfig) zentrox.Handler {
+	def := DefaultUserAgentFilter()
+	if cfg.ContextKey == "" {
+		cfg.ContextKey = def.ContextKey
+	}
+	if cfg.OnBlock == nil {
+		cfg.OnBlock = def.OnBlock
+	}
+	if cfg.OnChallenge == nil {
+		cfg.OnChallenge = def.OnChallenge
+	}
+
+	compile := func(p string) *regexp.Regexp {
+		return regexp.MustCompile("(?i)" + p)
+	}
+	allow := make([]*regexp.Regexp, 0, len(cfg.Allow))
+	for _, p := range cfg.Allow {
+		allow = append(allow, compile(p))
+	}
+	rules := make([]compiledUARule, 0, len(cfg.Deny)+len(cfg.Rules))
+	for _, p := range cfg.Deny {
+		rules = append(rules, compiledUARule{re: compile(p), action: UABlock})
+	}
+	for _, r := range cfg.Rules {
+		rules = append(rules, compiledUARule{re: compile(r.Pattern), action: r.Action, tag: r.Tag})
+	}
+
+	apply := func(c *zentrox.Context, action UserAgentAction, tag string) {
+		switch action {
+		case UABlock:
+			cfg.OnBlock(c)
+			c.Abort()
+		case UAChallenge:
+			cfg.OnChallenge(c)
+			c.Abort()
+		case UATag:
+			c.Set(cfg.ContextKey, tag)
+			c.Next()
+		default:
+			c.Next()
+		}
+	}
+
+	return func(c *zentrox.Context) {
+		ua := strings.TrimSpace(c.Request.UserAgent())
+		if ua == "" {
+			apply(c, cfg.EmptyAction, "empty")
+			return
+		}
+		for _, re := range allow {
+			if re.MatchString(ua) {
+				c.Next()
+				return
+			}
+		}
+		for _, r := range rules {
+			if r.re.MatchString(ua) {
+				apply(c, r.action, r.tag)
+				return
+			}
+		}
+		if IsBot(ua) {
+			apply(c, cfg.KnownBotAction, "bot")
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,174 @@
+package zentrox
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mockResponse is an example response served in mock mode.
+type mockResponse struct {
+	status int
+	body   any
+}
+
+// MockExample registers a literal example response for method+pattern, served
+// instead of the real handler when mock mode is enabled. Strings are written
+// as text; everything else is JSON encoded.
+func (a *App) MockExample(method, pattern string, status int, body any) *App {
+	if a.mocks == nil {
+		a.mocks = make(map[string]mockResponse)
+	}
+	a.mocks[strings.ToUpper(method)+"\t"+pattern] = mockResponse{status: status, body: body}
+	return a
+}
+
+// MockFake registers a generated example for method+pattern built from the
+// shape of sample (typically the response or binding struct). Field values
+// come from `example:"..."` tags when present, otherwise from type-based
+// placeholders. Pass a slice (e.g. []User{}) to get a list with one element.
+func (a *App) MockFake(method, pattern string, status int, sample any) *App {
+	return a.MockExample(method, pattern, status, fakeValue(reflect.TypeOf(sample), "", 0))
+}
+
+// SetMockMode toggles mock mode. When enabled, matched routes run only the
+// global middlewares followed by a mock responder: registered examples are
+// served, and routes without one echo their method, pattern and params.
+func (a *App) SetMockMode(v bool) *App {
+	a.mockMode = v
+	return a
+}
+
+// RunMock starts a blocking server in mock mode, so frontend teams can
+// develop against the API shape before handlers exist.
+func (a *App) RunMock(addr string) error {
+	a.SetMockMode(true)
+	return a.Run(addr)
+}
+
+// mockStack returns the handler stack used for entry in mock mode.
+func (a *App) mockStack(entry *routeEntry) []Handler {
+	stack := make([]Handler, 0, len(a.plug)+1)
+	stack = append(stack, a.plug...)
+	return append(stack, func(c *Context) { a.serveMock(c, entry) })
+}
+
+func (a *App) serveMock(c *Context, entry *routeEntry) {
+	if m, ok := a.mocks[entry.method+"\t"+entry.pattern]; ok {
+		if s, isStr := m.body.(string); isStr {
+			c.String(m.status, "%s", s)
+			return
+		}
+		if m.body == nil {
+			c.SendStatus(m.status)
+			return
+		}
+		c.JSON(m.status, m.body)
+		return
+	}
+
+	status := http.StatusOK
+	switch entry.method {
+	case http.MethodPost:
+		status = http.StatusCreated
+	case http.MethodDelete:
+		c.SendStatus(http.StatusNoContent)
+		return
+	}
+	c.JSON(status, map[string]any{
+		"mock":   true,
+		"method": entry.method,
+		"route":  entry.pattern,
+		"params": c.Params(),
+	})
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// fakeValue builds a JSON-friendly placeholder for t.
+func fakeValue(t reflect.Type, example string, depth int) any {
+	if t == nil || depth > 6 {
+		return nil
+	}
+	if example != "" {
+		return parseExample(t, example)
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return fakeValue(t.Elem(), "", depth+1)
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return 1
+	case reflect.Float32, reflect.Float64:
+		return 1.5
+	case reflect.Slice, reflect.Array:
+		return []any{fakeValue(t.Elem(), "", depth+1)}
+	case reflect.Map:
+		return map[string]any{"key": fakeValue(t.Elem(), "", depth+1)}
+	case reflect.Struct:
+		if t == timeType {
+			return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+		}
+		out := make(map[string]any, t.NumField())
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name := sf.Name
+			if tag := sf.Tag.Get("json"); tag != "" {
+				if n := strings.Split(tag, ",")[0]; n == "-" {
+					continue
+				} else if n != "" {
+					name = n
+				}
+			}
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				if m, ok := fakeValue(sf.Type, "", depth+1).(map[string]any); ok {
+					for k, v := range m {
+						out[k] = v
+					}
+					continue
+				}
+			}
+			out[name] = fakeValue(sf.Type, sf.Tag.Get("example"), depth+1)
+		}
+		return out
+	}
+	return nil
+}
+
+func parseExample(t reflect.Type, s string) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return f
+		}
+	case reflect.Slice:
+		parts := strings.Split(s, ",")
+		out := make([]any, 0, len(parts))
+		for _, p := range parts {
+			out = append(out, parseExample(t.Elem(), strings.TrimSpace(p)))
+		}
+		return out
+	}
+	return s
+}
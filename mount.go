@@ -0,0 +1,109 @@
+package zentrox
+
+import (
+	"net/http"
+	"strings"
+)
+
+// mountMethods are the methods forwarded to a mounted handler.
+var mountMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodDelete, http.MethodConnect, http.MethodTrace, http.MethodOptions,
+}
+
+// MountOptions controls Mount and MountApp.
+type MountOptions struct {
+	// KeepPrefix passes the full request path to the mounted handler instead
+	// of stripping the mount prefix.
+	KeepPrefix bool
+	// Isolated skips the App's global middleware (Plug) for mounted requests.
+	Isolated bool
+	// Middlewares run before the mounted handler, after global middleware.
+	Middlewares []Handler
+}
+
+// Mount serves every request under prefix (any method) with h, stripping the
+// prefix from the path by default:
+//
+//	app.Mount("/legacy", legacyMux)
+//	app.Mount("/debug/vars", expvar.Handler(), zentrox.MountOptions{KeepPrefix: true})
+//
+// Global middleware runs first unless opt.Isolated is set.
+func (a *App) Mount(prefix string, h http.Handler, opt ...MountOptions) {
+	var o MountOptions
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	prefix = strings.TrimSuffix(prefix, "/")
+	if !o.KeepPrefix && prefix != "" {
+		h = stripPrefix(prefix, h)
+	}
+	handler := func(c *Context) {
+		h.ServeHTTP(c.Writer, c.Request)
+	}
+	mws := append([]Handler{}, o.Middlewares...)
+	if !o.Isolated {
+		mws = append(append([]Handler{}, a.plug...), mws...)
+	}
+	for _, p := range []string{prefix, prefix + "/*path"} {
+		if p == "" {
+			p = "/"
+		}
+		for _, m := range mountMethods {
+			a.rt.add(m, p, mws, handler)
+		}
+	}
+}
+
+// MountApp serves sub under prefix, so separately built modules can share
+// one server. sub handles requests with its own Pre and Plug middleware,
+// hooks and settings; opt applies as in Mount. Routes registered on sub
+// before mounting are listed by ListRoutes and usable with URLFor under
+// the prefix.
+func (a *App) MountApp(prefix string, sub *App, opt ...MountOptions) {
+	a.Mount(prefix, sub, opt...)
+	prefix = strings.TrimSuffix(prefix, "/")
+	if a.routeIndex == nil {
+		a.routeIndex = make(map[string]RouteInfo)
+	}
+	for _, ri := range sub.ListRoutes() {
+		ri.Path = prefix + ri.Path
+		ri.Group = prefix + ri.Group
+		a.routeIndex[ri.Method+"\t"+ri.Path] = ri
+	}
+	for name, pattern := range sub.routeNames {
+		if a.routeNames == nil {
+			a.routeNames = make(map[string]string)
+		}
+		if _, ok := a.routeNames[name]; !ok {
+			a.routeNames[name] = prefix + pattern
+		}
+	}
+}
+
+// stripPrefix is http.StripPrefix, except that the bare prefix maps to "/".
+func stripPrefix(prefix string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+		r2 := new(http.Request)
+		*r2 = *r
+		u := *r.URL
+		r2.URL = &u
+		r2.URL.Path = p
+		if r2.URL.Path == "" {
+			r2.URL.Path = "/"
+		}
+		if r.URL.RawPath != "" {
+			rp, _ := strings.CutPrefix(r.URL.RawPath, prefix)
+			if rp == "" {
+				rp = "/"
+			}
+			r2.URL.RawPath = rp
+		}
+		h.ServeHTTP(w, r2)
+	})
+}
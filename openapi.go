@@ -0,0 +1,460 @@
+package zentrox
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RoutesOpenAPI is the ExportRoutes format producing an OpenAPI 3.1 document.
+const RoutesOpenAPI = "openapi"
+
+// OpenAPIInfo is the info block (and servers) of a generated document.
+type OpenAPIInfo struct {
+	Title       string
+	Version     string
+	Description string
+	// Servers lists base URLs, e.g. "https://api.example.com".
+	Servers []string
+}
+
+// RouteDoc documents a route for GenerateOpenAPI. Build it with NewRouteDoc
+// and attach it with Route.Doc:
+//
+//	app.POST("/users", createUser).Doc(zentrox.NewRouteDoc().
+//		Summary("Create a user").
+//		Tags("users").
+//		Request(CreateUser{}).
+//		Response(http.StatusCreated, User{}).
+//		Response(http.StatusConflict, nil))
+//
+// Request and response bodies are example values (or typed nil pointers);
+// only their types are used.
+type RouteDoc struct {
+	summary     string
+	description string
+	operationID string
+	tags        []string
+	deprecated  bool
+	request     any
+	responses   map[int]any
+	query       []docParam
+	headers     []docParam
+}
+
+type docParam struct {
+	name        string
+	example     any
+	description string
+	required    bool
+}
+
+// NewRouteDoc returns an empty RouteDoc.
+func NewRouteDoc() *RouteDoc {
+	return &RouteDoc{}
+}
+
+// Summary sets the operation summary; Route.Summary is used when unset.
+func (d *RouteDoc) Summary(s string) *RouteDoc { d.summary = s; return d }
+
+// Description sets the long-form operation description (Markdown).
+func (d *RouteDoc) Description(s string) *RouteDoc { d.description = s; return d }
+
+// OperationID sets the operationId; the route name is used when unset.
+func (d *RouteDoc) OperationID(id string) *RouteDoc { d.operationID = id; return d }
+
+// Tags groups the operation in documentation UIs.
+func (d *RouteDoc) Tags(tags ...string) *RouteDoc { d.tags = append(d.tags, tags...); return d }
+
+// Deprecated marks the operation as deprecated.
+func (d *RouteDoc) Deprecated() *RouteDoc { d.deprecated = true; return d }
+
+// Request sets the JSON request body type.
+func (d *RouteDoc) Request(body any) *RouteDoc { d.request = body; return d }
+
+// Response documents the body returned with code; body may be nil for
+// responses without content.
+func (d *RouteDoc) Response(code int, body any) *RouteDoc {
+	if d.responses == nil {
+		d.responses = make(map[int]any)
+	}
+	d.responses[code] = body
+	return d
+}
+
+// Query documents an optional query parameter whose type is that of example.
+func (d *RouteDoc) Query(name string, example any, description string) *RouteDoc {
+	d.query = append(d.query, docParam{name: name, example: example, description: description})
+	return d
+}
+
+// Header documents a request header; required headers are marked so.
+func (d *RouteDoc) Header(name string, required bool, description string) *RouteDoc {
+	d.headers = append(d.headers, docParam{name: name, example: "", description: description, required: required})
+	return d
+}
+
+// Doc attaches API documentation used by GenerateOpenAPI.
+func (r *Route) Doc(d *RouteDoc) *Route {
+	r.update(func(ri *RouteInfo) { ri.Doc = d })
+	return r
+}
+
+// GenerateOpenAPI returns an OpenAPI 3.1 JSON document describing the
+// registered routes. Path parameters come from the patterns; bodies from
+// Route.Doc, falling back to Route.Body and Route.Returns (as the 200
+// response). Struct types become component schemas, with `validate`
+// rules such as required, min, max and oneof mapped to JSON Schema.
+func (a *App) GenerateOpenAPI(info OpenAPIInfo) ([]byte, error) {
+	return json.MarshalIndent(a.openAPIDocument(info), "", "  ")
+}
+
+func (a *App) openAPIDocument(info OpenAPIInfo) map[string]any {
+	if info.Title == "" {
+		info.Title = "API"
+	}
+	if info.Version == "" {
+		info.Version = a.version
+	}
+	if info.Version == "" {
+		info.Version = "0.0.0"
+	}
+	g := &openAPIGen{schemas: map[string]any{}, names: map[reflect.Type]string{}}
+
+	paths := map[string]map[string]any{}
+	for _, ri := range a.ListRoutes() {
+		p := openAPIPath(ri.Path)
+		if paths[p] == nil {
+			paths[p] = map[string]any{}
+		}
+		paths[p][strings.ToLower(ri.Method)] = g.operation(ri)
+	}
+
+	infoObj := map[string]any{"title": info.Title, "version": info.Version}
+	if info.Description != "" {
+		infoObj["description"] = info.Description
+	}
+	doc := map[string]any{
+		"openapi": "3.1.0",
+		"info":    infoObj,
+		"paths":   paths,
+	}
+	if len(info.Servers) > 0 {
+		servers := make([]map[string]string, 0, len(info.Servers))
+		for _, s := range info.Servers {
+			servers = append(servers, map[string]string{"url": s})
+		}
+		doc["servers"] = servers
+	}
+	if len(g.schemas) > 0 {
+		doc["components"] = map[string]any{"schemas": g.schemas}
+	}
+	return doc
+}
+
+// openAPIPath rewrites ":id" and "*path" segments as "{id}" and "{path}".
+func openAPIPath(pattern string) string {
+	segs := splitPattern(pattern)
+	for i, s := range segs {
+		if len(s) > 1 && (s[0] == ':' || s[0] == '*') {
+			segs[i] = "{" + paramName(s) + "}"
+		}
+	}
+	return strings.Join(segs, "/")
+}
+
+// constraintSchema describes a path parameter constraint as a schema.
+func constraintSchema(spec string) map[string]any {
+	switch spec {
+	case "":
+		return map[string]any{"type": "string"}
+	case "int":
+		return map[string]any{"type": "integer"}
+	case "uint":
+		return map[string]any{"type": "integer", "minimum": 0}
+	case "uuid":
+		return map[string]any{"type": "string", "format": "uuid"}
+	case "alpha":
+		return map[string]any{"type": "string", "pattern": "^[A-Za-z]+$"}
+	case "alnum":
+		return map[string]any{"type": "string", "pattern": "^[A-Za-z0-9]+$"}
+	}
+	expr := strings.TrimSuffix(strings.TrimPrefix(spec, "^"), "$")
+	return map[string]any{"type": "string", "pattern": "^(?:" + expr + ")$"}
+}
+
+// openAPIGen converts routes and Go types into OpenAPI objects, collecting
+// named structs under components/schemas.
+type openAPIGen struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+}
+
+func (g *openAPIGen) operation(ri RouteInfo) map[string]any {
+	d := ri.Doc
+	if d == nil {
+		d = &RouteDoc{}
+	}
+	op := map[string]any{}
+	summary := d.summary
+	if summary == "" {
+		summary = ri.Summary
+	}
+	if summary != "" {
+		op["summary"] = summary
+	}
+	if d.description != "" {
+		op["description"] = d.description
+	}
+	if id := d.operationID; id != "" {
+		op["operationId"] = id
+	} else if ri.Name != "" {
+		op["operationId"] = ri.Name
+	}
+	if len(d.tags) > 0 {
+		op["tags"] = d.tags
+	}
+	if d.deprecated {
+		op["deprecated"] = true
+	}
+
+	var params []map[string]any
+	for _, seg := range compilePattern(ri.Path) {
+		if !seg.isParam && !seg.isWildcard {
+			continue
+		}
+		params = append(params, map[string]any{
+			"name": seg.name, "in": "path", "required": true,
+			"schema": constraintSchema(seg.constraint),
+		})
+	}
+	for _, q := range d.query {
+		params = append(params, g.param(q, "query"))
+	}
+	for _, h := range d.headers {
+		params = append(params, g.param(h, "header"))
+	}
+	if len(params) > 0 {
+		op["parameters"] = params
+	}
+
+	req := d.request
+	if req == nil {
+		req = ri.Body
+	}
+	if req != nil {
+		op["requestBody"] = map[string]any{
+			"required": true,
+			"content":  map[string]any{ContentTypeJSON: map[string]any{"schema": g.schema(reflect.TypeOf(req))}},
+		}
+	}
+
+	responses := map[string]any{}
+	for code, body := range d.responses {
+		responses[strconv.Itoa(code)] = g.response(code, body)
+	}
+	if len(responses) == 0 {
+		responses["200"] = g.response(http.StatusOK, ri.Response)
+	}
+	op["responses"] = responses
+	return op
+}
+
+func (g *openAPIGen) param(p docParam, in string) map[string]any {
+	out := map[string]any{"name": p.name, "in": in, "schema": map[string]any{"type": "string"}}
+	if p.example != nil {
+		out["schema"] = g.schema(reflect.TypeOf(p.example))
+	}
+	if p.required {
+		out["required"] = true
+	}
+	if p.description != "" {
+		out["description"] = p.description
+	}
+	return out
+}
+
+func (g *openAPIGen) response(code int, body any) map[string]any {
+	desc := http.StatusText(code)
+	if desc == "" {
+		desc = "Response"
+	}
+	out := map[string]any{"description": desc}
+	if body != nil {
+		out["content"] = map[string]any{ContentTypeJSON: map[string]any{"schema": g.schema(reflect.TypeOf(body))}}
+	}
+	return out
+}
+
+var (
+	openAPITimeType = reflect.TypeOf(time.Time{})
+	openAPIRawType  = reflect.TypeOf(json.RawMessage(nil))
+)
+
+// schema returns the JSON Schema for t, as a $ref for named structs.
+func (g *openAPIGen) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == openAPITimeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == openAPIRawType:
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return map[string]any{"type": "integer"}
+	case reflect.Int32, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name, ok := g.names[t]
+		if !ok {
+			name = t.Name()
+			base := name
+			for i := 2; g.schemas[name] != nil; i++ {
+				name = fmt.Sprintf("%s%d", base, i)
+			}
+			g.names[t] = name
+			g.schemas[name] = map[string]any{} // placeholder for recursive types
+			g.schemas[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]any{}
+}
+
+func (g *openAPIGen) structSchema(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	var required []string
+	g.fields(t, props, &required)
+	out := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		sort.Strings(required)
+		out["required"] = required
+	}
+	return out
+}
+
+// fields adds the JSON properties of t, flattening untagged embedded structs
+// the way encoding/json does.
+func (g *openAPIGen) fields(t reflect.Type, props map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			for ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s := g.schema(f.Type)
+		rules, ok := f.Tag.Lookup("validate")
+		if !ok {
+			rules = f.Tag.Get("binding")
+		}
+		if applyRules(s, rules) {
+			*required = append(*required, name)
+		}
+		props[name] = s
+	}
+}
+
+// applyRules maps validation rules onto schema s and reports whether the
+// field is required. Constraints are skipped for $ref schemas.
+func applyRules(s map[string]any, rules string) (required bool) {
+	if rules == "" || rules == "-" {
+		return false
+	}
+	typ, _ := s["type"].(string)
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name == "required" {
+			required = true
+			continue
+		}
+		if _, ref := s["$ref"]; ref {
+			continue
+		}
+		n, numErr := strconv.ParseFloat(param, 64)
+		switch {
+		case name == "email" && typ == "string":
+			s["format"] = "email"
+		case name == "oneof" && param != "":
+			vals := strings.Fields(param)
+			enum := make([]any, 0, len(vals))
+			for _, v := range vals {
+				if typ == "integer" || typ == "number" {
+					if f, err := strconv.ParseFloat(v, 64); err == nil {
+						enum = append(enum, f)
+						continue
+					}
+				}
+				enum = append(enum, v)
+			}
+			s["enum"] = enum
+		case numErr != nil:
+		case name == "min" || name == "max" || name == "len":
+			keys := map[string][2]string{
+				"string": {"minLength", "maxLength"},
+				"array":  {"minItems", "maxItems"},
+				"object": {"minProperties", "maxProperties"},
+			}
+			k, ok := keys[typ]
+			if !ok {
+				if name == "min" {
+					s["minimum"] = n
+				} else if name == "max" {
+					s["maximum"] = n
+				}
+				continue
+			}
+			if name != "max" {
+				s[k[0]] = int(n)
+			}
+			if name != "min" {
+				s[k[1]] = int(n)
+			}
+		}
+	}
+	return required
+}
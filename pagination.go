@@ -0,0 +1,162 @@
+package zentrox
+
+import (
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Pagination holds normalized paging parameters parsed from the query string.
+// Offset is derived from Page and Limit for SQL-style backends; Cursor is
+// passed through untouched for keyset pagination.
+type Pagination struct {
+	Page   int
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+// PaginationConfig controls query parameter names and bounds used by ParsePagination.
+type PaginationConfig struct {
+	PageParam   string // default "page"
+	LimitParam  string // default "limit"
+	CursorParam string // default "cursor"
+
+	// DefaultLimit is used when the limit is missing or invalid (default 20).
+	DefaultLimit int
+	// MaxLimit caps the requested limit (default 100).
+	MaxLimit int
+}
+
+// DefaultPagination returns the configuration used by ParsePagination.
+func DefaultPagination() PaginationConfig {
+	return PaginationConfig{
+		PageParam:    "page",
+		LimitParam:   "limit",
+		CursorParam:  "cursor",
+		DefaultLimit: 20,
+		MaxLimit:     100,
+	}
+}
+
+// ParsePagination reads page/limit/cursor from the query string using DefaultPagination.
+// Invalid or out-of-range values are clamped instead of rejected.
+func ParsePagination(c *Context) Pagination {
+	return ParsePaginationWithConfig(c, DefaultPagination())
+}
+
+// ParsePaginationWithConfig reads page/limit/cursor using custom parameter names and bounds.
+func ParsePaginationWithConfig(c *Context, cfg PaginationConfig) Pagination {
+	def := DefaultPagination()
+	if cfg.PageParam == "" {
+		cfg.PageParam = def.PageParam
+	}
+	if cfg.LimitParam == "" {
+		cfg.LimitParam = def.LimitParam
+	}
+	if cfg.CursorParam == "" {
+		cfg.CursorParam = def.CursorParam
+	}
+	if cfg.DefaultLimit <= 0 {
+		cfg.DefaultLimit = def.DefaultLimit
+	}
+	if cfg.MaxLimit <= 0 {
+		cfg.MaxLimit = def.MaxLimit
+	}
+	if cfg.DefaultLimit > cfg.MaxLimit {
+		cfg.DefaultLimit = cfg.MaxLimit
+	}
+
+	q := c.Request.URL.Query()
+
+	page, err := strconv.Atoi(strings.TrimSpace(q.Get(cfg.PageParam)))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(q.Get(cfg.LimitParam)))
+	if err != nil || limit < 1 {
+		limit = cfg.DefaultLimit
+	}
+	if limit > cfg.MaxLimit {
+		limit = cfg.MaxLimit
+	}
+
+	// Guard against overflow for absurd page numbers.
+	if page > math.MaxInt/limit {
+		page = math.MaxInt / limit
+	}
+
+	return Pagination{
+		Page:   page,
+		Limit:  limit,
+		Offset: (page - 1) * limit,
+		Cursor: strings.TrimSpace(q.Get(cfg.CursorParam)),
+	}
+}
+
+// SetPaginationHeaders writes X-Total-Count and an RFC 5988 Link header with
+// first/prev/next/last relations. Links reuse the current request path and
+// preserve all other query parameters; "page" and "limit" are rewritten.
+func (c *Context) SetPaginationHeaders(total, page, limit int) {
+	c.SetPaginationHeadersWithConfig(total, page, limit, DefaultPagination())
+}
+
+// SetPaginationHeadersWithConfig is SetPaginationHeaders for the parameter
+// names of cfg, as passed to ParsePaginationWithConfig: links rewrite
+// cfg.PageParam and cfg.LimitParam.
+func (c *Context) SetPaginationHeadersWithConfig(total, page, limit int, cfg PaginationConfig) {
+	def := DefaultPagination()
+	if cfg.PageParam == "" {
+		cfg.PageParam = def.PageParam
+	}
+	if cfg.LimitParam == "" {
+		cfg.LimitParam = def.LimitParam
+	}
+	if cfg.DefaultLimit <= 0 {
+		cfg.DefaultLimit = def.DefaultLimit
+	}
+	if total < 0 {
+		total = 0
+	}
+	if limit < 1 {
+		limit = cfg.DefaultLimit
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	h := c.Writer.Header()
+	h.Set(HeaderXTotalCount, strconv.Itoa(total))
+
+	last := (total + limit - 1) / limit
+	if last < 1 {
+		last = 1
+	}
+
+	link := func(page int, rel string) string {
+		return paginationLink(c.Request.URL, cfg, page, limit, rel)
+	}
+	links := make([]string, 0, 4)
+	links = append(links, link(1, "first"))
+	if page > 1 {
+		prev := page - 1
+		if prev > last {
+			prev = last
+		}
+		links = append(links, link(prev, "prev"))
+	}
+	if page < last {
+		links = append(links, link(page+1, "next"))
+	}
+	links = append(links, link(last, "last"))
+
+	h.Set(HeaderLink, strings.Join(links, ", "))
+}
+
+func paginationLink(u *url.URL, cfg PaginationConfig, page, limit int, rel string) string {
+	q := u.Query()
+	q.Set(cfg.PageParam, strconv.Itoa(page))
+	q.Set(cfg.LimitParam, strconv.Itoa(limit))
+	return `<` + u.Path + "?" + q.Encode() + `>; rel="` + rel + `"`
+}
@@ -0,0 +1,142 @@
+package zentrox
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Typed accessors for path and query parameters. Path parameters are
+// required, so the Param* methods return an HTTPError with status 400 when
+// the value is missing or malformed; pass it to c.SetError or c.Fail. Query
+// parameters are optional, so the Query* methods fall back to def.
+
+// ParamInt returns the path parameter key as an int.
+func (c *Context) ParamInt(key string) (int, error) {
+	v, err := c.ParamInt64(key)
+	return int(v), err
+}
+
+// ParamInt64 returns the path parameter key as an int64.
+func (c *Context) ParamInt64(key string) (int64, error) {
+	s, err := c.requiredParam(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, paramError(key, s, err)
+	}
+	return n, nil
+}
+
+// ParamUint64 returns the path parameter key as a uint64.
+func (c *Context) ParamUint64(key string) (uint64, error) {
+	s, err := c.requiredParam(key)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, paramError(key, s, err)
+	}
+	return n, nil
+}
+
+// ParamFloat64 returns the path parameter key as a float64.
+func (c *Context) ParamFloat64(key string) (float64, error) {
+	s, err := c.requiredParam(key)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, paramError(key, s, err)
+	}
+	return f, nil
+}
+
+func (c *Context) requiredParam(key string) (string, error) {
+	s, ok := c.lookupParam(key)
+	if !ok || s == "" {
+		return "", NewHTTPError(http.StatusBadRequest, fmt.Sprintf("missing path parameter %q", key))
+	}
+	return s, nil
+}
+
+func paramError(key, value string, err error) error {
+	if ne, ok := err.(*strconv.NumError); ok {
+		err = ne.Err
+	}
+	return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid path parameter %q", key),
+		map[string]string{"value": value, "error": err.Error()})
+}
+
+// QueryInt returns the query parameter key as an int, or def when it is
+// missing or not an integer.
+func (c *Context) QueryInt(key string, def int) int {
+	return int(c.QueryInt64(key, int64(def)))
+}
+
+// QueryInt64 returns the query parameter key as an int64, or def.
+func (c *Context) QueryInt64(key string, def int64) int64 {
+	if n, err := strconv.ParseInt(c.Query(key), 10, 64); err == nil {
+		return n
+	}
+	return def
+}
+
+// QueryFloat64 returns the query parameter key as a float64, or def.
+func (c *Context) QueryFloat64(key string, def float64) float64 {
+	if f, err := strconv.ParseFloat(c.Query(key), 64); err == nil {
+		return f
+	}
+	return def
+}
+
+// QueryBool returns the query parameter key as a bool, or def. Besides the
+// strconv.ParseBool forms it accepts on/off and yes/no; a present but empty
+// parameter ("?debug") counts as true.
+func (c *Context) QueryBool(key string, def bool) bool {
+	vals, ok := c.QueryValues()[key]
+	if !ok || len(vals) == 0 {
+		return def
+	}
+	switch strings.ToLower(vals[0]) {
+	case "", "on", "yes":
+		return true
+	case "off", "no":
+		return false
+	}
+	if b, err := strconv.ParseBool(vals[0]); err == nil {
+		return b
+	}
+	return def
+}
+
+// QueryDuration returns the query parameter key parsed with
+// time.ParseDuration ("1h30m"), or def.
+func (c *Context) QueryDuration(key string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(c.Query(key)); err == nil {
+		return d
+	}
+	return def
+}
+
+// QueryTime parses the query parameter key with layout (e.g. time.RFC3339
+// or time.DateOnly). It returns the zero time and no error when the
+// parameter is missing, and a 400 HTTPError when it is malformed.
+func (c *Context) QueryTime(key, layout string) (time.Time, error) {
+	s := c.Query(key)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid query parameter %q", key),
+			map[string]string{"value": s, "layout": layout})
+	}
+	return t, nil
+}
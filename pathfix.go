@@ -0,0 +1,223 @@
+package zentrox
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// RouterOptions controls how requests whose path differs from a registered
+// pattern are handled. All options are off by default: a trailing slash is
+// ignored and paths are matched case-sensitively.
+type RouterOptions struct {
+	// RedirectTrailingSlash redirects "/users/" to "/users" (or the reverse
+	// when the pattern ends with a slash) instead of serving both.
+	RedirectTrailingSlash bool
+	// RedirectFixedPath cleans the path ("//", "..") and matches it
+	// case-insensitively, redirecting to the registered spelling, so
+	// "/Users/" is redirected to "/users".
+	RedirectFixedPath bool
+	// CaseInsensitiveRouting serves "/Users" from the "/users" route without
+	// a redirect.
+	CaseInsensitiveRouting bool
+}
+
+func (o *RouterOptions) enabled() bool {
+	return o.RedirectTrailingSlash || o.RedirectFixedPath || o.CaseInsensitiveRouting
+}
+
+// SetRouterOptions sets the path fix-up options for routes that do not
+// override them with Scope.SetRouterOptions:
+//
+//	app.SetRouterOptions(zentrox.RouterOptions{
+//		RedirectTrailingSlash: true,
+//		RedirectFixedPath:     true,
+//	})
+//
+// Redirects use 301 for GET and HEAD and 308 otherwise, keeping the query.
+func (a *App) SetRouterOptions(o RouterOptions) *App {
+	a.routing = o
+	a.fixPaths = a.fixPaths || o.enabled()
+	return a
+}
+
+// SetRouterOptions overrides the App's RouterOptions for routes registered on
+// this scope (and nested scopes) afterwards.
+func (s *Scope) SetRouterOptions(o RouterOptions) *Scope {
+	s.routing = &o
+	s.app.fixPaths = s.app.fixPaths || o.enabled()
+	return s
+}
+
+// routerOptions returns the options in effect for entry.
+func (a *App) routerOptions(e *routeEntry) *RouterOptions {
+	if e.routing != nil {
+		return e.routing
+	}
+	return &a.routing
+}
+
+// fixPath applies RouterOptions to the request. It returns the entry to
+// serve (which may differ from entry after a case-insensitive match) and
+// whether a redirect was already written.
+func (a *App) fixPath(c *Context, rt *router, entry *routeEntry) (*routeEntry, bool) {
+	r := c.Request
+	p := r.URL.Path
+
+	if entry != nil {
+		opts := a.routerOptions(entry)
+		if !opts.RedirectTrailingSlash && !opts.RedirectFixedPath {
+			return entry, false
+		}
+		if strings.Contains(entry.pattern, "/*") {
+			return entry, false
+		}
+		doubled := strings.Contains(p, "//")
+		if !doubled && strings.HasSuffix(p, "/") == strings.HasSuffix(entry.pattern, "/") {
+			return entry, false
+		}
+		if doubled && !opts.RedirectFixedPath {
+			return entry, false
+		}
+		want := canonicalPath(entry.pattern, c.params)
+		if !doubled && !opts.RedirectTrailingSlash || want == p {
+			return entry, false
+		}
+		redirectFixed(c, want)
+		return nil, true
+	}
+
+	// No exact match: look the cleaned path up case-insensitively.
+	clean := p
+	if clean != "/" {
+		clean = path.Clean("/" + p)
+		if strings.HasSuffix(p, "/") && clean != "/" {
+			clean += "/"
+		}
+	}
+	c.params = c.params[:0]
+	e := rt.matchFold(r.Method, clean, &c.params)
+	if e == nil && r.Method == http.MethodHead {
+		c.params = c.params[:0]
+		e = rt.matchFold(http.MethodGet, clean, &c.params)
+	}
+	if e == nil {
+		c.params = c.params[:0]
+		return nil, false
+	}
+	opts := a.routerOptions(e)
+	want := canonicalPath(e.pattern, c.params)
+	switch {
+	case opts.RedirectFixedPath:
+		redirectFixed(c, want)
+		return nil, true
+	case opts.CaseInsensitiveRouting && clean == p:
+		if opts.RedirectTrailingSlash && !strings.Contains(e.pattern, "/*") &&
+			strings.HasSuffix(p, "/") != strings.HasSuffix(e.pattern, "/") {
+			redirectFixed(c, want)
+			return nil, true
+		}
+		if e.method != r.Method {
+			// HEAD served by the GET route.
+			c.head = headWriter{ResponseWriter: c.Writer}
+			c.Writer = &c.head
+		}
+		return e, false
+	}
+	c.params = c.params[:0]
+	return nil, false
+}
+
+// redirectFixed redirects to p, keeping the query string.
+func redirectFixed(c *Context, p string) {
+	code := http.StatusMovedPermanently
+	if m := c.Request.Method; m != http.MethodGet && m != http.MethodHead {
+		code = http.StatusPermanentRedirect
+	}
+	u := url.URL{Path: p, RawQuery: c.Request.URL.RawQuery}
+	c.Redirect(code, u.String())
+}
+
+// canonicalPath rebuilds the request path from the registered pattern and
+// matched params, restoring the pattern's spelling and trailing slash.
+func canonicalPath(pattern string, params []param) string {
+	var sb strings.Builder
+	i := 0
+	for _, seg := range compilePattern(pattern) {
+		sb.WriteByte('/')
+		if (seg.isParam || seg.isWildcard) && i < len(params) {
+			sb.WriteString(params[i].value)
+			i++
+			continue
+		}
+		sb.WriteString(seg.literal)
+	}
+	if sb.Len() == 0 || strings.HasSuffix(pattern, "/") && !strings.HasSuffix(sb.String(), "/") {
+		sb.WriteByte('/')
+	}
+	return sb.String()
+}
+
+// matchFold is match with case-insensitive static segments and
+// backtracking. It only runs for requests that did not match exactly.
+func (r *router) matchFold(method, p string, params *[]param) *routeEntry {
+	var segs []string
+	it := newPathIter(p)
+	for {
+		seg, ok := it.next()
+		if !ok {
+			break
+		}
+		segs = append(segs, seg)
+	}
+	return r.root.fold(method, segs, strings.HasSuffix(p, "/"), params)
+}
+
+func (n *routeNode) fold(method string, segs []string, trailing bool, params *[]param) *routeEntry {
+	if len(segs) == 0 {
+		return n.handlers[method]
+	}
+	seg := segs[0]
+	if next := n.static[seg]; next != nil {
+		if e := next.fold(method, segs[1:], trailing, params); e != nil {
+			return e
+		}
+	}
+	for lit, next := range n.static {
+		if lit != seg && strings.EqualFold(lit, seg) {
+			if e := next.fold(method, segs[1:], trailing, params); e != nil {
+				return e
+			}
+		}
+	}
+	mark := len(*params)
+	for _, c := range n.cparams {
+		if !c.check(seg) {
+			continue
+		}
+		*params = append(*params, param{c.name, seg})
+		if e := c.node.fold(method, segs[1:], trailing, params); e != nil {
+			return e
+		}
+		*params = (*params)[:mark]
+	}
+	if n.param != nil {
+		*params = append(*params, param{n.pname, seg})
+		if e := n.param.fold(method, segs[1:], trailing, params); e != nil {
+			return e
+		}
+		*params = (*params)[:mark]
+	}
+	if n.wildcard != nil {
+		if e := n.wildcard.handlers[method]; e != nil {
+			tail := strings.Join(segs, "/")
+			if trailing {
+				tail += "/"
+			}
+			*params = append(*params, param{n.wname, tail})
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package zentrox
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"strings"
+)
+
+// EnableProfiling registers the net/http/pprof and expvar endpoints under
+// prefix, guarded by mws (typically authentication):
+//
+//	app.EnableProfiling("/debug", middleware.JWT(adminJWT))
+//
+// serves the pprof index at /debug/pprof/, profiles such as
+// /debug/pprof/heap and /debug/pprof/profile?seconds=30, and expvar at
+// /debug/vars. Never expose these endpoints without protection.
+func (a *App) EnableProfiling(prefix string, mws ...Handler) {
+	s := a.Scope(strings.TrimSuffix(prefix, "/"), mws...)
+	wrap := func(h http.HandlerFunc) Handler {
+		return func(c *Context) { h(c.Writer, c.Request) }
+	}
+
+	index := func(c *Context) {
+		// The index links to profiles relative to the trailing slash.
+		if !strings.HasSuffix(c.Request.URL.Path, "/") {
+			c.Redirect(http.StatusMovedPermanently, c.Request.URL.Path+"/")
+			return
+		}
+		pprof.Index(c.Writer, c.Request)
+	}
+	s.GET("/pprof", index)
+	s.GET("/pprof/cmdline", wrap(pprof.Cmdline))
+	s.GET("/pprof/profile", wrap(pprof.Profile))
+	s.GET("/pprof/symbol", wrap(pprof.Symbol))
+	s.POST("/pprof/symbol", wrap(pprof.Symbol))
+	s.GET("/pprof/trace", wrap(pprof.Trace))
+	s.GET("/pprof/:name", func(c *Context) {
+		pprof.Handler(c.Param("name")).ServeHTTP(c.Writer, c.Request)
+	})
+	s.GET("/vars", wrap(expvar.Handler().ServeHTTP))
+}
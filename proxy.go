@@ -0,0 +1,263 @@
+package zentrox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ProxyOptions configures Proxy and ProxyHandler.
+type ProxyOptions struct {
+	// StripPrefix is removed from the request path before it is appended
+	// to the target path, e.g. "/payments" forwards /payments/charges to
+	// <target>/charges.
+	StripPrefix string
+	// PreserveHost forwards the client's Host header instead of the
+	// target's.
+	PreserveHost bool
+	// SetRequestHeaders and RemoveRequestHeaders rewrite headers sent
+	// upstream; SetResponseHeaders and RemoveResponseHeaders rewrite headers
+	// sent back to the client.
+	SetRequestHeaders     map[string]string
+	RemoveRequestHeaders  []string
+	SetResponseHeaders    map[string]string
+	RemoveResponseHeaders []string
+	// Retries is how many times an idempotent request (GET, HEAD, OPTIONS,
+	// PUT, DELETE) with a body of at most 1 MiB is retried after a
+	// connection error or a 502, 503 or 504 from the target.
+	Retries int
+	// RetryBackoff is the delay before the first retry, doubled on each
+	// further one; default 100ms.
+	RetryBackoff time.Duration
+	// Timeout bounds the whole upstream exchange, retries included; the
+	// client gets a 504 when it passes. Zero means no limit.
+	Timeout time.Duration
+	// Transport sends the upstream requests; default http.DefaultTransport.
+	Transport http.RoundTripper
+	// ModifyResponse, if set, may change or reject the upstream response.
+	ModifyResponse func(*http.Response) error
+	// OnError renders failures; the default sends 502, 503 when no upstream
+	// of a pool is healthy, or 504 on timeout.
+	OnError func(c *Context, err error)
+}
+
+// DefaultProxyOptions returns the default ProxyOptions.
+func DefaultProxyOptions() ProxyOptions {
+	return ProxyOptions{
+		RetryBackoff: 100 * time.Millisecond,
+		Transport:    http.DefaultTransport,
+		OnError: func(c *Context, err error) {
+			if errors.Is(err, context.DeadlineExceeded) {
+				c.Fail(http.StatusGatewayTimeout, MsgGatewayTimeout)
+				return
+			}
+			if errors.Is(err, ErrNoUpstream) {
+				c.Fail(http.StatusServiceUnavailable, MsgServiceUnavailable)
+				return
+			}
+			c.Fail(http.StatusBadGateway, MsgBadGateway)
+		},
+	}
+}
+
+// maxRetryBody is the largest request body buffered so it can be resent.
+const maxRetryBody = 1 << 20
+
+// proxyCtxKey carries the Context to the ReverseProxy error handler.
+type proxyCtxKey struct{}
+
+// Proxy forwards every method on path to target, turning the App into a
+// small API gateway:
+//
+//	app.Proxy("/payments/*path", "http://payments.internal:8080", zentrox.ProxyOptions{
+//		StripPrefix:       "/payments",
+//		SetRequestHeaders: map[string]string{"X-Gateway": "zentrox"},
+//		Retries:           2,
+//		Timeout:           5 * time.Second,
+//	})
+//
+// X-Forwarded-For, X-Forwarded-Host and X-Forwarded-Proto are set. It panics
+// if target is not an absolute URL.
+func (a *App) Proxy(path, target string, opt ...ProxyOptions) []*Route {
+	return a.Match(proxyMethods, path, ProxyHandler(target, opt...))
+}
+
+// Proxy is App.Proxy for routes in the scope.
+func (s *Scope) Proxy(path, target string, opt ...ProxyOptions) []*Route {
+	return s.Match(proxyMethods, path, ProxyHandler(target, opt...))
+}
+
+// proxyMethods also forwards OPTIONS so CORS preflights reach the target.
+var proxyMethods = append(append([]string{}, anyMethods...), http.MethodOptions)
+
+// ProxyHandler returns a handler forwarding requests to target, for use with
+// any route registration method.
+func ProxyHandler(target string, opt ...ProxyOptions) Handler {
+	u, err := url.Parse(target)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		panic("zentrox: Proxy target must be an absolute URL: " + target)
+	}
+	return PoolProxyHandler(NewUpstreamPool([]string{target}, UpstreamPoolConfig{MaxFails: -1}), opt...)
+}
+
+// ProxyPool is like Proxy, balancing requests across the upstreams of pool:
+//
+//	pool := zentrox.NewUpstreamPool([]string{"http://10.0.0.1:8080", "http://10.0.0.2:8080"},
+//		zentrox.UpstreamPoolConfig{Strategy: zentrox.LeastConnections})
+//	app.ProxyPool("/work/*path", pool, zentrox.ProxyOptions{Retries: 1})
+//
+// With Retries, a failed attempt is retried on another upstream.
+func (a *App) ProxyPool(path string, pool *UpstreamPool, opt ...ProxyOptions) []*Route {
+	return a.Match(proxyMethods, path, PoolProxyHandler(pool, opt...))
+}
+
+// ProxyPool is App.ProxyPool for routes in the scope.
+func (s *Scope) ProxyPool(path string, pool *UpstreamPool, opt ...ProxyOptions) []*Route {
+	return s.Match(proxyMethods, path, PoolProxyHandler(pool, opt...))
+}
+
+// PoolProxyHandler returns a handler balancing requests across pool.
+func PoolProxyHandler(pool *UpstreamPool, opt ...ProxyOptions) Handler {
+	def := DefaultProxyOptions()
+	conf := def
+	if len(opt) > 0 {
+		conf = opt[0]
+		if conf.RetryBackoff <= 0 {
+			conf.RetryBackoff = def.RetryBackoff
+		}
+		if conf.Transport == nil {
+			conf.Transport = def.Transport
+		}
+		if conf.OnError == nil {
+			conf.OnError = def.OnError
+		}
+	}
+
+	var transport http.RoundTripper = &poolTransport{pool: pool, rt: conf.Transport}
+	if conf.Retries > 0 {
+		transport = &retryTransport{rt: transport, retries: conf.Retries, backoff: conf.RetryBackoff}
+	}
+	rp := &httputil.ReverseProxy{
+		Transport: transport,
+		// The URL is completed by poolTransport once an upstream is picked.
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			if p := conf.StripPrefix; p != "" {
+				pr.Out.URL.Path = "/" + strings.TrimPrefix(strings.TrimPrefix(pr.Out.URL.Path, p), "/")
+				pr.Out.URL.RawPath = ""
+			}
+			pr.SetXForwarded()
+			if !conf.PreserveHost {
+				pr.Out.Host = ""
+			}
+			for k, v := range conf.SetRequestHeaders {
+				pr.Out.Header.Set(k, v)
+			}
+			for _, k := range conf.RemoveRequestHeaders {
+				pr.Out.Header.Del(k)
+			}
+		},
+		ModifyResponse: func(res *http.Response) error {
+			for k, v := range conf.SetResponseHeaders {
+				res.Header.Set(k, v)
+			}
+			for _, k := range conf.RemoveResponseHeaders {
+				res.Header.Del(k)
+			}
+			if conf.ModifyResponse != nil {
+				return conf.ModifyResponse(res)
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			c, _ := r.Context().Value(proxyCtxKey{}).(*Context)
+			if c == nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			c.err = err
+			conf.OnError(c, err)
+		},
+	}
+
+	return func(c *Context) {
+		ctx := context.WithValue(c.Request.Context(), proxyCtxKey{}, c)
+		if conf.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, conf.Timeout)
+			defer cancel()
+		}
+		rp.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
+
+// retryTransport retries idempotent requests after connection errors and
+// gateway failures, with exponential backoff.
+type retryTransport struct {
+	rt      http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return t.rt.RoundTrip(req)
+	}
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		b, err := io.ReadAll(io.LimitReader(req.Body, maxRetryBody+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > maxRetryBody {
+			// Too large to replay: send once.
+			r := req.Clone(req.Context())
+			r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(b), req.Body))
+			return t.rt.RoundTrip(r)
+		}
+		body = b
+	}
+
+	delay := t.backoff
+	for attempt := 0; ; attempt++ {
+		r := req
+		if body != nil {
+			r = req.Clone(req.Context())
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		res, err := t.rt.RoundTrip(r)
+		if attempt == t.retries || req.Context().Err() != nil || !retryable(res, err) {
+			return res, err
+		}
+		if res != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4<<10))
+			res.Body.Close()
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+		delay *= 2
+	}
+}
+
+func retryable(res *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch res.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
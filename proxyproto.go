@@ -0,0 +1,184 @@
+package zentrox
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrProxyHeader is returned when a connection does not start with a valid
+// PROXY protocol header.
+var ErrProxyHeader = errors.New("zentrox: invalid PROXY protocol header")
+
+var proxyV2Sig = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// NewProxyProtocolListener wraps ln so every accepted connection must begin
+// with a PROXY protocol v1 or v2 header, as sent by HAProxy or an AWS NLB in
+// TCP mode. RemoteAddr and LocalAddr report the addresses from the header, so
+// Request.RemoteAddr and RealIP see the real client.
+//
+// The header is read lazily on the connection's own goroutine; timeout bounds
+// how long that may take (0 means 5s). Connections without a valid header are
+// closed. Only enable this behind a proxy that always sends the header;
+// otherwise clients could spoof their address.
+func NewProxyProtocolListener(ln net.Listener, timeout time.Duration) net.Listener {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &proxyListener{Listener: ln, timeout: timeout}
+}
+
+type proxyListener struct {
+	net.Listener
+	timeout time.Duration
+}
+
+func (l *proxyListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &proxyConn{Conn: c, br: bufio.NewReader(c), timeout: l.timeout}, nil
+}
+
+type proxyConn struct {
+	net.Conn
+	br      *bufio.Reader
+	timeout time.Duration
+
+	once   sync.Once
+	err    error
+	remote net.Addr
+	local  net.Addr
+}
+
+func (c *proxyConn) init() {
+	c.once.Do(func() {
+		_ = c.Conn.SetReadDeadline(time.Now().Add(c.timeout))
+		c.remote, c.local, c.err = readProxyHeader(c.br)
+		if c.err != nil {
+			_ = c.Conn.Close()
+			return
+		}
+		_ = c.Conn.SetReadDeadline(time.Time{})
+	})
+}
+
+func (c *proxyConn) Read(p []byte) (int, error) {
+	c.init()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.br.Read(p)
+}
+
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.init()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+func (c *proxyConn) LocalAddr() net.Addr {
+	c.init()
+	if c.local != nil {
+		return c.local
+	}
+	return c.Conn.LocalAddr()
+}
+
+// readProxyHeader parses a v1 or v2 header. Nil addresses mean the proxy
+// sent UNKNOWN/LOCAL and the socket addresses apply.
+func readProxyHeader(br *bufio.Reader) (remote, local net.Addr, err error) {
+	sig, err := br.Peek(len(proxyV2Sig))
+	if err == nil && bytes.Equal(sig, proxyV2Sig) {
+		return readProxyV2(br)
+	}
+	if len(sig) >= 6 && string(sig[:6]) == "PROXY " {
+		return readProxyV1(br)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrProxyHeader, err)
+	}
+	return nil, nil, ErrProxyHeader
+}
+
+func readProxyV1(br *bufio.Reader) (net.Addr, net.Addr, error) {
+	// A v1 header is at most 107 bytes including CRLF.
+	var line []byte
+	for len(line) < 107 {
+		b, err := br.ReadByte()
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrProxyHeader, err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	s, ok := strings.CutSuffix(string(line), "\r\n")
+	if !ok {
+		return nil, nil, ErrProxyHeader
+	}
+	f := strings.Split(s, " ")
+	if len(f) >= 2 && f[1] == "UNKNOWN" {
+		return nil, nil, nil
+	}
+	if len(f) != 6 || (f[1] != "TCP4" && f[1] != "TCP6") {
+		return nil, nil, ErrProxyHeader
+	}
+	src, dst := net.ParseIP(f[2]), net.ParseIP(f[3])
+	sport, err1 := strconv.ParseUint(f[4], 10, 16)
+	dport, err2 := strconv.ParseUint(f[5], 10, 16)
+	if src == nil || dst == nil || err1 != nil || err2 != nil {
+		return nil, nil, ErrProxyHeader
+	}
+	return &net.TCPAddr{IP: src, Port: int(sport)}, &net.TCPAddr{IP: dst, Port: int(dport)}, nil
+}
+
+func readProxyV2(br *bufio.Reader) (net.Addr, net.Addr, error) {
+	var hdr [16]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrProxyHeader, err)
+	}
+	if hdr[12]>>4 != 2 {
+		return nil, nil, ErrProxyHeader
+	}
+	cmd, fam := hdr[12]&0x0f, hdr[13]
+	body := make([]byte, binary.BigEndian.Uint16(hdr[14:16]))
+	if _, err := io.ReadFull(br, body); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrProxyHeader, err)
+	}
+	if cmd == 0 { // LOCAL: health checks from the proxy itself
+		return nil, nil, nil
+	}
+	if cmd != 1 {
+		return nil, nil, ErrProxyHeader
+	}
+	var n int
+	switch fam {
+	case 0x11, 0x12: // TCP/UDP over IPv4
+		n = 4
+	case 0x21, 0x22: // TCP/UDP over IPv6
+		n = 16
+	default: // UNSPEC or unix sockets
+		return nil, nil, nil
+	}
+	if len(body) < 2*n+4 {
+		return nil, nil, ErrProxyHeader
+	}
+	src := net.IP(append([]byte(nil), body[:n]...))
+	dst := net.IP(append([]byte(nil), body[n:2*n]...))
+	sport := binary.BigEndian.Uint16(body[2*n:])
+	dport := binary.BigEndian.Uint16(body[2*n+2:])
+	return &net.TCPAddr{IP: src, Port: int(sport)}, &net.TCPAddr{IP: dst, Port: int(dport)}, nil
+}
@@ -0,0 +1,87 @@
+package zentrox
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+)
+
+// jsonpCallback accepts dotted JavaScript identifiers such as "cb" or
+// "jQuery.handlers.cb_1"; anything else could inject script.
+var jsonpCallback = regexp.MustCompile(`^[A-Za-z_$][\w$]*(\.[A-Za-z_$][\w$]*)*$`)
+
+// marshalJSON encodes v with the App's codec, writing a 500 instead when
+// that fails. It reports whether the caller should write b.
+func (c *Context) marshalJSON(v any) ([]byte, bool) {
+	b, err := c.jsonCodec().Marshal(v)
+	if err != nil {
+		c.err = err
+		c.setContentType(jsonContentType)
+		c.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = c.Writer.Write([]byte(`{"code":500,"message":"` + MsgJSONEncodeFailed + `"}`))
+		return nil, false
+	}
+	return b, true
+}
+
+// IndentedJSON sends v as JSON indented with four spaces, for humans reading
+// debug endpoints. Prefer JSON in production: it is smaller and faster.
+func (c *Context) IndentedJSON(code int, v any) {
+	b, ok := c.marshalJSON(v)
+	if !ok {
+		return
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", "    "); err != nil {
+		buf.Reset()
+		buf.Write(b)
+	}
+	buf.WriteByte('\n')
+	c.setContentType(jsonContentType)
+	c.Writer.WriteHeader(code)
+	_, _ = c.Writer.Write(buf.Bytes())
+}
+
+// JSONP sends v wrapped in a call to the function named by the "callback"
+// query parameter, for legacy browser integrations:
+//
+//	GET /users?callback=render  ->  /**/render({"id":1});
+//
+// Without a valid callback the response is plain JSON. The leading comment
+// guards against content sniffing attacks such as Rosetta Flash.
+func (c *Context) JSONP(code int, v any) {
+	cb := c.Query("callback")
+	if !jsonpCallback.MatchString(cb) {
+		c.JSON(code, v)
+		return
+	}
+	b, ok := c.marshalJSON(v)
+	if !ok {
+		return
+	}
+	c.Writer.Header().Set(HeaderContentType, ContentTypeJavaScriptUTF8)
+	c.Writer.WriteHeader(code)
+	_, _ = c.Writer.Write([]byte("/**/" + cb + "("))
+	_, _ = c.Writer.Write(b)
+	_, _ = c.Writer.Write([]byte(");"))
+}
+
+// SecureJSON sends v as JSON preceded by a prefix (SecureJSONPrefix, i.e.
+// "while(1);", by default) so the response cannot be executed by a <script>
+// tag on another site (JSON hijacking). Clients strip the prefix before
+// parsing.
+func (c *Context) SecureJSON(code int, v any, prefix ...string) {
+	p := SecureJSONPrefix
+	if len(prefix) > 0 {
+		p = prefix[0]
+	}
+	b, ok := c.marshalJSON(v)
+	if !ok {
+		return
+	}
+	c.setContentType(jsonContentType)
+	c.Writer.WriteHeader(code)
+	_, _ = c.Writer.Write([]byte(p))
+	_, _ = c.Writer.Write(b)
+}
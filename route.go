@@ -0,0 +1,104 @@
+package zentrox
+
+import (
+	"fmt"
+	"time"
+)
+
+// Route is a registered route. Its methods configure per-route options and
+// return the Route for chaining:
+//
+//	api.GET("/reports/:id", h).Timeout(30 * time.Second)
+type Route struct {
+	app   *App
+	entry *routeEntry
+}
+
+// Method returns the HTTP method of the route.
+func (r *Route) Method() string {
+	return r.entry.method
+}
+
+// Path returns the registered route pattern.
+func (r *Route) Path() string {
+	return r.entry.pattern
+}
+
+// Timeout overrides the Timeout middleware duration for this route.
+func (r *Route) Timeout(d time.Duration) *Route {
+	r.entry.timeout = d
+	return r
+}
+
+// Meta attaches a key/value annotation to the route, read by middleware
+// with Context.RouteMeta:
+//
+//	app.GET("/orders", h).Meta("audit", true).Meta("scope", "orders:read")
+func (r *Route) Meta(key string, value any) *Route {
+	if r.entry.meta == nil {
+		r.entry.meta = make(map[string]any)
+	}
+	r.entry.meta[key] = value
+	r.update(func(ri *RouteInfo) { ri.Meta = r.entry.meta })
+	return r
+}
+
+// Name registers name for the route so URLFor can build its path:
+//
+//	app.GET("/users/:id", showUser).Name("user.show")
+//
+// It panics if name is already used by another route pattern.
+func (r *Route) Name(name string) *Route {
+	if r.app.routeNames == nil {
+		r.app.routeNames = make(map[string]string)
+	}
+	if p, ok := r.app.routeNames[name]; ok && p != r.entry.pattern {
+		panic(fmt.Sprintf("zentrox: route name %q already used by %s", name, p))
+	}
+	r.app.routeNames[name] = r.entry.pattern
+	r.update(func(ri *RouteInfo) { ri.Name = name })
+	return r
+}
+
+// Summary sets a one-line description used by ExportRoutes.
+func (r *Route) Summary(s string) *Route {
+	r.update(func(ri *RouteInfo) { ri.Summary = s })
+	return r
+}
+
+// Body records an example request body, typically a zero or sample value of
+// the binding struct. Exporters render it as JSON.
+func (r *Route) Body(example any) *Route {
+	r.update(func(ri *RouteInfo) { ri.Body = example })
+	return r
+}
+
+// Returns records an example response body.
+func (r *Route) Returns(example any) *Route {
+	r.update(func(ri *RouteInfo) { ri.Response = example })
+	return r
+}
+
+// SkipPlugs removes middleware from this route's chain by name, e.g. to keep
+// health checks out of access logs:
+//
+//	app.GET("/healthz", h).SkipPlugs("Logger", "Compress")
+//
+// A name matches the constructor of a middleware as listed by ListRoutes
+// ("Logger" matches "Logger.func1"), case-insensitively. Global, scope and
+// route middleware are all eligible; the handler itself is kept.
+func (r *Route) SkipPlugs(names ...string) *Route {
+	n := len(r.entry.stack) - 1
+	mws := skipHandlers(r.entry.stack[:n], names)
+	r.entry.stack = append(mws, r.entry.stack[n])
+	r.update(func(ri *RouteInfo) { ri.Middlewares = middlewareNames(mws) })
+	return r
+}
+
+func (r *Route) update(fn func(*RouteInfo)) {
+	key := routeKey(r.entry.method, r.entry.host, r.entry.pattern)
+	if ri, ok := r.app.routeIndex[key]; ok {
+		fn(&ri)
+		r.app.routeIndex[key] = ri
+	}
+}
@@ -0,0 +1,241 @@
+package zentrox
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// Route export formats accepted by ExportRoutes.
+const (
+	RoutesTable    = "table"
+	RoutesGrouped  = "grouped"
+	RoutesJSON     = "json"
+	RoutesMarkdown = "markdown"
+	RoutesPostman  = "postman"
+)
+
+// EnvExportRoutes makes Run, RunTLS, RunAutoTLS, Start and StartTLS write
+// the route table to stdout in the named format and exit before starting
+// anything else. The "zentrox routes" command sets it.
+const EnvExportRoutes = "ZENTROX_EXPORT_ROUTES"
+
+// ExportedRoute is the JSON form of a route written by ExportRoutes.
+type ExportedRoute struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Params      []string `json:"params,omitempty"`
+	Handler     string   `json:"handler,omitempty"`
+	Middlewares []string `json:"middlewares,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Group       string   `json:"group,omitempty"`
+	Summary     string   `json:"summary,omitempty"`
+	Body        any      `json:"body,omitempty"`
+	Response    any      `json:"response,omitempty"`
+}
+
+// ExportRoutes writes the route table to w as plain text (optionally grouped
+// by Scope), JSON, a Markdown reference or a Postman v2.1 collection
+// (importable by Insomnia). Examples recorded with
+// Route.Body and Route.Returns are included. RoutesGoClient produces a typed
+// Go client; see GenerateClient. RoutesOpenAPI produces an OpenAPI 3.1
+// document; see GenerateOpenAPI.
+func (a *App) ExportRoutes(w io.Writer, format string) error {
+	routes := a.exportedRoutes()
+	switch strings.ToLower(format) {
+	case RoutesTable:
+		a.PrintRoutes(w)
+		return nil
+	case RoutesGrouped:
+		return a.writeRoutesGrouped(w)
+	case RoutesJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(routes)
+	case RoutesMarkdown, "md":
+		return writeRoutesMarkdown(w, routes)
+	case RoutesPostman:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(a.postmanCollection(routes))
+	case RoutesGoClient:
+		return a.GenerateClient(w, "client")
+	case RoutesOpenAPI:
+		b, err := a.GenerateOpenAPI(OpenAPIInfo{})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(b, '\n'))
+		return err
+	}
+	if pkg, ok := strings.CutPrefix(format, RoutesGoClient+":"); ok {
+		return a.GenerateClient(w, pkg)
+	}
+	return fmt.Errorf("zentrox: unknown route export format %q", format)
+}
+
+func (a *App) exportedRoutes() []ExportedRoute {
+	list := a.ListRoutes()
+	out := make([]ExportedRoute, 0, len(list))
+	for _, ri := range list {
+		out = append(out, ExportedRoute{
+			Method:      ri.Method,
+			Path:        ri.Path,
+			Params:      pathParams(ri.Path),
+			Handler:     ri.HandlerName,
+			Middlewares: ri.Middlewares,
+			Name:        ri.Name,
+			Group:       ri.Group,
+			Summary:     ri.Summary,
+			Body:        exampleValue(ri.Body),
+			Response:    exampleValue(ri.Response),
+		})
+	}
+	return out
+}
+
+// exportRoutesFromEnv writes the route table to stdout when EnvExportRoutes
+// is set, and reports whether it did.
+func (a *App) exportRoutesFromEnv() (bool, error) {
+	format := os.Getenv(EnvExportRoutes)
+	if format == "" {
+		return false, nil
+	}
+	return true, a.ExportRoutes(os.Stdout, format)
+}
+
+// exitIfExportingRoutes handles EnvExportRoutes for the server entry points
+// (Run, RunTLS, RunAutoTLS, Start, StartTLS): it exports the routes and ends
+// the process. They call it before building the server, so scheduled jobs
+// and ConfigureServer hooks never start during an export. "zentrox routes"
+// relies on this to get the route table out of an app's main.
+func (a *App) exitIfExportingRoutes() {
+	ok, err := a.exportRoutesFromEnv()
+	if !ok {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// pathParams returns the names of ":name" and "*name" segments.
+func pathParams(pattern string) []string {
+	var out []string
+	for _, seg := range splitPattern(pattern) {
+		if len(seg) > 1 && (seg[0] == ':' || seg[0] == '*') {
+			out = append(out, paramName(seg))
+		}
+	}
+	return out
+}
+
+// exampleValue turns nil pointers to structs into zero values so binding
+// types can be passed as (*T)(nil).
+func exampleValue(v any) any {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return reflect.New(rv.Type().Elem()).Interface()
+	}
+	return v
+}
+
+func exampleJSON(v any) string {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+func writeRoutesMarkdown(w io.Writer, routes []ExportedRoute) error {
+	var b strings.Builder
+	b.WriteString("# Routes\n\n| Method | Path | Handler | Summary |\n|---|---|---|---|\n")
+	for _, r := range routes {
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n", r.Method, r.Path, r.Handler, r.Summary)
+	}
+	for _, r := range routes {
+		if r.Body == nil && r.Response == nil && len(r.Params) == 0 && r.Summary == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s %s\n", r.Method, r.Path)
+		if r.Summary != "" {
+			fmt.Fprintf(&b, "\n%s\n", r.Summary)
+		}
+		if len(r.Params) > 0 {
+			fmt.Fprintf(&b, "\nPath parameters: `%s`\n", strings.Join(r.Params, "`, `"))
+		}
+		if r.Body != nil {
+			fmt.Fprintf(&b, "\nRequest body:\n\n```json\n%s\n```\n", exampleJSON(r.Body))
+		}
+		if r.Response != nil {
+			fmt.Fprintf(&b, "\nResponse:\n\n```json\n%s\n```\n", exampleJSON(r.Response))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (a *App) postmanCollection(routes []ExportedRoute) map[string]any {
+	name := "API"
+	if a.version != "" {
+		name += " " + a.version
+	}
+	items := make([]map[string]any, 0, len(routes))
+	for _, r := range routes {
+		var segs []string
+		var vars []map[string]any
+		for _, seg := range splitPattern(strings.Trim(r.Path, "/")) {
+			if len(seg) > 1 && (seg[0] == '*' || seg[0] == ':') {
+				seg = ":" + paramName(seg)
+			}
+			if len(seg) > 1 && seg[0] == ':' {
+				vars = append(vars, map[string]any{"key": seg[1:], "value": ""})
+			}
+			if seg != "" {
+				segs = append(segs, seg)
+			}
+		}
+		url := map[string]any{
+			"raw":  "{{baseUrl}}" + "/" + strings.Join(segs, "/"),
+			"host": []string{"{{baseUrl}}"},
+			"path": segs,
+		}
+		if len(vars) > 0 {
+			url["variable"] = vars
+		}
+		req := map[string]any{
+			"method": r.Method,
+			"header": []any{},
+			"url":    url,
+		}
+		if r.Summary != "" {
+			req["description"] = r.Summary
+		}
+		if r.Body != nil {
+			req["header"] = []map[string]string{{"key": HeaderContentType, "value": ContentTypeJSON}}
+			req["body"] = map[string]any{
+				"mode":    "raw",
+				"raw":     exampleJSON(r.Body),
+				"options": map[string]any{"raw": map[string]string{"language": "json"}},
+			}
+		}
+		items = append(items, map[string]any{"name": r.Method + " " + r.Path, "request": req})
+	}
+	return map[string]any{
+		"info": map[string]any{
+			"name":   name,
+			"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+		},
+		"item":     items,
+		"variable": []map[string]string{{"key": "baseUrl", "value": "http://localhost:8000"}},
+	}
+}
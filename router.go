@@ -1,13 +1,26 @@
 package zentrox
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // routeEntry carries the final, compiled handler stack for a route.
 type routeEntry struct {
-	stack []Handler
+	stack   []Handler
+	method  string
+	pattern string // registered pattern, e.g. "/users/:id"
+	timeout time.Duration
+	auto    bool           // automatic OPTIONS handler; replaced by explicit routes
+	routing *RouterOptions // scope override of App.routing, see SetRouterOptions
+	host    string         // Host pattern, see App.Host
+	meta    map[string]any // see Route.Meta
+	rt      *router        // router holding the entry
 }
 
 // routeNode represents a node in the route trie.
@@ -19,12 +32,27 @@ type routeNode struct {
 	param *routeNode
 	pname string
 
+	// Constrained parameter children (e.g. ":id|int"), tried in registration
+	// order before the unconstrained one.
+	cparams []*paramChild
+
 	// Wildcard child (e.g. "*filepath"), stores the name without the '*'.
 	wildcard *routeNode
 	wname    string
 
 	// handlers per HTTP method at this node.
 	handlers map[string]*routeEntry
+	// allow is the Allow header value for this node, kept current by add.
+	allow string
+}
+
+// paramChild is a parameter edge that only matches segments accepted by
+// check.
+type paramChild struct {
+	name  string
+	spec  string
+	check func(string) bool
+	node  *routeNode
 }
 
 // router owns the root node of the trie.
@@ -37,12 +65,60 @@ func newRouter() *router {
 }
 
 // add compiles the pattern into the trie and attaches the final stack.
-func (r *router) add(method, pattern string, mws []Handler, h Handler) {
+func (r *router) add(method, pattern string, mws []Handler, h Handler) *routeEntry {
+	cur := r.node(pattern)
+	stack := append([]Handler{}, mws...)
+	stack = append(stack, h)
+	entry := &routeEntry{stack: stack, method: method, pattern: pattern, rt: r}
+	cur.handlers[method] = entry
+	cur.allow = strings.Join(cur.methods(), ", ")
+	return entry
+}
+
+// addAutoOptions attaches an automatic OPTIONS handler to pattern unless
+// one was registered explicitly.
+func (r *router) addAutoOptions(pattern string, mws []Handler) {
+	if e := r.node(pattern).handlers[http.MethodOptions]; e != nil && !e.auto {
+		return
+	}
+	r.add(http.MethodOptions, pattern, mws, autoOptions).auto = true
+}
+
+// autoOptions answers OPTIONS with the methods registered for the path.
+func autoOptions(c *Context) {
+	if c.entry != nil {
+		if allow := c.entry.rt.allowed(c.Request.URL.Path); allow != "" {
+			c.SetHeader(HeaderAllow, allow)
+		}
+	}
+	c.SendStatus(http.StatusNoContent)
+}
+
+// node returns the trie node for pattern, creating it as needed.
+func (r *router) node(pattern string) *routeNode {
 	segs := compilePattern(pattern)
 
 	cur := r.root
 	for i, s := range segs {
 		switch {
+		case s.isParam && s.constraint != "":
+			var child *paramChild
+			for _, c := range cur.cparams {
+				if c.spec == s.constraint {
+					child = c
+					break
+				}
+			}
+			if child == nil {
+				child = &paramChild{
+					name:  s.name,
+					spec:  s.constraint,
+					check: paramConstraint(s.constraint, pattern),
+					node:  &routeNode{static: map[string]*routeNode{}},
+				}
+				cur.cparams = append(cur.cparams, child)
+			}
+			cur = child.node
 		case s.isParam:
 			if cur.param == nil {
 				cur.param = &routeNode{static: map[string]*routeNode{}}
@@ -51,7 +127,7 @@ func (r *router) add(method, pattern string, mws []Handler, h Handler) {
 			cur = cur.param
 		case s.isWildcard:
 			if i != len(segs)-1 {
-				panic("wildcard must be the last segment")
+				panic(fmt.Sprintf("wildcard must be the last segment in route %q", pattern))
 			}
 			if cur.wildcard == nil {
 				cur.wildcard = &routeNode{static: map[string]*routeNode{}}
@@ -74,122 +150,124 @@ func (r *router) add(method, pattern string, mws []Handler, h Handler) {
 	if cur.handlers == nil {
 		cur.handlers = map[string]*routeEntry{}
 	}
-	stack := append([]Handler{}, mws...)
-	stack = append(stack, h)
-	cur.handlers[method] = &routeEntry{stack: stack}
+	return cur
 }
 
-// match walks the trie using a zero-allocation path iterator. It fills params.
-func (r *router) match(method, path string, params map[string]string) *routeEntry {
-	cur := r.root
-	it := newPathIter(path)
+// match walks the trie using a zero-allocation path iterator. It appends the
+// matched params to *params.
+func (r *router) match(method, path string, params *[]param) *routeEntry {
+	node := r.root.lookup(method, newPathIter(path), params)
+	if node == nil {
+		return nil
+	}
+	return node.handlers[method]
+}
 
-	for {
-		seg, ok := it.next()
-		if !ok {
-			break
-		}
+// findNode walks the trie using the path only (ignores HTTP method) and
+// returns the node of the route matching it, or nil.
+func (r *router) findNode(path string) *routeNode {
+	return r.root.lookup("", newPathIter(path), nil)
+}
 
-		// Static first
-		if cur.static != nil {
-			if next := cur.static[seg]; next != nil {
-				cur = next
-				continue
-			}
+// lookup returns the node below n that matches the rest of it and has a
+// handler for method (any handler when method is ""). At each level it
+// tries the static child, the constrained parameters in registration
+// order, the plain parameter and then the wildcard, backtracking to the
+// next candidate when a subtree has no such route, so "/users/42/profile"
+// reaches "/users/:name/profile" even though "/users/:id|int/posts" also
+// accepts 42. Params of abandoned candidates are removed from *params;
+// params may be nil when they are not needed.
+func (n *routeNode) lookup(method string, it pathIter, params *[]param) *routeNode {
+	seg, ok := it.next()
+	if !ok {
+		if n.serves(method) {
+			return n
 		}
+		return nil
+	}
 
-		// Param
-		if cur.param != nil {
-			params[cur.pname] = seg
-			cur = cur.param
-			continue
+	// Static first
+	if next := n.static[seg]; next != nil {
+		if found := next.lookup(method, it, params); found != nil {
+			return found
 		}
+	}
 
-		// Wildcard
-		if cur.wildcard != nil {
-			params[cur.wname] = it.tail(seg)
-			cur = cur.wildcard
-			// Wildcard is always terminal.
-			break
+	// Params: constrained ones, then the unconstrained one.
+	for _, c := range n.cparams {
+		if c.check(seg) {
+			if found := c.node.lookupParam(method, c.name, seg, it, params); found != nil {
+				return found
+			}
+		}
+	}
+	if n.param != nil {
+		if found := n.param.lookupParam(method, n.pname, seg, it, params); found != nil {
+			return found
 		}
+	}
 
-		// No match at this level
-		return nil
+	// Wildcard is always terminal.
+	if n.wildcard != nil && n.wildcard.serves(method) {
+		if params != nil {
+			*params = append(*params, param{n.wname, it.tail(seg)})
+		}
+		return n.wildcard
 	}
+	return nil
+}
 
-	if cur.handlers == nil {
-		return nil
+// lookupParam is lookup on the parameter child n after binding name to seg.
+func (n *routeNode) lookupParam(method, name, seg string, it pathIter, params *[]param) *routeNode {
+	if params == nil {
+		return n.lookup(method, it, nil)
 	}
-	return cur.handlers[method]
+	mark := len(*params)
+	*params = append(*params, param{name, seg})
+	if found := n.lookup(method, it, params); found != nil {
+		return found
+	}
+	*params = (*params)[:mark]
+	return nil
 }
 
-// findNode walks the trie using the path only (ignores HTTP method) and
-// returns the final node if it exists (wildcard is terminal).
-func (r *router) findNode(path string) *routeNode {
-	cur := r.root
-	it := newPathIter(path)
-	for {
-		seg, ok := it.next()
-		if !ok {
-			break
-		}
-		// Prefer static match.
-		if cur.static != nil {
-			if next := cur.static[seg]; next != nil {
-				cur = next
-				continue
-			}
-		}
-		// Param match allows any single segment.
-		if cur.param != nil {
-			cur = cur.param
-			continue
-		}
-		// Wildcard match accepts the rest of the path.
-		if cur.wildcard != nil {
-			cur = cur.wildcard
-			break
-		}
-		// No node for this path.
-		return nil
+// serves reports whether n has a handler for method, or any handler when
+// method is "".
+func (n *routeNode) serves(method string) bool {
+	if method == "" {
+		return n.handlers != nil
 	}
-	return cur
+	return n.handlers[method] != nil
 }
 
-// allowed returns a list of allowed HTTP methods for the given path.
-// If a GET handler exists, HEAD is included automatically.
-// OPTIONS is always included when the path exists.
-func (r *router) allowed(path string) []string {
+// allowed returns the Allow header value for the given path, or "" when no
+// route matches it.
+func (r *router) allowed(path string) string {
 	node := r.findNode(path)
-	if node == nil || node.handlers == nil {
-		return nil
+	if node == nil {
+		return ""
 	}
-	// Collect registered methods.
-	seen := map[string]struct{}{}
-	out := make([]string, 0, len(node.handlers)+2)
-	for m := range node.handlers {
-		// Normalize to upper-case method names commonly used.
-		if m == "" {
-			continue
-		}
-		if _, ok := seen[m]; !ok {
-			seen[m] = struct{}{}
+	return node.allow
+}
+
+// methods returns the sorted methods registered at n. If a GET handler
+// exists, HEAD is included automatically; OPTIONS is always included.
+func (n *routeNode) methods() []string {
+	out := make([]string, 0, len(n.handlers)+2)
+	for m := range n.handlers {
+		if m != "" {
 			out = append(out, m)
 		}
 	}
-	// Add implicit HEAD if GET exists but HEAD is not explicit.
-	if _, hasGET := node.handlers[http.MethodGet]; hasGET {
-		if _, hasHEAD := node.handlers[http.MethodHead]; !hasHEAD {
-			if _, ok := seen[http.MethodHead]; !ok {
-				seen[http.MethodHead] = struct{}{}
-				out = append(out, http.MethodHead)
-			}
+	if _, hasGET := n.handlers[http.MethodGet]; hasGET {
+		if _, hasHEAD := n.handlers[http.MethodHead]; !hasHEAD {
+			out = append(out, http.MethodHead)
 		}
 	}
-	// Always include OPTIONS for an existing path.
-	if _, ok := seen[http.MethodOptions]; !ok {
+	if _, ok := n.handlers[http.MethodOptions]; !ok {
 		out = append(out, http.MethodOptions)
 	}
+	sort.Strings(out)
 	return out
 }
 
@@ -198,6 +276,7 @@ func (r *router) allowed(path string) []string {
 type compiledSeg struct {
 	literal    string // for static segments
 	name       string // for :name or *name (without prefix)
+	constraint string // for :name|constraint
 	isParam    bool
 	isWildcard bool
 }
@@ -214,14 +293,15 @@ func compilePattern(p string) []compiledSeg {
 	if p == "" {
 		return nil
 	}
-	parts := strings.Split(p, "/")
+	parts := splitPattern(p)
 	out := make([]compiledSeg, 0, len(parts))
 	for _, s := range parts {
 		if s == "" {
 			continue
 		}
 		if s[0] == ':' {
-			out = append(out, compiledSeg{isParam: true, name: s[1:]})
+			name, spec, _ := strings.Cut(s[1:], "|")
+			out = append(out, compiledSeg{isParam: true, name: name, constraint: spec})
 			continue
 		}
 		if s[0] == '*' {
@@ -233,6 +313,71 @@ func compilePattern(p string) []compiledSeg {
 	return out
 }
 
+// paramConstraints are the named constraints usable as ":name|<constraint>".
+var paramConstraints = map[string]func(string) bool{
+	"int": func(s string) bool {
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err == nil
+	},
+	"uint": func(s string) bool {
+		_, err := strconv.ParseUint(s, 10, 64)
+		return err == nil
+	},
+	"alpha": regexp.MustCompile(`^[A-Za-z]+$`).MatchString,
+	"alnum": regexp.MustCompile(`^[A-Za-z0-9]+$`).MatchString,
+	"uuid":  regexp.MustCompile(`^[0-9A-Fa-f]{8}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{4}-[0-9A-Fa-f]{12}$`).MatchString,
+}
+
+// splitPattern is strings.Split(p, "/"), except that a "/" inside a
+// constraint's brackets or parentheses, or escaped with a backslash, does
+// not end the segment, so ":name|[^/]+" stays one segment.
+func splitPattern(p string) []string {
+	var out []string
+	start, depth, class, inSpec := 0, 0, false, false
+	for i := 0; i < len(p); i++ {
+		switch ch := p[i]; {
+		case ch == '/' && depth == 0 && !class:
+			out = append(out, p[start:i])
+			start, inSpec = i+1, false
+		case !inSpec:
+			inSpec = ch == '|' && p[start] == ':'
+		case ch == '\\':
+			i++
+		case class:
+			class = ch != ']'
+		case ch == '[':
+			class = true
+		case ch == '(':
+			depth++
+		case ch == ')' && depth > 0:
+			depth--
+		}
+	}
+	return append(out, p[start:])
+}
+
+// paramConstraint returns the check for a named constraint, or compiles spec
+// as a regular expression anchored at both ends. Invalid expressions panic at
+// registration, naming the route pattern, like other malformed patterns.
+func paramConstraint(spec, pattern string) func(string) bool {
+	if f, ok := paramConstraints[spec]; ok {
+		return f
+	}
+	expr := strings.TrimSuffix(strings.TrimPrefix(spec, "^"), "$")
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	if err != nil {
+		panic(fmt.Sprintf("invalid constraint %q in route %q: %v", spec, pattern, err))
+	}
+	return re.MatchString
+}
+
+// paramName strips the ':' or '*' prefix and any "|constraint" from a
+// pattern segment.
+func paramName(seg string) string {
+	name, _, _ := strings.Cut(seg[1:], "|")
+	return name
+}
+
 // pathIter yields each segment of a URL path without allocating []string.
 // It returns slices referencing the original path string.
 type pathIter struct {
@@ -0,0 +1,317 @@
+package zentrox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// S3Config configures S3Storage. Any S3-compatible service works (AWS S3,
+// MinIO, Cloudflare R2, DigitalOcean Spaces); requests are signed with AWS
+// Signature Version 4.
+type S3Config struct {
+	// Endpoint is the service base URL, e.g. "https://s3.amazonaws.com" or
+	// "http://localhost:9000".
+	Endpoint string
+	// Region used for signing; default "us-east-1".
+	Region string
+	Bucket string
+	// Credentials. SessionToken is only needed for temporary credentials.
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	// PathStyle addresses objects as Endpoint/Bucket/key instead of
+	// Bucket.host/key. Most self-hosted services need it.
+	PathStyle bool
+	// PublicURL, if set, makes URL return PublicURL + "/" + key (e.g. a CDN
+	// in front of a public bucket) instead of a presigned GET URL.
+	PublicURL string
+	// URLExpiry is how long presigned URLs stay valid; default 15 minutes.
+	URLExpiry time.Duration
+	// Client sends the requests; default http.DefaultClient.
+	Client *http.Client
+}
+
+// DefaultS3Config returns a config with the default region and URL expiry.
+func DefaultS3Config() S3Config {
+	return S3Config{Region: "us-east-1", URLExpiry: 15 * time.Minute}
+}
+
+// S3Storage is an UploadStorage backed by an S3-compatible bucket. Save
+// streams the body without buffering when its size is known.
+type S3Storage struct {
+	cfg S3Config
+	now func() time.Time
+}
+
+// NewS3Storage returns an S3Storage for cfg.
+func NewS3Storage(cfg S3Config) *S3Storage {
+	def := DefaultS3Config()
+	if cfg.Region == "" {
+		cfg.Region = def.Region
+	}
+	if cfg.URLExpiry <= 0 {
+		cfg.URLExpiry = def.URLExpiry
+	}
+	if cfg.Client == nil {
+		cfg.Client = http.DefaultClient
+	}
+	cfg.Endpoint = strings.TrimSuffix(cfg.Endpoint, "/")
+	return &S3Storage{cfg: cfg, now: time.Now}
+}
+
+// Save implements UploadStorage.
+func (s *S3Storage) Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		// S3 needs a Content-Length for a single PUT.
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		r, size = bytes.NewReader(b), int64(len(b))
+	}
+	req, err := s.request(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	if contentType != "" {
+		req.Header.Set(HeaderContentType, contentType)
+	}
+	res, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// Open implements UploadStorage.
+func (s *S3Storage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+// Delete implements UploadStorage.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.do(req)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// URL implements UploadStorage. Without PublicURL it returns a presigned GET
+// URL valid for URLExpiry.
+func (s *S3Storage) URL(_ context.Context, key string) (string, error) {
+	if s.cfg.PublicURL != "" {
+		return strings.TrimSuffix(s.cfg.PublicURL, "/") + "/" + s3EscapePath(key), nil
+	}
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	t := s.now().UTC()
+	q := url.Values{}
+	q.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	q.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+s.scope(t))
+	q.Set("X-Amz-Date", t.Format("20060102T150405Z"))
+	q.Set("X-Amz-Expires", strconv.Itoa(int(s.cfg.URLExpiry/time.Second)))
+	q.Set("X-Amz-SignedHeaders", "host")
+	if s.cfg.SessionToken != "" {
+		q.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+	u.RawQuery = s3Query(q)
+	h := http.Header{}
+	h.Set("Host", u.Host)
+	sig := s.signature(t, http.MethodGet, u, h, "UNSIGNED-PAYLOAD")
+	u.RawQuery += "&X-Amz-Signature=" + sig
+	return u.String(), nil
+}
+
+// objectURL returns the URL of key, honouring PathStyle.
+func (s *S3Storage) objectURL(key string) (*url.URL, error) {
+	u, err := url.Parse(s.cfg.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	key = strings.TrimPrefix(key, "/")
+	if key == "" {
+		return nil, fmt.Errorf("s3: empty key")
+	}
+	if s.cfg.PathStyle {
+		u.Path = "/" + s.cfg.Bucket + "/" + key
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = "/" + key
+	}
+	u.RawPath = s3EscapePath(u.Path)
+	return u, nil
+}
+
+// request builds an unsigned request for key; do signs it.
+func (s *S3Storage) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+// do signs req in the Authorization header and sends it, turning non-2xx
+// responses into errors; a 404 matches fs.ErrNotExist. The payload is not
+// hashed (UNSIGNED-PAYLOAD) so bodies are streamed.
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	t := s.now().UTC()
+	req.Header.Set("X-Amz-Date", t.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+	h := req.Header.Clone()
+	h.Set("Host", req.URL.Host)
+	sig := s.signature(t, req.Method, req.URL, h, "UNSIGNED-PAYLOAD")
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey, s.scope(t), strings.Join(s3SignedHeaders(h), ";"), sig))
+
+	res, err := s.cfg.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 == 2 {
+		return res, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(res.Body, 1<<10))
+	res.Body.Close()
+	err = &S3Error{StatusCode: res.StatusCode, Body: string(msg)}
+	if res.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+	return nil, err
+}
+
+// S3Error is returned for non-2xx responses from the object store.
+type S3Error struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *S3Error) Error() string {
+	return fmt.Sprintf("s3: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), strings.TrimSpace(e.Body))
+}
+
+func (s *S3Storage) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.cfg.Region + "/s3/aws4_request"
+}
+
+// signature computes the SigV4 signature over the canonical request.
+func (s *S3Storage) signature(t time.Time, method string, u *url.URL, h http.Header, payloadHash string) string {
+	names := s3SignedHeaders(h)
+	var canon strings.Builder
+	canon.WriteString(method + "\n")
+	canon.WriteString(u.EscapedPath() + "\n")
+	canon.WriteString(u.RawQuery + "\n")
+	for _, name := range names {
+		canon.WriteString(name + ":" + strings.TrimSpace(h.Get(name)) + "\n")
+	}
+	canon.WriteString("\n" + strings.Join(names, ";") + "\n" + payloadHash)
+
+	sum := sha256.Sum256([]byte(canon.String()))
+	toSign := "AWS4-HMAC-SHA256\n" + t.Format("20060102T150405Z") + "\n" + s.scope(t) + "\n" + hex.EncodeToString(sum[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), t.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
+
+// s3SignedHeaders returns the lower-cased names of the headers to sign.
+func s3SignedHeaders(h http.Header) []string {
+	var names []string
+	for name := range h {
+		l := strings.ToLower(name)
+		if l == "host" || l == "content-type" || strings.HasPrefix(l, "x-amz-") {
+			names = append(names, l)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// s3Query encodes q sorted by key with RFC 3986 escaping, as SigV4 requires.
+func s3Query(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		for _, v := range q[k] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(s3Escape(k, true) + "=" + s3Escape(v, true))
+		}
+	}
+	return b.String()
+}
+
+// s3EscapePath escapes each segment of p, keeping the slashes.
+func s3EscapePath(p string) string {
+	return s3Escape(p, false)
+}
+
+// s3Escape percent-encodes everything but RFC 3986 unreserved characters
+// (and "/" unless encodeSlash).
+func s3Escape(s string, encodeSlash bool) string {
+	const hexDigits = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hexDigits[c>>4])
+		b.WriteByte(hexDigits[c&15])
+	}
+	return b.String()
+}
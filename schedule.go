@@ -0,0 +1,334 @@
+package zentrox
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CronSchedule computes the run times of a job, see ParseCron.
+type CronSchedule interface {
+	// Next returns the first run time after t.
+	Next(t time.Time) time.Time
+}
+
+// ScheduledJob is a job registered with App.Schedule.
+type ScheduledJob struct {
+	Spec     string
+	schedule CronSchedule
+	job      func(context.Context) error
+	running  atomic.Bool
+	next     atomic.Int64 // unix nanoseconds, 0 while stopped
+}
+
+// Next returns the next run time, or the zero time while the scheduler is
+// not running.
+func (j *ScheduledJob) Next() time.Time {
+	if n := j.next.Load(); n != 0 {
+		return time.Unix(0, n)
+	}
+	return time.Time{}
+}
+
+// scheduler runs the App's scheduled jobs while a server is up.
+type scheduler struct {
+	mu      sync.Mutex
+	jobs    []*ScheduledJob
+	running bool
+	// ctx ends the job loops; runCtx is passed to jobs and only ends when
+	// Shutdown gives up waiting for them.
+	ctx, runCtx context.Context
+	stop, abort context.CancelFunc
+	loops, runs sync.WaitGroup
+}
+
+// Schedule runs job periodically while the App serves, from when Run (or
+// Start, RunTLS, ...) builds the server until Shutdown. spec is a standard
+// five-field cron expression (minute hour day-of-month month day-of-week,
+// with *, lists, ranges, steps and month/day names) or one of @hourly,
+// @daily, @midnight, @weekly, @monthly, @yearly and @every <duration>:
+//
+//	app.Schedule("*/5 * * * *", func(ctx context.Context) error {
+//		return sessions.DeleteExpired(ctx)
+//	})
+//	app.Schedule("@every 30s", refreshRates)
+//
+// A run is skipped while the previous one is still going. Errors and panics
+// are logged with the App logger. Times are in the local time zone. It
+// panics if spec is invalid.
+func (a *App) Schedule(spec string, job func(ctx context.Context) error) *ScheduledJob {
+	sched, err := ParseCron(spec)
+	if err != nil {
+		panic("Schedule: " + err.Error())
+	}
+	j := &ScheduledJob{Spec: spec, schedule: sched, job: job}
+	s := &a.sched
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs = append(s.jobs, j)
+	if s.running {
+		s.loops.Add(1)
+		go a.scheduleLoop(j, s.ctx, s.runCtx)
+	}
+	return j
+}
+
+// ScheduledJobs returns the jobs registered with Schedule.
+func (a *App) ScheduledJobs() []*ScheduledJob {
+	a.sched.mu.Lock()
+	defer a.sched.mu.Unlock()
+	return append([]*ScheduledJob(nil), a.sched.jobs...)
+}
+
+// startSchedule starts the job loops; called when a server is built.
+func (a *App) startSchedule() {
+	s := &a.sched
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return
+	}
+	s.running = true
+	s.ctx, s.stop = context.WithCancel(context.Background())
+	s.runCtx, s.abort = context.WithCancel(context.Background())
+	for _, j := range s.jobs {
+		s.loops.Add(1)
+		go a.scheduleLoop(j, s.ctx, s.runCtx)
+	}
+}
+
+// stopSchedule stops starting new runs; running jobs continue.
+func (a *App) stopSchedule() {
+	s := &a.sched
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return
+	}
+	s.running = false
+	s.stop()
+	s.mu.Unlock()
+	s.loops.Wait()
+}
+
+// drainSchedule stops the scheduler and waits for running jobs until ctx is
+// done, then cancels their context.
+func (a *App) drainSchedule(ctx context.Context) error {
+	a.stopSchedule()
+	done := make(chan struct{})
+	go func() {
+		a.sched.runs.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	a.sched.mu.Lock()
+	if a.sched.abort != nil {
+		a.sched.abort()
+	}
+	a.sched.mu.Unlock()
+	return err
+}
+
+func (a *App) scheduleLoop(j *ScheduledJob, ctx, runCtx context.Context) {
+	s := &a.sched
+	defer s.loops.Done()
+	defer j.next.Store(0)
+	for {
+		next := j.schedule.Next(time.Now())
+		if next.IsZero() {
+			a.Logger().Error("zentrox: scheduled job never runs", "spec", j.Spec)
+			return
+		}
+		j.next.Store(next.UnixNano())
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if !j.running.CompareAndSwap(false, true) {
+			a.Logger().Warn("zentrox: scheduled job still running, skipping", "spec", j.Spec)
+			continue
+		}
+		s.runs.Add(1)
+		go func() {
+			defer s.runs.Done()
+			defer j.running.Store(false)
+			defer func() {
+				if r := recover(); r != nil {
+					a.Logger().Error("zentrox: scheduled job panic", "spec", j.Spec, "panic", r, "stack", string(debug.Stack()))
+				}
+			}()
+			if err := j.job(runCtx); err != nil {
+				a.Logger().Error("zentrox: scheduled job failed", "spec", j.Spec, "err", err)
+			}
+		}()
+	}
+}
+
+// ParseCron parses a schedule spec as accepted by App.Schedule.
+func ParseCron(spec string) (CronSchedule, error) {
+	spec = strings.TrimSpace(spec)
+	if rest, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid interval in %q", spec)
+		}
+		return everySchedule(d), nil
+	}
+	switch spec {
+	case "@yearly", "@annually":
+		spec = "0 0 1 1 *"
+	case "@monthly":
+		spec = "0 0 1 * *"
+	case "@weekly":
+		spec = "0 0 * * 0"
+	case "@daily", "@midnight":
+		spec = "0 0 * * *"
+	case "@hourly":
+		spec = "0 * * * *"
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron spec %q must have 5 fields", spec)
+	}
+	var c cronSchedule
+	var err error
+	if c.minute, err = parseCronField(fields[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseCronField(fields[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseCronField(fields[2], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseCronField(fields[3], 1, 12, cronMonths); err != nil {
+		return nil, err
+	}
+	if c.dow, err = parseCronField(fields[4], 0, 7, cronDays); err != nil {
+		return nil, err
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 is Sunday too
+	}
+	c.domAny = fields[2] == "*"
+	c.dowAny = fields[4] == "*"
+	return c, nil
+}
+
+type everySchedule time.Duration
+
+func (e everySchedule) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// cronSchedule holds one bit per allowed value of each field.
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	domAny, dowAny                bool
+}
+
+func (c cronSchedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if c.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches applies cron's rule that a restricted day-of-month and
+// day-of-week match when either does.
+func (c cronSchedule) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if c.domAny || c.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+var (
+	cronMonths = map[string]int{"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12}
+	cronDays = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+// parseCronField parses a comma-separated list of *, n, a-b, with an
+// optional /step, into a bit set.
+func parseCronField(field string, lo, hi int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", field)
+			}
+			step = n
+		}
+		from, to := lo, hi
+		if rng != "*" {
+			a, b, isRange := strings.Cut(rng, "-")
+			var err error
+			if from, err = cronValue(a, lo, hi, names); err != nil {
+				return 0, fmt.Errorf("%w in %q", err, field)
+			}
+			to = from
+			if isRange {
+				if to, err = cronValue(b, lo, hi, names); err != nil {
+					return 0, fmt.Errorf("%w in %q", err, field)
+				}
+			} else if hasStep {
+				to = hi
+			}
+			if from > to {
+				return 0, fmt.Errorf("invalid range in %q", field)
+			}
+		}
+		for v := from; v <= to; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func cronValue(s string, lo, hi int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || v > hi {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	return v, nil
+}
@@ -0,0 +1,26 @@
+package zentrox
+
+// Session is the request's server-side session, see Context.Session. It is
+// implemented by package session, whose *session.Session also offers
+// Regenerate and Destroy for login and logout.
+type Session interface {
+	ID() string
+	Get(key string) (any, bool)
+	Set(key string, value any)
+	Delete(key string)
+	// Save persists the session now instead of after the handler chain.
+	Save() error
+}
+
+// Session returns the session loaded by middleware.Session (or
+// session.Middleware), or nil when neither is installed:
+//
+//	app.Plug(middleware.Session(session.NewMemoryStore()))
+//
+//	app.POST("/cart", func(c *zentrox.Context) {
+//		c.Session().Set("cart", c.Query("sku"))
+//	})
+func (c *Context) Session() Session {
+	s, _ := Get[Session](c, SessionKey)
+	return s
+}
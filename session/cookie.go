@@ -0,0 +1,118 @@
+package session
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// maxCookieSize is the usual per-cookie browser limit minus room for the
+// attributes.
+const maxCookieSize = 4000
+
+var (
+	// ErrCookieTooLarge is reported when a CookieStore session does not fit
+	// in a cookie; keep large values in a server-side store.
+	ErrCookieTooLarge = errors.New("session: encoded session exceeds cookie size limit")
+	// ErrResponseStarted is returned by Save on a CookieStore session once
+	// the response headers have been written.
+	ErrResponseStarted = errors.New("session: response already started")
+)
+
+// CookieCodec is implemented by stores that keep the whole session in the
+// cookie instead of behind an ID. The middleware then encodes the session
+// into the cookie value rather than calling Load and Save.
+type CookieCodec interface {
+	Encode(id string, d Data, ttl time.Duration) (string, error)
+	Decode(value string) (id string, d Data, ok bool)
+}
+
+// CookieStore keeps sessions client-side in an AES-GCM encrypted cookie, so
+// no server state is needed. The first key encrypts; all keys are tried when
+// decrypting, which allows rotation by prepending a new key. Keys must be 16,
+// 24 or 32 bytes. Sessions are limited to roughly 4 KB and cannot be revoked
+// server-side before they expire.
+type CookieStore struct {
+	aeads []cipher.AEAD
+}
+
+type cookiePayload struct {
+	ID      string
+	Data    Data
+	Expires time.Time
+}
+
+func NewCookieStore(keys ...[]byte) (*CookieStore, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("session: CookieStore needs at least one key")
+	}
+	s := &CookieStore{}
+	for _, k := range keys {
+		block, err := aes.NewCipher(k)
+		if err != nil {
+			return nil, fmt.Errorf("session: %w", err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("session: %w", err)
+		}
+		s.aeads = append(s.aeads, aead)
+	}
+	return s, nil
+}
+
+func (s *CookieStore) Encode(id string, d Data, ttl time.Duration) (string, error) {
+	var buf bytes.Buffer
+	p := cookiePayload{ID: id, Data: d, Expires: time.Now().Add(ttl)}
+	if err := gob.NewEncoder(&buf).Encode(p); err != nil {
+		return "", err
+	}
+	aead := s.aeads[0]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+buf.Len()+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	v := base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, buf.Bytes(), nil))
+	if len(v) > maxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+	return v, nil
+}
+
+func (s *CookieStore) Decode(value string) (string, Data, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return "", Data{}, false
+	}
+	for _, aead := range s.aeads {
+		ns := aead.NonceSize()
+		if len(raw) < ns {
+			continue
+		}
+		plain, err := aead.Open(nil, raw[:ns], raw[ns:], nil)
+		if err != nil {
+			continue
+		}
+		var p cookiePayload
+		if gob.NewDecoder(bytes.NewReader(plain)).Decode(&p) != nil || time.Now().After(p.Expires) {
+			return "", Data{}, false
+		}
+		if p.Data.Values == nil {
+			p.Data.Values = make(map[string]any)
+		}
+		return p.ID, p.Data, true
+	}
+	return "", Data{}, false
+}
+
+// Load, Save and Delete satisfy Store; the middleware uses Encode and Decode
+// for a CookieStore, so they never touch server state.
+func (s *CookieStore) Load(string) (Data, bool, error)        { return Data{}, false, nil }
+func (s *CookieStore) Save(string, Data, time.Duration) error { return nil }
+func (s *CookieStore) Delete(string) error                    { return nil }
@@ -0,0 +1,74 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aminofox/zentrox/v2/internal/redis"
+)
+
+// RedisConfig configures a RedisStore.
+type RedisConfig struct {
+	Addr     string
+	Password string
+	DB       int
+	// Prefix is prepended to every session ID (default "zentrox:session:").
+	Prefix string
+}
+
+// RedisStore keeps sessions in Redis so they are shared across instances.
+// Values are gob-encoded; register custom types with gob.Register.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisStore(cfg RedisConfig) *RedisStore {
+	if cfg.Prefix == "" {
+		cfg.Prefix = "zentrox:session:"
+	}
+	return &RedisStore{
+		client: redis.New(redis.Config{Addr: cfg.Addr, Password: cfg.Password, DB: cfg.DB}),
+		prefix: cfg.Prefix,
+	}
+}
+
+func (s *RedisStore) Load(id string) (Data, bool, error) {
+	v, err := s.client.String(context.Background(), "GET", s.prefix+id)
+	if errors.Is(err, redis.ErrNil) {
+		return Data{}, false, nil
+	}
+	if err != nil {
+		return Data{}, false, err
+	}
+	d, err := decodeData([]byte(v))
+	if err != nil {
+		return Data{}, false, err
+	}
+	return d, true, nil
+}
+
+func (s *RedisStore) Save(id string, d Data, ttl time.Duration) error {
+	b, err := encodeData(d)
+	if err != nil {
+		return err
+	}
+	ms := ttl.Milliseconds()
+	if ms <= 0 {
+		ms = 1
+	}
+	_, err = s.client.String(context.Background(), "SET", s.prefix+id, string(b), "PX", fmt.Sprint(ms))
+	return err
+}
+
+func (s *RedisStore) Delete(id string) error {
+	_, err := s.client.Do(context.Background(), "DEL", s.prefix+id)
+	return err
+}
+
+// Close releases pooled connections.
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,408 @@
+// Package session provides server-side HTTP sessions for zentrox with idle and
+// absolute timeouts, sliding renewal and ID regeneration.
+package session
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+type Config struct {
+	Store        Store
+	CookieName   string
+	CookiePath   string
+	CookieDomain string
+	Secure       bool
+	SameSite     http.SameSite
+	// IdleTimeout expires a session that has not been used for this long.
+	// Every request within the window slides it forward.
+	IdleTimeout time.Duration
+	// AbsoluteTimeout caps the lifetime of a session regardless of activity.
+	// Zero disables the cap.
+	AbsoluteTimeout time.Duration
+	ContextKey      string
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Store:           NewMemoryStore(),
+		CookieName:      "zentrox_session",
+		CookiePath:      "/",
+		SameSite:        http.SameSiteLaxMode,
+		IdleTimeout:     30 * time.Minute,
+		AbsoluteTimeout: 24 * time.Hour,
+		ContextKey:      zentrox.SessionKey,
+	}
+}
+
+// Session is the per-request view of a session. It is not safe for concurrent
+// use by multiple goroutines.
+type Session struct {
+	id        string
+	oldID     string
+	data      Data
+	isNew     bool
+	changed   bool
+	destroyed bool
+
+	c   *zentrox.Context
+	cfg *Config
+
+	// codec is set when the store keeps data in the cookie (CookieStore);
+	// the cookie is then written just before the response starts.
+	codec       CookieCodec
+	cookieDirty bool
+	headerSent  bool
+}
+
+// Middleware loads the session identified by the request cookie, renews it on
+// every request and persists changes after the handler chain returns.
+// Expired or unknown sessions are replaced by a fresh, empty one; a cookie is
+// only issued for new sessions once something is stored in them.
+// The session cookie is always HttpOnly.
+func Middleware(cfg Config) zentrox.Handler {
+	def := DefaultConfig()
+	if cfg.Store == nil {
+		cfg.Store = def.Store
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = def.CookieName
+	}
+	if cfg.CookiePath == "" {
+		cfg.CookiePath = def.CookiePath
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = def.SameSite
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = def.IdleTimeout
+	}
+	if cfg.ContextKey == "" {
+		cfg.ContextKey = def.ContextKey
+	}
+
+	codec, _ := cfg.Store.(CookieCodec)
+
+	return func(c *zentrox.Context) {
+		now := time.Now()
+		s := &Session{c: c, cfg: &cfg, codec: codec}
+
+		if ck, err := c.Request.Cookie(cfg.CookieName); err == nil && ck.Value != "" {
+			if codec != nil {
+				if id, d, ok := codec.Decode(ck.Value); ok && !cfg.expired(d, now) {
+					s.id, s.data = id, d
+				}
+			} else {
+				d, ok, err := cfg.Store.Load(ck.Value)
+				if err != nil {
+					c.Fail(http.StatusInternalServerError, zentrox.MsgInternalServerError)
+					return
+				}
+				if ok && !cfg.expired(d, now) {
+					s.id, s.data = ck.Value, d
+				} else if ok {
+					_ = cfg.Store.Delete(ck.Value)
+				}
+			}
+		}
+		if s.id == "" {
+			s.id = newID()
+			s.isNew = true
+			s.data = Data{Values: map[string]any{}, CreatedAt: now}
+		}
+		if s.data.Values == nil {
+			s.data.Values = map[string]any{}
+		}
+		s.data.LastSeen = now
+		if !s.isNew {
+			s.writeCookie()
+		}
+
+		c.Set(cfg.ContextKey, s)
+		if cfg.ContextKey != zentrox.SessionKey {
+			c.Set(zentrox.SessionKey, s) // for Context.Session
+		}
+		if codec != nil {
+			orig := c.Writer
+			c.Writer = &commitWriter{ResponseWriter: orig, s: s}
+			c.Next()
+			c.Writer = orig
+			if !s.headerSent {
+				s.flushCookie()
+			}
+			return
+		}
+		c.Next()
+
+		if s.oldID != "" {
+			_ = cfg.Store.Delete(s.oldID)
+		}
+		if s.destroyed {
+			if !s.isNew {
+				_ = cfg.Store.Delete(s.id)
+			}
+			return
+		}
+		if s.isNew && !s.changed {
+			return
+		}
+		if err := cfg.Store.Save(s.id, s.data, cfg.ttl(s.data, now)); err != nil && c.Error() == nil {
+			c.SetError(err)
+		}
+	}
+}
+
+// From returns the session stored by Middleware under the default context key,
+// or nil when the middleware is not installed. Context.Session returns the
+// same session as a zentrox.Session.
+func From(c *zentrox.Context) *Session {
+	return FromKey(c, zentrox.SessionKey)
+}
+
+// FromKey is like From for a custom Config.ContextKey.
+func FromKey(c *zentrox.Context, key string) *Session {
+	s, _ := zentrox.Get[*Session](c, key)
+	return s
+}
+
+// ID returns the current session ID.
+func (s *Session) ID() string { return s.id }
+
+// IsNew reports whether the session was created by this request.
+func (s *Session) IsNew() bool { return s.isNew }
+
+// CreatedAt returns when the session was first created.
+func (s *Session) CreatedAt() time.Time { return s.data.CreatedAt }
+
+// Get returns a stored value.
+func (s *Session) Get(key string) (any, bool) {
+	v, ok := s.data.Values[key]
+	return v, ok
+}
+
+// Set stores a value.
+func (s *Session) Set(key string, v any) {
+	s.data.Values[key] = v
+	s.markChanged()
+}
+
+// Delete removes a value.
+func (s *Session) Delete(key string) {
+	delete(s.data.Values, key)
+	s.markChanged()
+}
+
+// Clear removes all values but keeps the session.
+func (s *Session) Clear() {
+	s.data.Values = map[string]any{}
+	s.markChanged()
+}
+
+// Save persists the session now rather than after the handler chain, e.g.
+// before streaming a long response. With a CookieStore it writes the cookie,
+// which is only possible until the response has started; CookieStore
+// sessions are otherwise written automatically just before that point.
+func (s *Session) Save() error {
+	if s.destroyed {
+		return nil
+	}
+	if s.codec != nil {
+		if s.headerSent {
+			return ErrResponseStarted
+		}
+		s.cookieDirty = true
+		return s.flushCookie()
+	}
+	if err := s.cfg.Store.Save(s.id, s.data, s.cfg.ttl(s.data, s.data.LastSeen)); err != nil {
+		return err
+	}
+	s.changed = false
+	return nil
+}
+
+// Regenerate moves the session to a new ID, keeping its data, and invalidates
+// the old ID. Call it after login or any privilege change to prevent session
+// fixation. It must be called before the response body is written.
+func (s *Session) Regenerate() {
+	if s.oldID == "" && !s.isNew {
+		s.oldID = s.id
+	}
+	s.id = newID()
+	s.destroyed = false
+	s.changed = true
+	s.writeCookie()
+}
+
+// Destroy deletes the session and expires the cookie, e.g. on logout.
+func (s *Session) Destroy() {
+	s.destroyed = true
+	s.data.Values = map[string]any{}
+	s.setCookie(&http.Cookie{Value: "", MaxAge: -1})
+}
+
+func (s *Session) markChanged() {
+	first := s.isNew && !s.changed
+	s.changed = true
+	if first || s.codec != nil {
+		s.writeCookie()
+	}
+}
+
+func (s *Session) writeCookie() {
+	if s.codec != nil {
+		s.cookieDirty = true
+		return
+	}
+	ttl := s.cfg.ttl(s.data, s.data.LastSeen)
+	maxAge := int((ttl + time.Second - 1) / time.Second)
+	s.setCookie(&http.Cookie{Value: s.id, MaxAge: maxAge, Expires: s.data.LastSeen.Add(ttl)})
+}
+
+// flushCookie encodes the session into the cookie for a CookieCodec store.
+func (s *Session) flushCookie() error {
+	if !s.cookieDirty || s.destroyed {
+		return nil
+	}
+	s.cookieDirty = false
+	ttl := s.cfg.ttl(s.data, s.data.LastSeen)
+	v, err := s.codec.Encode(s.id, s.data, ttl)
+	if err != nil {
+		if s.c.Error() == nil {
+			s.c.SetError(err)
+		}
+		return err
+	}
+	maxAge := int((ttl + time.Second - 1) / time.Second)
+	s.setCookie(&http.Cookie{Value: v, MaxAge: maxAge, Expires: s.data.LastSeen.Add(ttl)})
+	return nil
+}
+
+// commitWriter writes a CookieStore session cookie before the response
+// headers go out.
+type commitWriter struct {
+	http.ResponseWriter
+	s *Session
+}
+
+func (w *commitWriter) commit() {
+	if !w.s.headerSent {
+		_ = w.s.flushCookie()
+		w.s.headerSent = true
+	}
+}
+
+func (w *commitWriter) WriteHeader(code int) {
+	w.commit()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *commitWriter) Write(b []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *commitWriter) Flush() {
+	w.commit()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *commitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// setCookie replaces any Set-Cookie header previously written for the session
+// cookie in this response.
+func (s *Session) setCookie(ck *http.Cookie) {
+	ck.Name = s.cfg.CookieName
+	ck.Path = s.cfg.CookiePath
+	ck.Domain = s.cfg.CookieDomain
+	ck.Secure = s.cfg.Secure
+	ck.HttpOnly = true
+	ck.SameSite = s.cfg.SameSite
+
+	h := s.c.Writer.Header()
+	prefix := ck.Name + "="
+	var kept []string
+	for _, v := range h.Values(zentrox.HeaderSetCookie) {
+		if !strings.HasPrefix(v, prefix) {
+			kept = append(kept, v)
+		}
+	}
+	h.Del(zentrox.HeaderSetCookie)
+	for _, v := range kept {
+		h.Add(zentrox.HeaderSetCookie, v)
+	}
+	http.SetCookie(s.c.Writer, ck)
+}
+
+func (cfg *Config) expired(d Data, now time.Time) bool {
+	if now.Sub(d.LastSeen) > cfg.IdleTimeout {
+		return true
+	}
+	return cfg.AbsoluteTimeout > 0 && now.Sub(d.CreatedAt) > cfg.AbsoluteTimeout
+}
+
+// ttl is the remaining lifetime after activity at now: the idle window,
+// capped by what is left of the absolute timeout.
+func (cfg *Config) ttl(d Data, now time.Time) time.Duration {
+	ttl := cfg.IdleTimeout
+	if cfg.AbsoluteTimeout > 0 {
+		if left := d.CreatedAt.Add(cfg.AbsoluteTimeout).Sub(now); left < ttl {
+			ttl = left
+		}
+	}
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return ttl
+}
+
+func newID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// CSRFStorage keeps the token of middleware.CSRF in the session (synchronizer
+// token pattern) instead of a separate cookie. Key defaults to "_csrf" and
+// ContextKey to the default session context key.
+type CSRFStorage struct {
+	Key        string
+	ContextKey string
+}
+
+func (s CSRFStorage) session(c *zentrox.Context) *Session {
+	if s.ContextKey == "" {
+		return From(c)
+	}
+	return FromKey(c, s.ContextKey)
+}
+
+func (s CSRFStorage) key() string {
+	if s.Key == "" {
+		return "_csrf"
+	}
+	return s.Key
+}
+
+func (s CSRFStorage) Get(c *zentrox.Context) string {
+	if sess := s.session(c); sess != nil {
+		v, _ := sess.Get(s.key())
+		tok, _ := v.(string)
+		return tok
+	}
+	return ""
+}
+
+func (s CSRFStorage) Set(c *zentrox.Context, token string) {
+	if sess := s.session(c); sess != nil {
+		sess.Set(s.key(), token)
+	}
+}
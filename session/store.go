@@ -0,0 +1,107 @@
+package session
+
+import (
+	"bytes"
+	"encoding/gob"
+	"sync"
+	"time"
+)
+
+// Data is the persisted state of a session.
+type Data struct {
+	Values    map[string]any
+	CreatedAt time.Time
+	LastSeen  time.Time
+}
+
+// Store persists session data by ID. Save must expire the entry after ttl.
+type Store interface {
+	Load(id string) (Data, bool, error)
+	Save(id string, d Data, ttl time.Duration) error
+	Delete(id string) error
+}
+
+type memoryEntry struct {
+	data    Data
+	expires time.Time
+}
+
+// MemoryStore is an in-process Store, suitable for development and single
+// instance deployments.
+type MemoryStore struct {
+	mu          sync.Mutex
+	entries     map[string]memoryEntry
+	lastCleanup time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry), lastCleanup: time.Now()}
+}
+
+func (s *MemoryStore) Load(id string) (Data, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[id]
+	if !ok {
+		return Data{}, false, nil
+	}
+	if time.Now().After(e.expires) {
+		delete(s.entries, id)
+		return Data{}, false, nil
+	}
+	e.data.Values = copyValues(e.data.Values)
+	return e.data, true, nil
+}
+
+func (s *MemoryStore) Save(id string, d Data, ttl time.Duration) error {
+	now := time.Now()
+	d.Values = copyValues(d.Values)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastCleanup) >= time.Minute {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastCleanup = now
+	}
+	s.entries[id] = memoryEntry{data: d, expires: now.Add(ttl)}
+	return nil
+}
+
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	delete(s.entries, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// encodeData serializes d for stores that keep bytes (Redis, cookies). Values
+// of custom types must be registered with gob.Register.
+func encodeData(d Data) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(d); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeData(b []byte) (Data, error) {
+	var d Data
+	err := gob.NewDecoder(bytes.NewReader(b)).Decode(&d)
+	if d.Values == nil {
+		d.Values = make(map[string]any)
+	}
+	return d, err
+}
+
+func copyValues(m map[string]any) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package zentrox
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DirListing is a directory listing passed to StaticOptions.BrowseRender.
+type DirListing struct {
+	// Path is the URL path of the directory, with a trailing slash.
+	Path string
+	// Parent is the URL of the parent directory; empty at the Static root.
+	Parent  string
+	Entries []DirEntry
+}
+
+// DirEntry is one file or subdirectory in a DirListing.
+type DirEntry struct {
+	Name    string
+	URL     string // escaped, ready for an href
+	IsDir   bool
+	Size    int64
+	ModTime time.Time
+}
+
+// indexFor returns the index file name for dir, a slash path under Dir.
+func (opt StaticOptions) indexFor(dir string) string {
+	if opt.DisableIndex {
+		return ""
+	}
+	if idx, ok := opt.DirIndex["/"+strings.Trim(dir, "/")]; ok {
+		return idx
+	}
+	return opt.Index
+}
+
+// serveDirListing lists dir, found at rel under the Static prefix, with
+// opt.BrowseRender or the built-in page.
+func serveDirListing(c *Context, opt StaticOptions, dir, prefix, rel string, allow map[string]struct{}) {
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		c.String(http.StatusInternalServerError, MsgOpenError)
+		return
+	}
+	l := DirListing{Path: dirURL(prefix, rel)}
+	if rel != "/" {
+		l.Parent = dirURL(prefix, path.Dir(rel))
+	}
+	escaped := (&url.URL{Path: l.Path}).EscapedPath()
+	for _, e := range ents {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		isDir := fi.IsDir()
+		if !isDir && len(allow) > 0 {
+			if _, ok := allow[strings.ToLower(filepath.Ext(name))]; !ok {
+				continue
+			}
+		}
+		u := escaped + url.PathEscape(name)
+		if isDir {
+			u += "/"
+		}
+		l.Entries = append(l.Entries, DirEntry{Name: name, URL: u, IsDir: isDir, Size: fi.Size(), ModTime: fi.ModTime().UTC()})
+	}
+	sort.Slice(l.Entries, func(i, j int) bool {
+		a, b := l.Entries[i], l.Entries[j]
+		if a.IsDir != b.IsDir {
+			return a.IsDir
+		}
+		return a.Name < b.Name
+	})
+
+	c.SetHeader(HeaderCacheControl, CacheControlNoCache)
+	if opt.BrowseRender != nil {
+		opt.BrowseRender(c, l)
+		return
+	}
+	c.setContentType(htmlContentType)
+	c.Writer.WriteHeader(http.StatusOK)
+	if c.Request.Method == http.MethodHead {
+		return
+	}
+	_ = dirListingTmpl.Execute(c.Writer, l)
+}
+
+// dirURL joins prefix and rel into a directory URL with a trailing slash.
+func dirURL(prefix, rel string) string {
+	p := path.Join(prefix, rel)
+	if p != "/" {
+		p += "/"
+	}
+	return p
+}
+
+// dirListingTmpl is the built-in listing page. html/template escapes names,
+// so hostile file names cannot inject markup.
+var dirListingTmpl = template.Must(template.New("dir").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>Index of {{.Path}}</title>
+<style>body{font-family:system-ui,sans-serif;margin:2em}td{padding:.2em 1em .2em 0}td.n{text-align:right}</style>
+</head><body><h1>Index of {{.Path}}</h1><table>
+{{if .Parent}}<tr><td><a href="{{.Parent}}">../</a></td><td></td><td></td></tr>
+{{end}}{{range .Entries}}<tr><td><a href="{{.URL}}">{{.Name}}{{if .IsDir}}/{{end}}</a></td><td class="n">{{if not .IsDir}}{{.Size}}{{end}}</td><td>{{.ModTime.Format "2006-01-02 15:04"}}</td></tr>
+{{end}}</table></body></html>
+`))
@@ -0,0 +1,109 @@
+package zentrox
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"time"
+)
+
+// StaticFileOptions controls StaticFile and StaticFileFS.
+type StaticFileOptions struct {
+	// If non-zero, sets "Cache-Control: public, max-age=<seconds>" (otherwise no-cache).
+	MaxAge time.Duration
+	// If true, use strong ETag (SHA1 of content). Otherwise weak ETag (size-modtime).
+	UseStrongETag bool
+}
+
+// StaticFile serves a single file from disk at route, e.g.
+//
+//	app.StaticFile("/favicon.ico", "./assets/favicon.ico")
+//
+// It sets ETag, Last-Modified and Cache-Control, answers conditional and
+// Range requests, and registers both GET and HEAD. A missing file yields 404.
+func (a *App) StaticFile(route, file string, opt ...StaticFileOptions) {
+	a.staticFile(route, opt, func() (fs.File, error) { return os.Open(file) })
+}
+
+// StaticFileFS is like StaticFile, reading name from fsys (e.g. an embed.FS).
+func (a *App) StaticFileFS(route string, fsys fs.FS, name string, opt ...StaticFileOptions) {
+	a.staticFile(route, opt, func() (fs.File, error) { return fsys.Open(name) })
+}
+
+func (a *App) staticFile(route string, opts []StaticFileOptions, open func() (fs.File, error)) {
+	if route == "" || route[0] != '/' {
+		panic("StaticFile: route must start with '/'")
+	}
+	var opt StaticFileOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+
+	h := func(c *Context) {
+		f, err := open()
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				c.String(http.StatusNotFound, MsgFileNotFound)
+				return
+			}
+			c.String(http.StatusInternalServerError, MsgOpenError)
+			return
+		}
+		defer f.Close()
+
+		fi, err := f.Stat()
+		if err != nil {
+			c.String(http.StatusInternalServerError, MsgStatError)
+			return
+		}
+		if fi.IsDir() {
+			c.String(http.StatusNotFound, MsgFileNotFound)
+			return
+		}
+
+		// http.ServeContent needs a seeker; fs.FS files are not required to
+		// implement one.
+		rs, ok := f.(io.ReadSeeker)
+		if !ok {
+			b, err := io.ReadAll(f)
+			if err != nil {
+				c.String(http.StatusInternalServerError, MsgOpenError)
+				return
+			}
+			rs = bytes.NewReader(b)
+		}
+
+		lastMod := fi.ModTime().UTC()
+		if opt.UseStrongETag {
+			h := sha1.New()
+			if _, err := io.Copy(h, rs); err == nil {
+				c.SetHeader(HeaderETag, `"`+hex.EncodeToString(h.Sum(nil))+`"`)
+			}
+			if _, err := rs.Seek(0, io.SeekStart); err != nil {
+				c.String(http.StatusInternalServerError, MsgOpenError)
+				return
+			}
+		} else {
+			c.SetHeader(HeaderETag, `W/"`+strconv.FormatInt(fi.Size(), 10)+"-"+strconv.FormatInt(lastMod.Unix(), 10)+`"`)
+		}
+
+		if opt.MaxAge > 0 {
+			sec := int(opt.MaxAge / time.Second)
+			c.SetHeader(HeaderCacheControl, "public, max-age="+strconv.Itoa(sec))
+		} else {
+			c.SetHeader(HeaderCacheControl, CacheControlNoCache)
+		}
+
+		http.ServeContent(c.Writer, c.Request, path.Base(fi.Name()), lastMod, rs)
+	}
+
+	a.GET(route, h)
+	a.on(http.MethodHead, route, h)
+}
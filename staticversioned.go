@@ -0,0 +1,159 @@
+package zentrox
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// StaticVersionedOptions controls StaticVersioned.
+type StaticVersionedOptions struct {
+	// Directory on disk to serve from.
+	Dir string
+	// HashLen is the number of hex digits of the content hash put in file
+	// names; default 6.
+	HashLen int
+}
+
+// versionedAsset is a file served under its hashed name.
+type versionedAsset struct {
+	file string
+	etag string
+}
+
+// StaticVersioned serves the files in opt.Dir under prefix with a content
+// hash in their names, e.g. app.js as /assets/app.83f2c1.js, so they can be
+// cached forever: hashed names are sent with
+// "Cache-Control: public, max-age=31536000, immutable" and change whenever
+// the content does. Plain names are still served, with no-cache. Link to
+// assets with Context.AssetURL or the "asset" template function:
+//
+//	app.StaticVersioned("/assets", zentrox.StaticVersionedOptions{Dir: "./public"})
+//	tmpl := template.New("").Funcs(app.AssetFuncs())
+//	// <script src="{{asset "app.js"}}"></script>
+//
+// Hashes are computed once, when StaticVersioned is called; restart the app
+// after changing the files.
+func (a *App) StaticVersioned(prefix string, opt StaticVersionedOptions) {
+	if prefix == "" || prefix[0] != '/' {
+		panic("StaticVersioned: prefix must start with '/'")
+	}
+	if opt.Dir == "" {
+		panic("StaticVersioned: Dir is required")
+	}
+	if opt.HashLen <= 0 {
+		opt.HashLen = 6
+	}
+	prefix = strings.TrimRight(prefix, "/")
+	root, err := filepath.Abs(opt.Dir)
+	if err != nil {
+		panic("StaticVersioned: cannot resolve directory: " + err.Error())
+	}
+
+	if a.assets == nil {
+		a.assets = map[string]string{}
+	}
+	hashed := map[string]versionedAsset{}
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		sum, err := sha256File(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		h := hex.EncodeToString(sum)
+		ext := path.Ext(rel)
+		name := strings.TrimSuffix(rel, ext) + "." + h[:min(opt.HashLen, len(h))] + ext
+		hashed["/"+name] = versionedAsset{file: p, etag: `"` + h + `"`}
+		url := prefix + "/" + name
+		a.assets[rel] = url
+		a.assets[prefix+"/"+rel] = url
+		return nil
+	})
+	if err != nil {
+		panic("StaticVersioned: " + err.Error())
+	}
+
+	h := func(c *Context) {
+		rel := path.Clean("/" + c.Param("filepath"))
+		file, immutable := "", false
+		if asset, ok := hashed[rel]; ok {
+			file, immutable = asset.file, true
+			c.SetHeader(HeaderETag, asset.etag)
+			c.SetHeader(HeaderCacheControl, CacheControlImmutable)
+		} else {
+			file = filepath.Join(root, filepath.FromSlash(rel))
+			if !isWithinBase(root, file) {
+				c.String(http.StatusForbidden, MsgForbidden)
+				return
+			}
+			c.SetHeader(HeaderCacheControl, CacheControlNoCache)
+		}
+		f, err := os.Open(file)
+		if err != nil {
+			c.String(http.StatusNotFound, MsgNotFound)
+			return
+		}
+		defer f.Close()
+		fi, err := f.Stat()
+		if err != nil || fi.IsDir() {
+			c.String(http.StatusNotFound, MsgNotFound)
+			return
+		}
+		if immutable && c.GetHeader(HeaderIfNoneMatch) != "" {
+			// The name pins the content, so any validator is current.
+			c.Writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		http.ServeContent(c.Writer, c.Request, fi.Name(), fi.ModTime(), f)
+	}
+	a.GET(prefix+"/*filepath", h)
+	a.on(http.MethodHead, prefix+"/*filepath", h)
+}
+
+// AssetURL returns the hashed URL of an asset served by StaticVersioned,
+// named relative to its directory ("app.js", "css/site.css") or by its plain
+// URL ("/assets/app.js"). Unknown names are returned unchanged.
+func (a *App) AssetURL(name string) string {
+	if u, ok := a.assets[strings.TrimPrefix(name, "./")]; ok {
+		return u
+	}
+	return name
+}
+
+// AssetURL returns the hashed URL of an asset; see App.AssetURL.
+func (c *Context) AssetURL(name string) string {
+	if c.app == nil {
+		return name
+	}
+	return c.app.AssetURL(name)
+}
+
+// AssetFuncs returns template functions for HTML templates: "asset" maps a
+// name to its hashed URL.
+func (a *App) AssetFuncs() template.FuncMap {
+	return template.FuncMap{"asset": a.AssetURL}
+}
+
+func sha256File(p string) ([]byte, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
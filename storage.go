@@ -0,0 +1,120 @@
+package zentrox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// UploadStorage stores uploaded files under slash-separated keys. Set
+// UploadOptions.Storage to stream uploads to it instead of a local directory.
+type UploadStorage interface {
+	// Save writes r under key. size is the length of r, or -1 if unknown.
+	Save(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open returns the content stored under key; a missing key yields an
+	// error matching fs.ErrNotExist.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL returns a URL clients can fetch key from.
+	URL(ctx context.Context, key string) (string, error)
+}
+
+// ErrUploadExists is returned when a file exists and overwriting is off.
+var ErrUploadExists = errors.New("upload: file exists")
+
+// LocalStorage is an UploadStorage backed by a directory.
+type LocalStorage struct {
+	// Dir is the root directory, created on first Save.
+	Dir string
+	// BaseURL prefixes keys in URL, e.g. "/uploads" when the directory is
+	// also served with App.Static.
+	BaseURL string
+	// Perm is the mode of new files; default 0600.
+	Perm os.FileMode
+
+	// exclusive makes Save fail with ErrUploadExists instead of replacing.
+	exclusive bool
+}
+
+// NewLocalStorage returns a LocalStorage rooted at dir.
+func NewLocalStorage(dir, baseURL string) *LocalStorage {
+	return &LocalStorage{Dir: dir, BaseURL: baseURL}
+}
+
+// path maps key into Dir, rejecting keys that escape it.
+func (s *LocalStorage) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	target := filepath.Join(s.Dir, filepath.FromSlash(clean))
+	if clean == "/" || !isWithinBase(s.Dir, target) {
+		return "", errors.New("upload: invalid path")
+	}
+	return target, nil
+}
+
+// Save implements UploadStorage.
+func (s *LocalStorage) Save(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	perm := s.Perm
+	if perm == 0 {
+		perm = 0o600
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if s.exclusive {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_EXCL
+	}
+	f, err := os.OpenFile(target, flags, perm)
+	if errors.Is(err, os.ErrExist) {
+		return ErrUploadExists
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		_ = os.Remove(target)
+		return err
+	}
+	return f.Close()
+}
+
+// Open implements UploadStorage.
+func (s *LocalStorage) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	target, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(target)
+}
+
+// Delete implements UploadStorage.
+func (s *LocalStorage) Delete(_ context.Context, key string) error {
+	target, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// URL implements UploadStorage.
+func (s *LocalStorage) URL(_ context.Context, key string) (string, error) {
+	segs := strings.Split(strings.TrimPrefix(path.Clean("/"+key), "/"), "/")
+	for i, seg := range segs {
+		segs[i] = url.PathEscape(seg)
+	}
+	return strings.TrimSuffix(s.BaseURL, "/") + "/" + strings.Join(segs, "/"), nil
+}
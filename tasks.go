@@ -0,0 +1,406 @@
+package zentrox
+
+import (
+	"container/heap"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrTasksClosed is returned by Enqueue once the queue is closing.
+	ErrTasksClosed = errors.New("zentrox: task queue closed")
+	// ErrUnknownTask is reported to OnFailure for tasks with no handler.
+	ErrUnknownTask = errors.New("zentrox: no handler for task")
+)
+
+// Task is a unit of background work, see TaskQueue.
+type Task struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempt    int             `json:"attempt"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+}
+
+// Bind decodes the payload into v.
+func (t *Task) Bind(v any) error {
+	return json.Unmarshal(t.Payload, v)
+}
+
+// TaskHandler runs a task; a returned error or panic schedules a retry.
+type TaskHandler func(ctx context.Context, t *Task) error
+
+// TaskBackend stores queued tasks. The in-memory default is
+// NewMemoryTaskBackend; implement it over Redis or a database to share
+// tasks between instances and keep them across restarts.
+type TaskBackend interface {
+	// Push stores t to be run at or after at.
+	Push(ctx context.Context, t *Task, at time.Time) error
+	// Pop blocks until a task is due and removes it, or returns ctx.Err().
+	Pop(ctx context.Context) (*Task, error)
+	// Ready reports how many tasks are due now.
+	Ready(ctx context.Context) (int, error)
+}
+
+// TaskConfig configures NewTaskQueue.
+type TaskConfig struct {
+	// Backend stores the tasks; default NewMemoryTaskBackend().
+	Backend TaskBackend
+	// Workers is the number of tasks run at once (default 4).
+	Workers int
+	// MaxAttempts per task, the first included (default 3).
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled on each further
+	// one (default 1s).
+	Backoff time.Duration
+	// OnFailure receives tasks that failed MaxAttempts times or have no
+	// handler; the default logs them to Logger.
+	OnFailure func(t *Task, err error)
+	// Logger receives backend errors and, by default, failed tasks
+	// (default slog.Default(); App.Tasks uses the App logger).
+	Logger *slog.Logger
+}
+
+// DefaultTaskConfig returns the default TaskConfig.
+func DefaultTaskConfig() TaskConfig {
+	return TaskConfig{
+		Workers:     4,
+		MaxAttempts: 3,
+		Backoff:     time.Second,
+	}
+}
+
+// TaskQueue runs handlers for named tasks on a pool of workers, retrying
+// failures with exponential backoff:
+//
+//	tasks := app.Tasks()
+//	tasks.Handle("email.welcome", func(ctx context.Context, t *zentrox.Task) error {
+//		var u User
+//		if err := t.Bind(&u); err != nil {
+//			return err
+//		}
+//		return mailer.SendWelcome(ctx, u)
+//	})
+//
+//	app.POST("/signup", func(c *zentrox.Context) {
+//		// ...
+//		_ = c.App().Tasks().Enqueue("email.welcome", user)
+//	})
+//
+// Workers start with the first Handle call. App.Shutdown drains the queue.
+type TaskQueue struct {
+	cfg TaskConfig
+
+	mu       sync.RWMutex
+	handlers map[string]TaskHandler
+	closed   atomic.Bool
+
+	start   sync.Once
+	started atomic.Bool
+	// ctx stops the workers; runCtx is passed to handlers and only ends
+	// when Close gives up waiting for them.
+	ctx, runCtx     context.Context
+	stop, cancelRun context.CancelFunc
+	workers         sync.WaitGroup
+	running         atomic.Int64
+}
+
+// NewTaskQueue returns a TaskQueue; register it with App.SetTasks, or use
+// it on its own and call Close when done.
+func NewTaskQueue(cfg ...TaskConfig) *TaskQueue {
+	def := DefaultTaskConfig()
+	conf := def
+	if len(cfg) > 0 {
+		conf = cfg[0]
+		if conf.Workers <= 0 {
+			conf.Workers = def.Workers
+		}
+		if conf.MaxAttempts <= 0 {
+			conf.MaxAttempts = def.MaxAttempts
+		}
+		if conf.Backoff <= 0 {
+			conf.Backoff = def.Backoff
+		}
+	}
+	if conf.Backend == nil {
+		conf.Backend = NewMemoryTaskBackend()
+	}
+	if conf.Logger == nil {
+		conf.Logger = slog.Default()
+	}
+	if conf.OnFailure == nil {
+		log := conf.Logger
+		conf.OnFailure = func(t *Task, err error) {
+			log.Error("zentrox: task failed", "task", t.Name, "id", t.ID, "attempt", t.Attempt, "err", err)
+		}
+	}
+	q := &TaskQueue{cfg: conf, handlers: map[string]TaskHandler{}}
+	q.ctx, q.stop = context.WithCancel(context.Background())
+	q.runCtx, q.cancelRun = context.WithCancel(context.Background())
+	return q
+}
+
+// SetTasks replaces the queue returned by Tasks.
+func (a *App) SetTasks(q *TaskQueue) *App {
+	a.tasksMu.Lock()
+	a.tasks = q
+	a.tasksMu.Unlock()
+	return a
+}
+
+// Tasks returns the App's task queue, created with DefaultTaskConfig and
+// the App logger on first use unless SetTasks was called.
+func (a *App) Tasks() *TaskQueue {
+	a.tasksMu.Lock()
+	defer a.tasksMu.Unlock()
+	if a.tasks == nil {
+		cfg := DefaultTaskConfig()
+		cfg.Logger = a.Logger()
+		a.tasks = NewTaskQueue(cfg)
+	}
+	return a.tasks
+}
+
+// Handle registers the handler for tasks called name and starts the
+// workers if they are not running yet.
+func (q *TaskQueue) Handle(name string, h TaskHandler) {
+	q.mu.Lock()
+	q.handlers[name] = h
+	q.mu.Unlock()
+	q.start.Do(func() {
+		q.started.Store(true)
+		for range q.cfg.Workers {
+			q.workers.Add(1)
+			go q.work()
+		}
+	})
+}
+
+// Enqueue queues a task; payload is encoded as JSON.
+func (q *TaskQueue) Enqueue(name string, payload any) error {
+	return q.EnqueueIn(name, payload, 0)
+}
+
+// EnqueueIn queues a task to run after delay.
+func (q *TaskQueue) EnqueueIn(name string, payload any, delay time.Duration) error {
+	if q.closed.Load() {
+		return ErrTasksClosed
+	}
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("zentrox: encode task payload: %w", err)
+	}
+	now := time.Now()
+	t := &Task{ID: newTaskID(), Name: name, Payload: b, EnqueuedAt: now.UTC()}
+	return q.cfg.Backend.Push(context.Background(), t, now.Add(delay))
+}
+
+// Close stops accepting tasks, waits until the tasks that are due have run
+// or ctx ends, then stops the workers; handlers still running at that point
+// see their context cancelled. Tasks scheduled for later stay in the
+// backend; with the memory backend they are lost.
+func (q *TaskQueue) Close(ctx context.Context) error {
+	if q.closed.Swap(true) {
+		return nil
+	}
+	var err error
+	tick := time.NewTicker(10 * time.Millisecond)
+	defer tick.Stop()
+drain:
+	for q.started.Load() {
+		n, rerr := q.cfg.Backend.Ready(ctx)
+		if rerr != nil || (n == 0 && q.running.Load() == 0) {
+			break
+		}
+		select {
+		case <-tick.C:
+		case <-ctx.Done():
+			err = ctx.Err()
+			q.cancelRun()
+			break drain
+		}
+	}
+	// A worker may have taken a task after the last check; it runs it
+	// before exiting, so the wait is bounded by ctx too.
+	q.stop()
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+		q.cancelRun()
+		<-done
+	}
+	q.cancelRun()
+	return err
+}
+
+func (q *TaskQueue) work() {
+	defer q.workers.Done()
+	for {
+		t, err := q.cfg.Backend.Pop(q.ctx)
+		if err != nil {
+			if q.ctx.Err() != nil {
+				return
+			}
+			q.cfg.Logger.Error("zentrox: task backend", "err", err)
+			select {
+			case <-time.After(time.Second):
+			case <-q.ctx.Done():
+				return
+			}
+			continue
+		}
+		q.run(t)
+	}
+}
+
+// run executes t and retries or reports it on failure.
+func (q *TaskQueue) run(t *Task) {
+	q.running.Add(1)
+	defer q.running.Add(-1)
+	t.Attempt++
+
+	q.mu.RLock()
+	h := q.handlers[t.Name]
+	q.mu.RUnlock()
+	if h == nil {
+		q.cfg.OnFailure(t, ErrUnknownTask)
+		return
+	}
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("zentrox: task panic: %v", r)
+			}
+		}()
+		return h(q.runCtx, t)
+	}()
+	if err == nil {
+		return
+	}
+	if t.Attempt >= q.cfg.MaxAttempts {
+		q.cfg.OnFailure(t, err)
+		return
+	}
+	delay := q.cfg.Backoff << (t.Attempt - 1)
+	if perr := q.cfg.Backend.Push(context.Background(), t, time.Now().Add(delay)); perr != nil {
+		q.cfg.OnFailure(t, errors.Join(err, perr))
+	}
+}
+
+func newTaskID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// MemoryTaskBackend keeps tasks in process memory, ordered by due time.
+type MemoryTaskBackend struct {
+	mu    sync.Mutex
+	tasks taskHeap
+	wake  chan struct{}
+}
+
+// NewMemoryTaskBackend returns an empty MemoryTaskBackend.
+func NewMemoryTaskBackend() *MemoryTaskBackend {
+	return &MemoryTaskBackend{wake: make(chan struct{}, 1)}
+}
+
+func (b *MemoryTaskBackend) Push(_ context.Context, t *Task, at time.Time) error {
+	b.mu.Lock()
+	heap.Push(&b.tasks, scheduledTask{task: t, at: at})
+	b.mu.Unlock()
+	b.signal()
+	return nil
+}
+
+func (b *MemoryTaskBackend) Pop(ctx context.Context) (*Task, error) {
+	for {
+		b.mu.Lock()
+		wait := time.Duration(-1)
+		if len(b.tasks) > 0 {
+			wait = time.Until(b.tasks[0].at)
+			if wait <= 0 {
+				st := heap.Pop(&b.tasks).(scheduledTask)
+				more := len(b.tasks) > 0 && !b.tasks[0].at.After(time.Now())
+				b.mu.Unlock()
+				if more {
+					b.signal() // let another worker take the next one
+				}
+				return st.task, nil
+			}
+		}
+		b.mu.Unlock()
+
+		var timer *time.Timer
+		var due <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+		select {
+		case <-b.wake:
+		case <-due:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (b *MemoryTaskBackend) Ready(context.Context) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now, n := time.Now(), 0
+	for _, st := range b.tasks {
+		if !st.at.After(now) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (b *MemoryTaskBackend) signal() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+type scheduledTask struct {
+	task *Task
+	at   time.Time
+}
+
+// taskHeap is a min-heap of tasks by due time.
+type taskHeap []scheduledTask
+
+func (h taskHeap) Len() int           { return len(h) }
+func (h taskHeap) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+func (h taskHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *taskHeap) Push(x any)        { *h = append(*h, x.(scheduledTask)) }
+func (h *taskHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -0,0 +1,104 @@
+package zentrox
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// RunTLS starts a blocking HTTPS server with the same defaults as Run.
+func (a *App) RunTLS(addr, certFile, keyFile string) error {
+	a.exitIfExportingRoutes()
+	cfg := &ServerConfig{Addr: addr}
+	srv := a.buildServer(cfg)
+	return a.serve(srv, cfg, true, certFile, keyFile)
+}
+
+// AutoTLSConfig configures RunAutoTLSWithConfig.
+type AutoTLSConfig struct {
+	// Domains the certificates are requested for; other SNI names are refused.
+	Domains []string
+	// CacheDir keeps certificates and the ACME account key across restarts,
+	// so certificates are not re-issued (and rate-limited) on every deploy.
+	// Default: <user cache dir>/zentrox/autocert. Keep it private.
+	CacheDir string
+	// Email is given to Let's Encrypt for expiry and problem notices.
+	Email string
+	// Addr is the HTTPS address (default ":443").
+	Addr string
+	// HTTPAddr answers ACME HTTP-01 challenges and redirects all other
+	// requests to HTTPS (default ":80"; "-" disables it, leaving TLS-ALPN-01).
+	HTTPAddr string
+	// Server tunes the HTTPS server; its Addr and TLSConfig are replaced.
+	Server *ServerConfig
+}
+
+// NewAutoTLSManager returns the Let's Encrypt certificate manager used by
+// RunAutoTLSWithConfig, for serving it yourself via ServerConfig.TLSConfig:
+//
+//	m := zentrox.NewAutoTLSManager(zentrox.AutoTLSConfig{Domains: []string{"example.com"}})
+//	srv, _ := app.StartTLS(&zentrox.ServerConfig{Addr: ":443", TLSConfig: m.TLSConfig()}, "", "")
+func NewAutoTLSManager(cfg AutoTLSConfig) *autocert.Manager {
+	dir := cfg.CacheDir
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			base = "."
+		}
+		dir = filepath.Join(base, "zentrox", "autocert")
+	}
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+		Cache:      autocert.DirCache(dir),
+		Email:      cfg.Email,
+	}
+}
+
+// RunAutoTLS serves HTTPS on :443 for domains with certificates obtained
+// and renewed automatically from Let's Encrypt, and redirects :80 to HTTPS.
+// It blocks like Run.
+func (a *App) RunAutoTLS(domains ...string) error {
+	return a.RunAutoTLSWithConfig(AutoTLSConfig{Domains: domains})
+}
+
+// RunAutoTLSWithConfig is RunAutoTLS with a cache directory, contact email,
+// addresses and server settings.
+func (a *App) RunAutoTLSWithConfig(cfg AutoTLSConfig) error {
+	a.exitIfExportingRoutes()
+	m := NewAutoTLSManager(cfg)
+
+	var sc ServerConfig
+	if cfg.Server != nil {
+		sc = *cfg.Server
+	}
+	sc.Addr = cfg.Addr
+	if sc.Addr == "" {
+		sc.Addr = ":443"
+	}
+	sc.TLSConfig = m.TLSConfig()
+	srv := a.buildServer(&sc)
+
+	httpAddr := cfg.HTTPAddr
+	if httpAddr == "" {
+		httpAddr = ":80"
+	}
+	if httpAddr != "-" {
+		redirect := &http.Server{
+			Addr:              httpAddr,
+			Handler:           m.HTTPHandler(nil),
+			ReadHeaderTimeout: 5 * time.Second,
+			ErrorLog:          srv.ErrorLog,
+		}
+		go func() {
+			if err := redirect.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				srv.ErrorLog.Printf("acme http listener: %v", err)
+			}
+		}()
+		srv.RegisterOnShutdown(func() { _ = redirect.Close() })
+	}
+	return a.serve(srv, &sc, true, "", "")
+}
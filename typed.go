@@ -0,0 +1,141 @@
+package zentrox
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/aminofox/zentrox/v2/binding"
+	"github.com/aminofox/zentrox/v2/validation"
+)
+
+// StatusCoder lets a typed handler response choose its status code, see H.
+type StatusCoder interface {
+	StatusCode() int
+}
+
+// H adapts a typed function to a Handler. The request is bound into Req
+// (the query for GET, HEAD and DELETE or the body otherwise, then tagged
+// path parameters, which win over the body) and validated; the result is
+// sent as JSON:
+//
+//	type CreateUser struct {
+//		Name  string `json:"name" validate:"required"`
+//		Email string `json:"email" validate:"required,email"`
+//	}
+//
+//	app.POST("/users", zentrox.H(func(c *zentrox.Context, in CreateUser) (User, error) {
+//		return users.Create(c, in)
+//	}))
+//
+// Binding errors get 400, validation errors 422 with the failed fields.
+// A returned HTTPError is sent as is, errors registered with App.MapError
+// get their status and other errors become 500; the error is kept in
+// Context.Error for logging. The status is 200, or 204 when Res is
+// struct{}; a Res implementing StatusCoder picks its own. Register fn with
+// Typed instead to have Req and Res documented on the route as well.
+func H[Req, Res any](fn func(c *Context, req Req) (Res, error)) Handler {
+	reqType := reflect.TypeFor[Req]()
+	h := func(c *Context) {
+		var req Req
+		if err := bindTyped(c, &req, reqType); err != nil {
+			var verrs validation.Errors
+			if errors.As(err, &verrs) {
+				c.Fail(http.StatusUnprocessableEntity, MsgValidationFailed, verrs)
+				return
+			}
+			c.Fail(http.StatusBadRequest, MsgInvalidRequestBody, err.Error())
+			return
+		}
+		res, err := fn(c, req)
+		if err != nil {
+			c.failWith(err)
+			return
+		}
+		var v any = res
+		if sc, ok := v.(StatusCoder); ok {
+			c.JSON(sc.StatusCode(), res)
+			return
+		}
+		if reflect.TypeFor[Res]() == reflect.TypeFor[struct{}]() {
+			c.SendStatus(http.StatusNoContent)
+			return
+		}
+		c.JSON(http.StatusOK, res)
+	}
+	return h
+}
+
+// Typed registers H(fn) with register, a route method of an App or Scope
+// such as app.POST, after the route middleware mws. Req and Res are recorded
+// as the route's Body and Returns and fn as its handler, so ListRoutes and
+// GenerateOpenAPI describe the route without extra annotations:
+//
+//	zentrox.Typed(api.POST, "/users", createUser)
+//	zentrox.Typed(api.GET, "/users/:id", getUser, auth)
+func Typed[Req, Res any](register func(path string, handlers ...Handler) *Route, path string, fn func(c *Context, req Req) (Res, error), mws ...Handler) *Route {
+	r := register(path, append(mws[:len(mws):len(mws)], H(fn))...)
+	reqType, resType := reflect.TypeFor[Req](), reflect.TypeFor[Res]()
+	r.update(func(ri *RouteInfo) {
+		if name, file, line := funcName(fn); name != "" {
+			ri.HandlerName, ri.File, ri.Line = name, file, line
+		}
+		switch ri.Method {
+		case http.MethodGet, http.MethodHead, http.MethodDelete:
+		default:
+			if reqType.Kind() == reflect.Struct && reqType.NumField() > 0 {
+				ri.Body = reflect.New(reqType).Elem().Interface()
+			}
+		}
+		if resType != reflect.TypeFor[struct{}]() {
+			ri.Response = reflect.New(resType).Elem().Interface()
+		}
+	})
+	return r
+}
+
+// bindTyped fills req for H: the query or body first, then tagged path
+// parameters, so the URL wins over fields the body also sets, then
+// validates the result.
+func bindTyped(c *Context, req any, t reflect.Type) error {
+	if t.Kind() != reflect.Struct || t.NumField() == 0 {
+		return nil
+	}
+	var err error
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		err = binding.Query.Bind(c.Request, req)
+	default:
+		err = c.bindBody(req)
+	}
+	if err != nil {
+		return err
+	}
+	if hasTag(t, "path") {
+		if err := c.BindPathInto(req); err != nil {
+			return err
+		}
+	}
+	return c.Validate(req)
+}
+
+func hasTag(t reflect.Type, tag string) bool {
+	for i := range t.NumField() {
+		if _, ok := t.Field(i).Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// failWith sends err as the response: an HTTPError or MapError match as
+// is, anything else as 500. err is kept in Context.Error either way.
+func (c *Context) failWith(err error) {
+	he, ok := c.app.ResolveError(err)
+	if !ok {
+		he = internalError()
+	}
+	c.sendError(he)
+	c.err = err
+	c.Abort()
+}
@@ -0,0 +1,286 @@
+package zentrox
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Upload errors. Errors for a single file wrap them with the file name, so
+// test with errors.Is.
+var (
+	ErrUploadTooLarge = errors.New("upload: file too large")
+	ErrUploadExt      = errors.New("upload: disallowed file extension")
+	ErrUploadType     = errors.New("upload: disallowed content type")
+	ErrUploadTooMany  = errors.New("upload: too many files")
+)
+
+// UploadOptions controls how files are accepted and saved.
+type UploadOptions struct {
+	// Maximum memory used by ParseMultipartForm; files larger than this are stored in temporary files.
+	MaxMemory int64 // default 10 << 20 (10 MiB)
+	// Allowed file extensions (lowercase, with dot). Empty means allow all.
+	AllowedExt []string
+	// AllowedTypes lists MIME types the content must have, sniffed from the
+	// first 512 bytes with http.DetectContentType rather than trusted from
+	// the client, e.g. "image/png" or "image/*". Empty means allow all.
+	AllowedTypes []string
+	// MaxFileSize rejects files larger than this many bytes; 0 means no limit.
+	MaxFileSize int64
+	// MaxFiles limits how many files SaveUploadedFiles accepts; 0 means no limit.
+	MaxFiles int
+	// Progress, if set, is called as each file is written with the bytes
+	// written so far and the file size.
+	Progress func(filename string, written, total int64)
+	// If true, sanitize the base filename (only [a-zA-Z0-9._-]) to avoid weird characters.
+	Sanitize bool
+	// If true, always generate a unique filename (timestamp + random suffix).
+	GenerateUniqueName bool
+	// If false and file exists, returns ErrUploadExists. If true, overwrite
+	// existing file. Only local directories enforce it.
+	Overwrite bool
+	// Image, if set, requires files to be images and checks or transforms
+	// them; see ImageOptions.
+	Image *ImageOptions
+	// Storage, if set, receives the files instead of the local filesystem.
+	// The destination directory becomes a key prefix (it may be empty) and
+	// the returned values are storage keys.
+	//
+	//	store := zentrox.NewS3Storage(zentrox.S3Config{Endpoint: ..., Bucket: "media", ...})
+	//	key, err := c.SaveUploadedFile("avatar", "avatars", zentrox.UploadOptions{Storage: store})
+	Storage UploadStorage
+}
+
+// SaveUploadedFile reads file from multipart form by field name and writes it into dstDir.
+// It validates extension (if provided), prevents path traversal, and can sanitize/generate names.
+// Returns the full path saved to, or the key when opt.Storage is set.
+func (c *Context) SaveUploadedFile(field, dstDir string, opt UploadOptions) (string, error) {
+	if dstDir == "" && opt.Storage == nil {
+		return "", errors.New("upload: destination directory required")
+	}
+	if opt.MaxMemory <= 0 {
+		opt.MaxMemory = 10 << 20 // 10 MiB
+	}
+	if err := c.Request.ParseMultipartForm(opt.MaxMemory); err != nil {
+		return "", err
+	}
+	files := c.Request.MultipartForm.File[field]
+	if len(files) == 0 {
+		return "", http.ErrMissingFile
+	}
+	return saveUpload(c.Request.Context(), files[0], dstDir, opt)
+}
+
+// FormFiles returns the headers of all files uploaded under field, in form
+// order. It returns http.ErrMissingFile when there are none.
+func (c *Context) FormFiles(field string) ([]*multipart.FileHeader, error) {
+	if err := c.Request.ParseMultipartForm(10 << 20); err != nil {
+		return nil, err
+	}
+	files := c.Request.MultipartForm.File[field]
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+	return files, nil
+}
+
+// SaveUploadedFiles saves every file uploaded under field into dstDir,
+// applying the checks of SaveUploadedFile plus MaxFiles, MaxFileSize and
+// AllowedTypes to each file. It is all or nothing: when one file is rejected,
+// the files already saved are removed. Returns the saved paths (or storage
+// keys) in form order.
+//
+//	paths, err := c.SaveUploadedFiles("photos", "./uploads", zentrox.UploadOptions{
+//		MaxFiles:           10,
+//		MaxFileSize:        5 << 20,
+//		AllowedTypes:       []string{"image/jpeg", "image/png"},
+//		GenerateUniqueName: true,
+//	})
+//	if errors.Is(err, zentrox.ErrUploadType) { ... }
+func (c *Context) SaveUploadedFiles(field, dstDir string, opt UploadOptions) ([]string, error) {
+	if dstDir == "" && opt.Storage == nil {
+		return nil, errors.New("upload: destination directory required")
+	}
+	if opt.MaxMemory <= 0 {
+		opt.MaxMemory = 10 << 20 // 10 MiB
+	}
+	if err := c.Request.ParseMultipartForm(opt.MaxMemory); err != nil {
+		return nil, err
+	}
+	files := c.Request.MultipartForm.File[field]
+	if len(files) == 0 {
+		return nil, http.ErrMissingFile
+	}
+	if opt.MaxFiles > 0 && len(files) > opt.MaxFiles {
+		return nil, ErrUploadTooMany
+	}
+	saved := make([]string, 0, len(files))
+	for _, hdr := range files {
+		p, err := saveUpload(c.Request.Context(), hdr, dstDir, opt)
+		if err != nil {
+			for _, s := range saved {
+				if opt.Storage != nil {
+					_ = opt.Storage.Delete(c.Request.Context(), s)
+				} else {
+					_ = os.Remove(s)
+				}
+			}
+			return nil, err
+		}
+		saved = append(saved, p)
+	}
+	return saved, nil
+}
+
+// UploadedFile returns the multipart file and header for advanced use.
+// Caller must close the returned multipart.File.
+func (c *Context) UploadedFile(field string, maxMemory int64) (multipart.File, *multipart.FileHeader, error) {
+	if maxMemory <= 0 {
+		maxMemory = 10 << 20
+	}
+	if err := c.Request.ParseMultipartForm(maxMemory); err != nil {
+		return nil, nil, err
+	}
+	return c.Request.FormFile(field)
+}
+
+// saveUpload validates one uploaded file and writes it into dstDir, or
+// under the dstDir prefix of opt.Storage.
+func saveUpload(ctx context.Context, hdr *multipart.FileHeader, dstDir string, opt UploadOptions) (string, error) {
+	if opt.MaxFileSize > 0 && hdr.Size > opt.MaxFileSize {
+		return "", fmt.Errorf("%w: %s", ErrUploadTooLarge, hdr.Filename)
+	}
+
+	// Decide target filename
+	name := hdr.Filename
+	if opt.Sanitize {
+		name = sanitizeFilename(name)
+	}
+	if opt.GenerateUniqueName {
+		var suffix [4]byte
+		_, _ = rand.Read(suffix[:])
+		ext := strings.ToLower(filepath.Ext(name))
+		base := strings.TrimSuffix(name, ext)
+		name = base + "-" + time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(suffix[:]) + ext
+	}
+	if name == "" {
+		return "", errors.New("upload: empty filename")
+	}
+
+	// Extension allow-list
+	if len(opt.AllowedExt) > 0 {
+		ext := strings.ToLower(filepath.Ext(name))
+		allowed := false
+		for _, e := range opt.AllowedExt {
+			if strings.ToLower(e) == ext {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return "", fmt.Errorf("%w: %s", ErrUploadExt, hdr.Filename)
+		}
+	}
+
+	file, err := hdr.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var body io.Reader = file
+	size := hdr.Size
+	if opt.Image != nil {
+		if body, size, err = processImage(file, size, opt.Image); err != nil {
+			return "", fmt.Errorf("%w: %s: %v", ErrUploadImage, hdr.Filename, err)
+		}
+	}
+
+	// Content sniffing: the client's Content-Type and extension can lie.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	head = head[:n]
+	sniffed := http.DetectContentType(head)
+	if len(opt.AllowedTypes) > 0 && !mimeAllowed(sniffed, opt.AllowedTypes) {
+		return "", fmt.Errorf("%w: %s", ErrUploadType, hdr.Filename)
+	}
+	var src io.Reader = io.MultiReader(bytes.NewReader(head), body)
+	if opt.Progress != nil {
+		src = &progressReader{r: src, name: hdr.Filename, total: size, fn: opt.Progress}
+	}
+
+	if opt.Storage != nil {
+		key := strings.TrimPrefix(path.Join(filepath.ToSlash(dstDir), filepath.Base(name)), "/")
+		if err := opt.Storage.Save(ctx, key, src, size, sniffed); err != nil {
+			return "", err
+		}
+		return key, nil
+	}
+
+	// Local disk (0600 for privacy by default); LocalStorage prevents path
+	// traversal and, unless Overwrite, replacing existing files.
+	local := &LocalStorage{Dir: dstDir, exclusive: !opt.Overwrite}
+	if err := local.Save(ctx, filepath.Base(name), src, size, sniffed); err != nil {
+		return "", err
+	}
+	return filepath.Join(dstDir, filepath.Base(name)), nil
+}
+
+// mimeAllowed reports whether the sniffed type matches one of allowed,
+// which may use "type/*".
+func mimeAllowed(sniffed string, allowed []string) bool {
+	sniffed, _, _ = strings.Cut(sniffed, ";")
+	for _, a := range allowed {
+		if matchesMedia(a, sniffed) {
+			return true
+		}
+	}
+	return false
+}
+
+// progressReader reports bytes consumed by the destination to an
+// UploadOptions.Progress callback.
+type progressReader struct {
+	r       io.Reader
+	name    string
+	written int64
+	total   int64
+	fn      func(string, int64, int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.written += int64(n)
+		p.fn(p.name, p.written, p.total)
+	}
+	return n, err
+}
+
+var sanitizeFilenameRe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// sanitizeFilename strips unsupported characters from a file name.
+func sanitizeFilename(name string) string {
+	name = filepath.Base(name)
+	name = sanitizeFilenameRe.ReplaceAllString(name, "_")
+	// Avoid empty name
+	if name == "" || name == "." || name == ".." {
+		name = "file"
+	}
+	return name
+}
@@ -0,0 +1,147 @@
+package zentrox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"slices"
+)
+
+// ErrUploadImage is returned when a file is not an acceptable image. The
+// wrapped message gives the file name and reason.
+var ErrUploadImage = errors.New("upload: invalid image")
+
+// ImageOptions checks and transforms uploaded images. Set it on
+// UploadOptions.Image.
+type ImageOptions struct {
+	// Formats lists accepted formats as named by image.DecodeConfig
+	// ("jpeg", "png", "gif"). Empty means all three.
+	Formats []string
+	// MaxWidth and MaxHeight reject larger images, checked from the header
+	// before any pixels are decoded; 0 means no limit.
+	MaxWidth  int
+	MaxHeight int
+	// Transforms run in order on the decoded image, e.g. ResizeImage.
+	Transforms []ImageTransform
+	// StripMetadata re-encodes the image so EXIF and other metadata (GPS
+	// position, camera serial) are dropped. Transforms always re-encode.
+	StripMetadata bool
+	// JPEGQuality used when re-encoding JPEGs; default 90.
+	JPEGQuality int
+}
+
+// ImageTransform changes a decoded upload before it is re-encoded.
+type ImageTransform func(image.Image) image.Image
+
+// ResizeImage returns a transform that scales images down, keeping the
+// aspect ratio, to fit within maxWidth x maxHeight (0 means unbounded).
+// Smaller images are left alone.
+func ResizeImage(maxWidth, maxHeight int) ImageTransform {
+	return func(img image.Image) image.Image {
+		b := img.Bounds()
+		w, h := b.Dx(), b.Dy()
+		scale := 1.0
+		if maxWidth > 0 && w > maxWidth {
+			scale = float64(maxWidth) / float64(w)
+		}
+		if maxHeight > 0 && h > maxHeight && float64(maxHeight)/float64(h) < scale {
+			scale = float64(maxHeight) / float64(h)
+		}
+		if scale >= 1 {
+			return img
+		}
+		return resizeArea(img, max(1, int(float64(w)*scale)), max(1, int(float64(h)*scale)))
+	}
+}
+
+// resizeArea downscales img to w x h, averaging the source pixels covered by
+// each destination pixel.
+func resizeArea(img image.Image, w, h int) *image.NRGBA {
+	b := img.Bounds()
+	src := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	sw, sh := b.Dx(), b.Dy()
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0, y1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					sum[0] += int(p[0])
+					sum[1] += int(p[1])
+					sum[2] += int(p[2])
+					sum[3] += int(p[3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			d := dst.Pix[y*dst.Stride+x*4:]
+			for i := range 4 {
+				d[i] = uint8(sum[i] / n)
+			}
+		}
+	}
+	return dst
+}
+
+// processImage validates the image in r and applies opt's transforms. It
+// returns the content to store and its size.
+func processImage(r io.ReadSeeker, size int64, opt *ImageOptions) (io.Reader, int64, error) {
+	cfg, format, err := image.DecodeConfig(r)
+	if err != nil {
+		return nil, 0, errors.New("not a supported image")
+	}
+	formats := opt.Formats
+	if len(formats) == 0 {
+		formats = []string{"jpeg", "png", "gif"}
+	}
+	if !slices.Contains(formats, format) {
+		return nil, 0, fmt.Errorf("format %s not allowed", format)
+	}
+	if (opt.MaxWidth > 0 && cfg.Width > opt.MaxWidth) || (opt.MaxHeight > 0 && cfg.Height > opt.MaxHeight) {
+		return nil, 0, fmt.Errorf("%dx%d exceeds %dx%d", cfg.Width, cfg.Height, opt.MaxWidth, opt.MaxHeight)
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, err
+	}
+	if len(opt.Transforms) == 0 && !opt.StripMetadata {
+		return r, size, nil
+	}
+
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, 0, errors.New("corrupt image")
+	}
+	for _, t := range opt.Transforms {
+		img = t(img)
+	}
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		q := opt.JPEGQuality
+		if q <= 0 {
+			q = 90
+		}
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: q})
+	case "png":
+		err = png.Encode(&buf, img)
+	case "gif":
+		err = gif.Encode(&buf, img, nil) // first frame only
+	default:
+		err = fmt.Errorf("cannot re-encode %s", format)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return &buf, int64(buf.Len()), nil
+}
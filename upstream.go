@@ -0,0 +1,237 @@
+package zentrox
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BalanceStrategy chooses the upstream for each proxied request.
+type BalanceStrategy int
+
+const (
+	// RoundRobin cycles through the healthy upstreams.
+	RoundRobin BalanceStrategy = iota
+	// LeastConnections picks the healthy upstream with the fewest requests
+	// in flight, for backends with uneven request costs.
+	LeastConnections
+)
+
+// ErrNoUpstream is returned when every upstream of a pool is ejected.
+var ErrNoUpstream = errors.New("zentrox: no healthy upstream")
+
+// UpstreamPoolConfig configures NewUpstreamPool.
+type UpstreamPoolConfig struct {
+	Strategy BalanceStrategy
+	// MaxFails consecutive failures (connection errors or 502/503/504)
+	// eject an upstream; default 3, negative disables health checking.
+	MaxFails int
+	// FailTimeout is how long an ejected upstream is skipped before one
+	// probe request is let through; success restores it. Default 10s.
+	FailTimeout time.Duration
+}
+
+// DefaultUpstreamPool returns the default UpstreamPoolConfig.
+func DefaultUpstreamPool() UpstreamPoolConfig {
+	return UpstreamPoolConfig{Strategy: RoundRobin, MaxFails: 3, FailTimeout: 10 * time.Second}
+}
+
+// UpstreamPool balances proxied requests across several targets, ejecting
+// failing ones (passive health checking: a circuit breaker per upstream).
+// Use it with App.ProxyPool or PoolProxyHandler.
+type UpstreamPool struct {
+	cfg       UpstreamPoolConfig
+	upstreams []*upstream
+	next      atomic.Uint64
+}
+
+type upstream struct {
+	url    *url.URL
+	active atomic.Int64
+
+	mu        sync.Mutex
+	fails     int
+	downUntil time.Time
+	probing   bool
+}
+
+// UpstreamStatus reports the state of one upstream, see UpstreamPool.Status.
+type UpstreamStatus struct {
+	URL     string `json:"url"`
+	Healthy bool   `json:"healthy"`
+	Active  int64  `json:"active"`
+}
+
+// NewUpstreamPool returns a pool over targets, which must be absolute URLs.
+// It panics otherwise, or when targets is empty.
+func NewUpstreamPool(targets []string, cfg ...UpstreamPoolConfig) *UpstreamPool {
+	if len(targets) == 0 {
+		panic("zentrox: NewUpstreamPool requires at least one target")
+	}
+	def := DefaultUpstreamPool()
+	conf := def
+	if len(cfg) > 0 {
+		conf = cfg[0]
+		if conf.MaxFails == 0 {
+			conf.MaxFails = def.MaxFails
+		}
+		if conf.FailTimeout <= 0 {
+			conf.FailTimeout = def.FailTimeout
+		}
+	}
+	p := &UpstreamPool{cfg: conf}
+	for _, t := range targets {
+		u, err := url.Parse(t)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			panic("zentrox: upstream must be an absolute URL: " + t)
+		}
+		p.upstreams = append(p.upstreams, &upstream{url: u})
+	}
+	return p
+}
+
+// Status returns the state of each upstream, in configuration order; handy
+// for a health endpoint.
+func (p *UpstreamPool) Status() []UpstreamStatus {
+	now := time.Now()
+	out := make([]UpstreamStatus, len(p.upstreams))
+	for i, u := range p.upstreams {
+		u.mu.Lock()
+		healthy := !now.Before(u.downUntil)
+		u.mu.Unlock()
+		out[i] = UpstreamStatus{URL: u.url.String(), Healthy: healthy, Active: u.active.Load()}
+	}
+	return out
+}
+
+// pick returns the upstream for the next request, or nil when all are
+// ejected and none is due for a probe.
+func (p *UpstreamPool) pick() *upstream {
+	now := time.Now()
+	n := len(p.upstreams)
+	start := int(p.next.Add(1)-1) % n
+	var best *upstream
+	for i := 0; i < n; i++ {
+		u := p.upstreams[(start+i)%n]
+		if !u.available(now) {
+			continue
+		}
+		if p.cfg.Strategy == RoundRobin {
+			return u.claim()
+		}
+		if best == nil || u.active.Load() < best.active.Load() {
+			best = u
+		}
+	}
+	if best != nil {
+		return best.claim()
+	}
+	return nil
+}
+
+// available reports whether u is healthy or due for a half-open probe.
+func (u *upstream) available(now time.Time) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return !now.Before(u.downUntil) && !u.probing
+}
+
+// claim marks the probe in flight when u was ejected.
+func (u *upstream) claim() *upstream {
+	u.mu.Lock()
+	if !u.downUntil.IsZero() {
+		u.probing = true
+	}
+	u.mu.Unlock()
+	return u
+}
+
+// endProbe lets another probe through after one ended without an outcome.
+func (u *upstream) endProbe() {
+	u.mu.Lock()
+	u.probing = false
+	u.mu.Unlock()
+}
+
+// report records the outcome of a request to u.
+func (p *UpstreamPool) report(u *upstream, ok bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.probing = false
+	if ok {
+		u.fails = 0
+		u.downUntil = time.Time{}
+		return
+	}
+	u.fails++
+	if p.cfg.MaxFails > 0 && u.fails >= p.cfg.MaxFails {
+		u.downUntil = time.Now().Add(p.cfg.FailTimeout)
+	}
+}
+
+// poolTransport sends each request to an upstream picked from the pool.
+// Wrapped by retryTransport, every retry picks again.
+type poolTransport struct {
+	pool *UpstreamPool
+	rt   http.RoundTripper
+}
+
+func (t *poolTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	u := t.pool.pick()
+	if u == nil {
+		return nil, ErrNoUpstream
+	}
+	r := req.Clone(req.Context())
+	r.URL.Scheme = u.url.Scheme
+	r.URL.Host = u.url.Host
+	r.URL.Path = joinURLPath(u.url.Path, req.URL.Path)
+	r.URL.RawPath = ""
+	if u.url.RawQuery != "" {
+		if r.URL.RawQuery == "" {
+			r.URL.RawQuery = u.url.RawQuery
+		} else {
+			r.URL.RawQuery = u.url.RawQuery + "&" + r.URL.RawQuery
+		}
+	}
+
+	u.active.Add(1)
+	res, err := t.rt.RoundTrip(r)
+	if req.Context().Err() == nil {
+		t.pool.report(u, !retryable(res, err))
+	} else {
+		// A client that went away or a proxy timeout says nothing about u.
+		u.endProbe()
+	}
+	if err != nil || res.StatusCode == http.StatusSwitchingProtocols {
+		// Upgraded connections need the raw body (an io.ReadWriteCloser).
+		u.active.Add(-1)
+		return res, err
+	}
+	res.Body = &upstreamBody{ReadCloser: res.Body, u: u}
+	return res, nil
+}
+
+// upstreamBody keeps a request counted as active until its body is closed.
+type upstreamBody struct {
+	io.ReadCloser
+	u    *upstream
+	once sync.Once
+}
+
+func (b *upstreamBody) Close() error {
+	b.once.Do(func() { b.u.active.Add(-1) })
+	return b.ReadCloser.Close()
+}
+
+// joinURLPath joins a target base path and a request path with one slash.
+func joinURLPath(base, p string) string {
+	if base == "" || base == "/" {
+		return p
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(p, "/")
+}
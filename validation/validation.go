@@ -7,11 +7,92 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 )
 
-// ValidateStruct supports `validate:"required,min=,max=,len="`
-// - numbers: min/max value
-// - strings/slices: min/max/len length
+// FieldError describes a field that failed a rule.
+type FieldError struct {
+	// Field is the dotted path of the field, using json tag names when
+	// present, e.g. "address.zip" or "items[2].sku".
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string { return e.Message }
+
+// Errors is returned by ValidateStruct when one or more rules fail. Render
+// it directly as the detail of an error response.
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Rule checks v against param (the text after "=" in the tag, or ""). The
+// returned error text completes the message, e.g. "must be >= 3".
+type Rule func(v reflect.Value, param string) error
+
+// Validator is a pluggable validation engine, e.g. an adapter around a
+// third-party library. See zentrox.App.SetValidator.
+type Validator interface {
+	ValidateStruct(v any) error
+}
+
+// ValidatorFunc adapts a function to Validator.
+type ValidatorFunc func(v any) error
+
+func (f ValidatorFunc) ValidateStruct(v any) error { return f(v) }
+
+var (
+	rulesMu sync.RWMutex
+	rules   = map[string]Rule{
+		"min":   checkMin,
+		"max":   checkMax,
+		"len":   checkLen,
+		"email": func(v reflect.Value, _ string) error { return checkEmail(v) },
+		"oneof": checkOneOf,
+		"regex": checkRegex,
+	}
+)
+
+// Register adds or replaces a rule usable in tags as name or name=param:
+//
+//	validation.Register("slug", func(v reflect.Value, _ string) error {
+//		if !slugRe.MatchString(v.String()) {
+//			return errors.New("must be a slug")
+//		}
+//		return nil
+//	})
+//
+// "required" and "omitempty" are reserved.
+func Register(name string, fn Rule) {
+	rulesMu.Lock()
+	rules[name] = fn
+	rulesMu.Unlock()
+}
+
+func lookupRule(name string) (Rule, bool) {
+	rulesMu.RLock()
+	fn, ok := rules[name]
+	rulesMu.RUnlock()
+	return fn, ok
+}
+
+// ValidateStruct checks `validate` tags (or `binding` tags when no
+// `validate` tag is present), e.g. `validate:"required,min=3,max=50"`:
+//   - required: non-zero value (non-nil pointer)
+//   - omitempty: skip the remaining rules when the value is zero
+//   - min/max/len: value for numbers, length for strings, slices and maps
+//   - email, oneof=a b c, regex=pattern, and rules added with Register
+//
+// Nested structs, pointers to structs and slices of structs are validated
+// recursively. Failures are returned as Errors.
 func ValidateStruct(v any) error {
 	val := reflect.ValueOf(v)
 	if val.Kind() == reflect.Pointer {
@@ -23,8 +104,15 @@ func ValidateStruct(v any) error {
 	if val.Kind() != reflect.Struct {
 		return errors.New("need struct or *struct")
 	}
+	var errs Errors
+	validateStruct(val, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
 
-	var errs []string
+func validateStruct(val reflect.Value, prefix string, errs *Errors) {
 	t := val.Type()
 	for i := 0; i < t.NumField(); i++ {
 		sf := t.Field(i)
@@ -32,59 +120,89 @@ func ValidateStruct(v any) error {
 			continue
 		} // unexported
 		fv := val.Field(i)
+		if sf.Anonymous && fv.Kind() == reflect.Struct && sf.Tag.Get("json") == "" {
+			validateStruct(fv, prefix, errs) // embedded fields are promoted
+			continue
+		}
+		name := prefix + fieldName(sf)
+
+		tag, ok := sf.Tag.Lookup("validate")
+		if !ok {
+			tag = sf.Tag.Get("binding")
+		}
+		if tag != "" && tag != "-" {
+			validateField(fv, name, tag, errs)
+		}
+		validateNested(fv, name, errs)
+	}
+}
 
-		if fv.Kind() == reflect.Struct {
-			if err := ValidateStruct(fv.Interface()); err != nil {
-				errs = append(errs, err.Error())
+// validateNested descends into struct, *struct and []struct fields.
+func validateNested(fv reflect.Value, name string, errs *Errors) {
+	switch fv.Kind() {
+	case reflect.Pointer:
+		if !fv.IsNil() && fv.Elem().Kind() == reflect.Struct {
+			validateStruct(fv.Elem(), name+".", errs)
+		}
+	case reflect.Struct:
+		validateStruct(fv, name+".", errs)
+	case reflect.Slice, reflect.Array:
+		for j := 0; j < fv.Len(); j++ {
+			ev := fv.Index(j)
+			if ev.Kind() == reflect.Pointer && !ev.IsNil() {
+				ev = ev.Elem()
 			}
-			continue
+			if ev.Kind() != reflect.Struct {
+				return
+			}
+			validateStruct(ev, fmt.Sprintf("%s[%d].", name, j), errs)
 		}
+	}
+}
 
-		tag := sf.Tag.Get("validate")
-		if tag == "" {
+func validateField(fv reflect.Value, name, tag string, errs *Errors) {
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
 			continue
 		}
-		for _, rule := range strings.Split(tag, ",") {
-			rule = strings.TrimSpace(rule)
-			if rule == "" {
-				continue
+		rname, param, _ := strings.Cut(rule, "=")
+		switch rname {
+		case "required":
+			if isZero(fv) {
+				*errs = append(*errs, FieldError{Field: name, Rule: rname, Message: name + " is required"})
+				return
 			}
-			switch {
-			case rule == "required":
-				if isZero(fv) {
-					errs = append(errs, fmt.Sprintf("%s is required", sf.Name))
-				}
-			case strings.HasPrefix(rule, "min="):
-				if err := checkMin(fv, strings.TrimPrefix(rule, "min=")); err != nil {
-					errs = append(errs, fmt.Sprintf("%s %v", sf.Name, err))
-				}
-			case strings.HasPrefix(rule, "max="):
-				if err := checkMax(fv, strings.TrimPrefix(rule, "max=")); err != nil {
-					errs = append(errs, fmt.Sprintf("%s %v", sf.Name, err))
-				}
-			case strings.HasPrefix(rule, "len="):
-				if err := checkLen(fv, strings.TrimPrefix(rule, "len=")); err != nil {
-					errs = append(errs, fmt.Sprintf("%s %v", sf.Name, err))
-				}
-			case rule == "email":
-				if err := checkEmail(fv); err != nil {
-					errs = append(errs, fmt.Sprintf("%s %v", sf.Name, err))
-				}
-			case strings.HasPrefix(rule, "oneof="):
-				if err := checkOneOf(fv, strings.TrimPrefix(rule, "oneof=")); err != nil {
-					errs = append(errs, fmt.Sprintf("%s %v", sf.Name, err))
-				}
-			case strings.HasPrefix(rule, "regex="):
-				if err := checkRegex(fv, strings.TrimPrefix(rule, "regex=")); err != nil {
-					errs = append(errs, fmt.Sprintf("%s %v", sf.Name, err))
-				}
+			continue
+		case "omitempty":
+			if isZero(fv) {
+				return
+			}
+			continue
+		}
+		fn, ok := lookupRule(rname)
+		if !ok {
+			continue
+		}
+		v := fv
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				continue
 			}
+			v = v.Elem()
+		}
+		if err := fn(v, param); err != nil {
+			*errs = append(*errs, FieldError{Field: name, Rule: rname, Param: param, Message: name + " " + err.Error()})
 		}
 	}
-	if len(errs) > 0 {
-		return errors.New(strings.Join(errs, "; "))
+}
+
+// fieldName is the json tag name of sf, or its Go name.
+func fieldName(sf reflect.StructField) string {
+	if n, _, _ := strings.Cut(sf.Tag.Get("json"), ","); n != "" && n != "-" {
+		return n
 	}
-	return nil
+	return sf.Name
 }
 
 func isZero(v reflect.Value) bool {
@@ -108,7 +226,7 @@ func isZero(v reflect.Value) bool {
 
 func checkMin(v reflect.Value, s string) error {
 	switch v.Kind() {
-	case reflect.String, reflect.Slice, reflect.Array:
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
 		min, err := strconv.Atoi(s)
 		if err != nil {
 			return fmt.Errorf("min invalid: %v", err)
@@ -146,7 +264,7 @@ func checkMin(v reflect.Value, s string) error {
 
 func checkMax(v reflect.Value, s string) error {
 	switch v.Kind() {
-	case reflect.String, reflect.Slice, reflect.Array:
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
 		max, err := strconv.Atoi(s)
 		if err != nil {
 			return fmt.Errorf("max invalid: %v", err)
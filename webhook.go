@@ -0,0 +1,42 @@
+package zentrox
+
+import (
+	"context"
+	"errors"
+)
+
+// WebhookEmitter sends domain events to webhook subscribers; see the webhook
+// package for a dispatcher and App.SetWebhooks.
+type WebhookEmitter interface {
+	// Emit queues event with payload for delivery and returns without
+	// waiting for it.
+	Emit(ctx context.Context, event string, payload any) error
+}
+
+// ErrNoWebhooks is returned by Context.EmitWebhook when App.SetWebhooks was
+// not called.
+var ErrNoWebhooks = errors.New("zentrox: no webhook emitter set")
+
+// SetWebhooks sets the emitter used by Context.EmitWebhook. If it also has a
+// Close(context.Context) error method, Shutdown drains it after the server
+// has stopped.
+func (a *App) SetWebhooks(e WebhookEmitter) *App {
+	a.webhooks = e
+	return a
+}
+
+// EmitWebhook notifies subscribers of a domain event through the App's
+// WebhookEmitter:
+//
+//	if err := c.EmitWebhook("order.paid", order); err != nil {
+//		c.Logger().Warn("webhook not queued", "err", err)
+//	}
+//
+// Delivery happens in the background and is not cancelled when the request
+// ends.
+func (c *Context) EmitWebhook(event string, payload any) error {
+	if c.app == nil || c.app.webhooks == nil {
+		return ErrNoWebhooks
+	}
+	return c.app.webhooks.Emit(context.WithoutCancel(c.Request.Context()), event, payload)
+}
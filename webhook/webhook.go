@@ -0,0 +1,425 @@
+// Package webhook delivers domain events to subscriber URLs: payloads are
+// signed, failed deliveries are retried with exponential backoff and
+// deliveries that keep failing are handed to a dead-letter callback.
+//
+//	hooks := webhook.New(webhook.Config{
+//		OnDeadLetter: func(d webhook.Delivery, err error) { slog.Error("webhook", "url", d.Endpoint.URL, "err", err) },
+//	})
+//	hooks.Register(webhook.Endpoint{ID: "crm", URL: "https://crm.example.com/hooks", Secret: secret, Events: []string{"order.*"}})
+//	app.SetWebhooks(hooks)
+//
+//	app.POST("/orders/:id/pay", func(c *zentrox.Context) {
+//		// ...
+//		_ = c.EmitWebhook("order.paid", order)
+//	})
+//
+// Receivers verify the X-Signature header with middleware.VerifySignature.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// Headers set on every delivery, besides X-Timestamp and X-Signature when
+// the endpoint has a secret.
+const (
+	HeaderEvent   = "X-Webhook-Event"
+	HeaderID      = "X-Webhook-ID"
+	HeaderAttempt = "X-Webhook-Attempt"
+)
+
+var (
+	// ErrClosed is returned by Emit after Close, and passed to OnDeadLetter
+	// for deliveries abandoned by Close.
+	ErrClosed = errors.New("webhook: dispatcher closed")
+	// ErrQueueFull is returned by Emit when QueueSize deliveries are waiting.
+	ErrQueueFull = errors.New("webhook: queue full")
+)
+
+// Endpoint is a subscriber.
+type Endpoint struct {
+	// ID identifies the endpoint for Unregister; it defaults to URL.
+	ID  string
+	URL string
+	// Secret signs deliveries with zentrox.SignRequest; empty sends them
+	// unsigned.
+	Secret []byte
+	// Events the endpoint subscribes to: exact names or prefixes ending in
+	// ".*" such as "order.*". Empty subscribes to all events.
+	Events []string
+	// Headers are added to every delivery, e.g. an API key.
+	Headers map[string]string
+}
+
+func (ep Endpoint) subscribed(event string) bool {
+	if len(ep.Events) == 0 {
+		return true
+	}
+	for _, e := range ep.Events {
+		if e == event || e == "*" || (strings.HasSuffix(e, ".*") && strings.HasPrefix(event, e[:len(e)-1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is the JSON body of a delivery.
+type Event struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Delivery is one event sent to one endpoint.
+type Delivery struct {
+	Endpoint Endpoint
+	Event    Event
+	Body     []byte
+	// Attempt is the number of attempts made so far.
+	Attempt int
+	// Status is the response status of the last attempt; 0 if none was
+	// received.
+	Status int
+}
+
+// Config configures New.
+type Config struct {
+	// Client sends deliveries; default a client with a 10s timeout.
+	Client *http.Client
+	// Workers is the number of concurrent deliveries (default 4).
+	Workers int
+	// QueueSize bounds the deliveries waiting for a worker (default 1024).
+	QueueSize int
+	// MaxAttempts per delivery, the first included (default 8).
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled on each
+	// further one up to MaxBackoff (defaults 1s and 10m).
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// OnDeadLetter receives deliveries that failed MaxAttempts times, got a
+	// non-retryable response, or were abandoned by Close.
+	OnDeadLetter func(d Delivery, err error)
+	// OnDelivered, if set, is called after each successful delivery.
+	OnDelivered func(d Delivery)
+}
+
+// DefaultConfig returns the default Config.
+func DefaultConfig() Config {
+	return Config{
+		Client:         &http.Client{Timeout: 10 * time.Second},
+		Workers:        4,
+		QueueSize:      1024,
+		MaxAttempts:    8,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Minute,
+	}
+}
+
+// Dispatcher queues events and delivers them to registered endpoints. It
+// implements zentrox.WebhookEmitter.
+type Dispatcher struct {
+	cfg Config
+
+	mu        sync.RWMutex
+	endpoints []Endpoint
+	closed    bool
+	retrying  map[*Delivery]*time.Timer
+
+	queue   chan *Delivery
+	pending sync.WaitGroup // deliveries without a final outcome
+	timers  sync.WaitGroup // retry timers not yet run
+	ctx     context.Context
+	stop    context.CancelFunc
+	workers sync.WaitGroup
+}
+
+var _ zentrox.WebhookEmitter = (*Dispatcher)(nil)
+
+// New starts a Dispatcher; call Close to stop its workers.
+func New(cfg ...Config) *Dispatcher {
+	def := DefaultConfig()
+	conf := def
+	if len(cfg) > 0 {
+		conf = cfg[0]
+		if conf.Client == nil {
+			conf.Client = def.Client
+		}
+		if conf.Workers <= 0 {
+			conf.Workers = def.Workers
+		}
+		if conf.QueueSize <= 0 {
+			conf.QueueSize = def.QueueSize
+		}
+		if conf.MaxAttempts <= 0 {
+			conf.MaxAttempts = def.MaxAttempts
+		}
+		if conf.InitialBackoff <= 0 {
+			conf.InitialBackoff = def.InitialBackoff
+		}
+		if conf.MaxBackoff <= 0 {
+			conf.MaxBackoff = def.MaxBackoff
+		}
+	}
+	d := &Dispatcher{
+		cfg:      conf,
+		retrying: map[*Delivery]*time.Timer{},
+		queue:    make(chan *Delivery, conf.QueueSize),
+	}
+	d.ctx, d.stop = context.WithCancel(context.Background())
+	for range conf.Workers {
+		d.workers.Add(1)
+		go d.work()
+	}
+	return d
+}
+
+// Register adds or replaces (by ID) an endpoint.
+func (d *Dispatcher) Register(ep Endpoint) error {
+	if !strings.HasPrefix(ep.URL, "http://") && !strings.HasPrefix(ep.URL, "https://") {
+		return fmt.Errorf("webhook: endpoint URL must be http(s): %q", ep.URL)
+	}
+	if ep.ID == "" {
+		ep.ID = ep.URL
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, e := range d.endpoints {
+		if e.ID == ep.ID {
+			d.endpoints[i] = ep
+			return nil
+		}
+	}
+	d.endpoints = append(d.endpoints, ep)
+	return nil
+}
+
+// Unregister removes the endpoint with id; queued deliveries are still sent.
+func (d *Dispatcher) Unregister(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, e := range d.endpoints {
+		if e.ID == id {
+			d.endpoints = append(d.endpoints[:i], d.endpoints[i+1:]...)
+			return
+		}
+	}
+}
+
+// Endpoints returns the registered endpoints.
+func (d *Dispatcher) Endpoints() []Endpoint {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return append([]Endpoint(nil), d.endpoints...)
+}
+
+// Emit queues event for every subscribed endpoint. payload is encoded as
+// JSON in the data field of the body. It returns ErrQueueFull if some
+// deliveries could not be queued.
+func (d *Dispatcher) Emit(ctx context.Context, event string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("webhook: encode payload: %w", err)
+	}
+	ev := Event{ID: newEventID(), Event: event, CreatedAt: time.Now().UTC(), Data: data}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("webhook: encode event: %w", err)
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+	for _, ep := range d.endpoints {
+		if !ep.subscribed(event) {
+			continue
+		}
+		d.pending.Add(1)
+		select {
+		case d.queue <- &Delivery{Endpoint: ep, Event: ev, Body: body}:
+		default:
+			d.pending.Done()
+			err = ErrQueueFull
+		}
+	}
+	return err
+}
+
+// Close stops accepting events and waits until queued deliveries and their
+// retries are done, or ctx ends; deliveries still pending then go to
+// OnDeadLetter with ErrClosed.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.pending.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	d.stop()
+	d.workers.Wait()
+
+	// Abandon scheduled retries and anything left in the queue.
+	d.mu.Lock()
+	for dl, t := range d.retrying {
+		if t.Stop() {
+			d.timers.Done()
+			d.deadLetter(dl, ErrClosed)
+		}
+		delete(d.retrying, dl)
+	}
+	d.mu.Unlock()
+	d.timers.Wait()
+	for {
+		select {
+		case dl := <-d.queue:
+			d.deadLetter(dl, ErrClosed)
+		default:
+			return err
+		}
+	}
+}
+
+func (d *Dispatcher) work() {
+	defer d.workers.Done()
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case dl := <-d.queue:
+			d.attempt(dl)
+		}
+	}
+}
+
+// attempt sends dl once and settles it or schedules a retry.
+func (d *Dispatcher) attempt(dl *Delivery) {
+	dl.Attempt++
+	err := d.send(dl)
+	if err == nil {
+		if d.cfg.OnDelivered != nil {
+			d.cfg.OnDelivered(*dl)
+		}
+		d.pending.Done()
+		return
+	}
+	if d.ctx.Err() != nil {
+		d.deadLetter(dl, ErrClosed)
+		return
+	}
+	if !retryable(dl.Status) || dl.Attempt >= d.cfg.MaxAttempts {
+		d.deadLetter(dl, err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.timers.Add(1)
+	d.retrying[dl] = time.AfterFunc(d.backoff(dl.Attempt), func() {
+		defer d.timers.Done()
+		d.mu.Lock()
+		delete(d.retrying, dl)
+		d.mu.Unlock()
+		select {
+		case d.queue <- dl:
+		case <-d.ctx.Done():
+			d.deadLetter(dl, ErrClosed)
+		}
+	})
+}
+
+func (d *Dispatcher) send(dl *Delivery) error {
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, dl.Endpoint.URL, bytes.NewReader(dl.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(zentrox.HeaderContentType, zentrox.ContentTypeJSON)
+	req.Header.Set("User-Agent", "zentrox-webhook")
+	req.Header.Set(HeaderEvent, dl.Event.Event)
+	req.Header.Set(HeaderID, dl.Event.ID)
+	req.Header.Set(HeaderAttempt, strconv.Itoa(dl.Attempt))
+	for k, v := range dl.Endpoint.Headers {
+		req.Header.Set(k, v)
+	}
+	if len(dl.Endpoint.Secret) > 0 {
+		if err := zentrox.SignRequest(req, dl.Endpoint.Secret); err != nil {
+			return err
+		}
+	}
+
+	dl.Status = 0
+	res, err := d.cfg.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	res.Body.Close()
+	dl.Status = res.StatusCode
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("webhook: %s responded %s", dl.Endpoint.URL, res.Status)
+	}
+	return nil
+}
+
+func (d *Dispatcher) deadLetter(dl *Delivery, err error) {
+	if d.cfg.OnDeadLetter != nil {
+		d.cfg.OnDeadLetter(*dl, err)
+	}
+	d.pending.Done()
+}
+
+func (d *Dispatcher) backoff(attempt int) time.Duration {
+	delay := d.cfg.InitialBackoff
+	for i := 1; i < attempt && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.MaxBackoff)
+}
+
+// retryable reports whether a delivery that got status (0 for a transport
+// error) should be tried again. Other 4xx responses mean the receiver will
+// never accept it.
+func retryable(status int) bool {
+	switch {
+	case status == 0, status >= 500:
+		return true
+	case status == http.StatusRequestTimeout, status == http.StatusConflict,
+		status == http.StatusTooEarly, status == http.StatusTooManyRequests:
+		return true
+	}
+	return false
+}
+
+func newEventID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "evt_" + hex.EncodeToString(b)
+}
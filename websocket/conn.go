@@ -0,0 +1,348 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType is the type of a data message.
+type MessageType int
+
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// Close codes (RFC 6455 section 7.4.1).
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	CloseUnsupportedData = 1003
+	CloseNoStatus        = 1005
+	CloseInvalidPayload  = 1007
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseInternalError   = 1011
+)
+
+// CloseError is returned by ReadMessage when the peer closes the connection
+// or the connection is closed because of a protocol violation.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: close %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: close %d: %s", e.Code, e.Reason)
+}
+
+var (
+	// ErrClosed is returned by writes after the connection was closed.
+	ErrClosed = errors.New("websocket: connection closed")
+	// ErrControlTooLong is returned by Ping for payloads over 125 bytes.
+	ErrControlTooLong = errors.New("websocket: control frame payload exceeds 125 bytes")
+)
+
+// Conn is an upgraded WebSocket connection. One goroutine may read while
+// others write; writes are serialized internally.
+type Conn struct {
+	conn  net.Conn
+	br    *bufio.Reader
+	bw    *bufio.Writer
+	cfg   Config
+	proto string
+
+	wmu       sync.Mutex
+	closeSent bool
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Subprotocol returns the negotiated subprotocol, or "".
+func (c *Conn) Subprotocol() string { return c.proto }
+
+// RemoteAddr returns the peer's network address.
+func (c *Conn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// ReadMessage returns the next complete text or binary message. Pings are
+// answered and pongs consumed transparently. When the peer closes, the close
+// is acknowledged and a *CloseError is returned.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var (
+		typ MessageType
+		msg []byte
+	)
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, c.fail(err)
+		}
+		if c.cfg.PingInterval > 0 {
+			_ = c.conn.SetReadDeadline(time.Now().Add(c.cfg.PongWait))
+		}
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			ce := &CloseError{Code: CloseNoStatus}
+			switch {
+			case len(payload) == 1:
+				return 0, nil, c.fail(&CloseError{Code: CloseProtocolError, Reason: "invalid close payload"})
+			case len(payload) >= 2:
+				ce.Code = int(binary.BigEndian.Uint16(payload))
+				ce.Reason = string(payload[2:])
+				if !validCloseCode(ce.Code) {
+					return 0, nil, c.fail(&CloseError{Code: CloseProtocolError, Reason: "invalid close code"})
+				}
+				if !utf8.ValidString(ce.Reason) {
+					return 0, nil, c.fail(&CloseError{Code: CloseInvalidPayload, Reason: "invalid close reason"})
+				}
+			}
+			code := ce.Code
+			if code == CloseNoStatus {
+				code = CloseNormal
+			}
+			_ = c.CloseWithReason(code, "")
+			return 0, nil, ce
+		case opText, opBinary:
+			if typ != 0 {
+				return 0, nil, c.fail(&CloseError{Code: CloseProtocolError, Reason: "expected continuation frame"})
+			}
+			typ = MessageType(op)
+		case opContinuation:
+			if typ == 0 {
+				return 0, nil, c.fail(&CloseError{Code: CloseProtocolError, Reason: "unexpected continuation frame"})
+			}
+		default:
+			return 0, nil, c.fail(&CloseError{Code: CloseProtocolError, Reason: "unknown opcode"})
+		}
+		if int64(len(msg)+len(payload)) > c.cfg.ReadLimit {
+			return 0, nil, c.fail(&CloseError{Code: CloseMessageTooBig, Reason: "message too big"})
+		}
+		msg = append(msg, payload...)
+		if !fin {
+			continue
+		}
+		if typ == TextMessage && !utf8.Valid(msg) {
+			return 0, nil, c.fail(&CloseError{Code: CloseInvalidPayload, Reason: "invalid UTF-8"})
+		}
+		return typ, msg, nil
+	}
+}
+
+// ReadJSON reads the next message and decodes it into v.
+func (c *Conn) ReadJSON(v any) error {
+	_, msg, err := c.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(msg, v)
+}
+
+// WriteMessage sends data as a single frame.
+func (c *Conn) WriteMessage(t MessageType, data []byte) error {
+	if t != TextMessage && t != BinaryMessage {
+		return fmt.Errorf("websocket: invalid message type %d", t)
+	}
+	return c.writeFrame(byte(t), data)
+}
+
+// WriteJSON encodes v as a text message.
+func (c *Conn) WriteJSON(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.WriteMessage(TextMessage, b)
+}
+
+// Ping sends a ping with an optional payload of at most 125 bytes; longer
+// payloads return ErrControlTooLong.
+func (c *Conn) Ping(data []byte) error {
+	if len(data) > 125 {
+		return ErrControlTooLong
+	}
+	return c.writeFrame(opPing, data)
+}
+
+// Close sends a normal close frame and closes the connection.
+func (c *Conn) Close() error {
+	return c.CloseWithReason(CloseNormal, "")
+}
+
+// CloseWithReason sends a close frame with code and reason (if not sent
+// already) and closes the connection. reason is cut at a rune boundary to
+// fit the 123 bytes a control frame leaves for it.
+func (c *Conn) CloseWithReason(code int, reason string) error {
+	if len(reason) > 123 {
+		n := 123
+		for n > 0 && !utf8.RuneStart(reason[n]) {
+			n--
+		}
+		reason = reason[:n]
+	}
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	payload = append(payload, reason...)
+	err := c.writeFrame(opClose, payload)
+	if errors.Is(err, ErrClosed) {
+		err = nil
+	}
+	c.closeOnce.Do(func() {
+		close(c.done)
+		if cerr := c.conn.Close(); err == nil {
+			err = cerr
+		}
+	})
+	return err
+}
+
+// validCloseCode reports whether a peer may send code (RFC 6455 §7.4):
+// 1004-1006 and 1015 are reserved for local use, and 1016-2999 are
+// unassigned.
+func validCloseCode(code int) bool {
+	return (code >= 1000 && code <= 1003) || (code >= 1007 && code <= 1014) || (code >= 3000 && code <= 4999)
+}
+
+// fail closes the connection after a read error, sending a close frame for
+// protocol violations.
+func (c *Conn) fail(err error) error {
+	var ce *CloseError
+	if errors.As(err, &ce) {
+		_ = c.CloseWithReason(ce.Code, ce.Reason)
+		return err
+	}
+	c.closeOnce.Do(func() {
+		close(c.done)
+		_ = c.conn.Close()
+	})
+	return err
+}
+
+func (c *Conn) pingLoop() {
+	t := time.NewTicker(c.cfg.PingInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-t.C:
+			if err := c.Ping(nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var hdr [2]byte
+	if _, err = io.ReadFull(c.br, hdr[:]); err != nil {
+		return
+	}
+	fin = hdr[0]&0x80 != 0
+	op = hdr[0] & 0x0f
+	if hdr[0]&0x70 != 0 {
+		err = &CloseError{Code: CloseProtocolError, Reason: "reserved bits set"}
+		return
+	}
+	if hdr[1]&0x80 == 0 {
+		err = &CloseError{Code: CloseProtocolError, Reason: "client frames must be masked"}
+		return
+	}
+	n := uint64(hdr[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if op >= opClose && (n > 125 || !fin) {
+		err = &CloseError{Code: CloseProtocolError, Reason: "invalid control frame"}
+		return
+	}
+	if n > uint64(c.cfg.ReadLimit) {
+		err = &CloseError{Code: CloseMessageTooBig, Reason: "message too big"}
+		return
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return
+}
+
+func (c *Conn) writeFrame(op byte, data []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closeSent {
+		return ErrClosed
+	}
+	if op == opClose {
+		c.closeSent = true
+	}
+
+	var hdr [10]byte
+	hdr[0] = 0x80 | op
+	n := 2
+	switch l := len(data); {
+	case l <= 125:
+		hdr[1] = byte(l)
+	case l <= 0xffff:
+		hdr[1] = 126
+		binary.BigEndian.PutUint16(hdr[2:], uint16(l))
+		n = 4
+	default:
+		hdr[1] = 127
+		binary.BigEndian.PutUint64(hdr[2:], uint64(l))
+		n = 10
+	}
+	_ = c.conn.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout))
+	if _, err := c.bw.Write(hdr[:n]); err != nil {
+		return err
+	}
+	if _, err := c.bw.Write(data); err != nil {
+		return err
+	}
+	return c.bw.Flush()
+}
@@ -0,0 +1,229 @@
+// Package websocket implements RFC 6455 WebSocket endpoints for zentrox:
+// the upgrade handshake, message reads and writes, ping/pong keep-alive and
+// the closing handshake.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+const (
+	headerUpgrade     = "Upgrade"
+	headerSecKey      = "Sec-WebSocket-Key"
+	headerSecVersion  = "Sec-WebSocket-Version"
+	headerSecAccept   = "Sec-WebSocket-Accept"
+	headerSecProtocol = "Sec-WebSocket-Protocol"
+
+	acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+// Handshake errors returned by Upgrade. Upgrade has already written the
+// error response when it returns one of them.
+var (
+	ErrBadHandshake = errors.New("websocket: not a websocket handshake")
+	ErrBadVersion   = errors.New("websocket: unsupported version")
+	ErrBadOrigin    = errors.New("websocket: origin not allowed")
+)
+
+// Config controls Upgrade. Zero fields take the DefaultConfig values, except
+// PingInterval, where zero disables keep-alive pings.
+type Config struct {
+	// CheckOrigin reports whether the handshake's Origin is acceptable.
+	// Default: no Origin header, or an Origin whose host equals the request Host.
+	CheckOrigin func(r *http.Request) bool
+	// Subprotocols lists supported subprotocols in order of preference.
+	Subprotocols []string
+	// ReadLimit caps the size of a received message (default 1 MiB).
+	ReadLimit int64
+	// PingInterval sends a ping this often; the peer must answer (or send
+	// anything) within PongWait or the connection is closed. Zero disables.
+	PingInterval time.Duration
+	PongWait     time.Duration
+	// WriteTimeout bounds each write (default 10s).
+	WriteTimeout time.Duration
+}
+
+// DefaultConfig returns the configuration used when Upgrade gets none.
+func DefaultConfig() Config {
+	return Config{
+		CheckOrigin:  sameOrigin,
+		ReadLimit:    1 << 20,
+		PingInterval: 30 * time.Second,
+		PongWait:     60 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+}
+
+// Handler returns a route handler that upgrades the request and runs fn with
+// the connection, closing it when fn returns:
+//
+//	app.GET("/ws", websocket.Handler(func(ws *websocket.Conn) {
+//		for {
+//			t, msg, err := ws.ReadMessage()
+//			if err != nil {
+//				return
+//			}
+//			_ = ws.WriteMessage(t, msg)
+//		}
+//	}))
+func Handler(fn func(*Conn), cfg ...Config) zentrox.Handler {
+	return func(c *zentrox.Context) {
+		ws, err := Upgrade(c, cfg...)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+		fn(ws)
+	}
+}
+
+// Upgrade performs the handshake and takes over the connection. On failure
+// it writes a 400, 403 or 426 response, aborts the chain and returns the error.
+func Upgrade(c *zentrox.Context, cfg ...Config) (*Conn, error) {
+	conf := DefaultConfig()
+	if len(cfg) > 0 {
+		conf = withDefaults(cfg[0])
+	}
+	r := c.Request
+
+	if r.Method != http.MethodGet ||
+		!headerHasToken(r.Header, zentrox.HeaderConnection, "upgrade") ||
+		!headerHasToken(r.Header, headerUpgrade, "websocket") {
+		c.Fail(http.StatusBadRequest, ErrBadHandshake.Error())
+		return nil, ErrBadHandshake
+	}
+	if r.Header.Get(headerSecVersion) != "13" {
+		c.SetHeader(headerSecVersion, "13")
+		c.Fail(http.StatusUpgradeRequired, ErrBadVersion.Error())
+		return nil, ErrBadVersion
+	}
+	key := r.Header.Get(headerSecKey)
+	if k, err := base64.StdEncoding.DecodeString(key); err != nil || len(k) != 16 {
+		c.Fail(http.StatusBadRequest, ErrBadHandshake.Error())
+		return nil, ErrBadHandshake
+	}
+	if !conf.CheckOrigin(r) {
+		c.Fail(http.StatusForbidden, ErrBadOrigin.Error())
+		return nil, ErrBadOrigin
+	}
+	proto := selectProtocol(r, conf.Subprotocols)
+
+	netConn, brw, err := http.NewResponseController(c.Writer).Hijack()
+	if err != nil {
+		c.Fail(http.StatusInternalServerError, zentrox.MsgInternalServerError)
+		return nil, err
+	}
+	// Clear deadlines inherited from the http.Server.
+	_ = netConn.SetDeadline(time.Time{})
+
+	var b strings.Builder
+	b.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	b.WriteString(headerSecAccept + ": " + acceptKey(key) + "\r\n")
+	if proto != "" {
+		b.WriteString(headerSecProtocol + ": " + proto + "\r\n")
+	}
+	b.WriteString("\r\n")
+	_ = netConn.SetWriteDeadline(time.Now().Add(conf.WriteTimeout))
+	if _, err = brw.WriteString(b.String()); err == nil {
+		err = brw.Flush()
+	}
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	_ = netConn.SetWriteDeadline(time.Time{})
+	c.Abort()
+
+	return newConn(netConn, brw, conf, proto), nil
+}
+
+func withDefaults(cfg Config) Config {
+	def := DefaultConfig()
+	if cfg.CheckOrigin == nil {
+		cfg.CheckOrigin = def.CheckOrigin
+	}
+	if cfg.ReadLimit <= 0 {
+		cfg.ReadLimit = def.ReadLimit
+	}
+	if cfg.PingInterval > 0 && cfg.PongWait <= 0 {
+		cfg.PongWait = 2 * cfg.PingInterval
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = def.WriteTimeout
+	}
+	return cfg
+}
+
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func selectProtocol(r *http.Request, supported []string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+	var offered []string
+	for _, v := range r.Header.Values(headerSecProtocol) {
+		for _, p := range strings.Split(v, ",") {
+			offered = append(offered, strings.TrimSpace(p))
+		}
+	}
+	for _, s := range supported {
+		for _, o := range offered {
+			if s == o {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get(zentrox.HeaderOrigin)
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func newConn(nc net.Conn, brw *bufio.ReadWriter, cfg Config, proto string) *Conn {
+	ws := &Conn{
+		conn:  nc,
+		br:    brw.Reader,
+		bw:    brw.Writer,
+		cfg:   cfg,
+		proto: proto,
+		done:  make(chan struct{}),
+	}
+	if cfg.PingInterval > 0 {
+		_ = nc.SetReadDeadline(time.Now().Add(cfg.PongWait))
+		go ws.pingLoop()
+	}
+	return ws
+}
@@ -0,0 +1,79 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestServeSwaggerSpec(t *testing.T) {
+	app := zentrox.NewApp()
+	app.ServeSwaggerSpec("/docs/swagger.json", []byte(`{"openapi":"3.0.0"}`))
+	fsys := fstest.MapFS{"docs/openapi.yaml": {Data: []byte("openapi: 3.1.0\n")}}
+	if _, err := app.ServeSwaggerSpecFS("/docs/openapi.yaml", fsys, "docs/openapi.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := app.ServeSwaggerSpecFS("/missing", fsys, "nope.json"); err == nil {
+		t.Fatal("want error for a missing file")
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/swagger.json", nil))
+	if w.Code != 200 || w.Body.String() != `{"openapi":"3.0.0"}` || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Fatalf("json spec: %d %q %q", w.Code, w.Header().Get("Content-Type"), w.Body.String())
+	}
+	etag := w.Header().Get("ETag")
+	req := httptest.NewRequest(http.MethodGet, "/docs/swagger.json", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("want 304, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.yaml", nil))
+	if w.Code != 200 || w.Header().Get("Content-Type") != zentrox.ContentTypeYAML {
+		t.Fatalf("yaml spec: %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	app := zentrox.NewApp()
+	app.ServeOpenAPI("/openapi.json", zentrox.OpenAPIInfo{Title: "T"})
+	app.GET("/late", func(c *zentrox.Context) {})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), `"/late"`) {
+		t.Fatalf("generated spec: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestDocsUI(t *testing.T) {
+	app := zentrox.NewApp()
+	app.ServeSwagger("/docs")
+	app.ServeRedoc("/redoc", zentrox.DocsUIOptions{Title: "Users <API>", SpecURL: "/v1/spec.json"})
+	app.ServeScalar("/scalar", zentrox.DocsUIOptions{ScriptURL: "/assets/scalar.js"})
+
+	for path, want := range map[string][]string{
+		"/docs":   {"swagger-ui-bundle.js", `url: "/openapi.json"`},
+		"/redoc":  {"<title>Users &lt;API&gt;</title>", `spec-url="/v1/spec.json"`, "redoc.standalone.js"},
+		"/scalar": {`data-url="/openapi.json"`, `src="/assets/scalar.js"`},
+	} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != 200 || w.Header().Get("Content-Type") != zentrox.ContentTypeHTMLUTF8 {
+			t.Fatalf("%s: %d %q", path, w.Code, w.Header().Get("Content-Type"))
+		}
+		for _, s := range want {
+			if !strings.Contains(w.Body.String(), s) {
+				t.Fatalf("%s: missing %q in\n%s", path, s, w.Body.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package z_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestAuthorize(t *testing.T) {
+	secret := []byte("authz-secret")
+	policy := map[string]bool{
+		"alice /api/users/:id GET":    true,
+		"alice /api/users/:id DELETE": true,
+		"bob /api/users/:id GET":      true,
+	}
+	e := middleware.EnforcerFunc(func(rvals ...any) (bool, error) {
+		if rvals[0] == "mallory" {
+			return false, errors.New("policy store down")
+		}
+		return policy[rvals[0].(string)+" "+rvals[1].(string)+" "+rvals[2].(string)], nil
+	})
+
+	app := zentrox.NewApp()
+	api := app.Scope("/api", middleware.JWT(middleware.JWTConfig{Secret: secret, SkipIfMissing: true}), middleware.Authorize(e))
+	ok := func(c *zentrox.Context) { c.SendStatus(http.StatusOK) }
+	api.GET("/users/:id", ok)
+	api.DELETE("/users/:id", ok)
+
+	cases := []struct {
+		method, sub string
+		code        int
+	}{
+		{http.MethodGet, "alice", 200},
+		{http.MethodDelete, "alice", 200},
+		{http.MethodGet, "bob", 200},
+		{http.MethodDelete, "bob", 403},
+		{http.MethodGet, "mallory", 500},
+		{http.MethodGet, "", 401},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, "/api/users/7", nil)
+		if tc.sub != "" {
+			tok, _ := middleware.SignHS256(map[string]any{"sub": tc.sub}, secret)
+			req.Header.Set("Authorization", "Bearer "+tok)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != tc.code {
+			t.Fatalf("%s as %q: want %d, got %d", tc.method, tc.sub, tc.code, w.Code)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package z_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func newBannerApp() *zentrox.App {
+	app := zentrox.NewApp()
+	app.GET("/health", func(c *zentrox.Context) {})
+	api := app.Scope("/api")
+	api.GET("/users", func(c *zentrox.Context) {})
+	api.POST("/users", func(c *zentrox.Context) {})
+	return app
+}
+
+func TestBannerAndRoutePrinting(t *testing.T) {
+	app := newBannerApp().SetVersion("1.0.0")
+	var got zentrox.BannerInfo
+	var out bytes.Buffer
+	app.SetBanner(func(b zentrox.BannerInfo) { got = b })
+	app.SetPrintRoutes(true, zentrox.PrintRoutesOptions{Format: zentrox.RoutesGrouped, Output: &out})
+
+	srv, _ := app.Start(&zentrox.ServerConfig{Addr: "127.0.0.1:0"})
+	defer srv.Close()
+
+	if got.Addr != "127.0.0.1:0" || got.Version != "1.0.0" || got.Routes != 3 {
+		t.Fatalf("banner info = %+v", got)
+	}
+	text := out.String()
+	root, api := strings.Index(text, "(root)\n"), strings.Index(text, "/api\n")
+	if root < 0 || api < 0 || root > api {
+		t.Fatalf("grouped output:\n%s", text)
+	}
+	if !strings.Contains(text[api:], "/api/users") || strings.Contains(text[api:], "/health") {
+		t.Fatalf("routes not grouped by scope:\n%s", text)
+	}
+}
+
+func TestQuietSuppressesStartupOutput(t *testing.T) {
+	app := newBannerApp()
+	called := false
+	var out bytes.Buffer
+	app.SetBanner(func(zentrox.BannerInfo) { called = true })
+	app.SetPrintRoutes(true, zentrox.PrintRoutesOptions{Output: &out})
+	app.SetQuiet(true)
+
+	srv, _ := app.Start(&zentrox.ServerConfig{Addr: "127.0.0.1:0"})
+	defer srv.Close()
+
+	if called || out.Len() > 0 {
+		t.Fatalf("quiet mode wrote output: banner=%v routes=%q", called, out.String())
+	}
+}
+
+func TestExportRoutes_GroupField(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newBannerApp().ExportRoutes(&buf, zentrox.RoutesJSON); err != nil {
+		t.Fatal(err)
+	}
+	var routes []zentrox.ExportedRoute
+	if err := json.Unmarshal(buf.Bytes(), &routes); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range routes {
+		want := ""
+		if strings.HasPrefix(r.Path, "/api") {
+			want = "/api"
+		}
+		if r.Group != want {
+			t.Fatalf("%s %s: group = %q, want %q", r.Method, r.Path, r.Group, want)
+		}
+	}
+}
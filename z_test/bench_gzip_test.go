@@ -31,3 +31,53 @@ func BenchmarkGzip_BigJSON(b *testing.B) {
 		w.Result().Body.Close()
 	}
 }
+
+func newGzipBenchApp(body []byte) *zentrox.App {
+	app := zentrox.NewApp()
+	app.Plug(middleware.Gzip())
+	app.GET("/json", func(c *zentrox.Context) {
+		c.SetHeader(zentrox.HeaderContentType, zentrox.ContentTypeJSON)
+		c.SendBytes(http.StatusOK, body)
+	})
+	return app
+}
+
+// discardWriter is a minimal ResponseWriter so the numbers reflect the
+// middleware rather than httptest.ResponseRecorder.
+type discardWriter struct{ h http.Header }
+
+func (d *discardWriter) Header() http.Header               { return d.h }
+func (d *discardWriter) Write(p []byte) (int, error)       { return len(p), nil }
+func (d *discardWriter) WriteString(s string) (int, error) { return len(s), nil }
+func (d *discardWriter) WriteHeader(int)                   {}
+
+func benchmarkGzip(b *testing.B, body []byte) {
+	app := newGzipBenchApp(body)
+	req := httptest.NewRequest(http.MethodGet, "/json", nil)
+	req.Header.Set(zentrox.HeaderAcceptEncoding, "gzip")
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		w := &discardWriter{h: http.Header{}}
+		for pb.Next() {
+			clear(w.h)
+			app.ServeHTTP(w, req)
+		}
+	})
+}
+
+// BenchmarkGzip_Pooled_* measure steady-state allocations under parallel
+// load; gzip writers and response buffers come from sync.Pools.
+func BenchmarkGzip_Pooled_Small(b *testing.B) {
+	benchmarkGzip(b, []byte(`{"ok":true}`))
+}
+
+func BenchmarkGzip_Pooled_4KB(b *testing.B) {
+	benchmarkGzip(b, []byte(`{"data":"`+strings.Repeat("abcdef0123456789", 256)+`"}`))
+}
+
+func BenchmarkGzip_Pooled_64KB(b *testing.B) {
+	benchmarkGzip(b, []byte(`{"data":"`+strings.Repeat("abcdef0123456789", 4096)+`"}`))
+}
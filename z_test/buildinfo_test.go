@@ -0,0 +1,44 @@
+package z_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestMountBuildInfo(t *testing.T) {
+	app := zentrox.NewApp()
+	app.SetVersion("1.2.3")
+	app.MountBuildInfo("/version")
+	app.MountBuildInfo("/debug/version", zentrox.BuildInfoOptions{Runtime: true})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var info zentrox.BuildInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.GoVersion != runtime.Version() || info.AppVersion != "1.2.3" {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if info.Runtime != nil {
+		t.Fatal("runtime stats included without Runtime option")
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/version", nil))
+	info = zentrox.BuildInfo{}
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Runtime == nil || info.Runtime.Goroutines == 0 || info.Runtime.NumCPU == 0 {
+		t.Fatalf("missing runtime stats: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,91 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		transitions []string
+		failing     = true
+		calls       int
+	)
+	app := zentrox.NewApp()
+	app.Plug(middleware.CircuitBreaker(middleware.CircuitBreakerConfig{
+		MinRequests: 4,
+		OpenTimeout: 50 * time.Millisecond,
+		OnStateChange: func(key string, from, to middleware.CircuitState) {
+			mu.Lock()
+			transitions = append(transitions, key+": "+from.String()+" -> "+to.String())
+			mu.Unlock()
+		},
+	}))
+	app.GET("/pay", func(c *zentrox.Context) {
+		calls++
+		if failing {
+			c.SendStatus(http.StatusInternalServerError)
+			return
+		}
+		c.SendStatus(http.StatusOK)
+	})
+	app.GET("/other", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+	get := func(p string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, p, nil))
+		return w
+	}
+
+	for i := 0; i < 4; i++ {
+		get("/pay")
+	}
+	w := get("/pay")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get(zentrox.HeaderRetryAfter) != "1" || calls != 4 {
+		t.Fatalf("open circuit: %d %v after %d calls", w.Code, w.Header(), calls)
+	}
+	if w = get("/other"); w.Code != 200 {
+		t.Fatalf("other route affected: %d", w.Code)
+	}
+
+	// After OpenTimeout a failing probe reopens, a passing one closes.
+	time.Sleep(60 * time.Millisecond)
+	if w = get("/pay"); w.Code != 500 {
+		t.Fatalf("probe: %d", w.Code)
+	}
+	if w = get("/pay"); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("reopened: %d", w.Code)
+	}
+	failing = false
+	time.Sleep(60 * time.Millisecond)
+	if w = get("/pay"); w.Code != 200 {
+		t.Fatalf("probe: %d", w.Code)
+	}
+	if w = get("/pay"); w.Code != 200 {
+		t.Fatalf("closed: %d", w.Code)
+	}
+
+	want := []string{
+		"GET /pay: closed -> open",
+		"GET /pay: open -> half-open",
+		"GET /pay: half-open -> open",
+		"GET /pay: open -> half-open",
+		"GET /pay: half-open -> closed",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(transitions) != len(want) {
+		t.Fatalf("transitions = %q", transitions)
+	}
+	for i := range want {
+		if transitions[i] != want[i] {
+			t.Fatalf("transitions = %q", transitions)
+		}
+	}
+}
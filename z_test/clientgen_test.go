@@ -0,0 +1,85 @@
+package z_test
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+type clientAddress struct {
+	City string `json:"city"`
+}
+
+type clientUser struct {
+	ID        int            `json:"id"`
+	Name      string         `json:"name"`
+	Address   *clientAddress `json:"address,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	Tags      []string       `json:"tags"`
+}
+
+type clientCreateUser struct {
+	Name string `json:"name"`
+}
+
+func TestGenerateClient(t *testing.T) {
+	app := zentrox.NewApp()
+	api := app.Scope("/api/v1")
+	h := func(c *zentrox.Context) {}
+	api.GET("/users", h).Returns([]clientUser{})
+	api.POST("/users", h).Summary("Create a user.").Body(clientCreateUser{}).Returns(clientUser{})
+	api.GET("/users/:id", h).Returns(&clientUser{})
+	api.PUT("/users/:id", h).Body(clientCreateUser{})
+	api.DELETE("/users/:id", h)
+	api.GET("/users/:id/orders/:order_id", h)
+	api.GET("/files/*path", h)
+	app.GET("/health", h)
+
+	var buf bytes.Buffer
+	if err := app.GenerateClient(&buf, "apiclient"); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+	for _, want := range []string{
+		"package apiclient",
+		"func (s *UsersService) List(ctx context.Context) ([]ClientUser, error)",
+		"func (s *UsersService) Create(ctx context.Context, body ClientCreateUser) (*ClientUser, error)",
+		"func (s *UsersService) Get(ctx context.Context, id string) (*ClientUser, error)",
+		"func (s *UsersService) Update(ctx context.Context, id string, body ClientCreateUser) error",
+		"func (s *UsersService) Delete(ctx context.Context, id string) error",
+		"func (s *UsersService) GetOrders(ctx context.Context, id string, orderID string) error",
+		"func (s *FilesService) Get(ctx context.Context, path string) error",
+		"func (s *HealthService) List(ctx context.Context) error",
+		"Address   *ClientAddress `json:\"address,omitempty\"`",
+		"CreatedAt time.Time",
+	} {
+		if !strings.Contains(src, want) {
+			t.Fatalf("generated client missing %q:\n%s", want, src)
+		}
+	}
+
+	// The generated package must compile on its own.
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/apiclient\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "client.go"), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(gobin, "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated client does not compile: %v\n%s\n%s", err, out, src)
+	}
+}
@@ -0,0 +1,56 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestClientIP(t *testing.T) {
+	newApp := func() *zentrox.App {
+		app := zentrox.NewApp()
+		app.GET("/ip", func(c *zentrox.Context) { c.String(http.StatusOK, "%s", c.ClientIP()) })
+		return app
+	}
+	ip := func(app *zentrox.App, remote string, headers map[string]string) string {
+		req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+		req.RemoteAddr = remote
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+
+	app := newApp().SetTrustedProxies("10.0.0.0/8", "192.168.1.1")
+	cases := []struct {
+		name    string
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"untrusted peer ignores headers", "198.51.100.7:1", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "198.51.100.7"},
+		{"spoofed left entry skipped", "10.0.0.1:1", map[string]string{"X-Forwarded-For": "6.6.6.6, 203.0.113.5, 192.168.1.1"}, "203.0.113.5"},
+		{"x-real-ip fallback", "10.0.0.1:1", map[string]string{"X-Real-IP": "203.0.113.6"}, "203.0.113.6"},
+		{"no headers", "10.0.0.1:1", nil, "10.0.0.1"},
+	}
+	for _, tc := range cases {
+		if got := ip(app, tc.remote, tc.headers); got != tc.want {
+			t.Errorf("%s: want %s, got %s", tc.name, tc.want, got)
+		}
+	}
+
+	app = newApp().SetTrustedProxies("10.0.0.0/8").SetClientIPHeaders("CF-Connecting-IP", "Forwarded")
+	if got := ip(app, "10.0.0.1:1", map[string]string{"Cf-Connecting-Ip": "203.0.113.9", "X-Forwarded-For": "1.1.1.1"}); got != "203.0.113.9" {
+		t.Errorf("custom header: got %s", got)
+	}
+	if got := ip(app, "10.0.0.1:1", map[string]string{"Forwarded": `for=192.0.2.60;proto=https, for="[2001:db8::1]:4711"`}); got != "2001:db8::1" {
+		t.Errorf("Forwarded: got %s", got)
+	}
+	if got := ip(app, "10.0.0.1:1", map[string]string{"X-Forwarded-For": "1.1.1.1"}); got != "10.0.0.1" {
+		t.Errorf("X-Forwarded-For is not in the configured headers: got %s", got)
+	}
+}
@@ -0,0 +1,141 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestCoalesce(t *testing.T) {
+	var calls atomic.Int32
+	entered := make(chan struct{}, 10)
+	release := make(chan struct{})
+	app := zentrox.NewApp()
+	app.GET("/products/:id", middleware.Coalesce(), func(c *zentrox.Context) {
+		calls.Add(1)
+		entered <- struct{}{}
+		<-release
+		c.SetHeader("X-Source", "db")
+		c.JSON(http.StatusOK, map[string]string{"id": c.Param("id")})
+	})
+
+	serve := func(target string, header ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			r.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*httptest.ResponseRecorder, 5)
+	wg.Add(1)
+	go func() { defer wg.Done(); results[0] = serve("/products/1?a=1&b=2") }()
+	<-entered
+	for i := 1; i < 4; i++ {
+		wg.Add(1)
+		go func() { defer wg.Done(); results[i] = serve("/products/1?b=2&a=1") }()
+	}
+	// A different user does not share the response.
+	wg.Add(1)
+	go func() { defer wg.Done(); results[4] = serve("/products/1?a=1&b=2", "Authorization", "Bearer other") }()
+	<-entered
+	time.Sleep(20 * time.Millisecond) // let the duplicates queue up
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("handler ran %d times, want 2", n)
+	}
+	for i, w := range results {
+		if w.Code != http.StatusOK || w.Body.String() != "{\"id\":\"1\"}\n" || w.Header().Get("X-Source") != "db" {
+			t.Fatalf("response %d: %d %q %v", i, w.Code, w.Body.String(), w.Header())
+		}
+	}
+
+	// Once the flight is over the next request runs the handler again.
+	if w := serve("/products/1?a=1&b=2"); w.Code != http.StatusOK || calls.Load() != 3 {
+		t.Fatalf("later request: %d, calls %d", w.Code, calls.Load())
+	}
+}
+
+func TestCoalesce_SetCookieNotShared(t *testing.T) {
+	var calls atomic.Int32
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	app := zentrox.NewApp()
+	app.GET("/session", middleware.Coalesce(), func(c *zentrox.Context) {
+		if calls.Add(1) == 1 {
+			entered <- struct{}{}
+			<-release
+		}
+		c.SetCookie(&http.Cookie{Name: "sid", Value: "x"})
+		c.String(http.StatusOK, "ok")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/session", nil))
+		}()
+		if i == 0 {
+			<-entered
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("handler ran %d times, want 2", n)
+	}
+}
+
+func TestCoalesce_HostInKey(t *testing.T) {
+	var calls atomic.Int32
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	app := zentrox.NewApp()
+	app.GET("/home", middleware.Coalesce(), func(c *zentrox.Context) {
+		calls.Add(1)
+		entered <- struct{}{}
+		<-release
+		c.String(http.StatusOK, "%s", c.Request.Host)
+	})
+
+	var wg sync.WaitGroup
+	results := make([]*httptest.ResponseRecorder, 2)
+	for i, host := range []string{"a.example.com", "b.example.com"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodGet, "/home", nil)
+			r.Host = host
+			results[i] = httptest.NewRecorder()
+			app.ServeHTTP(results[i], r)
+		}()
+		select {
+		case <-entered:
+		case <-time.After(time.Second):
+			t.Fatalf("request for %s was coalesced with another host", host)
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("handler ran %d times, want 2", n)
+	}
+	if results[0].Body.String() != "a.example.com" || results[1].Body.String() != "b.example.com" {
+		t.Fatalf("hosts shared a response: %q %q", results[0].Body.String(), results[1].Body.String())
+	}
+}
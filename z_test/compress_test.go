@@ -0,0 +1,130 @@
+package z_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+// upperWriter is a stand-in third-party coding that upper-cases the body.
+type upperWriter struct{ w io.Writer }
+
+func (u *upperWriter) Write(p []byte) (int, error) { return u.w.Write(bytes.ToUpper(p)) }
+func (u *upperWriter) Close() error                { return nil }
+func (u *upperWriter) Flush() error                { return nil }
+func (u *upperWriter) Reset(w io.Writer)           { u.w = w }
+
+func compressApp(cfg middleware.CompressConfig) *zentrox.App {
+	app := zentrox.NewApp()
+	app.Plug(middleware.Compress(cfg))
+	big := strings.Repeat("abcdef0123456789", 256)
+	app.GET("/text", func(c *zentrox.Context) { c.String(http.StatusOK, "%s", big) })
+	app.GET("/json", func(c *zentrox.Context) { c.JSON(http.StatusOK, map[string]string{"data": big}) })
+	return app
+}
+
+func getEncoded(app *zentrox.App, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set(zentrox.HeaderAcceptEncoding, accept)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	return w
+}
+
+func TestCompress_Negotiation(t *testing.T) {
+	middleware.RegisterEncoder("x-upper", func(int) (middleware.CompressWriter, error) {
+		return &upperWriter{}, nil
+	})
+	app := compressApp(middleware.CompressConfig{Encodings: []string{"x-upper", "gzip", "deflate"}})
+
+	for accept, want := range map[string]string{
+		"gzip, deflate":                      "gzip",
+		"deflate, gzip;q=0.5":                "deflate",
+		"gzip;q=0.5, x-upper;q=0.5":          "x-upper", // tie: server preference
+		"gzip;q=0, *":                        "x-upper",
+		"gzip;q=0, deflate;q=0, x-upper;q=0": "",
+		"br":                                 "",
+		"":                                   "",
+	} {
+		w := getEncoded(app, "/text", accept)
+		if got := w.Header().Get(zentrox.HeaderContentEncoding); got != want {
+			t.Errorf("Accept-Encoding %q: want %q, got %q", accept, want, got)
+		}
+	}
+
+	w := getEncoded(app, "/text", "x-upper")
+	if !strings.HasPrefix(w.Body.String(), "ABCDEF") {
+		t.Fatalf("registered encoder not used: %q", w.Body.String()[:16])
+	}
+
+	w = getEncoded(app, "/text", "deflate")
+	raw, err := io.ReadAll(flate.NewReader(w.Body))
+	if err != nil || !strings.HasPrefix(string(raw), "abcdef") {
+		t.Fatalf("deflate body: %v", err)
+	}
+}
+
+func TestCompress_TypeFilters(t *testing.T) {
+	app := compressApp(middleware.CompressConfig{
+		Encodings:    []string{"gzip"},
+		IncludeTypes: []string{"application/json"},
+		Levels:       map[string]int{"gzip": 1},
+	})
+	if enc := getEncoded(app, "/json", "gzip").Header().Get(zentrox.HeaderContentEncoding); enc != "gzip" {
+		t.Fatalf("json should be compressed, got %q", enc)
+	}
+	if enc := getEncoded(app, "/text", "gzip").Header().Get(zentrox.HeaderContentEncoding); enc != "" {
+		t.Fatalf("text is not in IncludeTypes, got %q", enc)
+	}
+
+	app = compressApp(middleware.CompressConfig{ExcludeTypes: []string{"text/"}})
+	if enc := getEncoded(app, "/text", "gzip").Header().Get(zentrox.HeaderContentEncoding); enc != "" {
+		t.Fatalf("text is excluded, got %q", enc)
+	}
+}
+
+func TestCompress_BrotliAndZstd(t *testing.T) {
+	app := compressApp(middleware.DefaultCompress())
+
+	w := getEncoded(app, "/text", "br")
+	if enc := w.Header().Get(zentrox.HeaderContentEncoding); enc != "br" {
+		t.Fatalf("br: got %q", enc)
+	}
+	raw, err := io.ReadAll(brotli.NewReader(w.Body))
+	if err != nil || !strings.HasPrefix(string(raw), "abcdef") {
+		t.Fatalf("br body: %v", err)
+	}
+
+	w = getEncoded(app, "/text", "gzip, br, zstd")
+	if enc := w.Header().Get(zentrox.HeaderContentEncoding); enc != "zstd" {
+		t.Fatalf("zstd: got %q", enc)
+	}
+	zr, err := zstd.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer zr.Close()
+	raw, err = io.ReadAll(zr)
+	if err != nil || !strings.HasPrefix(string(raw), "abcdef") {
+		t.Fatalf("zstd body: %v", err)
+	}
+}
+
+func TestCompress_UnknownEncodingPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for an unregistered coding")
+		}
+	}()
+	middleware.Compress(middleware.CompressConfig{Encodings: []string{"x-missing"}})
+}
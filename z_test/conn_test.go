@@ -0,0 +1,190 @@
+package z_test
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// startConnServer starts app on a free loopback port and waits until it
+// accepts connections.
+func startConnServer(t *testing.T, app *zentrox.App, cfg zentrox.ServerConfig) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	cfg.Addr = addr
+	srv, _ := app.Start(&cfg)
+	t.Cleanup(func() { srv.Close() })
+	for i := 0; i < 100; i++ {
+		if c, err := net.Dial("tcp", addr); err == nil {
+			c.Close()
+			return addr
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("server did not start")
+	return ""
+}
+
+func keepAliveGet(t *testing.T, conn net.Conn) (*http.Response, error) {
+	t.Helper()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.WriteString(conn, "GET /ok HTTP/1.1\r\nHost: x\r\n\r\n"); err != nil {
+		return nil, err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp, nil
+}
+
+func newConnApp() *zentrox.App {
+	app := zentrox.NewApp()
+	app.GET("/ok", func(c *zentrox.Context) { c.String(http.StatusOK, "ok") })
+	return app
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 200 && !cond(); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !cond() {
+		t.Fatal("condition not met")
+	}
+}
+
+func TestDrainingSetsConnectionClose(t *testing.T) {
+	app := newConnApp()
+	app.SetDraining(true)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if got := w.Header().Get(zentrox.HeaderConnection); got != "close" {
+		t.Fatalf("Connection = %q, want close", got)
+	}
+	app.SetDraining(false)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if got := w.Header().Get(zentrox.HeaderConnection); got != "" {
+		t.Fatalf("Connection = %q after drain ended", got)
+	}
+}
+
+func TestMaxConnsPerHost(t *testing.T) {
+	app := newConnApp()
+	addr := startConnServer(t, app, zentrox.ServerConfig{MaxConnsPerHost: 1})
+	// Let the server close the readiness probe connection first.
+	waitFor(t, func() bool { st := app.ConnStats(); return st.Accepted >= 1 && st.Active == 0 })
+
+	c1, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c1.Close()
+	if _, err := keepAliveGet(t, c1); err != nil {
+		t.Fatal(err)
+	}
+
+	c2, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c2.Close()
+	if _, err := keepAliveGet(t, c2); err == nil {
+		t.Fatal("second connection from the same host should be closed")
+	}
+	if st := app.ConnStats(); st.Rejected != 1 || st.Active != 1 {
+		t.Fatalf("stats = %+v", st)
+	}
+}
+
+func TestMaxIdleConnsEvictsOldest(t *testing.T) {
+	app := newConnApp()
+	addr := startConnServer(t, app, zentrox.ServerConfig{MaxIdleConns: 1})
+
+	c1, _ := net.Dial("tcp", addr)
+	defer c1.Close()
+	if _, err := keepAliveGet(t, c1); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return app.ConnStats().Idle == 1 })
+
+	c2, _ := net.Dial("tcp", addr)
+	defer c2.Close()
+	if _, err := keepAliveGet(t, c2); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return app.ConnStats().Evicted == 1 })
+
+	// c1 was evicted; c2 is still usable.
+	if _, err := keepAliveGet(t, c1); err == nil {
+		t.Fatal("evicted connection still served a request")
+	}
+	if _, err := keepAliveGet(t, c2); err != nil {
+		t.Fatalf("surviving connection: %v", err)
+	}
+}
+
+func TestDisableKeepAlives(t *testing.T) {
+	app := newConnApp()
+	addr := startConnServer(t, app, zentrox.ServerConfig{DisableKeepAlives: true})
+	c, _ := net.Dial("tcp", addr)
+	defer c.Close()
+	resp, err := keepAliveGet(t, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Close {
+		t.Fatal("expected Connection: close with keep-alives disabled")
+	}
+	waitFor(t, func() bool { return app.ConnStats().Accepted >= 1 })
+}
+
+func TestConfigureServerAndH2C(t *testing.T) {
+	app := newConnApp()
+	app.GET("/proto", func(c *zentrox.Context) { c.String(http.StatusOK, "%s", c.Request.Proto) })
+	var hooked *http.Server
+	app.ConfigureServer(func(s *http.Server) {
+		s.MaxHeaderBytes = 4096
+		hooked = s
+	})
+	addr := startConnServer(t, app, zentrox.ServerConfig{H2C: true})
+	if hooked == nil || hooked.MaxHeaderBytes != 4096 {
+		t.Fatal("ConfigureServer hook not applied")
+	}
+
+	p := new(http.Protocols)
+	p.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: p}}
+	resp, err := client.Get("http://" + addr + "/proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.ProtoMajor != 2 || string(body) != "HTTP/2.0" {
+		t.Fatalf("want HTTP/2 over cleartext, got %s %q", resp.Proto, body)
+	}
+
+	resp, err = http.Get("http://" + addr + "/proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 1 {
+		t.Fatalf("HTTP/1.1 clients must still work, got %s", resp.Proto)
+	}
+}
@@ -0,0 +1,132 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func serveAllocs(t *testing.T, app *zentrox.App, path string) float64 {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	w := &discardWriter{h: http.Header{}}
+	app.ServeHTTP(w, req) // warm the context pool
+	return testing.AllocsPerRun(100, func() {
+		app.ServeHTTP(w, req)
+	})
+}
+
+func TestContext_ZeroAllocs(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/ping", func(c *zentrox.Context) { c.SendStatus(http.StatusNoContent) })
+	app.GET("/u/:id/posts/:pid", func(c *zentrox.Context) {
+		if c.Param("id") == "" || c.Param("pid") == "" {
+			t.Error("missing params")
+		}
+		c.SendStatus(http.StatusNoContent)
+	})
+
+	if n := serveAllocs(t, app, "/ping"); n != 0 {
+		t.Fatalf("GET /ping: %v allocs, want 0", n)
+	}
+	if n := serveAllocs(t, app, "/u/1/posts/2"); n != 0 {
+		t.Fatalf("GET /u/1/posts/2: %v allocs, want 0", n)
+	}
+}
+
+func TestContext_QueryReparsedAfterRawQueryChange(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/q", func(c *zentrox.Context) {
+		first := c.Query("a")
+		c.Request.URL.RawQuery = "a=2"
+		c.String(http.StatusOK, "%s,%s", first, c.Query("a"))
+	})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/q?a=1", nil))
+	if got := w.Body.String(); got != "1,2" {
+		t.Fatalf("body = %q, want 1,2", got)
+	}
+}
+
+func BenchmarkContext_ParamlessGET(b *testing.B) {
+	app := zentrox.NewApp()
+	app.GET("/ping", func(c *zentrox.Context) { c.SendStatus(http.StatusNoContent) })
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	w := &discardWriter{h: http.Header{}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		app.ServeHTTP(w, req)
+	}
+}
+
+func TestContext_ZeroAllocsCommonPaths(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(func(c *zentrox.Context) {
+		c.Set("user", "u1")
+		c.Next()
+	})
+	app.GET("/text", func(c *zentrox.Context) {
+		if c.GetString("user") != "u1" {
+			t.Error("store value lost")
+		}
+		c.String(http.StatusOK, "pong")
+	})
+
+	if n := serveAllocs(t, app, "/text"); n != 0 {
+		t.Fatalf("GET /text: %v allocs, want 0", n)
+	}
+	req := httptest.NewRequest(http.MethodHead, "/text", nil)
+	w := &discardWriter{h: http.Header{}}
+	app.ServeHTTP(w, req)
+	if n := testing.AllocsPerRun(100, func() { app.ServeHTTP(w, req) }); n != 0 {
+		t.Fatalf("HEAD /text: %v allocs, want 0", n)
+	}
+}
+
+func benchmarkContext(b *testing.B, app *zentrox.App, method, path string) {
+	req := httptest.NewRequest(method, path, nil)
+	w := &discardWriter{h: http.Header{}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		app.ServeHTTP(w, req)
+	}
+}
+
+func BenchmarkContext_ParamGET(b *testing.B) {
+	app := zentrox.NewApp()
+	app.GET("/u/:id/posts/:pid", func(c *zentrox.Context) {
+		_ = c.Param("id")
+		_ = c.Param("pid")
+		c.SendStatus(http.StatusNoContent)
+	})
+	benchmarkContext(b, app, http.MethodGet, "/u/1/posts/2")
+}
+
+func BenchmarkContext_StoreAndString(b *testing.B) {
+	app := zentrox.NewApp()
+	app.Plug(func(c *zentrox.Context) {
+		c.Set("user", "u1")
+		c.Next()
+	})
+	app.GET("/text", func(c *zentrox.Context) {
+		_ = c.GetString("user")
+		c.String(http.StatusOK, "pong")
+	})
+	benchmarkContext(b, app, http.MethodGet, "/text")
+}
+
+func BenchmarkContext_HEAD(b *testing.B) {
+	app := zentrox.NewApp()
+	app.GET("/text", func(c *zentrox.Context) { c.String(http.StatusOK, "pong") })
+	benchmarkContext(b, app, http.MethodHead, "/text")
+}
+
+func BenchmarkContext_OPTIONS(b *testing.B) {
+	app := zentrox.NewApp()
+	app.GET("/text", func(c *zentrox.Context) { c.String(http.StatusOK, "pong") })
+	app.POST("/text", func(c *zentrox.Context) { c.SendStatus(http.StatusNoContent) })
+	benchmarkContext(b, app, http.MethodOptions, "/text")
+}
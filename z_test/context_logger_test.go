@@ -0,0 +1,104 @@
+package z_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestContext_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	secret := []byte("s3cr3t")
+
+	app := zentrox.NewApp()
+	app.SetLogger(slog.New(slog.NewJSONHandler(&buf, nil)))
+	app.Plug(middleware.RequestID(middleware.DefaultRequestID()))
+	app.Plug(middleware.JWT(middleware.JWTConfig{Secret: secret}))
+	app.GET("/orders/:id", func(c *zentrox.Context) {
+		c.Logger().Info("loaded order", "id", c.Param("id"))
+		c.SendStatus(http.StatusNoContent)
+	})
+
+	tok, _ := middleware.SignHS256(map[string]any{"sub": "u1", "tenant": "acme"}, secret)
+	req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	req.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+tok)
+	req.Header.Set(zentrox.XRequestID, "req-1")
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("log output: %v %q", err, buf.String())
+	}
+	want := map[string]string{
+		"msg":        "loaded order",
+		"request_id": "req-1",
+		"method":     "GET",
+		"route":      "/orders/:id",
+		"user":       "u1",
+		"tenant":     "acme",
+		"id":         "7",
+	}
+	for k, v := range want {
+		if rec[k] != v {
+			t.Errorf("%s: want %q, got %v", k, v, rec[k])
+		}
+	}
+}
+
+func TestStructuredLogger(t *testing.T) {
+	var buf bytes.Buffer
+	app := zentrox.NewApp()
+	app.Plug(middleware.RequestID(middleware.DefaultRequestID()))
+	app.Plug(middleware.StructuredLogger(slog.NewJSONHandler(&buf, nil)))
+	app.GET("/orders/:id", func(c *zentrox.Context) {
+		c.LogAttr("order_id", c.Param("id"))
+		c.String(http.StatusNotFound, "nope")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	req.Header.Set(zentrox.XRequestID, "req-2")
+	req.Header.Set("User-Agent", "test-agent")
+	req.RemoteAddr = "203.0.113.9:1234"
+	app.ServeHTTP(httptest.NewRecorder(), req)
+
+	var rec map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("log output: %v %q", err, buf.String())
+	}
+	want := map[string]any{
+		"msg":        "request",
+		"level":      "WARN",
+		"method":     "GET",
+		"path":       "/orders/7",
+		"route":      "/orders/:id",
+		"status":     float64(404),
+		"bytes":      float64(4),
+		"client_ip":  "203.0.113.9",
+		"user_agent": "test-agent",
+		"request_id": "req-2",
+		"order_id":   "7",
+	}
+	for k, v := range want {
+		if rec[k] != v {
+			t.Errorf("%s: want %v, got %v", k, v, rec[k])
+		}
+	}
+	if _, ok := rec["latency"]; !ok {
+		t.Error("missing latency")
+	}
+
+	// Per-request fields must not leak into the next pooled context.
+	buf.Reset()
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	rec = nil
+	_ = json.Unmarshal(buf.Bytes(), &rec)
+	if _, ok := rec["order_id"]; ok {
+		t.Fatal("LogAttr fields leaked across requests")
+	}
+}
@@ -0,0 +1,86 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+type ctxUser struct{ Name string }
+
+var currentUser = zentrox.NewCtxValue[*ctxUser]("user")
+
+func TestContext_GetGeneric(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(func(c *zentrox.Context) {
+		currentUser.Set(c, &ctxUser{Name: "ann"})
+		c.Set("count", 3)
+		c.Next()
+	})
+	app.GET("/", func(c *zentrox.Context) {
+		u, ok := currentUser.Get(c)
+		if !ok || u.Name != "ann" {
+			t.Errorf("typed key: %v %v", u, ok)
+		}
+		if u, ok := zentrox.Get[*ctxUser](c, currentUser.Key()); !ok || u.Name != "ann" {
+			t.Errorf("Get[*ctxUser]: %v %v", u, ok)
+		}
+		if n, ok := zentrox.Get[int](c, "count"); !ok || n != 3 {
+			t.Errorf("Get[int]: %v %v", n, ok)
+		}
+		if s, ok := zentrox.Get[string](c, "count"); ok || s != "" {
+			t.Errorf("wrong type must report false, got %q %v", s, ok)
+		}
+		if _, ok := zentrox.Get[int](c, "missing"); ok {
+			t.Error("missing key must report false")
+		}
+		c.SendStatus(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got %d", w.Code)
+	}
+}
+
+func TestContext_GetHelpers(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/", func(c *zentrox.Context) {
+		c.Set("name", "ann")
+		c.Set("age", 30)
+		c.Set("admin", true)
+
+		if c.GetString("name") != "ann" || c.GetInt("age") != 30 || !c.GetBool("admin") {
+			t.Error("typed getters returned wrong values")
+		}
+		if c.GetString("age") != "" || c.GetInt("missing") != 0 || c.GetBool("name") {
+			t.Error("typed getters must return zero values on mismatch")
+		}
+		if got := c.Keys(); len(got) != 3 || got[0] != "admin" || got[1] != "age" || got[2] != "name" {
+			t.Errorf("Keys: %v", got)
+		}
+		if c.MustGet("name") != "ann" {
+			t.Error("MustGet returned wrong value")
+		}
+		func() {
+			defer func() {
+				r := recover()
+				if msg, _ := r.(string); !strings.Contains(msg, `"missing"`) {
+					t.Errorf("MustGet panic: %v", r)
+				}
+			}()
+			c.MustGet("missing")
+		}()
+		c.SendStatus(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("got %d", w.Code)
+	}
+}
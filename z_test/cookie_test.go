@@ -0,0 +1,111 @@
+package z_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestCookieHelpers(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/set", func(c *zentrox.Context) {
+		c.SetCookie(&http.Cookie{Name: "theme", Value: "dark", Path: "/"})
+		c.SendStatus(http.StatusNoContent)
+	})
+	app.GET("/get", func(c *zentrox.Context) {
+		v, err := c.Cookie("theme")
+		if errors.Is(err, http.ErrNoCookie) {
+			c.String(http.StatusOK, "none")
+			return
+		}
+		c.String(http.StatusOK, "%s", v)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/set", nil))
+	cks := w.Result().Cookies()
+	if len(cks) != 1 || cks[0].Value != "dark" {
+		t.Fatalf("cookies %+v", cks)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/get", nil)
+	req.AddCookie(cks[0])
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Body.String() != "dark" {
+		t.Fatalf("got %q", w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/get", nil))
+	if w.Body.String() != "none" {
+		t.Fatalf("got %q", w.Body.String())
+	}
+}
+
+func TestSecureCookies(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	for _, encrypt := range []bool{false, true} {
+		app := zentrox.NewApp()
+		app.SetSecureCookies(zentrox.NewSecureCookies(secret, zentrox.SecureCookieOptions{Encrypt: encrypt}))
+		app.GET("/login", func(c *zentrox.Context) {
+			if err := c.SetSecureCookie(&http.Cookie{Name: "uid", Value: "user-42", HttpOnly: true}); err != nil {
+				t.Error(err)
+			}
+			c.SendStatus(http.StatusNoContent)
+		})
+		app.GET("/me", func(c *zentrox.Context) {
+			v, err := c.SecureCookie("uid")
+			if err != nil {
+				c.String(http.StatusUnauthorized, "%v", err)
+				return
+			}
+			c.String(http.StatusOK, "%s", v)
+		})
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+		ck := w.Result().Cookies()[0]
+		if !ck.HttpOnly || ck.Value == "user-42" {
+			t.Fatalf("encrypt=%v: cookie %+v", encrypt, ck)
+		}
+		if encrypt && strings.Contains(ck.Value, "dXNlci00Mg") { // base64 of the plain value
+			t.Fatal("encrypted cookie leaks the value")
+		}
+
+		get := func(ck *http.Cookie) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, "/me", nil)
+			req.AddCookie(ck)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			return w
+		}
+		if w := get(ck); w.Body.String() != "user-42" {
+			t.Fatalf("encrypt=%v: got %d %q", encrypt, w.Code, w.Body.String())
+		}
+		tampered := *ck
+		mid := len(ck.Value) / 2
+		flip := map[byte]byte{'A': 'B'}[ck.Value[mid]]
+		if flip == 0 {
+			flip = 'A'
+		}
+		tampered.Value = ck.Value[:mid] + string(flip) + ck.Value[mid+1:]
+		if w := get(&tampered); w.Code != http.StatusUnauthorized {
+			t.Fatalf("encrypt=%v: tampered cookie accepted", encrypt)
+		}
+		codec := zentrox.NewSecureCookies(secret, zentrox.SecureCookieOptions{Encrypt: encrypt})
+		if v, err := codec.Decode("admin", ck.Value); err == nil {
+			t.Fatalf("value must be bound to its cookie name, got %q", v)
+		}
+	}
+
+	sc := zentrox.NewSecureCookies(secret, zentrox.SecureCookieOptions{MaxAge: time.Nanosecond})
+	v, _ := sc.Encode("a", "b")
+	time.Sleep(1100 * time.Millisecond)
+	if _, err := sc.Decode("a", v); !errors.Is(err, zentrox.ErrCookieExpired) {
+		t.Fatalf("want ErrCookieExpired, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestCORSOrigins(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.CORS(middleware.CORSConfig{
+		AllowOrigins:       []string{"https://app.example.com", "https://*.example.org"},
+		AllowOriginRegexps: []*regexp.Regexp{regexp.MustCompile(`^https://pr-\d+\.preview\.dev$`)},
+		AllowOriginFunc:    func(o string) bool { return o == "https://tenant.io" },
+		AllowMethods:       []string{"GET", "POST"},
+		AllowHeaders:       []string{"Content-Type"},
+		AllowCredentials:   true,
+	}))
+	app.GET("/x", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+
+	for origin, ok := range map[string]bool{
+		"https://app.example.com":       true,
+		"https://a.example.org":         true,
+		"https://a.b.example.org":       true,
+		"https://example.org":           false,
+		"http://a.example.org":          false,
+		"https://evil.com/.example.org": false,
+		"https://pr-42.preview.dev":     true,
+		"https://pr-x.preview.dev":      false,
+		"https://tenant.io":             true,
+		"https://other.io":              false,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/x", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		got := w.Header().Get("Access-Control-Allow-Origin")
+		if (got == origin) != ok || (!ok && got != "") {
+			t.Fatalf("%s: allowed=%v, ACAO=%q", origin, ok, got)
+		}
+		if ok && w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+			t.Fatalf("%s: missing credentials header", origin)
+		}
+	}
+}
+
+func TestCORSPreflightEchoesWithCredentials(t *testing.T) {
+	app := zentrox.NewApp()
+	cfg := middleware.DefaultCORS()
+	cfg.AllowCredentials = true
+	app.Plug(middleware.CORS(cfg))
+	app.POST("/x", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodOptions, "/x", nil)
+	req.Header.Set("Origin", "https://web.example")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "content-type, x-trace")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+
+	h := w.Header()
+	if w.Code != http.StatusNoContent ||
+		h.Get("Access-Control-Allow-Origin") != "https://web.example" ||
+		h.Get("Access-Control-Allow-Headers") != "content-type, x-trace" ||
+		h.Get("Access-Control-Max-Age") != "3600" {
+		t.Fatalf("preflight: %d %v", w.Code, h)
+	}
+	if vary := strings.Join(h.Values("Vary"), ","); !strings.Contains(vary, "Access-Control-Request-Headers") {
+		t.Fatalf("Vary = %q", vary)
+	}
+
+	// Without credentials "*" is sent as is.
+	app = zentrox.NewApp()
+	app.Plug(middleware.CORS(middleware.DefaultCORS()))
+	app.POST("/x", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Access-Control-Allow-Headers") != "*" {
+		t.Fatalf("wildcard preflight: %v", w.Header())
+	}
+}
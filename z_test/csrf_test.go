@@ -0,0 +1,136 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+	"github.com/aminofox/zentrox/v2/session"
+)
+
+func csrfApp(cfg middleware.CSRFConfig, plugs ...zentrox.Handler) *zentrox.App {
+	app := zentrox.NewApp()
+	app.Plug(plugs...)
+	app.Plug(middleware.CSRF(cfg))
+	app.GET("/form", func(c *zentrox.Context) { c.String(http.StatusOK, "%s", c.CSRFToken()) })
+	app.POST("/submit", func(c *zentrox.Context) { c.String(http.StatusOK, "ok") })
+	app.POST("/api/hook", func(c *zentrox.Context) { c.String(http.StatusOK, "hook") })
+	return app
+}
+
+func TestCSRF_DoubleSubmit(t *testing.T) {
+	app := csrfApp(middleware.CSRFConfig{ExemptPaths: []string{"/api/"}})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+	var ck *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "__Host-csrf" {
+			ck = c
+		}
+	}
+	token := w.Body.String()
+	if ck == nil || !ck.Secure || ck.Domain != "" || ck.Path != "/" || token == "" || token == ck.Value {
+		t.Fatalf("expected cookie and masked token, got %+v %q", ck, token)
+	}
+
+	post := func(header, form string, withCookie bool) int {
+		req := httptest.NewRequest(http.MethodPost, "/submit", strings.NewReader(url.Values{"_csrf": {form}}.Encode()))
+		if form != "" {
+			req.Header.Set(zentrox.HeaderContentType, zentrox.ContentTypeFormURLEncoded)
+		}
+		if header != "" {
+			req.Header.Set(zentrox.HeaderXCSRFToken, header)
+		}
+		if withCookie {
+			req.AddCookie(ck)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := post("", "", true); code != http.StatusForbidden {
+		t.Fatalf("missing token: got %d", code)
+	}
+	if code := post(token, "", false); code != http.StatusForbidden {
+		t.Fatalf("missing cookie: got %d", code)
+	}
+	if code := post(token, "", true); code != http.StatusOK {
+		t.Fatalf("masked header token: got %d", code)
+	}
+	if code := post("", token, true); code != http.StatusOK {
+		t.Fatalf("form token: got %d", code)
+	}
+	if code := post(ck.Value, "", true); code != http.StatusOK {
+		t.Fatalf("raw cookie echo: got %d", code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/hook", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("exempt path: got %d", w.Code)
+	}
+}
+
+func TestCSRF_CookieName(t *testing.T) {
+	name := func(cfg middleware.CSRFConfig) string {
+		w := httptest.NewRecorder()
+		csrfApp(cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+		return w.Result().Cookies()[0].Name
+	}
+	if n := name(middleware.CSRFConfig{CookieDomain: "example.com"}); n != "_csrf" {
+		t.Fatalf("domain cookie: %s", n)
+	}
+	if n := name(middleware.CSRFConfig{CookiePath: "/app"}); n != "_csrf" {
+		t.Fatalf("path cookie: %s", n)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("__Host- cookie with a domain should panic")
+		}
+	}()
+	middleware.CSRF(middleware.CSRFConfig{CookieName: "__Host-x", CookieDomain: "example.com"})
+}
+
+func TestCSRF_SessionStorage(t *testing.T) {
+	app := csrfApp(middleware.CSRFConfig{Storage: session.CSRFStorage{}},
+		session.Middleware(session.Config{}))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/form", nil))
+	token := w.Body.String()
+	var sess *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "_csrf" {
+			t.Fatal("session storage should not set a csrf cookie")
+		}
+		if c.Name == "zentrox_session" {
+			sess = c
+		}
+	}
+	if sess == nil {
+		t.Fatal("expected session cookie")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/submit", nil)
+	req.Header.Set(zentrox.HeaderXCSRFToken, token)
+	req.AddCookie(sess)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/submit", nil)
+	req.Header.Set(zentrox.HeaderXCSRFToken, token)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("token without its session must fail, got %d", w.Code)
+	}
+}
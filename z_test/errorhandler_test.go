@@ -2,6 +2,8 @@ package z_test
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -47,3 +49,108 @@ func TestErrorHandler_Fail(t *testing.T) {
 		t.Fatalf("want 400, got %d", w.Code)
 	}
 }
+
+var errNoRows = errors.New("no rows")
+
+var errLocked = zentrox.NewError(http.StatusConflict, "order is locked")
+
+func TestErrorHandler_MapError(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.ErrorHandler(middleware.DefaultErrorHandler()))
+	app.MapError(errNoRows, http.StatusNotFound, "not found")
+	app.GET("/missing", func(c *zentrox.Context) {
+		c.SetError(fmt.Errorf("load order 7: %w", errNoRows))
+	})
+	app.GET("/locked", func(c *zentrox.Context) {
+		c.SetError(fmt.Errorf("update: %w", errLocked.WithDetails(map[string]any{"order_id": 7})))
+	})
+	app.GET("/typed", zentrox.H(func(c *zentrox.Context, _ struct{}) (struct{}, error) {
+		return struct{}{}, errNoRows
+	}))
+
+	for path, want := range map[string]httpErr{
+		"/missing": {Code: 404, Message: "not found"},
+		"/typed":   {Code: 404, Message: "not found"},
+		"/locked":  {Code: 409, Message: "order is locked", Detail: map[string]any{"order_id": float64(7)}},
+	} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		var got httpErr
+		_ = json.Unmarshal(w.Body.Bytes(), &got)
+		if w.Code != want.Code || got.Code != want.Code || got.Message != want.Message || fmt.Sprint(got.Detail) != fmt.Sprint(want.Detail) {
+			t.Fatalf("%s: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestHTTPError_Is(t *testing.T) {
+	cause := errors.New("row locked by tx 42")
+	err := fmt.Errorf("checkout: %w", errLocked.WithDetails("retry later").Wrap(cause))
+	if !errors.Is(err, errLocked) || !errors.Is(err, cause) {
+		t.Fatalf("errors.Is failed for %v", err)
+	}
+	if errors.Is(err, zentrox.NewError(http.StatusConflict, "other")) {
+		t.Fatal("matched a different error")
+	}
+	var he zentrox.HTTPError
+	if !errors.As(err, &he) || he.Code != http.StatusConflict || he.Detail != "retry later" {
+		t.Fatalf("errors.As = %+v", he)
+	}
+	if he.Error() != "order is locked: row locked by tx 42" {
+		t.Fatalf("Error() = %q", he.Error())
+	}
+}
+
+func TestErrorHandler_ProblemJSON(t *testing.T) {
+	app := zentrox.NewApp().SetProblemJSON(true)
+	app.Plug(middleware.ErrorHandler(middleware.DefaultErrorHandler()))
+	app.MapError(errNoRows, http.StatusNotFound, "order not found")
+	app.GET("/panic", func(c *zentrox.Context) { panic("boom") })
+	app.GET("/mapped", func(c *zentrox.Context) { c.SetError(errNoRows) })
+	app.GET("/fail", func(c *zentrox.Context) {
+		c.Fail(http.StatusUnprocessableEntity, "validation failed", []string{"name is required"})
+	})
+	app.GET("/custom", func(c *zentrox.Context) {
+		c.Problem(zentrox.ProblemDetails{
+			Type:   "https://example.com/probs/out-of-credit",
+			Status: http.StatusForbidden,
+			Detail: "balance is 30",
+			Ext:    map[string]any{"balance": 30},
+		})
+	})
+	app.GET("/fields", func(c *zentrox.Context) {
+		c.ProblemFields(http.StatusConflict, "", "duplicate order", "order 7 exists", "/orders/7", nil)
+	})
+
+	for _, tc := range []struct {
+		method, path string
+		status       int
+		want         map[string]any
+	}{
+		{http.MethodGet, "/panic", 500, map[string]any{"type": "about:blank", "title": "internal server error", "instance": "/panic"}},
+		{http.MethodGet, "/mapped", 404, map[string]any{"title": "order not found"}},
+		{http.MethodGet, "/fail", 422, map[string]any{"title": "validation failed", "errors": []any{"name is required"}}},
+		{http.MethodGet, "/custom", 403, map[string]any{"type": "https://example.com/probs/out-of-credit", "title": "Forbidden", "detail": "balance is 30", "balance": float64(30)}},
+		{http.MethodGet, "/fields", 409, map[string]any{"type": "about:blank", "title": "duplicate order", "detail": "order 7 exists", "instance": "/orders/7"}},
+		{http.MethodGet, "/nowhere", 404, map[string]any{"title": "Not Found", "instance": "/nowhere"}},
+		{http.MethodPost, "/panic", 405, map[string]any{"title": "Method Not Allowed"}},
+	} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.status || w.Header().Get("Content-Type") != zentrox.ContentTypeProblemJSONUTF8 {
+			t.Fatalf("%s %s: %d %s", tc.method, tc.path, w.Code, w.Header().Get("Content-Type"))
+		}
+		var got map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got["status"] != float64(tc.status) {
+			t.Fatalf("%s: status member = %v", tc.path, got["status"])
+		}
+		for k, v := range tc.want {
+			if fmt.Sprint(got[k]) != fmt.Sprint(v) {
+				t.Fatalf("%s: %s = %v, want %v (%s)", tc.path, k, got[k], v, w.Body.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestETag(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.ETag(middleware.ETagConfig{MaxSize: 64, IncludeTypes: []string{"application/json"}}))
+	app.GET("/items", func(c *zentrox.Context) { c.JSON(200, []string{"a", "b"}) })
+	app.GET("/big", func(c *zentrox.Context) { c.JSON(200, strings.Repeat("x", 100)) })
+	app.GET("/text", func(c *zentrox.Context) { c.String(200, "plain") })
+	app.GET("/missing", func(c *zentrox.Context) { c.JSON(404, "nope") })
+
+	get := func(p, inm string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, p, nil)
+		if inm != "" {
+			req.Header.Set(zentrox.HeaderIfNoneMatch, inm)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/items", "")
+	etag := w.Header().Get(zentrox.HeaderETag)
+	if w.Code != 200 || !strings.HasPrefix(etag, `W/"`) || w.Body.String() != "[\"a\",\"b\"]\n" {
+		t.Fatalf("first GET: %d %q %q", w.Code, etag, w.Body.String())
+	}
+	if w = get("/items", etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("matching If-None-Match: %d %q", w.Code, w.Body.String())
+	}
+	if w = get("/items", `"other"`); w.Code != 200 {
+		t.Fatalf("stale If-None-Match: %d", w.Code)
+	}
+
+	for _, p := range []string{"/big", "/text", "/missing"} {
+		if w = get(p, ""); w.Header().Get(zentrox.HeaderETag) != "" || w.Body.Len() == 0 {
+			t.Fatalf("%s should pass through untagged: %d %v", p, w.Code, w.Header())
+		}
+	}
+	if w = get("/big", ""); w.Code != 200 || len(w.Body.String()) != 103 {
+		t.Fatalf("large body mangled: %d %d", w.Code, w.Body.Len())
+	}
+}
@@ -0,0 +1,78 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestEvents(t *testing.T) {
+	var (
+		mu  sync.Mutex
+		log []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		log = append(log, s)
+		mu.Unlock()
+	}
+	async := make(chan zentrox.Event, 1)
+
+	app := zentrox.NewApp()
+	app.Plug(middleware.Recovery())
+	app.On(zentrox.EventRequestStart, func(e zentrox.Event) { record("start " + e.Method + " " + e.Path) })
+	app.On(zentrox.EventRequestFinish, func(e zentrox.Event) {
+		if e.Context == nil || e.Duration <= 0 {
+			t.Errorf("finish event: %+v", e)
+		}
+		record("finish " + e.Route + " " + http.StatusText(e.Status))
+	})
+	app.On(zentrox.EventRequestPanic, func(e zentrox.Event) { record("panic " + e.Data.(string)) })
+	app.On("order.created", func(e zentrox.Event) {
+		record("order " + e.Data.(map[string]string)["id"] + " by " + e.Context.GetString("user"))
+	})
+	app.OnAsync("order.created", func(e zentrox.Event) { async <- e })
+
+	app.POST("/orders/:id", func(c *zentrox.Context) {
+		c.Set("user", "ann")
+		c.Emit("order.created", map[string]string{"id": c.Param("id")})
+		c.SendStatus(http.StatusCreated)
+	})
+	app.GET("/boom", func(c *zentrox.Context) { panic("kaboom") })
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders/7", nil))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	select {
+	case e := <-async:
+		if e.Context != nil || e.Route != "/orders/:id" || e.Method != http.MethodPost {
+			t.Fatalf("async event: %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("async subscriber not called")
+	}
+
+	want := []string{
+		"start POST /orders/7",
+		"order 7 by ann",
+		"finish /orders/:id Created",
+		"start GET /boom",
+		"panic kaboom",
+		"finish /boom Internal Server Error",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(log) != len(want) {
+		t.Fatalf("events = %q", log)
+	}
+	for i := range want {
+		if log[i] != want[i] {
+			t.Fatalf("events = %q", log)
+		}
+	}
+}
@@ -73,3 +73,42 @@ func TestGzip_SkipSmallAndSkipTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestGzip_PooledWriterReuse(t *testing.T) {
+	var upstreamStatus int
+	app := zentrox.NewApp()
+	app.Plug(func(c *zentrox.Context) {
+		c.Next()
+		if rw, ok := c.Writer.(interface{ Status() int }); ok {
+			upstreamStatus = rw.Status()
+		}
+	})
+	app.Plug(middleware.Gzip())
+	app.GET("/big", func(c *zentrox.Context) {
+		c.SendBytes(http.StatusCreated, []byte(strings.Repeat("x", 4096)))
+	})
+	app.GET("/small", func(c *zentrox.Context) { c.String(http.StatusAccepted, "tiny") })
+
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/big", nil)
+		req.Header.Set(zentrox.HeaderAcceptEncoding, "gzip")
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("round %d: %v", i, err)
+		}
+		body, _ := io.ReadAll(zr)
+		if len(body) != 4096 || upstreamStatus != http.StatusCreated {
+			t.Fatalf("round %d: len=%d upstream status=%d", i, len(body), upstreamStatus)
+		}
+
+		req = httptest.NewRequest(http.MethodGet, "/small", nil)
+		req.Header.Set(zentrox.HeaderAcceptEncoding, "gzip")
+		w = httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Body.String() != "tiny" || w.Header().Get(zentrox.HeaderContentEncoding) != "" || upstreamStatus != http.StatusAccepted {
+			t.Fatalf("round %d: small response %q %v status=%d", i, w.Body.String(), w.Header(), upstreamStatus)
+		}
+	}
+}
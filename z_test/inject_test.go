@@ -0,0 +1,59 @@
+package z_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+type userRepo struct{ name string }
+
+type mailer interface{ Send(to string) string }
+
+type smtpMailer struct{}
+
+func (smtpMailer) Send(to string) string { return "sent to " + to }
+
+func TestInject(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.Recovery())
+	app.Provide("users", &userRepo{name: "primary"})
+	app.Provide("replica", &userRepo{name: "replica"})
+	app.Provide("mailer", smtpMailer{})
+	app.Provide("greeting", "hello")
+
+	app.Plug(func(c *zentrox.Context) {
+		c.Set("greeting", zentrox.InjectKey[string](c, "greeting"))
+		c.Next()
+	})
+	app.GET("/", func(c *zentrox.Context) {
+		repo := zentrox.Inject[*userRepo](c)
+		replica := zentrox.InjectKey[*userRepo](c, "replica")
+		m := zentrox.Inject[mailer](c)
+		_, ok := zentrox.TryInject[fmt.Stringer](c)
+		c.String(http.StatusOK, "%s %s %s %s %v", c.GetString("greeting"), repo.name, replica.name, m.Send("ann"), ok)
+	})
+	app.GET("/missing", func(c *zentrox.Context) {
+		_ = zentrox.Inject[*http.Client](c)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := w.Body.String(); got != "hello primary replica sent to ann false" {
+		t.Fatalf("body = %q", got)
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("missing dependency: %d", w.Code)
+	}
+
+	app.Provide("users", &userRepo{name: "swapped"})
+	if v, _ := app.Provided("users"); v.(*userRepo).name != "swapped" {
+		t.Fatal("Provide should replace the value under a key")
+	}
+}
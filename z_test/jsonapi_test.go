@@ -0,0 +1,110 @@
+package z_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+type apiPerson struct {
+	ID   int    `jsonapi:"primary,people"`
+	Name string `jsonapi:"attr,name"`
+}
+
+type apiArticle struct {
+	ID     string     `jsonapi:"primary,articles"`
+	Title  string     `jsonapi:"attr,title" validate:"required"`
+	Author *apiPerson `jsonapi:"relation,author"`
+}
+
+func TestJSONAPI_RenderWithIncluded(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/articles", func(c *zentrox.Context) {
+		c.JSONAPI(http.StatusOK, []apiArticle{
+			{ID: "1", Title: "a", Author: &apiPerson{ID: 9, Name: "ann"}},
+			{ID: "2", Title: "b", Author: &apiPerson{ID: 9, Name: "ann"}},
+		})
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles", nil))
+
+	if ct := w.Header().Get(zentrox.HeaderContentType); ct != zentrox.ContentTypeJSONAPI {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	var doc struct {
+		Data []struct {
+			Type          string `json:"type"`
+			ID            string `json:"id"`
+			Relationships map[string]struct {
+				Data zentrox.JSONAPIIdentifier `json:"data"`
+			} `json:"relationships"`
+		} `json:"data"`
+		Included []zentrox.JSONAPIResource `json:"included"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(doc.Data) != 2 || doc.Data[0].Type != "articles" {
+		t.Fatalf("unexpected data: %s", w.Body.String())
+	}
+	if got := doc.Data[1].Relationships["author"].Data; got.Type != "people" || got.ID != "9" {
+		t.Fatalf("unexpected relationship: %+v", got)
+	}
+	if len(doc.Included) != 1 || doc.Included[0].Attributes["name"] != "ann" {
+		t.Fatalf("included should contain the author once: %+v", doc.Included)
+	}
+}
+
+func TestJSONAPI_Bind(t *testing.T) {
+	app := zentrox.NewApp()
+	app.POST("/articles", func(c *zentrox.Context) {
+		var a apiArticle
+		if err := c.BindJSONAPIInto(&a); err != nil {
+			c.JSONAPIErrors(http.StatusUnprocessableEntity, zentrox.JSONAPIError{Detail: err.Error()})
+			return
+		}
+		c.String(http.StatusOK, "%s|%s|%d", a.ID, a.Title, a.Author.ID)
+	})
+
+	body := `{"data":{"type":"articles","id":"7","attributes":{"title":"hi"},
+		"relationships":{"author":{"data":{"type":"people","id":"3"}}}}}`
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/articles", strings.NewReader(body)))
+	if w.Code != http.StatusOK || w.Body.String() != "7|hi|3" {
+		t.Fatalf("unexpected: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/articles", strings.NewReader(`{"data":{"type":"articles"}}`)))
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"status":"422"`) {
+		t.Fatalf("want 422 errors document, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestJSONAPI_NullData(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/articles/none", func(c *zentrox.Context) {
+		var a *apiArticle
+		c.JSONAPI(http.StatusOK, a)
+	})
+	app.GET("/articles/bad", func(c *zentrox.Context) {
+		c.JSONAPIErrors(http.StatusBadRequest, zentrox.JSONAPIError{Detail: "bad"})
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/none", nil))
+	if got := strings.TrimSpace(w.Body.String()); got != `{"data":null}` {
+		t.Fatalf("want null data, got %d %s", w.Code, got)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/articles/bad", nil))
+	if strings.Contains(w.Body.String(), `"data"`) || !strings.Contains(w.Body.String(), `"errors"`) {
+		t.Fatalf("errors document must not carry data: %s", w.Body.String())
+	}
+}
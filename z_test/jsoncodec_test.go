@@ -0,0 +1,57 @@
+package z_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestSetJSONCodec(t *testing.T) {
+	var marshals, unmarshals int
+	app := zentrox.NewApp()
+	app.SetJSONCodec(zentrox.JSONCodec{
+		Marshal: func(v any) ([]byte, error) {
+			marshals++
+			return json.Marshal(v)
+		},
+		Unmarshal: func(data []byte, v any) error {
+			unmarshals++
+			return json.Unmarshal(data, v)
+		},
+	})
+	type in struct {
+		Name string `json:"name" validate:"required"`
+	}
+	app.POST("/echo", func(c *zentrox.Context) {
+		var body in
+		if err := c.BindInto(&body); err != nil {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, map[string]string{"hello": body.Name + " <&>"})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(`{"name":"ada"}`))
+	req.Header.Set(zentrox.HeaderContentType, zentrox.ContentTypeJSON)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "{\"hello\":\"ada \\u003c\\u0026\\u003e\"}\n" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	if marshals != 1 || unmarshals != 1 {
+		t.Fatalf("codec not used: marshals=%d unmarshals=%d", marshals, unmarshals)
+	}
+
+	// The default codec does not escape HTML.
+	std := zentrox.NewApp()
+	std.GET("/", func(c *zentrox.Context) { c.JSON(http.StatusOK, "<&>") })
+	w = httptest.NewRecorder()
+	std.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Body.String() != "\"<&>\"\n" {
+		t.Fatalf("std body %q", w.Body.String())
+	}
+}
@@ -1,8 +1,21 @@
 package z_test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -74,3 +87,397 @@ func TestJWT_ExpiredToken(t *testing.T) {
 		t.Fatalf("want 401, got %d", w.Code)
 	}
 }
+
+func TestJWT_RequireExp(t *testing.T) {
+	secret := []byte("s3cr3t")
+	app := zentrox.NewApp()
+	app.Plug(middleware.JWT(middleware.JWTConfig{Secret: secret, RequireExp: true}))
+	app.GET("/me", func(c *zentrox.Context) { c.String(200, "ok") })
+
+	for claims, want := range map[string]int{
+		`{"sub":"u1"}`: 401,
+		fmt.Sprintf(`{"sub":"u1","exp":%d}`, time.Now().Add(time.Hour).Unix()): 200,
+	} {
+		var m map[string]any
+		_ = json.Unmarshal([]byte(claims), &m)
+		tok, _ := middleware.SignHS256(m, secret)
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+tok)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != want || (want == 401 && !strings.Contains(w.Body.String(), zentrox.MsgMissingExpiry)) {
+			t.Fatalf("%s: want %d, got %d %s", claims, want, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestJWT_StandardClaims(t *testing.T) {
+	secret := []byte("s3cr3t")
+	app := zentrox.NewApp()
+	app.Plug(middleware.JWT(middleware.JWTConfig{
+		Secret:   secret,
+		Issuer:   "myapp",
+		Audience: []string{"api", "admin"},
+		Leeway:   time.Minute,
+	}))
+	app.GET("/me", func(c *zentrox.Context) { c.String(200, "ok") })
+
+	now := time.Now()
+	base := func(extra map[string]any) map[string]any {
+		claims := map[string]any{"sub": "u1", "iss": "myapp", "aud": "api"}
+		for k, v := range extra {
+			claims[k] = v
+		}
+		return claims
+	}
+
+	for name, tc := range map[string]struct {
+		claims map[string]any
+		want   int
+		msg    string
+	}{
+		"valid":             {base(map[string]any{"exp": now.Add(time.Hour).Unix()}), 200, ""},
+		"expired in leeway": {base(map[string]any{"exp": now.Add(-30 * time.Second).Unix()}), 200, ""},
+		"expired":           {base(map[string]any{"exp": now.Add(-2 * time.Minute).Unix()}), 401, zentrox.MsgTokenExpired},
+		"not yet valid":     {base(map[string]any{"nbf": now.Add(5 * time.Minute).Unix()}), 401, zentrox.MsgTokenNotYetValid},
+		"issued in future":  {base(map[string]any{"iat": now.Add(5 * time.Minute).Unix()}), 401, zentrox.MsgTokenIssuedAt},
+		"wrong issuer":      {base(map[string]any{"iss": "other"}), 401, zentrox.MsgInvalidIssuer},
+		"audience array":    {base(map[string]any{"aud": []string{"web", "admin"}}), 200, ""},
+		"wrong audience":    {base(map[string]any{"aud": []string{"web"}}), 401, zentrox.MsgInvalidAudience},
+		"missing audience":  {map[string]any{"sub": "u1", "iss": "myapp"}, 401, zentrox.MsgInvalidAudience},
+		"nbf within leeway": {base(map[string]any{"nbf": now.Add(30 * time.Second).Unix()}), 200, ""},
+		"string exp":        {base(map[string]any{"exp": "0"}), 401, zentrox.MsgInvalidToken},
+	} {
+		tok, _ := middleware.SignHS256(tc.claims, secret)
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+tok)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != tc.want || (tc.msg != "" && !strings.Contains(w.Body.String(), tc.msg)) {
+			t.Fatalf("%s: want %d %q, got %d %s", name, tc.want, tc.msg, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestJWT_Revocation(t *testing.T) {
+	secret := []byte("s3cr3t")
+	fake := startFakeRedis(t)
+	for name, revoker := range map[string]middleware.Revoker{
+		"memory": middleware.NewStoreRevoker(middleware.NewMemoryStore()),
+		"redis":  middleware.NewRedisRevoker(middleware.RedisConfig{Addr: fake.Addr()}),
+	} {
+		app := zentrox.NewApp()
+		app.Plug(middleware.JWT(middleware.JWTConfig{Secret: secret, RevocationChecker: revoker}))
+		app.GET("/me", func(c *zentrox.Context) { c.String(200, "ok") })
+		app.POST("/logout", func(c *zentrox.Context) {
+			claims, _ := c.Get("user")
+			if err := middleware.RevokeClaims(c.Request.Context(), revoker, claims.(map[string]any)); err != nil {
+				c.Fail(500, err.Error())
+				return
+			}
+			c.SendStatus(http.StatusNoContent)
+		})
+
+		now := time.Now()
+		withJTI, _ := middleware.SignHS256(map[string]any{"sub": "u1", "jti": "t-1", "exp": now.Add(time.Hour).Unix()}, secret)
+		bySubject, _ := middleware.SignHS256(map[string]any{"sub": "u2", "iat": now.Unix()}, secret)
+		other, _ := middleware.SignHS256(map[string]any{"sub": "u3", "jti": "t-3"}, secret)
+
+		do := func(method, path, tok string) int {
+			req := httptest.NewRequest(method, path, nil)
+			req.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+tok)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		if code := do(http.MethodPost, "/logout", withJTI); code != http.StatusNoContent {
+			t.Fatalf("%s: logout want 204, got %d", name, code)
+		}
+		if code := do(http.MethodGet, "/me", withJTI); code != http.StatusUnauthorized {
+			t.Fatalf("%s: revoked jti token want 401, got %d", name, code)
+		}
+		if err := middleware.Revoke(context.Background(), revoker, bySubject); err != nil {
+			t.Fatalf("%s: revoke: %v", name, err)
+		}
+		if code := do(http.MethodGet, "/me", bySubject); code != http.StatusUnauthorized {
+			t.Fatalf("%s: revoked sub+iat token want 401, got %d", name, code)
+		}
+		if code := do(http.MethodGet, "/me", other); code != 200 {
+			t.Fatalf("%s: other tokens stay valid, got %d", name, code)
+		}
+	}
+}
+
+func TestJWT_KeyRotation(t *testing.T) {
+	oldCfg := middleware.JWTConfig{
+		Keys:         map[string][]byte{"2024": []byte("old-secret")},
+		SigningKeyID: "2024",
+	}
+	oldTok, err := oldCfg.Sign(map[string]any{"sub": "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacyTok, _ := middleware.SignHS256(map[string]any{"sub": "u0"}, []byte("old-secret"))
+
+	cfg := middleware.JWTConfig{
+		Keys: map[string][]byte{
+			"2024": []byte("old-secret"),
+			"2025": []byte("new-secret"),
+		},
+		SigningKeyID: "2025",
+	}
+	newTok, err := cfg.Sign(map[string]any{"sub": "u2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknownTok, _ := middleware.SignHS256WithKeyID(map[string]any{"sub": "u3"}, "2023", []byte("old-secret"))
+	forgedTok, _ := middleware.SignHS256WithKeyID(map[string]any{"sub": "u4"}, "2025", []byte("old-secret"))
+
+	app := zentrox.NewApp()
+	app.Plug(middleware.JWT(cfg))
+	app.GET("/me", func(c *zentrox.Context) { c.String(200, "ok") })
+
+	cases := []struct {
+		name, token string
+		want        int
+		msg         string
+	}{
+		{"old kid", oldTok, 200, ""},
+		{"new kid", newTok, 200, ""},
+		{"no kid tries all keys", legacyTok, 200, ""},
+		{"unknown kid", unknownTok, 401, zentrox.MsgUnknownKeyID},
+		{"wrong key for kid", forgedTok, 401, zentrox.MsgInvalidSignature},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+tc.token)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != tc.want || !strings.Contains(w.Body.String(), tc.msg) {
+			t.Fatalf("%s: got %d %s", tc.name, w.Code, w.Body.String())
+		}
+	}
+
+	if _, err := (middleware.JWTConfig{SigningKeyID: "missing"}).Sign(nil); err != middleware.ErrUnknownKeyID {
+		t.Fatalf("expected ErrUnknownKeyID, got %v", err)
+	}
+}
+
+func TestJWT_ErrorHandler(t *testing.T) {
+	secret := []byte("s3cr3t")
+	var got error
+	app := zentrox.NewApp()
+	app.Plug(middleware.JWT(middleware.JWTConfig{
+		Secret: secret,
+		ErrorHandler: func(c *zentrox.Context, err error) {
+			got = err
+			challenge := `Bearer realm="api"`
+			if !errors.Is(err, middleware.ErrMissingToken) {
+				challenge += `, error="invalid_token"`
+			}
+			c.SetHeader(zentrox.HeaderWWWAuthenticate, challenge)
+			c.Problemf(http.StatusUnauthorized, "Unauthorized", err.Error())
+		},
+	}))
+	app.GET("/me", func(c *zentrox.Context) { c.String(200, "ok") })
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != 401 || got != middleware.ErrMissingToken || w.Header().Get(zentrox.HeaderWWWAuthenticate) != `Bearer realm="api"` {
+		t.Fatalf("missing token: %d %v %q", w.Code, got, w.Header().Get(zentrox.HeaderWWWAuthenticate))
+	}
+
+	expired, _ := middleware.SignHS256(map[string]any{"exp": time.Now().Add(-time.Hour).Unix()}, secret)
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+expired)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if !errors.Is(got, middleware.ErrTokenExpired) {
+		t.Fatalf("expected ErrTokenExpired, got %v", got)
+	}
+	if !strings.HasPrefix(w.Header().Get(zentrox.HeaderContentType), zentrox.ContentTypeProblemJSON) ||
+		!strings.Contains(w.Header().Get(zentrox.HeaderWWWAuthenticate), "invalid_token") {
+		t.Fatalf("unexpected response: %v %s", w.Header(), w.Body.String())
+	}
+}
+
+func TestJWT_EdDSA(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+
+	tok, err := middleware.SignEdDSA(map[string]any{"sub": "u1"}, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hsTok, _ := middleware.SignHS256(map[string]any{"sub": "u1"}, []byte(pub))
+
+	run := func(cfg middleware.JWTConfig, token string) (int, string) {
+		app := zentrox.NewApp()
+		app.Plug(middleware.JWT(cfg))
+		app.GET("/me", func(c *zentrox.Context) {
+			claims, _ := c.Get("user")
+			c.String(200, "%s", claims.(map[string]any)["sub"])
+		})
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+token)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	if code, body := run(middleware.JWTConfig{PublicKey: pub}, tok); code != 200 || body != "u1" {
+		t.Fatalf("valid EdDSA token: %d %s", code, body)
+	}
+	if code, body := run(middleware.JWTConfig{PublicKey: otherPub}, tok); code != 401 || !strings.Contains(body, zentrox.MsgInvalidSignature) {
+		t.Fatalf("wrong key: %d %s", code, body)
+	}
+	if code, body := run(middleware.JWTConfig{Secret: []byte("s")}, tok); code != 401 || !strings.Contains(body, zentrox.MsgUnsupportedAlg) {
+		t.Fatalf("EdDSA without PublicKey: %d %s", code, body)
+	}
+	// An HS256 token signed with the public key bytes must not pass.
+	if code, body := run(middleware.JWTConfig{PublicKey: pub}, hsTok); code != 401 || !strings.Contains(body, zentrox.MsgUnsupportedAlg) {
+		t.Fatalf("alg confusion: %d %s", code, body)
+	}
+}
+
+func runJWT(cfg middleware.JWTConfig, token string) (int, string) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.JWT(cfg))
+	app.GET("/me", func(c *zentrox.Context) {
+		claims, _ := c.Get("user")
+		c.String(200, "%s", claims.(map[string]any)["sub"])
+	})
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+token)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	return w.Code, w.Body.String()
+}
+
+func TestJWT_RS256_ES256(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	rsTok, err := middleware.SignRS256(map[string]any{"sub": "rs"}, "", rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esTok, err := middleware.SignES256(map[string]any{"sub": "es"}, "", ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code, body := runJWT(middleware.JWTConfig{PublicKey: &rsaKey.PublicKey}, rsTok); code != 200 || body != "rs" {
+		t.Fatalf("RS256: %d %s", code, body)
+	}
+	if code, body := runJWT(middleware.JWTConfig{PublicKey: &ecKey.PublicKey}, esTok); code != 200 || body != "es" {
+		t.Fatalf("ES256: %d %s", code, body)
+	}
+	// A token must not switch to an algorithm of another key type.
+	if code, body := runJWT(middleware.JWTConfig{PublicKey: &rsaKey.PublicKey}, esTok); code != 401 || !strings.Contains(body, zentrox.MsgUnsupportedAlg) {
+		t.Fatalf("ES256 token with RSA key: %d %s", code, body)
+	}
+	tampered := rsTok[:len(rsTok)-4] + "AAAA"
+	if code, body := runJWT(middleware.JWTConfig{PublicKey: &rsaKey.PublicKey}, tampered); code != 401 || !strings.Contains(body, zentrox.MsgInvalidSignature) {
+		t.Fatalf("tampered RS256: %d %s", code, body)
+	}
+}
+
+func TestJWT_JWKS(t *testing.T) {
+	k1, _ := rsa.GenerateKey(rand.Reader, 2048)
+	k2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	var fetches atomic.Int32
+	var rotated atomic.Bool
+	jwks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		keys := []map[string]string{{
+			"kty": "RSA", "kid": "rsa-1", "use": "sig",
+			"n": b64(k1.N.Bytes()), "e": b64(big.NewInt(int64(k1.E)).Bytes()),
+		}}
+		if rotated.Load() {
+			keys = append(keys, map[string]string{
+				"kty": "EC", "kid": "ec-2", "crv": "P-256",
+				"x": b64(k2.X.FillBytes(make([]byte, 32))), "y": b64(k2.Y.FillBytes(make([]byte, 32))),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	}))
+	defer jwks.Close()
+
+	set := &middleware.JWKS{URL: jwks.URL, MinRefreshInterval: time.Nanosecond}
+	cfg := middleware.JWTConfig{JWKS: set}
+
+	tok1, _ := middleware.SignRS256(map[string]any{"sub": "a"}, "rsa-1", k1)
+	for i := 0; i < 3; i++ {
+		if code, body := runJWT(cfg, tok1); code != 200 || body != "a" {
+			t.Fatalf("JWKS RS256: %d %s", code, body)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Fatalf("key set fetched %d times, want 1 (cached)", n)
+	}
+
+	// Provider rotates in a new key: the unknown kid triggers a refetch.
+	rotated.Store(true)
+	tok2, _ := middleware.SignES256(map[string]any{"sub": "b"}, "ec-2", k2)
+	if code, body := runJWT(cfg, tok2); code != 200 || body != "b" {
+		t.Fatalf("rotated key: %d %s", code, body)
+	}
+
+	tok3, _ := middleware.SignRS256(map[string]any{"sub": "c"}, "nope", k1)
+	if code, body := runJWT(cfg, tok3); code != 401 || !strings.Contains(body, zentrox.MsgUnknownKeyID) {
+		t.Fatalf("unknown kid: %d %s", code, body)
+	}
+	hsTok, _ := middleware.SignHS256(map[string]any{"sub": "x"}, []byte("s"))
+	if code, body := runJWT(cfg, hsTok); code != 401 || !strings.Contains(body, zentrox.MsgUnsupportedAlg) {
+		t.Fatalf("HS256 with JWKS: %d %s", code, body)
+	}
+
+	down := middleware.JWTConfig{JWKSURL: "http://127.0.0.1:1/jwks"}
+	if code, body := runJWT(down, tok1); code != 503 || !strings.Contains(body, zentrox.MsgKeySetFailed) {
+		t.Fatalf("unreachable JWKS: %d %s", code, body)
+	}
+}
+
+func TestJWT_TokenLookup(t *testing.T) {
+	secret := []byte("lookup")
+	app := zentrox.NewApp()
+	app.Plug(middleware.JWT(middleware.JWTConfig{
+		Secret:      secret,
+		TokenLookup: "header:Authorization, cookie:token, query:access_token",
+	}))
+	app.GET("/me", func(c *zentrox.Context) { c.String(200, "ok") })
+	tok, _ := middleware.SignHS256(map[string]any{"sub": "u1"}, secret)
+
+	cases := map[string]func(*http.Request){
+		"header": func(r *http.Request) { r.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+tok) },
+		"cookie": func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "token", Value: tok}) },
+		"query":  func(r *http.Request) { r.URL.RawQuery = "access_token=" + tok },
+		// A bad header token is not retried from the cookie.
+		"header wins": func(r *http.Request) {
+			r.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+"x.y.z")
+			r.AddCookie(&http.Cookie{Name: "token", Value: tok})
+		},
+		"none": func(r *http.Request) {},
+	}
+	want := map[string]int{"header": 200, "cookie": 200, "query": 200, "header wins": 401, "none": 401}
+	for name, setup := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		setup(req)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != want[name] {
+			t.Fatalf("%s: want %d, got %d (%s)", name, want[name], w.Code, w.Body.String())
+		}
+	}
+}
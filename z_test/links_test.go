@@ -0,0 +1,153 @@
+package z_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestBuildPath(t *testing.T) {
+	got, err := zentrox.BuildPath("/users/:id/files/*path", "id", 42, "path", "a b/c.txt", "page", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "/users/42/files/a%20b/c.txt?page=2"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+	if _, err := zentrox.BuildPath("/users/:id"); err == nil {
+		t.Fatal("missing param should fail")
+	}
+}
+
+func TestLinkBuilder(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/users/:id/posts", func(c *zentrox.Context) {})
+	app.GET("/users/:id", func(c *zentrox.Context) {
+		lb := c.Links().
+			Self().
+			Add("posts", "/users/:id/posts", "id", c.Param("id"), "page", 1).
+			Add("delete", "/users/:id", "id", c.Param("id")).Method("delete", http.MethodDelete)
+		if err := lb.Err(); err != nil {
+			c.Fail(http.StatusInternalServerError, err.Error())
+			return
+		}
+		if c.Links().Add("x", "/missing/:id", "id", 1).Err() == nil {
+			c.Fail(http.StatusInternalServerError, "unregistered route should fail")
+			return
+		}
+		c.JSON(http.StatusOK, map[string]any{"_links": lb.Build()})
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/7?v=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Links zentrox.Links `json:"_links"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	if body.Links["self"].Href != "/users/7?v=1" ||
+		body.Links["posts"].Href != "/users/7/posts?page=1" ||
+		body.Links["delete"].Method != http.MethodDelete {
+		t.Fatalf("unexpected links: %+v", body.Links)
+	}
+}
+
+func TestURLFor_NamedRoutes(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/users/:id", func(c *zentrox.Context) {
+		loc, err := c.URLFor("user.posts", "id", c.Param("id"), "page", 2)
+		if err != nil {
+			t.Error(err)
+		}
+		c.SetHeader("Location", loc)
+		c.JSON(http.StatusOK, c.Links().Route("self", "user.show", "id", c.Param("id")).Build())
+	}).Name("user.show")
+	app.Scope("/users").GET("/:id/posts", func(c *zentrox.Context) {}).Name("user.posts")
+
+	if got, err := app.URLFor("user.show", "id", 42); err != nil || got != "/users/42" {
+		t.Fatalf("URLFor = %q, %v", got, err)
+	}
+	if _, err := app.URLFor("missing"); err == nil {
+		t.Fatal("expected error for unknown route name")
+	}
+	if _, err := app.URLFor("user.show"); err == nil {
+		t.Fatal("expected error for missing param")
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	if loc := w.Header().Get("Location"); loc != "/users/7/posts?page=2" {
+		t.Fatalf("Location = %q", loc)
+	}
+	if !strings.Contains(w.Body.String(), `"href":"/users/7"`) {
+		t.Fatalf("links = %s", w.Body.String())
+	}
+
+	var names []string
+	for _, ri := range app.ListRoutes() {
+		if ri.Name != "" {
+			names = append(names, ri.Name)
+		}
+	}
+	if len(names) != 2 {
+		t.Fatalf("route names in ListRoutes = %v", names)
+	}
+}
+
+func TestRouteName_DuplicatePanics(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/a", func(c *zentrox.Context) {}).Name("x")
+	app.POST("/a", func(c *zentrox.Context) {}).Name("x") // same pattern is fine
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for duplicate route name")
+		}
+	}()
+	app.GET("/b", func(c *zentrox.Context) {}).Name("x")
+}
+
+func TestRedirect(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/orders/:id", func(c *zentrox.Context) {}).Name("order")
+	app.POST("/orders", func(c *zentrox.Context) {
+		if err := c.RedirectToRoute(http.StatusSeeOther, "order", "id", 9); err != nil {
+			t.Error(err)
+		}
+	})
+	app.GET("/old", func(c *zentrox.Context) { c.Redirect(http.StatusMovedPermanently, "/new") })
+	app.GET("/missing", func(c *zentrox.Context) {
+		if err := c.RedirectToRoute(http.StatusFound, "nope"); err == nil {
+			t.Error("expected error for unknown route")
+		}
+		c.SendStatus(http.StatusNoContent)
+	})
+	app.GET("/bad", func(c *zentrox.Context) { c.Redirect(http.StatusOK, "/x") })
+
+	for path, want := range map[string]struct {
+		method, loc string
+		code        int
+	}{
+		"/orders":  {http.MethodPost, "/orders/9", http.StatusSeeOther},
+		"/old":     {http.MethodGet, "/new", http.StatusMovedPermanently},
+		"/missing": {http.MethodGet, "", http.StatusNoContent},
+	} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(want.method, path, nil))
+		if w.Code != want.code || w.Header().Get("Location") != want.loc {
+			t.Fatalf("%s: got %d %q", path, w.Code, w.Header().Get("Location"))
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("non-redirect status should panic")
+		}
+	}()
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bad", nil))
+}
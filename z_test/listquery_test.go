@@ -0,0 +1,69 @@
+package z_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+var listCfg = zentrox.ListQueryConfig{
+	SortFields: []string{"created_at", "name"},
+	FilterFields: map[string]zentrox.FilterField{
+		"status": {Type: zentrox.FieldString, Ops: []zentrox.FilterOp{zentrox.OpEq, zentrox.OpIn}},
+		"price":  {Type: zentrox.FieldFloat},
+	},
+}
+
+func parseList(t *testing.T, target string) (zentrox.ListQuery, error) {
+	t.Helper()
+	app := zentrox.NewApp()
+	var (
+		lq  zentrox.ListQuery
+		err error
+	)
+	app.GET("/orders", func(c *zentrox.Context) {
+		lq, err = zentrox.ParseListQuery(c, listCfg)
+		c.SendStatus(http.StatusOK)
+	})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	return lq, err
+}
+
+func TestParseListQuery_SortAndFilter(t *testing.T) {
+	lq, err := parseList(t, "/orders?sort=-created_at,name&filter[status]=eq:open&filter[price]=gte:100")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lq.Sort) != 2 || lq.Sort[0] != (zentrox.SortField{Field: "created_at", Desc: true}) || lq.Sort[1].Desc {
+		t.Fatalf("unexpected sort: %+v", lq.Sort)
+	}
+	if len(lq.Filters) != 2 {
+		t.Fatalf("want 2 filters, got %+v", lq.Filters)
+	}
+	price := lq.Filters[0]
+	if price.Field != "price" || price.Op != zentrox.OpGte || price.Value != float64(100) {
+		t.Fatalf("unexpected price filter: %+v", price)
+	}
+	status := lq.Filters[1]
+	if status.Field != "status" || status.Op != zentrox.OpEq || status.Value != "open" {
+		t.Fatalf("unexpected status filter: %+v", status)
+	}
+}
+
+func TestParseListQuery_RejectsUnknown(t *testing.T) {
+	for _, target := range []string{
+		"/orders?sort=password",
+		"/orders?filter[secret]=x",
+		"/orders?filter[status]=gt:open",
+		"/orders?filter[price]=abc",
+	} {
+		_, err := parseList(t, target)
+		var he zentrox.HTTPError
+		if !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+			t.Fatalf("%s: want 400 HTTPError, got %v", target, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestLoginThrottle(t *testing.T) {
+	cfg := middleware.DefaultLoginThrottle()
+	cfg.AccountFunc = middleware.AccountFromField("email")
+	cfg.FreeAttempts = 2
+	cfg.MaxAccountFailures = 4
+
+	app := zentrox.NewApp()
+	app.POST("/login", middleware.LoginThrottle(cfg), func(c *zentrox.Context) {
+		var in struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+		if err := c.BindJSONInto(&in); err != nil || in.Password != "right" {
+			c.Fail(http.StatusUnauthorized, "bad credentials")
+			return
+		}
+		c.SendStatus(http.StatusNoContent)
+	})
+
+	login := func(email, password, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/login",
+			strings.NewReader(`{"email":"`+email+`","password":"`+password+`"}`))
+		req.Header.Set(zentrox.HeaderContentType, zentrox.ContentTypeJSON)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := login("ann@example.com", "wrong", "10.0.0.1"); w.Code != http.StatusUnauthorized {
+			t.Fatalf("free attempt %d: want 401, got %d", i, w.Code)
+		}
+	}
+	if w := login("ann@example.com", "right", "10.0.0.1"); w.Code != http.StatusNoContent {
+		t.Fatalf("body must reach the handler intact, got %d", w.Code)
+	}
+
+	for _, ip := range []string{"10.1.0.1", "10.1.0.2", "10.1.0.3"} {
+		login("ann@example.com", "wrong", ip)
+	}
+	w := login("ann@example.com", "right", "10.0.0.2")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get(zentrox.HeaderRetryAfter) == "" {
+		t.Fatalf("account should be backed off from any IP, got %d", w.Code)
+	}
+	if w := login("bob@example.com", "right", "10.0.0.2"); w.Code != http.StatusNoContent {
+		t.Fatalf("other accounts are unaffected, got %d", w.Code)
+	}
+}
+
+func TestLoginThrottleLockout(t *testing.T) {
+	store := middleware.NewMemoryStore()
+	app := zentrox.NewApp()
+	app.POST("/login", middleware.LoginThrottle(middleware.LoginThrottleConfig{
+		Store:           store,
+		FreeAttempts:    5,
+		MaxIPFailures:   2,
+		LockoutDuration: time.Hour,
+	}), func(c *zentrox.Context) {
+		c.SendStatus(http.StatusUnauthorized)
+	})
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("want lockout, got %d", w.Code)
+	}
+	if ra := w.Header().Get(zentrox.HeaderRetryAfter); ra != "3600" {
+		t.Fatalf("want Retry-After 3600, got %q", ra)
+	}
+}
+
+func TestRateLimitWithStore(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.RateLimit(middleware.RateLimitConfig{Rate: 1, Burst: 2, Store: middleware.NewMemoryStore()}))
+	app.GET("/", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		codes = append(codes, w.Code)
+	}
+	if codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("unexpected codes %v", codes)
+	}
+}
@@ -0,0 +1,74 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestMethodOverride(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Pre(middleware.MethodOverride(middleware.DefaultMethodOverride()))
+	app.POST("/items/:id", func(c *zentrox.Context) { c.String(200, "post") })
+	app.PUT("/items/:id", func(c *zentrox.Context) { c.String(200, "put") })
+	app.DELETE("/items/:id", func(c *zentrox.Context) {
+		c.String(200, "delete %s", c.Request.PostFormValue("name"))
+	})
+
+	cases := []struct {
+		name   string
+		method string
+		header string
+		form   string
+		want   string
+	}{
+		{"header", http.MethodPost, "PUT", "", "put"},
+		{"form field", http.MethodPost, "", "_method=delete&name=x", "delete x"},
+		{"header wins", http.MethodPost, "PUT", "_method=DELETE", "put"},
+		{"unknown method ignored", http.MethodPost, "TRACE", "", "post"},
+		{"no override", http.MethodPost, "", "", "post"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, "/items/1", strings.NewReader(tc.form))
+		if tc.form != "" {
+			req.Header.Set(zentrox.HeaderContentType, zentrox.ContentTypeFormURLEncoded)
+		}
+		if tc.header != "" {
+			req.Header.Set(zentrox.HeaderXHTTPMethodOverride, tc.header)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Body.String() != tc.want {
+			t.Fatalf("%s: want %q, got %d %q", tc.name, tc.want, w.Code, w.Body.String())
+		}
+	}
+
+	// Only POST may be overridden.
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+	req.Header.Set(zentrox.HeaderXHTTPMethodOverride, "DELETE")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("GET override: want 405, got %d", w.Code)
+	}
+}
+
+func TestPreAbortStopsRouting(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Pre(func(c *zentrox.Context) {
+		if c.Request.URL.Path == "/blocked" {
+			c.Fail(http.StatusForbidden, zentrox.MsgForbidden)
+		}
+	})
+	app.GET("/blocked", func(c *zentrox.Context) { c.String(200, "reached") })
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/blocked", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("want 403, got %d %s", w.Code, w.Body.String())
+	}
+}
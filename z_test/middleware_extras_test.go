@@ -1,8 +1,11 @@
 package z_test
 
 import (
+	"errors"
+	"expvar"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -83,6 +86,65 @@ func TestTimeoutMiddleware(t *testing.T) {
 	}
 }
 
+func TestTimeoutMiddleware_SuppressesLateWrites(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
+		Duration:   20 * time.Millisecond,
+		StatusCode: http.StatusServiceUnavailable,
+	}))
+	stray := make(chan error, 1)
+	app.GET("/late", func(c *zentrox.Context) {
+		<-c.Done()
+		c.String(http.StatusOK, "too late")
+		w := c.Writer
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			_, err := w.Write([]byte("stray"))
+			stray <- err
+		}()
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/late", nil))
+	if w.Code != http.StatusServiceUnavailable || strings.Contains(w.Body.String(), "too late") {
+		t.Fatalf("want clean 503, got %d %q", w.Code, w.Body.String())
+	}
+	if err := <-stray; !errors.Is(err, http.ErrHandlerTimeout) {
+		t.Fatalf("stray write after timeout: want ErrHandlerTimeout, got %v", err)
+	}
+	if strings.Contains(w.Body.String(), "stray") {
+		t.Fatal("stray write reached the client")
+	}
+}
+
+func TestTimeoutMiddleware_RouteOverride(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.Timeout(20 * time.Millisecond))
+	work := func(c *zentrox.Context) {
+		select {
+		case <-time.After(40 * time.Millisecond):
+			c.String(http.StatusOK, "done")
+		case <-c.Done():
+		}
+	}
+	app.GET("/report", work).Timeout(time.Second)
+	reports := app.Scope("/exports").Timeout(time.Second)
+	reports.GET("/csv", work)
+	app.GET("/default", work)
+
+	for path, want := range map[string]int{
+		"/report":      http.StatusOK,
+		"/exports/csv": http.StatusOK,
+		"/default":     http.StatusGatewayTimeout,
+	} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Fatalf("%s: want %d, got %d", path, want, w.Code)
+		}
+	}
+}
+
 func TestHTTPProtection_BlocksMethodAndLongURI(t *testing.T) {
 	app := zentrox.NewApp()
 	app.Plug(middleware.HTTPProtection(middleware.HTTPProtectionConfig{
@@ -203,6 +265,237 @@ func TestConcurrencyLimit_QueueTimeout(t *testing.T) {
 	}
 }
 
+func TestConcurrencyLimit_PerRoute(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.ConcurrencyLimit(middleware.ConcurrencyLimitConfig{
+		MaxConcurrent: 1,
+		PerRoute:      true,
+	}))
+
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	done := make(chan struct{})
+	app.GET("/a/:id", func(c *zentrox.Context) {
+		entered <- struct{}{}
+		<-release
+		c.SendStatus(http.StatusOK)
+	})
+	app.GET("/b", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+
+	go func() {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a/1", nil))
+		close(done)
+	}()
+	select {
+	case <-entered:
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("first request did not enter handler")
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/a/2", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("same route: want 503, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/b", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("other route has its own slots, got %d", w.Code)
+	}
+
+	close(release)
+	<-done
+}
+
+func TestMaxConcurrent_Route(t *testing.T) {
+	app := zentrox.NewApp()
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	done := make(chan struct{})
+	app.GET("/search", middleware.MaxConcurrent(1, 10*time.Millisecond), func(c *zentrox.Context) {
+		entered <- struct{}{}
+		<-release
+		c.SendStatus(http.StatusOK)
+	})
+	app.GET("/other", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+
+	go func() {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/search", nil))
+		close(done)
+	}()
+	<-entered
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("want 503, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/other", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unlimited route: got %d", w.Code)
+	}
+	close(release)
+	<-done
+}
+
+func TestBulkhead_IsolatesScope(t *testing.T) {
+	app := zentrox.NewApp()
+	reports := app.Scope("/reports", middleware.Bulkhead(middleware.DefaultBulkhead(1)))
+
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	done := make(chan struct{})
+	reports.GET("/a", func(c *zentrox.Context) {
+		entered <- struct{}{}
+		<-release
+		c.SendStatus(http.StatusOK)
+	})
+	reports.GET("/b", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+	app.GET("/other", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+
+	go func() {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports/a", nil))
+		close(done)
+	}()
+	select {
+	case <-entered:
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("first request did not enter handler")
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports/b", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("scope bulkhead full: want 429, got %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/other", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("routes outside the bulkhead are unaffected, got %d", w.Code)
+	}
+
+	close(release)
+	<-done
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports/b", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("slot should be released, got %d", w.Code)
+	}
+}
+
+func TestBulkhead_PerRoute(t *testing.T) {
+	app := zentrox.NewApp()
+	cfg := middleware.DefaultBulkhead(1)
+	cfg.PerRoute = true
+	reports := app.Scope("/reports", middleware.Bulkhead(cfg))
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	reports.GET("/a", func(c *zentrox.Context) {
+		close(entered)
+		<-release
+		c.SendStatus(http.StatusOK)
+	})
+	reports.GET("/b", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+
+	go func() {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports/a", nil))
+		close(done)
+	}()
+	<-entered
+	for path, want := range map[string]int{"/reports/a": http.StatusTooManyRequests, "/reports/b": http.StatusOK} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Fatalf("%s: want %d, got %d", path, want, w.Code)
+		}
+	}
+	close(release)
+	<-done
+}
+
+func TestQueue_HoldsThenRejects(t *testing.T) {
+	depth := new(expvar.Int)
+	cfg := middleware.DefaultQueue()
+	cfg.MaxConcurrent = 1
+	cfg.MaxQueue = 1
+	cfg.MaxWait = time.Second
+	cfg.Depth = depth
+
+	app := zentrox.NewApp()
+	app.Plug(middleware.Queue(cfg))
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	app.GET("/work", func(c *zentrox.Context) {
+		entered <- struct{}{}
+		<-release
+		c.SendStatus(http.StatusOK)
+	})
+
+	codes := make(chan int, 2)
+	run := func() {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+		codes <- w.Code
+	}
+	go run()
+	<-entered
+	go run()
+	deadline := time.Now().Add(time.Second)
+	for depth.Value() != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if depth.Value() != 1 {
+		t.Fatalf("second request should be queued, depth %d", depth.Value())
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get(zentrox.HeaderRetryAfter) != "1" {
+		t.Fatalf("full queue: want 429 with Retry-After, got %d %q", w.Code, w.Header().Get(zentrox.HeaderRetryAfter))
+	}
+
+	close(release)
+	for i := 0; i < 2; i++ {
+		if code := <-codes; code != http.StatusOK {
+			t.Fatalf("queued request want 200, got %d", code)
+		}
+	}
+	if depth.Value() != 0 {
+		t.Fatalf("queue should drain, depth %d", depth.Value())
+	}
+}
+
+func TestQueue_MaxWait(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.Queue(middleware.QueueConfig{MaxConcurrent: 1, MaxQueue: 4, MaxWait: 20 * time.Millisecond, Depth: new(expvar.Int)}))
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	app.GET("/work", func(c *zentrox.Context) {
+		if c.Query("hold") != "" {
+			close(entered)
+			<-release
+		}
+		c.SendStatus(http.StatusOK)
+	})
+	done := make(chan struct{})
+	go func() {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/work?hold=1", nil))
+		close(done)
+	}()
+	<-entered
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("want 429 after max wait, got %d", w.Code)
+	}
+	close(release)
+	<-done
+}
+
 func TestDefaultAPIHardening_Preset(t *testing.T) {
 	app := zentrox.NewApp()
 	app.Plug(middleware.DefaultAPIHardening()...)
@@ -256,3 +549,71 @@ func TestDefaultAPIHardeningFast_Preset(t *testing.T) {
 		t.Fatalf("trace should be blocked by fast preset, got %d", w2.Code)
 	}
 }
+
+func TestQueue_Defaults(t *testing.T) {
+	hold := func(cfg middleware.QueueConfig) (app *zentrox.App, release func()) {
+		app = zentrox.NewApp()
+		app.Plug(middleware.Queue(cfg))
+		entered := make(chan struct{})
+		ch := make(chan struct{})
+		app.GET("/work", func(c *zentrox.Context) {
+			if c.Query("hold") != "" {
+				close(entered)
+				<-ch
+			}
+			c.SendStatus(http.StatusOK)
+		})
+		done := make(chan struct{})
+		go func() {
+			app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/work?hold=1", nil))
+			close(done)
+		}()
+		<-entered
+		return app, func() { close(ch); <-done }
+	}
+
+	// MaxQueue left at zero still queues.
+	app, release := hold(middleware.QueueConfig{MaxConcurrent: 1, MaxWait: time.Second})
+	code := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+		code <- w.Code
+	}()
+	time.Sleep(20 * time.Millisecond)
+	release()
+	if c := <-code; c != http.StatusOK {
+		t.Fatalf("default MaxQueue: want queued 200, got %d", c)
+	}
+
+	// A negative MaxQueue rejects at once.
+	app, release = hold(middleware.QueueConfig{MaxConcurrent: 1, MaxQueue: -1, MaxWait: time.Second})
+	start := time.Now()
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/work", nil))
+	release()
+	if w.Code != http.StatusTooManyRequests || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("MaxQueue -1: want immediate 429, got %d after %s", w.Code, time.Since(start))
+	}
+}
+
+func TestQueue_DepthNames(t *testing.T) {
+	before := 0
+	middleware.QueueDepthVar().Do(func(expvar.KeyValue) { before++ })
+	middleware.Queue(middleware.QueueConfig{})
+	middleware.Queue(middleware.QueueConfig{})
+	name := "reports-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	middleware.Queue(middleware.QueueConfig{Name: name})
+	after := 0
+	middleware.QueueDepthVar().Do(func(expvar.KeyValue) { after++ })
+	if after != before+3 {
+		t.Fatalf("want 3 new gauges, got %d", after-before)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicate Queue name should panic")
+		}
+	}()
+	middleware.Queue(middleware.QueueConfig{Name: name})
+}
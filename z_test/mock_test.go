@@ -0,0 +1,55 @@
+package z_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+type mockUser struct {
+	ID    int      `json:"id" example:"42"`
+	Email string   `json:"email" example:"ann@example.com"`
+	Name  string   `json:"name"`
+	Tags  []string `json:"tags"`
+}
+
+func TestMockMode(t *testing.T) {
+	app := zentrox.NewApp()
+	handlerCalled := false
+	h := func(c *zentrox.Context) { handlerCalled = true }
+	app.GET("/users", h)
+	app.GET("/users/:id", h)
+	app.POST("/orders", h)
+
+	app.MockFake(http.MethodGet, "/users", http.StatusOK, []mockUser{})
+	app.MockExample(http.MethodGet, "/users/:id", http.StatusOK, map[string]any{"id": 1})
+	app.SetMockMode(true)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	var users []map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &users); err != nil || len(users) != 1 {
+		t.Fatalf("unexpected fake list: %s", w.Body.String())
+	}
+	if users[0]["id"] != float64(42) || users[0]["email"] != "ann@example.com" || users[0]["name"] != "string" {
+		t.Fatalf("unexpected fake user: %+v", users[0])
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/9", nil))
+	if w.Body.String() != "{\"id\":1}\n" {
+		t.Fatalf("unexpected example: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", nil))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("want 201 for POST without example, got %d", w.Code)
+	}
+	if handlerCalled {
+		t.Fatal("handlers must not run in mock mode")
+	}
+}
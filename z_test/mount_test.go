@@ -0,0 +1,66 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestMountHandler(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(tagger())
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Method + " " + r.URL.Path))
+	})
+	app.Mount("/legacy", mux)
+	app.Mount("/raw/", mux, zentrox.MountOptions{KeepPrefix: true, Isolated: true})
+
+	cases := []struct{ method, path, body, tagged string }{
+		{http.MethodGet, "/legacy", "GET /", "1"},
+		{http.MethodPost, "/legacy/a/b", "POST /a/b", "1"},
+		{http.MethodOptions, "/legacy/x", "OPTIONS /x", "1"},
+		{http.MethodGet, "/raw/x", "GET /raw/x", ""},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Body.String() != tc.body || w.Header().Get("X-Tagged") != tc.tagged {
+			t.Fatalf("%s %s: body=%q tagged=%q", tc.method, tc.path, w.Body.String(), w.Header().Get("X-Tagged"))
+		}
+	}
+}
+
+func TestMountApp(t *testing.T) {
+	sub := zentrox.NewApp()
+	sub.GET("/users/:id", func(c *zentrox.Context) {
+		c.String(http.StatusOK, "user %s", c.Param("id"))
+	}).Name("v2.user")
+
+	app := zentrox.NewApp()
+	app.MountApp("/v2", sub)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/users/7", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "user 7" {
+		t.Fatalf("got %d %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v2/nope", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("want 404 from sub app, got %d", w.Code)
+	}
+
+	if u, err := app.URLFor("v2.user", "id", 7); err != nil || u != "/v2/users/7" {
+		t.Fatalf("URLFor = %q, %v", u, err)
+	}
+	found := false
+	for _, ri := range app.ListRoutes() {
+		found = found || (ri.Method == http.MethodGet && ri.Path == "/v2/users/:id")
+	}
+	if !found {
+		t.Fatalf("mounted route not listed: %+v", app.ListRoutes())
+	}
+}
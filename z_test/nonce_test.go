@@ -0,0 +1,46 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestNonce(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.Nonce(middleware.DefaultNonce()))
+	app.POST("/partner/orders", func(c *zentrox.Context) { c.SendStatus(http.StatusCreated) })
+
+	send := func(nonce string, ts time.Time) int {
+		req := httptest.NewRequest(http.MethodPost, "/partner/orders", nil)
+		if nonce != "" {
+			req.Header.Set(zentrox.HeaderXNonce, nonce)
+		}
+		req.Header.Set(zentrox.HeaderXTimestamp, strconv.FormatInt(ts.Unix(), 10))
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	now := time.Now()
+	if code := send("n-1", now); code != http.StatusCreated {
+		t.Fatalf("fresh request: want 201, got %d", code)
+	}
+	if code := send("n-1", now); code != http.StatusUnauthorized {
+		t.Fatalf("replay: want 401, got %d", code)
+	}
+	if code := send("n-2", now.Add(-10*time.Minute)); code != http.StatusUnauthorized {
+		t.Fatalf("stale timestamp: want 401, got %d", code)
+	}
+	if code := send("", now); code != http.StatusUnauthorized {
+		t.Fatalf("missing nonce: want 401, got %d", code)
+	}
+	if code := send("n-3", now.Add(time.Minute)); code != http.StatusCreated {
+		t.Fatalf("small clock skew should pass, got %d", code)
+	}
+}
@@ -0,0 +1,97 @@
+package z_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+type apiAddress struct {
+	City string `json:"city"`
+}
+
+type apiUser struct {
+	ID      int64       `json:"id"`
+	Name    string      `json:"name" validate:"required,min=3,max=50"`
+	Role    string      `json:"role,omitempty" validate:"oneof=admin user"`
+	Address *apiAddress `json:"address,omitempty"`
+	Friends []apiUser   `json:"friends,omitempty"`
+	secret  string
+}
+
+func TestGenerateOpenAPI(t *testing.T) {
+	app := zentrox.NewApp()
+	noop := func(c *zentrox.Context) {}
+	app.POST("/users", noop).Doc(zentrox.NewRouteDoc().
+		Summary("Create a user").
+		Tags("users").
+		Request(apiUser{}).
+		Response(http.StatusCreated, apiUser{}).
+		Response(http.StatusConflict, nil))
+	app.GET("/users/:id", noop).Name("user.show").Returns((*apiUser)(nil))
+	app.GET("/users", noop).Doc(zentrox.NewRouteDoc().Query("page", 0, "Page number"))
+
+	b, err := app.GenerateOpenAPI(zentrox.OpenAPIInfo{Title: "Users", Version: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		OpenAPI string                               `json:"openapi"`
+		Paths   map[string]map[string]map[string]any `json:"paths"`
+		Comps   struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Fatalf("openapi = %q", doc.OpenAPI)
+	}
+
+	create := doc.Paths["/users"]["post"]
+	if create["summary"] != "Create a user" || create["requestBody"] == nil {
+		t.Fatalf("post /users: %v", create)
+	}
+	resp := create["responses"].(map[string]any)
+	if resp["201"] == nil || resp["409"] == nil || resp["200"] != nil {
+		t.Fatalf("responses: %v", resp)
+	}
+
+	show := doc.Paths["/users/{id}"]["get"]
+	if show["operationId"] != "user.show" {
+		t.Fatalf("get /users/{id}: %v", show)
+	}
+	params := show["parameters"].([]any)
+	if p := params[0].(map[string]any); p["name"] != "id" || p["in"] != "path" || p["required"] != true {
+		t.Fatalf("path param: %v", p)
+	}
+	list := doc.Paths["/users"]["get"]["parameters"].([]any)[0].(map[string]any)
+	if list["in"] != "query" || list["schema"].(map[string]any)["type"] != "integer" {
+		t.Fatalf("query param: %v", list)
+	}
+
+	user := doc.Comps.Schemas["apiUser"]
+	props := user["properties"].(map[string]any)
+	name := props["name"].(map[string]any)
+	if name["minLength"] != 3.0 || name["maxLength"] != 50.0 {
+		t.Fatalf("name schema: %v", name)
+	}
+	if req := user["required"].([]any); len(req) != 1 || req[0] != "name" {
+		t.Fatalf("required: %v", req)
+	}
+	if props["secret"] != nil || props["id"].(map[string]any)["format"] != "int64" {
+		t.Fatalf("props: %v", props)
+	}
+	if ref := props["address"].(map[string]any)["$ref"]; ref != "#/components/schemas/apiAddress" {
+		t.Fatalf("address ref: %v", ref)
+	}
+	if items := props["friends"].(map[string]any)["items"].(map[string]any); items["$ref"] != "#/components/schemas/apiUser" {
+		t.Fatalf("recursive items: %v", items)
+	}
+	if enum := props["role"].(map[string]any)["enum"].([]any); len(enum) != 2 {
+		t.Fatalf("enum: %v", enum)
+	}
+}
@@ -0,0 +1,115 @@
+package z_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+const validatorSpec = `{
+  "openapi": "3.1.0",
+  "info": {"title": "t", "version": "1"},
+  "servers": [{"url": "https://api.example.com/api"}],
+  "paths": {
+    "/users/{id}": {
+      "parameters": [{"name": "id", "in": "path", "required": true, "schema": {"type": "integer", "minimum": 1}}],
+      "get": {
+        "parameters": [{"name": "fields", "in": "query", "schema": {"type": "array", "items": {"type": "string", "enum": ["name", "email"]}}}],
+        "responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}}}
+      }
+    },
+    "/users": {
+      "post": {
+        "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}},
+        "responses": {"201": {"description": "created"}}
+      }
+    }
+  },
+  "components": {"schemas": {"User": {
+    "type": "object",
+    "required": ["name"],
+    "additionalProperties": false,
+    "properties": {
+      "id": {"type": "integer"},
+      "name": {"type": "string", "minLength": 2},
+      "email": {"type": "string", "format": "email"}
+    }
+  }}}
+}`
+
+func TestOpenAPIValidator(t *testing.T) {
+	spec, err := middleware.LoadOpenAPI([]byte(validatorSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := zentrox.NewApp()
+	app.Plug(middleware.OpenAPIValidator(spec, middleware.OpenAPIValidatorConfig{ValidateResponses: true, RejectUnknown: true}))
+	app.GET("/api/users/:id", func(c *zentrox.Context) {
+		if c.Param("id") == "13" {
+			c.JSON(http.StatusOK, map[string]any{"name": 7}) // wrong type
+			return
+		}
+		c.JSON(http.StatusOK, map[string]any{"id": 1, "name": "Ann"})
+	})
+	app.POST("/api/users", func(c *zentrox.Context) {
+		var in struct {
+			Name string `json:"name"`
+		}
+		if err := c.BindJSONInto(&in); err != nil || in.Name != "Ann" {
+			t.Errorf("body not restored: %v", err)
+		}
+		c.SendStatus(http.StatusCreated)
+	})
+
+	do := func(method, path, body string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code, w.Body.String()
+	}
+
+	cases := []struct {
+		method, path, body string
+		code               int
+		field              string
+	}{
+		{"GET", "/api/users/1?fields=name,email", "", 200, ""},
+		{"GET", "/api/users/abc", "", 400, "id"},
+		{"GET", "/api/users/0", "", 400, "id"},
+		{"GET", "/api/users/1?fields=phone", "", 400, "fields[0]"},
+		{"GET", "/api/users/13", "", 500, "name"},
+		{"POST", "/api/users", `{"name":"Ann","email":"ann@example.com"}`, 201, ""},
+		{"POST", "/api/users", `{"name":"A","extra":1}`, 400, "extra"},
+		{"POST", "/api/users", `{"email":"nope"}`, 400, "email"},
+		{"POST", "/api/users", "", 400, ""},
+		{"DELETE", "/api/users/1", "", 405, ""},
+		{"GET", "/api/other", "", 404, ""},
+	}
+	for _, tc := range cases {
+		code, body := do(tc.method, tc.path, tc.body)
+		if code != tc.code {
+			t.Fatalf("%s %s: want %d, got %d %s", tc.method, tc.path, tc.code, code, body)
+		}
+		if tc.field != "" {
+			var e struct {
+				Detail []middleware.OpenAPIViolation `json:"detail"`
+			}
+			_ = json.Unmarshal([]byte(body), &e)
+			found := false
+			for _, v := range e.Detail {
+				found = found || v.Field == tc.field
+			}
+			if !found {
+				t.Fatalf("%s %s: no violation for %q in %s", tc.method, tc.path, tc.field, body)
+			}
+		}
+	}
+}
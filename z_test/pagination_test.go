@@ -0,0 +1,76 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestParsePagination_Bounds(t *testing.T) {
+	app := zentrox.NewApp()
+	var got zentrox.Pagination
+	app.GET("/items", func(c *zentrox.Context) {
+		got = zentrox.ParsePagination(c)
+		c.SendStatus(http.StatusNoContent)
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items?page=3&limit=500&cursor=abc", nil))
+	if got.Page != 3 || got.Limit != 100 || got.Offset != 200 || got.Cursor != "abc" {
+		t.Fatalf("unexpected pagination: %+v", got)
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items?page=-1&limit=x", nil))
+	if got.Page != 1 || got.Limit != 20 || got.Offset != 0 {
+		t.Fatalf("unexpected defaults: %+v", got)
+	}
+}
+
+func TestSetPaginationHeaders(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/items", func(c *zentrox.Context) {
+		p := zentrox.ParsePagination(c)
+		c.SetPaginationHeaders(95, p.Page, p.Limit)
+		c.SendStatus(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?page=2&limit=10&q=x", nil))
+
+	if got := w.Header().Get(zentrox.HeaderXTotalCount); got != "95" {
+		t.Fatalf("want total 95, got %q", got)
+	}
+	link := w.Header().Get(zentrox.HeaderLink)
+	for _, want := range []string{
+		`</items?limit=10&page=1&q=x>; rel="first"`,
+		`</items?limit=10&page=1&q=x>; rel="prev"`,
+		`</items?limit=10&page=3&q=x>; rel="next"`,
+		`</items?limit=10&page=10&q=x>; rel="last"`,
+	} {
+		if !strings.Contains(link, want) {
+			t.Fatalf("link header missing %q: %s", want, link)
+		}
+	}
+}
+
+func TestSetPaginationHeadersWithConfig(t *testing.T) {
+	cfg := zentrox.PaginationConfig{PageParam: "p", LimitParam: "per_page"}
+	app := zentrox.NewApp()
+	app.GET("/items", func(c *zentrox.Context) {
+		p := zentrox.ParsePaginationWithConfig(c, cfg)
+		c.SetPaginationHeadersWithConfig(30, p.Page, p.Limit, cfg)
+		c.SendStatus(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?p=2&per_page=10", nil))
+	link := w.Header().Get(zentrox.HeaderLink)
+	if strings.Contains(link, "limit=") || strings.Contains(link, "&page=") || strings.Contains(link, "?page=") {
+		t.Fatalf("link header uses default names: %s", link)
+	}
+	if !strings.Contains(link, `</items?p=3&per_page=10>; rel="next"`) {
+		t.Fatalf("unexpected link header: %s", link)
+	}
+}
@@ -0,0 +1,62 @@
+package z_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestTypedParams(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/users/:id", func(c *zentrox.Context) {
+		id, err := c.ParamInt("id")
+		if err != nil {
+			var he zentrox.HTTPError
+			if !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+				t.Errorf("want 400 HTTPError, got %v", err)
+			}
+			c.Fail(he.Code, he.Message)
+			return
+		}
+		since, err := c.QueryTime("since", time.DateOnly)
+		if err != nil {
+			c.Fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, map[string]any{
+			"id":      id,
+			"page":    c.QueryInt("page", 1),
+			"ratio":   c.QueryFloat64("ratio", 0.5),
+			"debug":   c.QueryBool("debug", false),
+			"archive": c.QueryBool("archive", true),
+			"ttl":     c.QueryDuration("ttl", time.Minute).String(),
+			"since":   since.Format(time.DateOnly),
+		})
+	})
+
+	cases := map[string]struct {
+		code int
+		body string
+	}{
+		"/users/7?page=3&ratio=2.5&debug&archive=off&ttl=90s&since=2024-05-01": {200,
+			`{"archive":false,"debug":true,"id":7,"page":3,"ratio":2.5,"since":"2024-05-01","ttl":"1m30s"}`},
+		"/users/7?page=x&ratio=y&debug=maybe&ttl=soon": {200,
+			`{"archive":true,"debug":false,"id":7,"page":1,"ratio":0.5,"since":"0001-01-01","ttl":"1m0s"}`},
+		"/users/abc":                {400, ""},
+		"/users/7?since=01-05-2024": {400, ""},
+	}
+	for path, want := range cases {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want.code {
+			t.Fatalf("%s: want %d, got %d %s", path, want.code, w.Code, w.Body.String())
+		}
+		if want.body != "" && w.Body.String() != want.body+"\n" {
+			t.Fatalf("%s: got %s", path, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package z_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2/auth"
+)
+
+func TestPasswordHashing(t *testing.T) {
+	fast := auth.PasswordParams{Memory: 1024, Iterations: 1, Parallelism: 1, BcryptCost: 4}
+
+	h, err := auth.HashPasswordWithParams("s3cret", fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := auth.VerifyPassword("s3cret", h); !ok || err != nil {
+		t.Fatalf("argon2id verify failed: %v %v", ok, err)
+	}
+	if ok, _ := auth.VerifyPassword("wrong", h); ok {
+		t.Fatal("wrong password must not verify")
+	}
+	if !auth.NeedsRehash(h) {
+		t.Fatal("hash weaker than defaults should need a rehash")
+	}
+	if auth.NeedsRehashWithParams(h, fast) {
+		t.Fatal("hash matching params should not need a rehash")
+	}
+
+	fast.Algorithm = auth.Bcrypt
+	b, err := auth.HashPasswordWithParams("s3cret", fast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := auth.VerifyPassword("s3cret", b); !ok || err != nil {
+		t.Fatalf("bcrypt verify failed: %v %v", ok, err)
+	}
+	if ok, err := auth.VerifyPassword("wrong", b); ok || err != nil {
+		t.Fatalf("bcrypt mismatch should be false, nil: %v %v", ok, err)
+	}
+	if !auth.NeedsRehash(b) {
+		t.Fatal("bcrypt hash should be migrated to argon2id")
+	}
+
+	if _, err := auth.VerifyPassword("x", "$argon2id$garbage"); err != auth.ErrInvalidHash {
+		t.Fatalf("want ErrInvalidHash, got %v", err)
+	}
+
+	// Parameters that would make argon2 panic or allocate without bound.
+	const salt, key = "c2FsdHNhbHRzYWx0c2FsdA", "a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2V5a2U"
+	for name, enc := range map[string]string{
+		"zero iterations":  "$argon2id$v=19$m=65536,t=0,p=4$" + salt + "$" + key,
+		"zero parallelism": "$argon2id$v=19$m=65536,t=3,p=0$" + salt + "$" + key,
+		"huge memory":      "$argon2id$v=19$m=4294967295,t=3,p=4$" + salt + "$" + key,
+		"empty salt":       "$argon2id$v=19$m=65536,t=3,p=4$$" + key,
+		"huge iterations":  "$argon2id$v=19$m=65536,t=4294967295,p=4$" + salt + "$" + key,
+		"many lanes":       "$argon2id$v=19$m=65536,t=3,p=255$" + salt + "$" + key,
+		"long salt":        "$argon2id$v=19$m=65536,t=3,p=4$" + strings.Repeat("A", 1400) + "$" + key,
+		"long key":         "$argon2id$v=19$m=65536,t=3,p=4$" + salt + "$" + strings.Repeat("A", 1400),
+	} {
+		if _, err := auth.VerifyPassword("x", enc); err != auth.ErrInvalidHash {
+			t.Fatalf("%s: want ErrInvalidHash, got %v", name, err)
+		}
+	}
+
+	// Hashing refuses parameters VerifyPassword would later reject.
+	if _, err := auth.HashPasswordWithParams("x", auth.PasswordParams{Iterations: 1000}); err != auth.ErrInvalidParams {
+		t.Fatalf("want ErrInvalidParams, got %v", err)
+	}
+}
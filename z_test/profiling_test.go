@@ -0,0 +1,48 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestEnableProfiling(t *testing.T) {
+	app := zentrox.NewApp()
+	auth := func(c *zentrox.Context) {
+		if c.GetHeader("X-Admin") != "yes" {
+			c.Fail(http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		c.Next()
+	}
+	app.EnableProfiling("/ops/debug", auth)
+
+	get := func(path string, admin bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if admin {
+			req.Header.Set("X-Admin", "yes")
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/ops/debug/pprof/", false); w.Code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated: want 401, got %d", w.Code)
+	}
+	if w := get("/ops/debug/pprof", true); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/ops/debug/pprof/" {
+		t.Fatalf("redirect: %d %q", w.Code, w.Header().Get("Location"))
+	}
+	if w := get("/ops/debug/pprof/", true); w.Code != 200 || !strings.Contains(w.Body.String(), "goroutine") {
+		t.Fatalf("index: %d", w.Code)
+	}
+	if w := get("/ops/debug/pprof/goroutine?debug=1", true); w.Code != 200 || !strings.Contains(w.Body.String(), "goroutine profile") {
+		t.Fatalf("goroutine: %d %.100s", w.Code, w.Body.String())
+	}
+	if w := get("/ops/debug/vars", true); w.Code != 200 || !strings.Contains(w.Body.String(), "memstats") {
+		t.Fatalf("expvar: %d", w.Code)
+	}
+}
@@ -0,0 +1,141 @@
+package z_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestProxy(t *testing.T) {
+	var calls atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/flaky":
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/v1/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Internal", "secret")
+		_, _ = io.WriteString(w, r.Method+" "+r.URL.Path+" "+r.Header.Get("X-Gateway")+" "+string(body))
+	}))
+	defer upstream.Close()
+
+	app := zentrox.NewApp()
+	app.Proxy("/payments/*path", upstream.URL+"/v1", zentrox.ProxyOptions{
+		StripPrefix:           "/payments",
+		SetRequestHeaders:     map[string]string{"X-Gateway": "zentrox"},
+		RemoveResponseHeaders: []string{"X-Internal"},
+		Retries:               2,
+		RetryBackoff:          time.Millisecond,
+		Timeout:               100 * time.Millisecond,
+	})
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := do(http.MethodPost, "/payments/charges", "amount=5")
+	if w.Code != 200 || w.Body.String() != "POST /v1/charges zentrox amount=5" || w.Header().Get("X-Internal") != "" {
+		t.Fatalf("forward: %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	if w = do(http.MethodPut, "/payments/flaky", "x"); w.Code != 200 || calls.Load() != 3 {
+		t.Fatalf("retry: %d after %d calls", w.Code, calls.Load())
+	}
+
+	// Non-idempotent methods are not retried.
+	calls.Store(0)
+	if w = do(http.MethodPost, "/payments/flaky", ""); w.Code != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Fatalf("POST retried: %d after %d calls", w.Code, calls.Load())
+	}
+
+	if w = do(http.MethodGet, "/payments/slow", ""); w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("timeout: want 504, got %d", w.Code)
+	}
+
+	down := zentrox.NewApp()
+	down.Proxy("/*path", "http://127.0.0.1:1")
+	w = httptest.NewRecorder()
+	down.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("unreachable target: want 502, got %d", w.Code)
+	}
+}
+
+func TestProxyPool(t *testing.T) {
+	var hitsA, hitsB atomic.Int32
+	a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hitsA.Add(1)
+		_, _ = io.WriteString(w, "a")
+	}))
+	defer a.Close()
+	failing := atomic.Bool{}
+	failing.Store(true)
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hitsB.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = io.WriteString(w, "b")
+	}))
+	defer b.Close()
+
+	pool := zentrox.NewUpstreamPool([]string{a.URL, b.URL}, zentrox.UpstreamPoolConfig{
+		MaxFails:    2,
+		FailTimeout: 50 * time.Millisecond,
+	})
+	app := zentrox.NewApp()
+	app.ProxyPool("/*path", pool, zentrox.ProxyOptions{Retries: 1, RetryBackoff: time.Millisecond})
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/job", nil))
+		return w
+	}
+
+	// b fails and is retried on a, so clients only see successes; after
+	// MaxFails it is ejected and receives no more traffic.
+	for i := 0; i < 10; i++ {
+		if w := get(); w.Code != 200 || w.Body.String() != "a" {
+			t.Fatalf("request %d: %d %q", i, w.Code, w.Body.String())
+		}
+	}
+	if hitsB.Load() != 2 || pool.Status()[1].Healthy {
+		t.Fatalf("b hits = %d, status = %+v", hitsB.Load(), pool.Status())
+	}
+
+	// After FailTimeout a probe goes through; success restores b.
+	failing.Store(false)
+	time.Sleep(60 * time.Millisecond)
+	seen := map[string]bool{}
+	for i := 0; i < 4; i++ {
+		seen[get().Body.String()] = true
+	}
+	if !seen["a"] || !seen["b"] || !pool.Status()[1].Healthy {
+		t.Fatalf("b not restored: %v %+v", seen, pool.Status())
+	}
+
+	single := zentrox.NewUpstreamPool([]string{"http://127.0.0.1:1"}, zentrox.UpstreamPoolConfig{MaxFails: 1, Strategy: zentrox.LeastConnections})
+	down := zentrox.NewApp()
+	down.ProxyPool("/*path", single)
+	codes := []int{}
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		down.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/x", nil))
+		codes = append(codes, w.Code)
+	}
+	if codes[0] != http.StatusBadGateway || codes[1] != http.StatusServiceUnavailable {
+		t.Fatalf("all upstreams down: %v", codes)
+	}
+}
@@ -0,0 +1,97 @@
+package z_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func startProxyProtoServer(t *testing.T) string {
+	t.Helper()
+	app := zentrox.NewApp()
+	app.GET("/ip", func(c *zentrox.Context) {
+		c.String(http.StatusOK, "%s %s", c.RealIP(), c.Request.RemoteAddr)
+	})
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: app}
+	go srv.Serve(zentrox.NewProxyProtocolListener(ln, time.Second))
+	t.Cleanup(func() { srv.Close() })
+	return ln.Addr().String()
+}
+
+func proxyRoundTrip(t *testing.T, addr string, header []byte) (string, error) {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	_, _ = conn.Write(header)
+	_, _ = io.WriteString(conn, "GET /ip HTTP/1.1\r\nHost: x\r\nConnection: close\r\n\r\n")
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return string(b), nil
+}
+
+func TestProxyProtocol_V1(t *testing.T) {
+	addr := startProxyProtoServer(t)
+	got, err := proxyRoundTrip(t, addr, []byte("PROXY TCP4 203.0.113.7 10.0.0.1 51234 443\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "203.0.113.7 203.0.113.7:51234" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestProxyProtocol_V2(t *testing.T) {
+	addr := startProxyProtoServer(t)
+	hdr := []byte("\r\n\r\n\x00\r\nQUIT\n")
+	hdr = append(hdr, 0x21, 0x21) // v2 PROXY, TCP over IPv6
+	hdr = binary.BigEndian.AppendUint16(hdr, 36)
+	hdr = append(hdr, net.ParseIP("2001:db8::1")...)
+	hdr = append(hdr, net.ParseIP("2001:db8::2")...)
+	hdr = binary.BigEndian.AppendUint16(hdr, 40000)
+	hdr = binary.BigEndian.AppendUint16(hdr, 443)
+	got, err := proxyRoundTrip(t, addr, hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "2001:db8::1 [2001:db8::1]:40000" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestProxyProtocol_V2Local(t *testing.T) {
+	addr := startProxyProtoServer(t)
+	hdr := append([]byte("\r\n\r\n\x00\r\nQUIT\n"), 0x20, 0x00, 0x00, 0x00)
+	got, err := proxyRoundTrip(t, addr, hdr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "127.0.0.1 ") {
+		t.Fatalf("LOCAL should keep socket address, got %q", got)
+	}
+}
+
+func TestProxyProtocol_MissingHeaderRejected(t *testing.T) {
+	addr := startProxyProtoServer(t)
+	if got, err := proxyRoundTrip(t, addr, nil); err == nil {
+		t.Fatalf("expected connection to fail, got %q", got)
+	}
+}
@@ -0,0 +1,60 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestRequireRolesAndPermissions(t *testing.T) {
+	secret := []byte("rbac-secret")
+	app := zentrox.NewApp()
+	api := app.Scope("/api", middleware.JWT(middleware.JWTConfig{Secret: secret}))
+	ok := func(c *zentrox.Context) { c.SendStatus(http.StatusOK) }
+	api.GET("/admin", middleware.RequireRoles("admin", "owner"), ok)
+	api.GET("/reports", middleware.RequirePermissions("reports:read", "reports:export"), ok)
+	scoped := middleware.RBACConfig{
+		PermissionsClaim: "scope",
+		Policy: func(c *zentrox.Context, claims map[string]any) bool {
+			return claims["sub"] == c.Param("id")
+		},
+	}
+	api.GET("/users/:id", scoped.RequirePermissions("profile"), ok)
+	app.GET("/open", middleware.RequireRoles("admin"), ok)
+
+	token := func(claims map[string]any) string {
+		tok, err := middleware.SignHS256(claims, secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tok
+	}
+	cases := []struct {
+		path   string
+		claims map[string]any
+		code   int
+	}{
+		{"/api/admin", map[string]any{"roles": []any{"user", "owner"}}, 200},
+		{"/api/admin", map[string]any{"role": "admin"}, 200},
+		{"/api/admin", map[string]any{"roles": "user editor"}, 403},
+		{"/api/reports", map[string]any{"permissions": []any{"reports:read", "reports:export"}}, 200},
+		{"/api/reports", map[string]any{"permissions": "reports:read"}, 403},
+		{"/api/users/42", map[string]any{"sub": "42", "scope": "openid profile"}, 200},
+		{"/api/users/43", map[string]any{"sub": "42", "scope": "openid profile"}, 403},
+		{"/open", nil, 401},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.claims != nil {
+			req.Header.Set("Authorization", "Bearer "+token(tc.claims))
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != tc.code {
+			t.Fatalf("%s %v: want %d, got %d %s", tc.path, tc.claims, tc.code, w.Code, w.Body.String())
+		}
+	}
+}
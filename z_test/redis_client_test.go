@@ -0,0 +1,51 @@
+package z_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2/internal/redis"
+)
+
+func TestRedisClient_ArrayWithErrorElement(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		br := bufio.NewReader(c)
+		replies := []string{"*3\r\n+OK\r\n-ERR wrong type\r\n:5\r\n", "+PONG\r\n"}
+		for _, r := range replies {
+			if _, err := readCommand(br); err != nil {
+				return
+			}
+			io.WriteString(c, r)
+		}
+	}()
+
+	cl := redis.New(redis.Config{Addr: ln.Addr().String(), PoolSize: 1})
+	defer cl.Close()
+	ctx := context.Background()
+
+	v, err := cl.Do(ctx, "EXEC")
+	if err != nil {
+		t.Fatalf("EXEC: %v", err)
+	}
+	arr, _ := v.([]any)
+	if len(arr) != 3 || arr[0] != "OK" || arr[1] != redis.Error("ERR wrong type") || arr[2] != int64(5) {
+		t.Fatalf("EXEC reply = %#v", v)
+	}
+	// The pooled connection must not hold leftovers from the array.
+	if s, err := cl.String(ctx, "PING"); err != nil || s != "PONG" {
+		t.Fatalf("PING = %q %v", s, err)
+	}
+}
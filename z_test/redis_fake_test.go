@@ -0,0 +1,126 @@
+package z_test
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a tiny in-process RESP server implementing the commands used by
+// the Redis-backed stores.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+	exp  map[string]time.Time
+	ln   net.Listener
+}
+
+func startFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeRedis{data: map[string]string{}, exp: map[string]time.Time{}, ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(c)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) Addr() string { return f.ln.Addr().String() }
+
+func (f *fakeRedis) serve(c net.Conn) {
+	defer c.Close()
+	br := bufio.NewReader(c)
+	for {
+		args, err := readCommand(br)
+		if err != nil {
+			return
+		}
+		io.WriteString(c, f.exec(args))
+	}
+}
+
+func readCommand(br *bufio.Reader) ([]string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	args := make([]string, n)
+	for i := range args {
+		hdr, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _ := strconv.Atoi(strings.TrimSpace(hdr[1:]))
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(br, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func (f *fakeRedis) live(key string) bool {
+	if e, ok := f.exp[key]; ok && time.Now().After(e) {
+		delete(f.data, key)
+		delete(f.exp, key)
+	}
+	_, ok := f.data[key]
+	return ok
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "SET":
+		f.data[args[1]] = args[2]
+		delete(f.exp, args[1])
+		if len(args) == 5 && strings.EqualFold(args[3], "PX") {
+			ms, _ := strconv.Atoi(args[4])
+			f.exp[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+		}
+		return "+OK\r\n"
+	case "GET":
+		if !f.live(args[1]) {
+			return "$-1\r\n"
+		}
+		v := f.data[args[1]]
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(v), v)
+	case "EXISTS":
+		if f.live(args[1]) {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "DEL":
+		n := 0
+		for _, k := range args[1:] {
+			if f.live(k) {
+				n++
+			}
+			delete(f.data, k)
+			delete(f.exp, k)
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "PING":
+		return "+PONG\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
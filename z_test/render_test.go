@@ -0,0 +1,252 @@
+package z_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestJSONRenderers(t *testing.T) {
+	app := zentrox.NewApp()
+	v := map[string]any{"id": 1, "tags": []string{"a"}}
+	app.GET("/pretty", func(c *zentrox.Context) { c.IndentedJSON(http.StatusOK, v) })
+	app.GET("/jsonp", func(c *zentrox.Context) { c.JSONP(http.StatusOK, v) })
+	app.GET("/secure", func(c *zentrox.Context) { c.SecureJSON(http.StatusOK, []int{1, 2}) })
+	app.GET("/secure2", func(c *zentrox.Context) { c.SecureJSON(http.StatusOK, []int{1}, ")]}',\n") })
+	app.GET("/bad", func(c *zentrox.Context) { c.IndentedJSON(http.StatusOK, func() {}) })
+
+	cases := []struct {
+		path, ct, body string
+		code           int
+	}{
+		{"/pretty", zentrox.ContentTypeJSONUTF8, "{\n    \"id\": 1,\n    \"tags\": [\n        \"a\"\n    ]\n}\n", 200},
+		{"/jsonp?callback=app.render_1", zentrox.ContentTypeJavaScriptUTF8, `/**/app.render_1({"id":1,"tags":["a"]});`, 200},
+		{"/jsonp?callback=alert(1)//", zentrox.ContentTypeJSONUTF8, "{\"id\":1,\"tags\":[\"a\"]}\n", 200},
+		{"/jsonp", zentrox.ContentTypeJSONUTF8, "{\"id\":1,\"tags\":[\"a\"]}\n", 200},
+		{"/secure", zentrox.ContentTypeJSONUTF8, "while(1);[1,2]", 200},
+		{"/secure2", zentrox.ContentTypeJSONUTF8, ")]}',\n[1]", 200},
+		{"/bad", zentrox.ContentTypeJSONUTF8, `{"code":500,"message":"json encode failed"}`, 500},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.code || w.Header().Get(zentrox.HeaderContentType) != tc.ct || w.Body.String() != tc.body {
+			t.Fatalf("%s: %d %q %q", tc.path, w.Code, w.Header().Get(zentrox.HeaderContentType), w.Body.String())
+		}
+	}
+}
+
+type clusterConfig struct {
+	Name     string `yaml:"name" toml:"name" validate:"required"`
+	Replicas int    `yaml:"replicas" toml:"replicas"`
+}
+
+func TestCodec_BuiltinYAMLAndTOML(t *testing.T) {
+	app := zentrox.NewApp()
+	app.POST("/cfg", func(c *zentrox.Context) {
+		var in clusterConfig
+		if err := c.BindInto(&in); err != nil {
+			c.String(http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		in.Replicas++
+		c.Negotiate(http.StatusOK, map[string]any{
+			zentrox.ContentTypeJSON: in,
+			zentrox.ContentTypeYAML: in,
+			zentrox.ContentTypeTOML: in,
+		})
+	})
+
+	post := func(ct, accept, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/cfg", strings.NewReader(body))
+		req.Header.Set(zentrox.HeaderContentType, ct)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("application/x-yaml; charset=utf-8", "application/yaml", "name: infra\nreplicas: 2\n")
+	if w.Code != http.StatusOK || w.Body.String() != "name: infra\nreplicas: 3\n" || w.Header().Get(zentrox.HeaderContentType) != zentrox.ContentTypeYAML {
+		t.Fatalf("yaml round trip: %d %q", w.Code, w.Body.String())
+	}
+	w = post(zentrox.ContentTypeTOML, zentrox.ContentTypeTOML, "name = \"infra\"\nreplicas = 1\n")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "replicas = 2") {
+		t.Fatalf("toml round trip: %d %q", w.Code, w.Body.String())
+	}
+	if w = post(zentrox.ContentTypeYAML, zentrox.ContentTypeYAML, "replicas: 2\n"); w.Code != http.StatusBadRequest {
+		t.Fatalf("validation: %d %q", w.Code, w.Body.String())
+	}
+}
+
+type note struct{ Text string }
+
+func TestCodec_SetCodecAndBodyLimit(t *testing.T) {
+	// A toy "key: value" codec replaces the built-in YAML one on one App.
+	app := zentrox.NewApp().SetCodec(zentrox.ContentTypeYAML, zentrox.Codec{
+		Marshal: func(v any) ([]byte, error) {
+			return []byte("custom: " + v.(note).Text), nil
+		},
+		Unmarshal: func(data []byte, v any) error {
+			v.(*note).Text = string(data)
+			return nil
+		},
+		MaxBodyBytes: 8,
+	})
+	app.POST("/echo", func(c *zentrox.Context) {
+		var n note
+		if err := c.BindYAMLInto(&n); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.SendStatus(http.StatusRequestEntityTooLarge)
+				return
+			}
+			c.String(http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		c.YAML(http.StatusOK, n)
+	})
+	other := zentrox.NewApp()
+	other.GET("/", func(c *zentrox.Context) { c.YAML(http.StatusOK, map[string]int{"a": 1}) })
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+		req.Header.Set(zentrox.HeaderContentType, zentrox.ContentTypeYAML)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+	if w := post("hi"); w.Body.String() != "custom: hi" {
+		t.Fatalf("custom codec: %d %q", w.Code, w.Body.String())
+	}
+	if w := post("way too long"); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("body limit: %d %q", w.Code, w.Body.String())
+	}
+
+	// Codecs are per App: the other App still uses the built-in one.
+	w := httptest.NewRecorder()
+	other.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Body.String() != "a: 1\n" {
+		t.Fatalf("other app: %q", w.Body.String())
+	}
+}
+
+func TestCodec_SetCodecBinary(t *testing.T) {
+	// encoding/gob replaces the built-in protobuf and msgpack codecs.
+	gobCodec := zentrox.Codec{
+		Marshal: func(v any) ([]byte, error) {
+			var buf bytes.Buffer
+			err := gob.NewEncoder(&buf).Encode(v)
+			return buf.Bytes(), err
+		},
+		Unmarshal: func(data []byte, v any) error {
+			return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+		},
+	}
+	type order struct{ ID, Qty int }
+	app := zentrox.NewApp().
+		SetCodec(zentrox.ContentTypeProtobuf, gobCodec).
+		SetCodec("application/x-msgpack", gobCodec)
+	app.POST("/proto", func(c *zentrox.Context) {
+		var o order
+		if err := c.BindProtoInto(&o); err != nil {
+			c.String(http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		o.Qty++
+		c.ProtoBuf(http.StatusOK, o)
+	})
+	app.POST("/msgpack", func(c *zentrox.Context) {
+		var o order
+		if err := c.BindMsgPackInto(&o); err != nil {
+			c.String(http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		c.MsgPack(http.StatusCreated, o)
+	})
+
+	for path, ct := range map[string]string{"/proto": zentrox.ContentTypeProtobuf, "/msgpack": zentrox.ContentTypeMsgPack} {
+		body, _ := gobCodec.Marshal(order{ID: 7, Qty: 1})
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		var got order
+		if err := gobCodec.Unmarshal(w.Body.Bytes(), &got); err != nil || got.ID != 7 {
+			t.Fatalf("%s: %d %v %+v", path, w.Code, err, got)
+		}
+		if w.Header().Get(zentrox.HeaderContentType) != ct {
+			t.Fatalf("%s: content type %q", path, w.Header().Get(zentrox.HeaderContentType))
+		}
+	}
+}
+
+func TestCodec_BuiltinProtoAndMsgPack(t *testing.T) {
+	type order struct {
+		ID  int `msgpack:"id"`
+		Qty int `msgpack:"qty" validate:"min=1"`
+	}
+	app := zentrox.NewApp()
+	app.POST("/proto", func(c *zentrox.Context) {
+		in := &wrapperspb.StringValue{}
+		if err := c.BindProtoInto(in); err != nil {
+			c.String(http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		c.ProtoBuf(http.StatusOK, wrapperspb.String("echo:"+in.GetValue()))
+	})
+	app.POST("/msgpack", func(c *zentrox.Context) {
+		var o order
+		if err := c.BindMsgPackInto(&o); err != nil {
+			c.String(http.StatusBadRequest, "%s", err.Error())
+			return
+		}
+		o.Qty++
+		c.MsgPack(http.StatusCreated, o)
+	})
+	app.GET("/proto/bad", func(c *zentrox.Context) { c.ProtoBuf(http.StatusOK, order{}) })
+
+	post := func(path, ct string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set(zentrox.HeaderContentType, ct)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	body, _ := proto.Marshal(wrapperspb.String("hi"))
+	w := post("/proto", zentrox.ContentTypeProtobuf, body)
+	var got wrapperspb.StringValue
+	if err := proto.Unmarshal(w.Body.Bytes(), &got); err != nil || got.GetValue() != "echo:hi" {
+		t.Fatalf("proto: %d %v %q", w.Code, err, got.GetValue())
+	}
+	if ct := w.Header().Get(zentrox.HeaderContentType); ct != zentrox.ContentTypeProtobuf {
+		t.Fatalf("proto content type %q", ct)
+	}
+
+	body, _ = msgpack.Marshal(order{ID: 7, Qty: 1})
+	w = post("/msgpack", "application/x-msgpack", body)
+	var o order
+	if err := msgpack.Unmarshal(w.Body.Bytes(), &o); err != nil || w.Code != http.StatusCreated || o != (order{ID: 7, Qty: 2}) {
+		t.Fatalf("msgpack: %d %v %+v", w.Code, err, o)
+	}
+	body, _ = msgpack.Marshal(order{ID: 7})
+	if w = post("/msgpack", zentrox.ContentTypeMsgPack, body); w.Code != http.StatusBadRequest {
+		t.Fatalf("msgpack validation: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/proto/bad", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("non-proto value: %d", w.Code)
+	}
+}
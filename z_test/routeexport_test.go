@@ -0,0 +1,127 @@
+package z_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+type exportCreateUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type exportUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func newExportApp() *zentrox.App {
+	app := zentrox.NewApp()
+	api := app.Scope("/api")
+	api.POST("/users", func(c *zentrox.Context) {}).
+		Summary("Create a user").
+		Body((*exportCreateUser)(nil)).
+		Returns(exportUser{ID: 1, Name: "ann"})
+	api.GET("/users/:id", func(c *zentrox.Context) {})
+	api.GET("/files/*path", func(c *zentrox.Context) {})
+	return app
+}
+
+func TestExportRoutes_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newExportApp().ExportRoutes(&buf, zentrox.RoutesJSON); err != nil {
+		t.Fatal(err)
+	}
+	var routes []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &routes); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(routes) != 3 {
+		t.Fatalf("want 3 routes, got %d", len(routes))
+	}
+	byPath := map[string]map[string]any{}
+	for _, r := range routes {
+		byPath[r["method"].(string)+" "+r["path"].(string)] = r
+	}
+	create := byPath["POST /api/users"]
+	if create["summary"] != "Create a user" {
+		t.Fatalf("summary: %v", create)
+	}
+	if body, _ := create["body"].(map[string]any); body == nil || body["email"] != "" {
+		t.Fatalf("body example from nil pointer: %v", create["body"])
+	}
+	if resp, _ := create["response"].(map[string]any); resp["name"] != "ann" {
+		t.Fatalf("response example: %v", create["response"])
+	}
+	if params, _ := byPath["GET /api/files/*path"]["params"].([]any); len(params) != 1 || params[0] != "path" {
+		t.Fatalf("params: %v", byPath["GET /api/files/*path"])
+	}
+}
+
+func TestExportRoutes_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newExportApp().ExportRoutes(&buf, zentrox.RoutesMarkdown); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"| POST | `/api/users` |",
+		"## POST /api/users",
+		"Create a user",
+		`"email": ""`,
+		"Path parameters: `id`",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("markdown missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestExportRoutes_Postman(t *testing.T) {
+	var buf bytes.Buffer
+	if err := newExportApp().ExportRoutes(&buf, zentrox.RoutesPostman); err != nil {
+		t.Fatal(err)
+	}
+	var col struct {
+		Info struct{ Schema string }
+		Item []struct {
+			Name    string
+			Request struct {
+				Method string
+				URL    struct {
+					Raw      string
+					Variable []struct{ Key string }
+				}
+				Body struct{ Mode, Raw string }
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &col); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(col.Info.Schema, "v2.1.0") || len(col.Item) != 3 {
+		t.Fatalf("collection: %+v", col)
+	}
+	for _, it := range col.Item {
+		switch it.Name {
+		case "POST /api/users":
+			if it.Request.Body.Mode != "raw" || !strings.Contains(it.Request.Body.Raw, `"name"`) {
+				t.Fatalf("body: %+v", it.Request.Body)
+			}
+		case "GET /api/users/:id":
+			if it.Request.URL.Raw != "{{baseUrl}}/api/users/:id" || len(it.Request.URL.Variable) != 1 || it.Request.URL.Variable[0].Key != "id" {
+				t.Fatalf("url: %+v", it.Request.URL)
+			}
+		}
+	}
+}
+
+func TestExportRoutes_UnknownFormat(t *testing.T) {
+	if err := zentrox.NewApp().ExportRoutes(&bytes.Buffer{}, "yaml"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
@@ -0,0 +1,112 @@
+package z_test
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestRoutePath(t *testing.T) {
+	var got string
+	app := zentrox.NewApp()
+	app.Plug(func(c *zentrox.Context) {
+		c.Next()
+		got = middleware.RouteLabel(c)
+		if c.FullPath() != c.RoutePath() {
+			t.Errorf("FullPath %q != RoutePath %q", c.FullPath(), c.RoutePath())
+		}
+	})
+	h := func(c *zentrox.Context) { c.SendStatus(http.StatusOK) }
+	app.GET("/users/:id", h)
+	app.GET("/files/*path", h)
+	app.Scope("/api").Scope("/v1").GET("/orders/:orderID/items/:itemID", h)
+	app.SetNotFound(func(c *zentrox.Context) {
+		got = middleware.RouteLabel(c)
+		c.SendStatus(http.StatusNotFound)
+	})
+
+	for _, tc := range []struct{ method, path, want string }{
+		{http.MethodGet, "/users/42", "/users/:id"},
+		{http.MethodHead, "/users/7", "/users/:id"},
+		{http.MethodGet, "/files/a/b.txt", "/files/*path"},
+		{http.MethodGet, "/api/v1/orders/9/items/3", "/api/v1/orders/:orderID/items/:itemID"},
+		{http.MethodGet, "/nope/123", "unmatched"},
+	} {
+		got = ""
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tc.method, tc.path, nil))
+		if got != tc.want {
+			t.Fatalf("%s %s: want %q, got %q", tc.method, tc.path, tc.want, got)
+		}
+	}
+}
+
+func TestStructuredLogger_RouteLabel(t *testing.T) {
+	var buf bytes.Buffer
+	app := zentrox.NewApp()
+	app.Plug(middleware.StructuredLogger(slog.NewTextHandler(&buf, nil)))
+	app.GET("/users/:id", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if !strings.Contains(buf.String(), "route=/users/:id") {
+		t.Fatalf("want the route template in %q", buf.String())
+	}
+}
+
+func TestSlowRequestByRoute(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.SlowRequest(middleware.SlowRequestConfig{
+		Threshold: time.Nanosecond,
+		Logger:    slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}))
+	app.GET("/items/:id", func(c *zentrox.Context) { c.SendStatus(http.StatusOK) })
+
+	m := middleware.SlowRequestsByRouteVar()
+	before := int64(0)
+	if v := m.Get("GET /items/:id"); v != nil {
+		before = v.(interface{ Value() int64 }).Value()
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/items/"+id, nil))
+	}
+	v := m.Get("GET /items/:id")
+	if v == nil || v.(interface{ Value() int64 }).Value()-before != 3 {
+		t.Fatalf("slow requests should be counted under the route template, got %v", v)
+	}
+	if m.Get("GET /items/1") != nil {
+		t.Fatal("raw paths must not become metric keys")
+	}
+}
+
+func TestRouteMeta(t *testing.T) {
+	var audited []string
+	app := zentrox.NewApp()
+	app.Plug(func(c *zentrox.Context) {
+		if audit, _ := c.RouteMeta()["audit"].(bool); audit {
+			audited = append(audited, c.RouteMeta()["scope"].(string))
+		}
+		c.Next()
+	})
+	h := func(c *zentrox.Context) { c.SendStatus(http.StatusOK) }
+	app.GET("/orders", h).Meta("audit", true).Meta("scope", "orders:read")
+	app.GET("/health", h)
+
+	for _, p := range []string{"/orders", "/health", "/missing"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, p, nil))
+	}
+	if len(audited) != 1 || audited[0] != "orders:read" {
+		t.Fatalf("audited = %v", audited)
+	}
+	for _, ri := range app.ListRoutes() {
+		if ri.Path == "/orders" && ri.Method == http.MethodGet && ri.Meta["scope"] != "orders:read" {
+			t.Fatalf("RouteInfo.Meta = %v", ri.Meta)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package z_test
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/aminofox/zentrox/v2"
@@ -94,3 +96,244 @@ func TestRouter_AutoHEAD(t *testing.T) {
 		t.Fatalf("HEAD should have empty body, got %d bytes", l)
 	}
 }
+
+func TestRouter_AnyAndMatch(t *testing.T) {
+	app := newApp()
+	echo := func(c *zentrox.Context) { c.String(http.StatusOK, "%s", c.Request.Method) }
+	app.ANY("/hook", echo)
+	app.Match([]string{"options", "GET", "post"}, "/cb", echo)
+	app.Scope("/v1").Match([]string{"PUT"}, "/x", echo)
+
+	cases := []struct {
+		method, path string
+		code         int
+	}{
+		{http.MethodGet, "/hook", 200},
+		{http.MethodPost, "/hook", 200},
+		{http.MethodPut, "/hook", 200},
+		{http.MethodPatch, "/hook", 200},
+		{http.MethodDelete, "/hook", 200},
+		{http.MethodGet, "/cb", 200},
+		{http.MethodPost, "/cb", 200},
+		{http.MethodOptions, "/cb", 200}, // explicit route, not the automatic 204
+		{http.MethodPut, "/cb", 405},
+		{http.MethodPut, "/v1/x", 200},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.code {
+			t.Fatalf("%s %s: want %d, got %d", tc.method, tc.path, tc.code, w.Code)
+		}
+		if tc.code == 200 && w.Body.String() != tc.method {
+			t.Fatalf("%s %s: body %q", tc.method, tc.path, w.Body.String())
+		}
+	}
+}
+
+func TestRouter_AutoOptionsAllow(t *testing.T) {
+	app := newApp()
+	ok := func(c *zentrox.Context) { c.SendStatus(http.StatusOK) }
+	app.GET("/items", ok)
+	app.POST("/items", ok)
+	app.Match([]string{http.MethodOptions}, "/custom", func(c *zentrox.Context) { c.String(http.StatusOK, "custom") })
+	app.GET("/custom", ok) // must not replace the explicit OPTIONS route
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/items", nil))
+	if w.Code != http.StatusNoContent || w.Header().Get("Allow") != "GET, HEAD, OPTIONS, POST" {
+		t.Fatalf("OPTIONS: %d Allow=%q", w.Code, w.Header().Get("Allow"))
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/items", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD, OPTIONS, POST" {
+		t.Fatalf("405: %d Allow=%q", w.Code, w.Header().Get("Allow"))
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/custom", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "custom" {
+		t.Fatalf("explicit OPTIONS overridden: %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRouter_ParamConstraints(t *testing.T) {
+	app := newApp()
+	app.GET("/users/:id|int", func(c *zentrox.Context) { c.String(http.StatusOK, "id=%s", c.Param("id")) })
+	app.GET("/users/:name", func(c *zentrox.Context) { c.String(http.StatusOK, "name=%s", c.Param("name")) })
+	app.GET("/files/:file|^[a-z0-9_-]+$", func(c *zentrox.Context) { c.String(http.StatusOK, "%s", c.Param("file")) })
+	app.GET("/codes/:code|a|b", func(c *zentrox.Context) { c.String(http.StatusOK, "%s", c.Param("code")) })
+	app.GET("/users/:id|int/posts", func(c *zentrox.Context) { c.String(http.StatusOK, "posts of %s", c.Param("id")) })
+	app.GET("/users/:name/profile", func(c *zentrox.Context) { c.String(http.StatusOK, "profile of %s", c.Param("name")) })
+	app.POST("/users/:name/posts", func(c *zentrox.Context) { c.String(http.StatusOK, "post as %s", c.Param("name")) })
+
+	cases := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/users/42", 200, "id=42"},
+		{"/users/bob", 200, "name=bob"},
+		{"/files/report_1", 200, "report_1"},
+		{"/files/Report", 404, ""},
+		{"/codes/a", 200, "a"},
+		{"/codes/ab", 404, ""},
+		// A constrained param that accepts the segment falls back to the
+		// plain one when its subtree has no matching route.
+		{"/users/42/posts", 200, "posts of 42"},
+		{"/users/42/profile", 200, "profile of 42"},
+		{"/users/bob/posts", 405, ""},
+		{"POST /users/42/posts", 200, "post as 42"},
+	}
+	for _, tc := range cases {
+		method, path, ok := strings.Cut(tc.path, " ")
+		if !ok {
+			method, path = http.MethodGet, tc.path
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		if w.Code != tc.code || (tc.body != "" && w.Body.String() != tc.body) {
+			t.Fatalf("%s: %d %q", tc.path, w.Code, w.Body.String())
+		}
+	}
+
+	if u, err := zentrox.BuildPath("/users/:id|int", "id", 7); err != nil || u != "/users/7" {
+		t.Fatalf("BuildPath: %q %v", u, err)
+	}
+}
+
+func TestRouter_ConstraintWithSlash(t *testing.T) {
+	app := newApp()
+	app.GET(`/files/:name|[^/]+\.txt/raw`, func(c *zentrox.Context) { c.String(http.StatusOK, "%s", c.Param("name")) })
+	app.GET("/keys/:key|(a/b|c)", func(c *zentrox.Context) { c.String(http.StatusOK, "%s", c.Param("key")) })
+
+	cases := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/files/a.txt/raw", 200, "a.txt"},
+		{"/files/a.png/raw", 404, ""},
+		{"/keys/c", 200, "c"},
+		{"/keys/a", 404, ""},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if w.Code != tc.code || (tc.body != "" && w.Body.String() != tc.body) {
+			t.Fatalf("%s: %d %q", tc.path, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestRouter_InvalidConstraintPanics(t *testing.T) {
+	defer func() {
+		msg := fmt.Sprint(recover())
+		if !strings.Contains(msg, `"/bad/:id|[a-"`) {
+			t.Fatalf("panic should name the route pattern, got %q", msg)
+		}
+	}()
+	newApp().GET("/bad/:id|[a-", func(c *zentrox.Context) {})
+}
+
+func TestRouter_RouterOptions(t *testing.T) {
+	app := newApp()
+	app.SetRouterOptions(zentrox.RouterOptions{RedirectTrailingSlash: true, RedirectFixedPath: true})
+	ok := func(c *zentrox.Context) { c.String(http.StatusOK, "%s", c.Param("id")) }
+	app.GET("/users", ok)
+	app.GET("/users/:id", ok)
+	app.POST("/users", ok)
+	legacy := app.Scope("/legacy").SetRouterOptions(zentrox.RouterOptions{CaseInsensitiveRouting: true})
+	legacy.GET("/Reports/:id", ok)
+
+	cases := []struct {
+		method, path string
+		code         int
+		location     string
+		body         string
+	}{
+		{http.MethodGet, "/users", 200, "", ""},
+		{http.MethodGet, "/users/", 301, "/users", ""},
+		{http.MethodGet, "/Users/?page=2", 301, "/users?page=2", ""},
+		{http.MethodGet, "/USERS/AbC", 301, "/users/AbC", ""},
+		{http.MethodGet, "//users", 301, "/users", ""},
+		{http.MethodGet, "/a/../users", 301, "/users", ""},
+		{http.MethodPost, "/users/", 308, "/users", ""},
+		{http.MethodGet, "/legacy/reports/7", 200, "", "7"},
+		{http.MethodGet, "/legacy/REPORTS/7/", 200, "", "7"},
+		{http.MethodGet, "/nope", 404, "", ""},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != tc.code || w.Header().Get("Location") != tc.location {
+			t.Fatalf("%s %s: %d Location=%q", tc.method, tc.path, w.Code, w.Header().Get("Location"))
+		}
+		if tc.body != "" && w.Body.String() != tc.body {
+			t.Fatalf("%s %s: body %q", tc.method, tc.path, w.Body.String())
+		}
+	}
+
+	// Off by default: a trailing slash is tolerated, case is not.
+	plain := newApp()
+	plain.GET("/users", ok)
+	for path, code := range map[string]int{"/users/": 200, "/Users": 404} {
+		w := httptest.NewRecorder()
+		plain.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != code {
+			t.Fatalf("default %s: %d", path, w.Code)
+		}
+	}
+}
+
+func TestRouter_HostRouting(t *testing.T) {
+	app := newApp()
+	say := func(s string) zentrox.Handler {
+		return func(c *zentrox.Context) { c.String(http.StatusOK, "%s", s) }
+	}
+	app.GET("/", say("default"))
+	app.GET("/users", say("default users"))
+	api := app.Host("API.example.com")
+	api.GET("/users", say("api users"))
+	api.Subdomain("v2").GET("/users", say("v2 users"))
+	app.Host("*.example.com").GET("/", say("tenant"))
+	app.Scope("/panel").Subdomain("admin").GET("/", say("admin"))
+
+	cases := []struct{ host, path, body string }{
+		{"api.example.com", "/users", "api users"},
+		{"api.example.com:8080", "/users", "api users"},
+		{"v2.api.example.com", "/users", "v2 users"},
+		{"api.example.com", "/", "tenant"},
+		{"acme.example.com", "/", "tenant"},
+		{"acme.example.com", "/users", "default users"},
+		{"example.com", "/", "default"},
+		{"admin.localhost", "/panel/", "admin"},
+		{"other.localhost", "/panel/", ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		req.Host = tc.host
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if tc.body == "" {
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("%s%s: want 404, got %d", tc.host, tc.path, w.Code)
+			}
+			continue
+		}
+		if w.Code != http.StatusOK || w.Body.String() != tc.body {
+			t.Fatalf("%s%s: %d %q", tc.host, tc.path, w.Code, w.Body.String())
+		}
+	}
+
+	hosts := 0
+	for _, ri := range app.ListRoutes() {
+		if ri.Host != "" && ri.Method == http.MethodGet {
+			hosts++
+		}
+	}
+	if hosts != 4 {
+		t.Fatalf("want 4 host routes, got %d", hosts)
+	}
+}
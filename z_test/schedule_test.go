@@ -0,0 +1,99 @@
+package z_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestParseCron(t *testing.T) {
+	base := time.Date(2026, time.March, 14, 10, 7, 30, 0, time.UTC) // a Saturday
+	cases := []struct {
+		spec string
+		want time.Time
+	}{
+		{"*/5 * * * *", time.Date(2026, 3, 14, 10, 10, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2026, 3, 15, 2, 30, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"15,45 8-9 * * *", time.Date(2026, 3, 15, 8, 15, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC)},
+		// Day-of-month and day-of-week both restricted: either matches.
+		{"0 0 20 * 1", time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", base.Add(90 * time.Second)},
+	}
+	for _, tc := range cases {
+		s, err := zentrox.ParseCron(tc.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.spec, err)
+		}
+		if got := s.Next(base); !got.Equal(tc.want) {
+			t.Errorf("%s: next = %v, want %v", tc.spec, got, tc.want)
+		}
+	}
+	for _, bad := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every -1s", "* * * foo *"} {
+		if _, err := zentrox.ParseCron(bad); err == nil {
+			t.Errorf("%q: want error", bad)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	app := zentrox.NewApp()
+	app.SetQuiet(true)
+	var runs, panics, overlapping atomic.Int32
+	app.Schedule("@every 10ms", func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	app.Schedule("@every 10ms", func(ctx context.Context) error {
+		if panics.Add(1) == 1 {
+			panic("boom")
+		}
+		return nil
+	})
+	slow := app.Schedule("@every 10ms", func(ctx context.Context) error {
+		overlapping.Add(1)
+		select {
+		case <-time.After(45 * time.Millisecond):
+		case <-ctx.Done():
+		}
+		return nil
+	})
+	if !slow.Next().IsZero() {
+		t.Fatal("jobs must not run before the server starts")
+	}
+
+	srv, err := app.Start(&zentrox.ServerConfig{Addr: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if slow.Next().IsZero() {
+		t.Fatal("Next should be set while running")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := app.Shutdown(ctx, srv); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	n := runs.Load()
+	if n < 4 {
+		t.Fatalf("runs = %d", n)
+	}
+	if panics.Load() < 2 {
+		t.Fatalf("job stopped after a panic: %d", panics.Load())
+	}
+	if o := overlapping.Load(); o < 1 || o > 3 {
+		t.Fatalf("slow job ran %d times; overlapping runs should be skipped", o)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if runs.Load() != n {
+		t.Fatal("jobs ran after shutdown")
+	}
+}
@@ -0,0 +1,312 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+	"github.com/aminofox/zentrox/v2/session"
+)
+
+func sessionCookie(t *testing.T, w *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == "zentrox_session" {
+			return ck
+		}
+	}
+	return nil
+}
+
+func TestSessionRegenerate(t *testing.T) {
+	store := session.NewMemoryStore()
+	app := zentrox.NewApp()
+	app.Plug(session.Middleware(session.Config{Store: store}))
+	app.GET("/visit", func(c *zentrox.Context) {
+		session.From(c).Set("cart", "book")
+		c.SendStatus(http.StatusNoContent)
+	})
+	app.POST("/login", func(c *zentrox.Context) {
+		s := session.From(c)
+		s.Regenerate()
+		s.Set("user", "ann")
+		c.SendStatus(http.StatusNoContent)
+	})
+	app.GET("/me", func(c *zentrox.Context) {
+		s := session.From(c)
+		user, _ := s.Get("user")
+		cart, _ := s.Get("cart")
+		c.String(http.StatusOK, "%v:%v", user, cart)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/me", nil))
+	if sessionCookie(t, w) != nil {
+		t.Fatal("untouched new session should not set a cookie")
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/visit", nil))
+	first := sessionCookie(t, w)
+	if first == nil || !first.HttpOnly {
+		t.Fatalf("expected HttpOnly session cookie, got %+v", first)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.AddCookie(first)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	second := sessionCookie(t, w)
+	if second == nil || second.Value == first.Value {
+		t.Fatalf("login should issue a new session id, got %+v", second)
+	}
+	if n := len(w.Result().Cookies()); n != 1 {
+		t.Fatalf("want a single Set-Cookie, got %d", n)
+	}
+	if _, ok, _ := store.Load(first.Value); ok {
+		t.Fatal("old session id must be invalidated")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.AddCookie(second)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Body.String() != "ann:book" {
+		t.Fatalf("data should survive regeneration, got %q", w.Body.String())
+	}
+}
+
+func TestSessionTimeouts(t *testing.T) {
+	store := session.NewMemoryStore()
+	app := zentrox.NewApp()
+	app.Plug(session.Middleware(session.Config{
+		Store:           store,
+		IdleTimeout:     time.Hour,
+		AbsoluteTimeout: 2 * time.Hour,
+	}))
+	app.GET("/", func(c *zentrox.Context) {
+		s := session.From(c)
+		_, ok := s.Get("user")
+		if !ok {
+			s.Set("user", "ann")
+		}
+		c.String(http.StatusOK, "%v", ok)
+	})
+
+	now := time.Now()
+	do := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "zentrox_session", Value: id})
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	_ = store.Save("idle", session.Data{Values: map[string]any{"user": "ann"}, CreatedAt: now.Add(-10 * time.Minute), LastSeen: now.Add(-61 * time.Minute)}, time.Hour)
+	if w := do("idle"); w.Body.String() != "false" || sessionCookie(t, w).Value == "idle" {
+		t.Fatalf("idle session should be replaced, got %q", w.Body.String())
+	}
+
+	_ = store.Save("old", session.Data{Values: map[string]any{"user": "ann"}, CreatedAt: now.Add(-3 * time.Hour), LastSeen: now}, time.Hour)
+	if w := do("old"); w.Body.String() != "false" {
+		t.Fatalf("session past absolute timeout should be replaced, got %q", w.Body.String())
+	}
+
+	_ = store.Save("live", session.Data{Values: map[string]any{"user": "ann"}, CreatedAt: now.Add(-110 * time.Minute), LastSeen: now.Add(-30 * time.Minute)}, time.Hour)
+	w := do("live")
+	ck := sessionCookie(t, w)
+	if w.Body.String() != "true" || ck == nil || ck.Value != "live" {
+		t.Fatalf("active session should be renewed, got %q %+v", w.Body.String(), ck)
+	}
+	if ck.MaxAge > 10*60+1 {
+		t.Fatalf("renewal must be capped by the absolute timeout, got max-age %d", ck.MaxAge)
+	}
+	d, ok, _ := store.Load("live")
+	if !ok || now.Sub(d.LastSeen) > time.Minute {
+		t.Fatalf("last seen should slide forward, got %+v", d)
+	}
+}
+
+func TestSessionSave(t *testing.T) {
+	store := session.NewMemoryStore()
+	app := zentrox.NewApp()
+	app.Plug(session.Middleware(session.Config{Store: store}))
+	saved := make(chan bool, 1)
+	app.GET("/stream", func(c *zentrox.Context) {
+		s := session.From(c)
+		s.Set("step", 1)
+		if err := s.Save(); err != nil {
+			t.Error(err)
+		}
+		_, ok, _ := store.Load(s.ID())
+		saved <- ok
+		c.SendStatus(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if !<-saved {
+		t.Fatal("Save should persist before the handler returns")
+	}
+	if sessionCookie(t, w) == nil {
+		t.Fatal("expected session cookie")
+	}
+}
+
+func TestSessionRedisStore(t *testing.T) {
+	fake := startFakeRedis(t)
+	store := session.NewRedisStore(session.RedisConfig{Addr: fake.Addr()})
+	defer store.Close()
+
+	app := zentrox.NewApp()
+	app.Plug(session.Middleware(session.Config{Store: store}))
+	app.POST("/login", func(c *zentrox.Context) {
+		session.From(c).Set("user", "ann")
+		c.SendStatus(http.StatusNoContent)
+	})
+	app.GET("/me", func(c *zentrox.Context) {
+		user, _ := session.From(c).Get("user")
+		c.String(http.StatusOK, "%v", user)
+	})
+	app.POST("/logout", func(c *zentrox.Context) {
+		session.From(c).Destroy()
+		c.SendStatus(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+	ck := sessionCookie(t, w)
+	if ck == nil {
+		t.Fatal("expected session cookie")
+	}
+	if d, ok, err := store.Load(ck.Value); err != nil || !ok || d.Values["user"] != "ann" {
+		t.Fatalf("redis load = %+v %v %v", d, ok, err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.AddCookie(ck)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Body.String() != "ann" {
+		t.Fatalf("got %q", w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/logout", nil)
+	req.AddCookie(ck)
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	if _, ok, _ := store.Load(ck.Value); ok {
+		t.Fatal("destroyed session should be deleted from redis")
+	}
+}
+
+func TestSessionCookieStore(t *testing.T) {
+	oldKey := []byte("0123456789abcdef0123456789abcdef")
+	newKey := []byte("fedcba9876543210fedcba9876543210")
+	store, err := session.NewCookieStore(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers := func(app *zentrox.App) {
+		app.POST("/login", func(c *zentrox.Context) {
+			session.From(c).Set("user", "ann")
+			c.String(http.StatusOK, "ok")
+		})
+		app.GET("/me", func(c *zentrox.Context) {
+			user, _ := session.From(c).Get("user")
+			c.String(http.StatusOK, "%v", user)
+		})
+	}
+	app := zentrox.NewApp()
+	app.Plug(session.Middleware(session.Config{Store: store}))
+	handlers(app)
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login", nil))
+	ck := sessionCookie(t, w)
+	if ck == nil {
+		t.Fatal("cookie must be written before the body")
+	}
+
+	get := func(app *zentrox.App, ck *http.Cookie) string {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.AddCookie(ck)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Body.String()
+	}
+	if got := get(app, ck); got != "ann" {
+		t.Fatalf("got %q", got)
+	}
+
+	tampered := *ck
+	tampered.Value = ck.Value[:len(ck.Value)-2] + "AA"
+	if got := get(app, &tampered); got != "<nil>" {
+		t.Fatalf("tampered cookie must be rejected, got %q", got)
+	}
+
+	// Rotation: new key first, old key still decrypts.
+	rotated, _ := session.NewCookieStore(newKey, oldKey)
+	app2 := zentrox.NewApp()
+	app2.Plug(session.Middleware(session.Config{Store: rotated}))
+	handlers(app2)
+	if got := get(app2, ck); got != "ann" {
+		t.Fatalf("rotated store should read old cookie, got %q", got)
+	}
+
+	if _, err := session.NewCookieStore([]byte("short")); err == nil {
+		t.Fatal("invalid key size should fail")
+	}
+}
+
+func TestSessionMiddlewareContext(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.Session(session.NewMemoryStore()))
+	app.POST("/cart", func(c *zentrox.Context) {
+		c.Session().Set("cart", "book")
+		c.Session().Set("coupon", "SAVE10")
+		c.SendStatus(http.StatusNoContent)
+	})
+	app.DELETE("/coupon", func(c *zentrox.Context) {
+		c.Session().Delete("coupon")
+		if err := c.Session().Save(); err != nil {
+			t.Error(err)
+		}
+		c.SendStatus(http.StatusNoContent)
+	})
+	app.GET("/cart", func(c *zentrox.Context) {
+		cart, _ := c.Session().Get("cart")
+		_, hasCoupon := c.Session().Get("coupon")
+		c.String(http.StatusOK, "%v %v", cart, hasCoupon)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/cart", nil))
+	ck := sessionCookie(t, w)
+	if ck == nil {
+		t.Fatal("no session cookie")
+	}
+	for _, step := range []struct{ method, path, want string }{
+		{http.MethodGet, "/cart", "book true"},
+		{http.MethodDelete, "/coupon", ""},
+		{http.MethodGet, "/cart", "book false"},
+	} {
+		r := httptest.NewRequest(step.method, step.path, nil)
+		r.AddCookie(ck)
+		w = httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		if w.Body.String() != step.want {
+			t.Fatalf("%s %s = %q, want %q", step.method, step.path, w.Body.String(), step.want)
+		}
+	}
+
+	bare := zentrox.NewApp()
+	bare.GET("/", func(c *zentrox.Context) {
+		if c.Session() != nil {
+			t.Error("Session without middleware should be nil")
+		}
+	})
+	bare.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
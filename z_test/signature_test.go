@@ -0,0 +1,99 @@
+package z_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestVerifySignature(t *testing.T) {
+	secret := []byte("partner-secret")
+	app := zentrox.NewApp()
+	partner := app.Scope("/partner",
+		middleware.VerifySignature(middleware.DefaultSignature(secret)),
+		middleware.Nonce(middleware.DefaultNonce()),
+	)
+	partner.POST("/orders", func(c *zentrox.Context) {
+		b, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusCreated, "%s", b)
+	})
+
+	newReq := func(body, nonce string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/partner/orders?src=api", strings.NewReader(body))
+		req.Header.Set(zentrox.HeaderXNonce, nonce)
+		if err := zentrox.SignRequest(req, secret); err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve(newReq(`{"qty":1}`, "n-1")); w.Code != http.StatusCreated || w.Body.String() != `{"qty":1}` {
+		t.Fatalf("signed request: %d %q", w.Code, w.Body.String())
+	}
+	if w := serve(newReq(`{"qty":1}`, "n-1")); w.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed nonce: want 401, got %d", w.Code)
+	}
+
+	req := newReq(`{"qty":1}`, "n-2")
+	req.Body = io.NopCloser(strings.NewReader(`{"qty":9}`))
+	if w := serve(req); w.Code != http.StatusUnauthorized {
+		t.Fatalf("tampered body: want 401, got %d", w.Code)
+	}
+	req = newReq(`{}`, "n-3")
+	req.Header.Set(zentrox.HeaderXNonce, "n-4")
+	if w := serve(req); w.Code != http.StatusUnauthorized {
+		t.Fatalf("tampered nonce: want 401, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/partner/orders", strings.NewReader(`{}`))
+	ts := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	req.Header.Set(zentrox.HeaderXNonce, "n-5")
+	req.Header.Set(zentrox.HeaderXTimestamp, ts)
+	req.Header.Set(zentrox.HeaderXSignature, zentrox.RequestSignature(secret, ts, "n-5", http.MethodPost, "/partner/orders", []byte(`{}`)))
+	if w := serve(req); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), zentrox.MsgStaleRequest) {
+		t.Fatalf("stale timestamp: %d %s", w.Code, w.Body.String())
+	}
+
+	if w := serve(httptest.NewRequest(http.MethodPost, "/partner/orders", nil)); w.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned: want 401, got %d", w.Code)
+	}
+}
+
+func TestVerifySignature_KeyFunc(t *testing.T) {
+	keys := map[string][]byte{"acme": []byte("acme-key")}
+	app := zentrox.NewApp()
+	app.Plug(middleware.VerifySignature(middleware.SignatureConfig{
+		KeyFunc: func(c *zentrox.Context) []byte { return keys[c.GetHeader("X-API-Key")] },
+	}))
+	app.GET("/ping", func(c *zentrox.Context) { c.SendStatus(http.StatusNoContent) })
+
+	send := func(apiKey string, secret []byte) int {
+		req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		_ = zentrox.SignRequest(req, secret)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+	if code := send("acme", keys["acme"]); code != http.StatusNoContent {
+		t.Fatalf("known partner: want 204, got %d", code)
+	}
+	if code := send("acme", []byte("wrong")); code != http.StatusUnauthorized {
+		t.Fatalf("wrong key: want 401, got %d", code)
+	}
+	if code := send("other", keys["acme"]); code != http.StatusUnauthorized {
+		t.Fatalf("unknown partner: want 401, got %d", code)
+	}
+}
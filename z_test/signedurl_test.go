@@ -0,0 +1,71 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestSignedURL(t *testing.T) {
+	secret := []byte("k3y")
+	app := zentrox.NewApp().SetURLSigningKey(secret)
+	app.GET("/files/:name", middleware.VerifySignedURL(secret), func(c *zentrox.Context) {
+		c.String(http.StatusOK, "%s for %s", c.Param("name"), c.Query("user"))
+	})
+
+	link, err := app.SignURL("/files/report.pdf", url.Values{"user": {"ann"}}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link, nil))
+	if w.Code != http.StatusOK || w.Body.String() != "report.pdf for ann" {
+		t.Fatalf("valid link rejected: %d %s", w.Code, w.Body.String())
+	}
+
+	for name, target := range map[string]string{
+		"tampered param": strings.Replace(link, "user=ann", "user=bob", 1),
+		"other path":     strings.Replace(link, "report.pdf", "secret.pdf", 1),
+		"unsigned":       "/files/report.pdf?user=ann",
+		"expired":        zentrox.SignURLWithKey(secret, "/files/report.pdf", nil, -time.Minute),
+	} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusForbidden {
+			t.Fatalf("%s: want 403, got %d", name, w.Code)
+		}
+		if name == "expired" && !strings.Contains(w.Body.String(), zentrox.MsgLinkExpired) {
+			t.Fatalf("expired link should say so: %s", w.Body.String())
+		}
+	}
+
+	// Escaped segments verify, and %2F does not collide with "/".
+	for _, path := range []string{"/files/a%20b", "/files/a b", "/files/a%2Fb"} {
+		link := zentrox.SignURLWithKey(secret, path, nil, time.Minute)
+		u, _ := url.Parse(link)
+		if err := zentrox.VerifyURLSignature(secret, u, time.Now()); err != nil {
+			t.Fatalf("%s: %v (%s)", path, err, link)
+		}
+	}
+	link = zentrox.SignURLWithKey(secret, "/files/a%2Fb", nil, time.Minute)
+	u, _ := url.Parse(strings.Replace(link, "%2F", "/", 1))
+	if err := zentrox.VerifyURLSignature(secret, u, time.Now()); err != zentrox.ErrSignedURLInvalid {
+		t.Fatalf("%%2F and / must differ: %v", err)
+	}
+	link, _ = app.SignURL("/files/my%20report.pdf", nil, time.Minute)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, link, nil))
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "my report.pdf") {
+		t.Fatalf("escaped link rejected: %d %s", w.Code, w.Body.String())
+	}
+
+	if _, err := zentrox.NewApp().SignURL("/x", nil, time.Minute); err != zentrox.ErrNoSigningKey {
+		t.Fatalf("want ErrNoSigningKey, got %v", err)
+	}
+}
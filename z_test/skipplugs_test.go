@@ -0,0 +1,57 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func tagger() zentrox.Handler {
+	return func(c *zentrox.Context) {
+		c.SetHeader("X-Tagged", "1")
+		c.Next()
+	}
+}
+
+func TestSkipPlugs(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(tagger())
+	ok := func(c *zentrox.Context) { c.SendStatus(http.StatusOK) }
+	app.GET("/a", ok)
+	app.GET("/healthz", ok).SkipPlugs("tagger")
+	ops := app.Scope("/ops").SkipPlugs("Tagger")
+	ops.GET("/metrics", ok)
+
+	for path, want := range map[string]string{"/a": "1", "/healthz": "", "/ops/metrics": ""} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || w.Header().Get("X-Tagged") != want {
+			t.Fatalf("%s: code=%d tagged=%q, want %q", path, w.Code, w.Header().Get("X-Tagged"), want)
+		}
+	}
+	for _, ri := range app.ListRoutes() {
+		if ri.Path == "/healthz" && len(ri.Middlewares) != 0 {
+			t.Fatalf("route info still lists %v", ri.Middlewares)
+		}
+	}
+}
+
+func TestUnless(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(middleware.Unless(middleware.PathIs("/healthz", "/internal/*"), tagger()))
+	ok := func(c *zentrox.Context) { c.SendStatus(http.StatusOK) }
+	app.GET("/a", ok)
+	app.GET("/healthz", ok)
+	app.GET("/internal/x", ok)
+
+	for path, want := range map[string]string{"/a": "1", "/healthz": "", "/internal/x": ""} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK || w.Header().Get("X-Tagged") != want {
+			t.Fatalf("%s: code=%d tagged=%q, want %q", path, w.Code, w.Header().Get("X-Tagged"), want)
+		}
+	}
+}
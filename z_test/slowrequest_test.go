@@ -0,0 +1,49 @@
+package z_test
+
+import (
+	"bytes"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestSlowRequest(t *testing.T) {
+	var buf bytes.Buffer
+	count := new(expvar.Int)
+	app := zentrox.NewApp()
+	app.Plug(middleware.RequestID(middleware.DefaultRequestID()))
+	app.Plug(middleware.SlowRequest(middleware.SlowRequestConfig{
+		Threshold: 20 * time.Millisecond,
+		Logger:    slog.New(slog.NewTextHandler(&buf, nil)),
+		Counter:   count,
+	}))
+	app.GET("/reports/:id", func(c *zentrox.Context) {
+		if c.Query("slow") != "" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		c.SendStatus(http.StatusOK)
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports/1", nil))
+	if count.Value() != 0 || buf.Len() != 0 {
+		t.Fatalf("fast request must not be flagged: %s", buf.String())
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/reports/7?slow=1", nil))
+	out := buf.String()
+	if count.Value() != 1 {
+		t.Fatalf("want counter 1, got %d", count.Value())
+	}
+	for _, want := range []string{"level=WARN", "route=/reports/:id", "params=map[id:7]", "request_id="} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("log missing %q: %s", want, out)
+		}
+	}
+}
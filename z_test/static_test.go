@@ -0,0 +1,62 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestStatic_RangeAndConditional(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "video.bin"), []byte("0123456789"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	app := zentrox.NewApp()
+	app.Static("/media", zentrox.StaticOptions{Dir: dir, UseStrongETag: true})
+
+	get := func(hdr ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/media/video.bin", nil)
+		for i := 0; i+1 < len(hdr); i += 2 {
+			req.Header.Set(hdr[i], hdr[i+1])
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get()
+	etag := w.Header().Get(zentrox.HeaderETag)
+	if w.Code != 200 || w.Header().Get("Accept-Ranges") != "bytes" || etag == "" {
+		t.Fatalf("GET: %d %v", w.Code, w.Header())
+	}
+
+	w = get("Range", "bytes=2-5")
+	if w.Code != http.StatusPartialContent || w.Body.String() != "2345" ||
+		w.Header().Get("Content-Range") != "bytes 2-5/10" {
+		t.Fatalf("Range: %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	// If-Range with the current ETag honours the range; a stale one sends
+	// the whole file.
+	if w = get("Range", "bytes=8-", "If-Range", etag); w.Code != http.StatusPartialContent || w.Body.String() != "89" {
+		t.Fatalf("If-Range match: %d %q", w.Code, w.Body.String())
+	}
+	if w = get("Range", "bytes=8-", "If-Range", `"stale"`); w.Code != 200 || w.Body.String() != "0123456789" {
+		t.Fatalf("If-Range stale: %d %q", w.Code, w.Body.String())
+	}
+
+	if w = get("Range", "bytes=20-"); w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Fatalf("unsatisfiable range: %d", w.Code)
+	}
+	if w = get(zentrox.HeaderIfNoneMatch, etag); w.Code != http.StatusNotModified {
+		t.Fatalf("If-None-Match: %d", w.Code)
+	}
+	lastMod := get().Header().Get(zentrox.HeaderLastModified)
+	if w = get(zentrox.HeaderIfModifiedSince, lastMod); w.Code != http.StatusNotModified {
+		t.Fatalf("If-Modified-Since: %d", w.Code)
+	}
+}
@@ -0,0 +1,72 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestStatic_DirectoryListing(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"a.txt":            "a",
+		".secret":          "s",
+		"<script>x.txt":    "x",
+		"sub dir/b.txt":    "b",
+		"docs/README.html": "readme",
+		"site/index.html":  "home",
+		"site/other.html":  "other",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app := zentrox.NewApp()
+	app.Static("/files", zentrox.StaticOptions{
+		Dir:      dir,
+		Index:    "index.html",
+		DirIndex: map[string]string{"/docs": "README.html"},
+		Browse:   true,
+	})
+	var listed zentrox.DirListing
+	app.Static("/raw", zentrox.StaticOptions{
+		Dir:          dir,
+		Browse:       true,
+		BrowseRender: func(c *zentrox.Context, l zentrox.DirListing) { listed = l; c.JSON(200, l) },
+	})
+	get := func(p string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, p, nil))
+		return w
+	}
+
+	w := get("/files/")
+	body := w.Body.String()
+	if w.Code != 200 || !strings.Contains(body, `href="/files/a.txt"`) || !strings.Contains(body, `href="/files/sub%20dir/"`) {
+		t.Fatalf("listing: %d %s", w.Code, body)
+	}
+	if strings.Contains(body, ".secret") || strings.Contains(body, "<script>") {
+		t.Fatalf("listing leaks dotfiles or unescaped names: %s", body)
+	}
+	if w = get("/files/sub%20dir/"); !strings.Contains(w.Body.String(), `href="/files/"`) {
+		t.Fatalf("missing parent link: %s", w.Body.String())
+	}
+	if w = get("/files/site/"); w.Body.String() != "home" {
+		t.Fatalf("default index: %q", w.Body.String())
+	}
+	if w = get("/files/docs"); w.Body.String() != "readme" {
+		t.Fatalf("per-directory index: %q", w.Body.String())
+	}
+
+	if w = get("/raw/site"); w.Code != 200 || len(listed.Entries) != 2 || listed.Path != "/raw/site/" || listed.Parent != "/raw/" {
+		t.Fatalf("custom render: %d %+v", w.Code, listed)
+	}
+}
@@ -0,0 +1,70 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestStaticFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "favicon.ico")
+	if err := os.WriteFile(file, []byte("icon"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	app := zentrox.NewApp()
+	app.StaticFile("/favicon.ico", file, zentrox.StaticFileOptions{MaxAge: 24 * time.Hour})
+	app.StaticFile("/missing.txt", filepath.Join(dir, "missing.txt"))
+	app.StaticFileFS("/robots.txt", fstest.MapFS{
+		"public/robots.txt": {Data: []byte("User-agent: *\n"), ModTime: time.Unix(1700000000, 0)},
+	}, "public/robots.txt")
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/favicon.ico", nil))
+	if w.Code != 200 || w.Body.String() != "icon" {
+		t.Fatalf("GET favicon: %d %q", w.Code, w.Body.String())
+	}
+	if cc := w.Header().Get(zentrox.HeaderCacheControl); cc != "public, max-age=86400" {
+		t.Fatalf("Cache-Control: %q", cc)
+	}
+	etag := w.Header().Get(zentrox.HeaderETag)
+	if etag == "" || w.Header().Get(zentrox.HeaderLastModified) == "" {
+		t.Fatalf("missing validators: %v", w.Header())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/favicon.ico", nil)
+	req.Header.Set(zentrox.HeaderIfNoneMatch, etag)
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Fatalf("conditional GET: want 304, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/favicon.ico", nil))
+	if w.Code != 200 || w.Body.Len() != 0 {
+		t.Fatalf("HEAD: %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing.txt", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("missing file: want 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/robots.txt", nil))
+	if w.Code != 200 || w.Body.String() != "User-agent: *\n" {
+		t.Fatalf("StaticFileFS: %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get(zentrox.HeaderContentType); ct != zentrox.ContentTypeTextUTF8 {
+		t.Fatalf("Content-Type: %q", ct)
+	}
+}
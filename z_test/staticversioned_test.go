@@ -0,0 +1,56 @@
+package z_test
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestStaticVersioned(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "css"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "app.js"), []byte("console.log(1)"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "css", "site.css"), []byte("body{}"), 0o644)
+
+	app := zentrox.NewApp()
+	app.StaticVersioned("/assets", zentrox.StaticVersionedOptions{Dir: dir})
+	var url string
+	app.GET("/", func(c *zentrox.Context) { url = c.AssetURL("app.js") })
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if !regexp.MustCompile(`^/assets/app\.[0-9a-f]{6}\.js$`).MatchString(url) {
+		t.Fatalf("AssetURL = %q", url)
+	}
+	if got := app.AssetURL("/assets/css/site.css"); !regexp.MustCompile(`^/assets/css/site\.[0-9a-f]{6}\.css$`).MatchString(got) {
+		t.Fatalf("AssetURL by URL = %q", got)
+	}
+	if got := app.AssetURL("missing.js"); got != "missing.js" {
+		t.Fatalf("unknown asset = %q", got)
+	}
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	if w.Code != 200 || w.Body.String() != "console.log(1)" || w.Header().Get(zentrox.HeaderCacheControl) != zentrox.CacheControlImmutable {
+		t.Fatalf("hashed: %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets/app.js", nil))
+	if w.Code != 200 || w.Header().Get(zentrox.HeaderCacheControl) != zentrox.CacheControlNoCache {
+		t.Fatalf("plain: %d %v", w.Code, w.Header())
+	}
+
+	var buf bytes.Buffer
+	tmpl := template.Must(template.New("page").Funcs(app.AssetFuncs()).Parse(`<script src="{{asset "app.js"}}"></script>`))
+	_ = tmpl.Execute(&buf, nil)
+	if buf.String() != `<script src="`+url+`"></script>` {
+		t.Fatalf("template: %s", buf.String())
+	}
+}
@@ -0,0 +1,123 @@
+package z_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// fakeS3 is a path-style object store that requires SigV4 headers.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AK/") ||
+		r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		b, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(b)
+		f.types[r.URL.Path] = r.Header.Get(zentrox.HeaderContentType)
+	case http.MethodGet:
+		v, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, v)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestUploadStorage_S3(t *testing.T) {
+	fake := &fakeS3{objects: map[string]string{}, types: map[string]string{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	store := zentrox.NewS3Storage(zentrox.S3Config{
+		Endpoint: srv.URL, Bucket: "media", AccessKey: "AK", SecretKey: "SK", PathStyle: true,
+	})
+	var (
+		key string
+		err error
+	)
+	app := zentrox.NewApp()
+	app.POST("/upload", func(c *zentrox.Context) {
+		key, err = c.SaveUploadedFile("file", "avatars", zentrox.UploadOptions{Storage: store})
+		c.SendStatus(http.StatusNoContent)
+	})
+	app.ServeHTTP(httptest.NewRecorder(), multipartRequest(t, "file", uploadPart{"me.png", pngHeader}))
+	if err != nil || key != "avatars/me.png" {
+		t.Fatalf("key=%q err=%v", key, err)
+	}
+	if fake.objects["/media/avatars/me.png"] != string(pngHeader) || fake.types["/media/avatars/me.png"] != "image/png" {
+		t.Fatalf("objects = %v types = %v", fake.objects, fake.types)
+	}
+
+	ctx := context.Background()
+	rc, err := store.Open(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(b) != string(pngHeader) {
+		t.Fatalf("Open = %q", b)
+	}
+
+	u, err := store.URL(ctx, key)
+	if err != nil || !strings.HasPrefix(u, srv.URL+"/media/avatars/me.png?") || !strings.Contains(u, "X-Amz-Signature=") {
+		t.Fatalf("URL = %q, %v", u, err)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Open(ctx, key); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("want fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestUploadStorage_Local(t *testing.T) {
+	ctx := context.Background()
+	store := zentrox.NewLocalStorage(t.TempDir(), "/uploads")
+	if err := store.Save(ctx, "a/b.txt", strings.NewReader("hi"), 2, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := store.Open(ctx, "a/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(b) != "hi" {
+		t.Fatalf("Open = %q", b)
+	}
+	if u, _ := store.URL(ctx, "a/b c.txt"); u != "/uploads/a/b%20c.txt" {
+		t.Fatalf("URL = %q", u)
+	}
+	if err := store.Delete(ctx, "a/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Open(ctx, "a/b.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("want fs.ErrNotExist, got %v", err)
+	}
+}
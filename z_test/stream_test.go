@@ -0,0 +1,69 @@
+package z_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestContext_Stream(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/export.csv", func(c *zentrox.Context) {
+		c.SetHeader(zentrox.HeaderContentType, "text/csv")
+		i := 0
+		c.Stream(func(w io.Writer) bool {
+			i++
+			fmt.Fprintf(w, "row,%d\n", i)
+			return i < 3
+		})
+	})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export.csv", nil))
+	if w.Body.String() != "row,1\nrow,2\nrow,3\n" || !w.Flushed {
+		t.Fatalf("body %q flushed=%v", w.Body.String(), w.Flushed)
+	}
+
+	calls := 0
+	gone := false
+	app.GET("/forever", func(c *zentrox.Context) {
+		gone = c.Stream(func(w io.Writer) bool {
+			calls++
+			return true
+		})
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, "/forever", nil).WithContext(ctx)
+	app.ServeHTTP(httptest.NewRecorder(), req)
+	if !gone || calls != 0 {
+		t.Fatalf("stream should stop for a gone client: gone=%v calls=%d", gone, calls)
+	}
+}
+
+func TestContext_DataFromReader(t *testing.T) {
+	app := zentrox.NewApp()
+	payload := strings.Repeat("x", 10000)
+	app.GET("/known", func(c *zentrox.Context) {
+		c.DataFromReader(http.StatusOK, int64(len(payload)), "application/x-backup", strings.NewReader(payload))
+	})
+	app.GET("/unknown", func(c *zentrox.Context) {
+		c.DataFromReader(http.StatusOK, -1, "", strings.NewReader(payload))
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/known", nil))
+	if w.Header().Get(zentrox.HeaderContentLength) != "10000" || w.Header().Get(zentrox.HeaderContentType) != "application/x-backup" || w.Body.Len() != 10000 {
+		t.Fatalf("known length: %v %d", w.Header(), w.Body.Len())
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if w.Header().Get(zentrox.HeaderContentLength) != "" || w.Header().Get(zentrox.HeaderContentType) != zentrox.ContentTypeOctetStream {
+		t.Fatalf("unknown length: %v", w.Header())
+	}
+}
@@ -0,0 +1,146 @@
+package z_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+func TestTasks(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		sent   []string
+		failed []string
+		flaky  atomic.Int32
+	)
+	app := zentrox.NewApp()
+	app.SetTasks(zentrox.NewTaskQueue(zentrox.TaskConfig{
+		Workers:     2,
+		MaxAttempts: 3,
+		Backoff:     5 * time.Millisecond,
+		OnFailure: func(task *zentrox.Task, err error) {
+			mu.Lock()
+			failed = append(failed, task.Name)
+			mu.Unlock()
+		},
+	}))
+	tasks := app.Tasks()
+	tasks.Handle("email.welcome", func(ctx context.Context, task *zentrox.Task) error {
+		var p struct{ Email string }
+		if err := task.Bind(&p); err != nil {
+			return err
+		}
+		if p.Email == "flaky@example.com" && flaky.Add(1) < 3 {
+			return errors.New("smtp unavailable")
+		}
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		sent = append(sent, p.Email)
+		mu.Unlock()
+		return nil
+	})
+	tasks.Handle("report", func(ctx context.Context, task *zentrox.Task) error {
+		panic("boom")
+	})
+
+	app.POST("/signup", func(c *zentrox.Context) {
+		if err := c.App().Tasks().Enqueue("email.welcome", map[string]string{"Email": c.Query("email")}); err != nil {
+			t.Errorf("enqueue: %v", err)
+		}
+		c.SendStatus(http.StatusAccepted)
+	})
+	for _, e := range []string{"a@example.com", "b@example.com", "c@example.com", "flaky@example.com"} {
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/signup?email="+e, nil))
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("signup: %d", w.Code)
+		}
+	}
+	_ = tasks.Enqueue("report", nil)
+	_ = tasks.Enqueue("unknown", nil)
+
+	// Let the retries become due; Shutdown then waits for queued and
+	// running tasks.
+	time.Sleep(150 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := app.Shutdown(ctx, &http.Server{}); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if err := tasks.Enqueue("email.welcome", nil); !errors.Is(err, zentrox.ErrTasksClosed) {
+		t.Fatalf("enqueue after close: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 4 {
+		t.Fatalf("sent = %v", sent)
+	}
+	if len(failed) != 2 {
+		t.Fatalf("failed = %v", failed)
+	}
+	if n := flaky.Load(); n != 3 {
+		t.Fatalf("flaky attempts = %d", n)
+	}
+}
+
+func TestTasks_DelayAndDrainTimeout(t *testing.T) {
+	q := zentrox.NewTaskQueue()
+	ran := make(chan time.Time, 1)
+	q.Handle("later", func(ctx context.Context, task *zentrox.Task) error {
+		ran <- time.Now()
+		return nil
+	})
+	start := time.Now()
+	_ = q.EnqueueIn("later", nil, 50*time.Millisecond)
+	select {
+	case at := <-ran:
+		if at.Sub(start) < 50*time.Millisecond {
+			t.Fatalf("ran after %v", at.Sub(start))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("delayed task did not run")
+	}
+
+	blocked := make(chan struct{})
+	q.Handle("slow", func(ctx context.Context, task *zentrox.Task) error {
+		close(blocked)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	_ = q.Enqueue("slow", nil)
+	<-blocked
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := q.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+func TestTasks_AppLogger(t *testing.T) {
+	var buf bytes.Buffer
+	app := zentrox.NewApp()
+	app.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+	tasks := app.Tasks()
+	tasks.Handle("known", func(ctx context.Context, task *zentrox.Task) error { return nil })
+	_ = tasks.Enqueue("unknown", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tasks.Close(ctx); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if !strings.Contains(buf.String(), "task failed") || !strings.Contains(buf.String(), "task=unknown") {
+		t.Fatalf("app logger got %q", buf.String())
+	}
+}
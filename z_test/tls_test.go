@@ -0,0 +1,123 @@
+package z_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+// selfSignedCert writes a localhost certificate and key to dir.
+func selfSignedCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kb, _ := x509.MarshalECPrivateKey(key)
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	_ = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600)
+	_ = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: kb}), 0o600)
+	return certFile, keyFile
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func httpsGet(t *testing.T, addr string) string {
+	t.Helper()
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	var err error
+	for i := 0; i < 100; i++ {
+		var resp *http.Response
+		if resp, err = client.Get("https://" + addr + "/"); err == nil {
+			defer resp.Body.Close()
+			b, _ := io.ReadAll(resp.Body)
+			return string(b)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal(err)
+	return ""
+}
+
+func TestRunTLS(t *testing.T) {
+	certFile, keyFile := selfSignedCert(t, t.TempDir())
+	app := zentrox.NewApp()
+	app.SetQuiet(true)
+	app.GET("/", func(c *zentrox.Context) { c.String(http.StatusOK, "secure") })
+
+	addr := freeAddr(t)
+	go func() { _ = app.RunTLS(addr, certFile, keyFile) }()
+	if got := httpsGet(t, addr); got != "secure" {
+		t.Fatalf("got %q", got)
+	}
+
+	// StartTLS with an in-memory certificate instead of files.
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr = freeAddr(t)
+	srv, _ := app.StartTLS(&zentrox.ServerConfig{
+		Addr:      addr,
+		TLSConfig: &tls.Config{Certificates: []tls.Certificate{pair}},
+	}, "", "")
+	defer srv.Shutdown(context.Background())
+	if got := httpsGet(t, addr); got != "secure" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestNewAutoTLSManager(t *testing.T) {
+	dir := t.TempDir()
+	m := zentrox.NewAutoTLSManager(zentrox.AutoTLSConfig{
+		Domains:  []string{"example.com"},
+		CacheDir: dir,
+		Email:    "ops@example.com",
+	})
+	if m.Email != "ops@example.com" || m.Cache == nil {
+		t.Fatalf("manager %+v", m)
+	}
+	if err := m.HostPolicy(context.Background(), "example.com"); err != nil {
+		t.Fatalf("configured domain refused: %v", err)
+	}
+	if err := m.HostPolicy(context.Background(), "evil.test"); err == nil {
+		t.Fatal("unlisted domain must be refused")
+	}
+	if err := m.Cache.Put(context.Background(), "k", []byte("v")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "k")); err != nil {
+		t.Fatalf("cache should write to CacheDir: %v", err)
+	}
+}
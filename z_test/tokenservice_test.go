@@ -0,0 +1,71 @@
+package z_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestTokenService(t *testing.T) {
+	ctx := context.Background()
+	var reused string
+	tokens := middleware.NewTokenService(middleware.TokenServiceConfig{
+		JWT:     middleware.JWTConfig{Secret: []byte("svc"), Issuer: "myapp"},
+		Revoker: middleware.NewStoreRevoker(middleware.NewMemoryStore()),
+		Claims: func(_ context.Context, sub string) (map[string]any, error) {
+			return map[string]any{"roles": []string{"admin"}}, nil
+		},
+		OnReuse: func(_ context.Context, claims map[string]any) { reused, _ = claims["sub"].(string) },
+	})
+
+	app := zentrox.NewApp()
+	app.GET("/me", tokens.Middleware(), middleware.RequireRoles("admin"), func(c *zentrox.Context) {
+		claims, _ := zentrox.Get[map[string]any](c, "user")
+		c.String(http.StatusOK, "%v", claims["sub"])
+	})
+	call := func(tok string) int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(zentrox.HeaderAuthorization, zentrox.BearerPrefix+tok)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	pair, err := tokens.IssuePair("u1", map[string]any{"roles": []string{"admin"}})
+	if err != nil || pair.TokenType != "Bearer" || pair.ExpiresIn != 900 {
+		t.Fatalf("IssuePair = %+v, %v", pair, err)
+	}
+	if code := call(pair.AccessToken); code != 200 {
+		t.Fatalf("access token: %d", code)
+	}
+	if code := call(pair.RefreshToken); code != 401 {
+		t.Fatalf("refresh token accepted as access token: %d", code)
+	}
+	if _, err := tokens.Refresh(ctx, pair.AccessToken); !errors.Is(err, middleware.ErrWrongTokenType) {
+		t.Fatalf("refresh with access token: %v", err)
+	}
+
+	next, err := tokens.Refresh(ctx, pair.RefreshToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := call(next.AccessToken); code != 200 {
+		t.Fatalf("refreshed access token: %d", code)
+	}
+	// The old refresh token was rotated out; reusing it is reported.
+	if _, err := tokens.Refresh(ctx, pair.RefreshToken); !errors.Is(err, middleware.ErrTokenRevoked) || reused != "u1" {
+		t.Fatalf("reuse: err=%v reused=%q", err, reused)
+	}
+
+	if err := tokens.Revoke(ctx, next.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	if code := call(next.AccessToken); code != 401 {
+		t.Fatalf("revoked access token: %d", code)
+	}
+}
@@ -0,0 +1,114 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/auth"
+	"github.com/aminofox/zentrox/v2/middleware"
+	"github.com/aminofox/zentrox/v2/session"
+)
+
+// RFC 6238 test secret "12345678901234567890".
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP(t *testing.T) {
+	for ts, want := range map[int64]string{59: "287082", 1111111109: "081804", 2000000000: "279037"} {
+		got, err := auth.TOTPCode(rfcTOTPSecret, time.Unix(ts, 0))
+		if err != nil || got != want {
+			t.Fatalf("T=%d: want %s, got %s (%v)", ts, want, got, err)
+		}
+	}
+
+	now := time.Unix(1111111109, 0)
+	opts := auth.DefaultTOTPOptions()
+	prev, _ := auth.TOTPCode(rfcTOTPSecret, now.Add(-30*time.Second))
+	step, ok := auth.VerifyTOTPWithOptions(rfcTOTPSecret, prev, 0, now, opts)
+	if !ok {
+		t.Fatal("code from the previous period should be accepted")
+	}
+	if _, ok := auth.VerifyTOTPWithOptions(rfcTOTPSecret, prev, step, now, opts); ok {
+		t.Fatal("replayed code must be rejected")
+	}
+	old, _ := auth.TOTPCode(rfcTOTPSecret, now.Add(-90*time.Second))
+	if _, ok := auth.VerifyTOTPWithOptions(rfcTOTPSecret, old, 0, now, opts); ok {
+		t.Fatal("code outside the drift window must be rejected")
+	}
+
+	// Sub-second periods are rounded up to one second instead of dividing
+	// by zero.
+	const k = 1234567
+	code, _ := auth.TOTPCode(rfcTOTPSecret, time.Unix(30*k, 0)) // step k at 30s
+	short := auth.TOTPOptions{Period: 500 * time.Millisecond}
+	if step, ok := auth.VerifyTOTPWithOptions(rfcTOTPSecret, code, 0, time.Unix(k, 0), short); !ok || step != k {
+		t.Fatalf("sub-second period: step %d ok %v", step, ok)
+	}
+	if uri := auth.TOTPURIWithOptions(rfcTOTPSecret, "Acme", "ann", short); !strings.Contains(uri, "period=1") {
+		t.Fatalf("sub-second period uri %s", uri)
+	}
+
+	// Digits beyond 8 are clamped rather than overflowing: the RFC 6238
+	// 8-digit vector for T=59 is 94287082.
+	long := auth.TOTPOptions{Digits: 10}
+	if _, ok := auth.VerifyTOTPWithOptions(rfcTOTPSecret, "94287082", 0, time.Unix(59, 0), long); !ok {
+		t.Fatal("8-digit code rejected")
+	}
+	if uri := auth.TOTPURIWithOptions(rfcTOTPSecret, "Acme", "ann", long); !strings.Contains(uri, "digits=8") {
+		t.Fatalf("clamped digits uri %s", uri)
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil || len(secret) != 32 {
+		t.Fatalf("unexpected secret %q (%v)", secret, err)
+	}
+	uri := auth.TOTPURI(secret, "Acme", "ann@example.com")
+	if !strings.HasPrefix(uri, "otpauth://totp/Acme:ann@example.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Fatalf("unexpected uri %s", uri)
+	}
+}
+
+func TestRequireTwoFactor(t *testing.T) {
+	app := zentrox.NewApp()
+	app.Plug(session.Middleware(session.DefaultConfig()))
+	app.POST("/2fa", func(c *zentrox.Context) {
+		session.From(c).Set("2fa_verified", true)
+		c.SendStatus(http.StatusNoContent)
+	})
+	app.GET("/claims", func(c *zentrox.Context) {
+		c.Set("user", map[string]any{"sub": "1", "2fa_verified": true})
+		c.Next()
+	}, middleware.RequireTwoFactor(middleware.DefaultTwoFactor()), func(c *zentrox.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	app.GET("/billing", middleware.RequireTwoFactor(middleware.DefaultTwoFactor()), func(c *zentrox.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/billing", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("want 403 without 2fa, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/claims", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("2fa claim should pass, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/2fa", nil))
+	req := httptest.NewRequest(http.MethodGet, "/billing", nil)
+	for _, ck := range w.Result().Cookies() {
+		req.AddCookie(ck)
+	}
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("2fa session flag should pass, got %d", w.Code)
+	}
+}
@@ -0,0 +1,179 @@
+package z_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+type typedCreate struct {
+	Name  string `json:"name" validate:"required"`
+	Email string `json:"email" validate:"required,email"`
+}
+
+type typedCreated struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func (typedCreated) StatusCode() int { return http.StatusCreated }
+
+type typedGet struct {
+	ID      int    `path:"id"`
+	Verbose bool   `query:"verbose"`
+	Fields  string `query:"fields"`
+}
+
+type typedItem struct {
+	ID      int    `json:"id"`
+	Verbose bool   `json:"verbose"`
+	Fields  string `json:"fields"`
+}
+
+func createTyped(c *zentrox.Context, in typedCreate) (typedCreated, error) {
+	return typedCreated{ID: 7, Name: in.Name}, nil
+}
+
+func TestTypedHandler(t *testing.T) {
+	app := zentrox.NewApp()
+	app.POST("/users", zentrox.H(createTyped))
+	app.GET("/items/:id", zentrox.H(func(c *zentrox.Context, in typedGet) (typedItem, error) {
+		if in.ID == 404 {
+			return typedItem{}, zentrox.NewHTTPError(http.StatusNotFound, "item not found")
+		}
+		if in.ID == 500 {
+			return typedItem{}, errors.New("db down")
+		}
+		return typedItem{ID: in.ID, Verbose: in.Verbose, Fields: in.Fields}, nil
+	}))
+	app.DELETE("/items/:id", zentrox.H(func(c *zentrox.Context, in typedGet) (struct{}, error) {
+		return struct{}{}, nil
+	}))
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		var r *http.Request
+		if body != "" {
+			r = httptest.NewRequest(method, target, strings.NewReader(body))
+			r.Header.Set("Content-Type", "application/json")
+		} else {
+			r = httptest.NewRequest(method, target, nil)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w
+	}
+
+	w := do(http.MethodPost, "/users", `{"name":"ann","email":"ann@example.com"}`)
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"id":7`) {
+		t.Fatalf("create: %d %s", w.Code, w.Body.String())
+	}
+	w = do(http.MethodPost, "/users", `{"name":"ann","email":"nope"}`)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), `"field":"email"`) {
+		t.Fatalf("validation: %d %s", w.Code, w.Body.String())
+	}
+	w = do(http.MethodPost, "/users", `{"name":`)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("malformed: %d %s", w.Code, w.Body.String())
+	}
+
+	w = do(http.MethodGet, "/items/3?verbose=true&fields=name", "")
+	var item typedItem
+	if err := json.Unmarshal(w.Body.Bytes(), &item); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("get: %d %s", w.Code, w.Body.String())
+	}
+	if item != (typedItem{ID: 3, Verbose: true, Fields: "name"}) {
+		t.Fatalf("item = %+v", item)
+	}
+	if w = do(http.MethodGet, "/items/404", ""); w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "item not found") {
+		t.Fatalf("http error: %d %s", w.Code, w.Body.String())
+	}
+	if w = do(http.MethodGet, "/items/500", ""); w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "db down") {
+		t.Fatalf("internal error: %d %s", w.Code, w.Body.String())
+	}
+	if w = do(http.MethodDelete, "/items/3", ""); w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Fatalf("delete: %d %q", w.Code, w.Body.String())
+	}
+}
+
+type typedUpdate struct {
+	ID   int    `path:"id" json:"id" validate:"min=1"`
+	Name string `json:"name" validate:"required"`
+}
+
+func TestTypedHandler_PathWinsOverBody(t *testing.T) {
+	app := zentrox.NewApp()
+	app.PUT("/users/:id", zentrox.H(func(c *zentrox.Context, in typedUpdate) (typedUpdate, error) {
+		return in, nil
+	}))
+
+	r := httptest.NewRequest(http.MethodPut, "/users/1", strings.NewReader(`{"id":999,"name":"ann"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	var got typedUpdate
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil || got.ID != 1 || got.Name != "ann" {
+		t.Fatalf("got %d %s", w.Code, w.Body.String())
+	}
+
+	// Validation sees the path value, not the body's.
+	r = httptest.NewRequest(http.MethodPut, "/users/0", strings.NewReader(`{"id":5,"name":"ann"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("want 422, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestTypedHandler_Docs(t *testing.T) {
+	app := zentrox.NewApp()
+	api := app.Scope("/api")
+	zentrox.Typed(api.POST, "/users", createTyped)
+	zentrox.Typed(app.GET, "/items/:id", func(c *zentrox.Context, in typedGet) (typedItem, error) {
+		return typedItem{}, nil
+	})
+	app.GET("/plain/:id", zentrox.H(func(c *zentrox.Context, in typedGet) (typedItem, error) {
+		return typedItem{}, nil
+	}))
+
+	for _, ri := range app.ListRoutes() {
+		switch ri.Path {
+		case "/api/users":
+			if _, ok := ri.Body.(typedCreate); !ok {
+				t.Fatalf("POST body = %T", ri.Body)
+			}
+			if _, ok := ri.Response.(typedCreated); !ok {
+				t.Fatalf("POST response = %T", ri.Response)
+			}
+			if !strings.HasSuffix(ri.HandlerName, "createTyped") || !strings.HasSuffix(ri.File, "typed_test.go") {
+				t.Fatalf("handler = %s %s", ri.HandlerName, ri.File)
+			}
+		case "/items/:id":
+			if ri.Body != nil {
+				t.Fatalf("GET body = %T", ri.Body)
+			}
+			if _, ok := ri.Response.(typedItem); !ok {
+				t.Fatalf("GET response = %T", ri.Response)
+			}
+		case "/plain/:id":
+			if ri.Response != nil {
+				t.Fatalf("H alone documented the route: %T", ri.Response)
+			}
+		}
+	}
+
+	doc, err := app.GenerateOpenAPI(zentrox.OpenAPIInfo{Title: "t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"typedCreate", "typedItem", `"email"`} {
+		if !strings.Contains(string(doc), want) {
+			t.Fatalf("openapi missing %s:\n%s", want, doc)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package z_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+type uploadPart struct {
+	name string
+	data []byte
+}
+
+func multipartRequest(t *testing.T, field string, parts ...uploadPart) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, p := range parts {
+		fw, err := mw.CreateFormFile(field, p.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = fw.Write(p.data)
+	}
+	_ = mw.Close()
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set(zentrox.HeaderContentType, mw.FormDataContentType())
+	return req
+}
+
+func TestSaveUploadedFiles(t *testing.T) {
+	dir := t.TempDir()
+	var (
+		saved    []string
+		err      error
+		progress = map[string]int64{}
+		count    int
+	)
+	opt := zentrox.UploadOptions{
+		MaxFiles:     2,
+		MaxFileSize:  1 << 10,
+		AllowedTypes: []string{"image/png", "text/*"},
+		Progress:     func(name string, written, total int64) { progress[name] = written },
+	}
+	app := zentrox.NewApp()
+	app.POST("/upload", func(c *zentrox.Context) {
+		files, ferr := c.FormFiles("files")
+		if ferr == nil {
+			count = len(files)
+		}
+		saved, err = c.SaveUploadedFiles("files", dir, opt)
+		c.SendStatus(http.StatusNoContent)
+	})
+
+	app.ServeHTTP(httptest.NewRecorder(), multipartRequest(t, "files",
+		uploadPart{"a.png", append(pngHeader, make([]byte, 100)...)},
+		uploadPart{"notes.txt", []byte("hello")},
+	))
+	if err != nil || len(saved) != 2 || count != 2 {
+		t.Fatalf("saved=%v count=%d err=%v", saved, count, err)
+	}
+	if progress["a.png"] != int64(len(pngHeader)+100) || progress["notes.txt"] != 5 {
+		t.Fatalf("progress = %v", progress)
+	}
+
+	// A disguised executable is rejected by sniffing, and earlier files in
+	// the batch are removed.
+	app.ServeHTTP(httptest.NewRecorder(), multipartRequest(t, "files",
+		uploadPart{"b.png", pngHeader},
+		uploadPart{"c.png", []byte("MZ\x90\x00\x03\x00\x00\x00\x04\x00\x00\x00\xff\xff\x00\x00")},
+	))
+	if !errors.Is(err, zentrox.ErrUploadType) {
+		t.Fatalf("want ErrUploadType, got %v", err)
+	}
+	if _, serr := os.Stat(filepath.Join(dir, "b.png")); !os.IsNotExist(serr) {
+		t.Fatal("partial batch was not cleaned up")
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), multipartRequest(t, "files", uploadPart{"big.txt", bytes.Repeat([]byte("a"), 2<<10)}))
+	if !errors.Is(err, zentrox.ErrUploadTooLarge) {
+		t.Fatalf("want ErrUploadTooLarge, got %v", err)
+	}
+
+	app.ServeHTTP(httptest.NewRecorder(), multipartRequest(t, "files",
+		uploadPart{"1.txt", []byte("1")}, uploadPart{"2.txt", []byte("2")}, uploadPart{"3.txt", []byte("3")}))
+	if !errors.Is(err, zentrox.ErrUploadTooMany) {
+		t.Fatalf("want ErrUploadTooMany, got %v", err)
+	}
+}
+
+func TestSaveUploadedFile_Image(t *testing.T) {
+	var src bytes.Buffer
+	_ = png.Encode(&src, image.NewRGBA(image.Rect(0, 0, 40, 20)))
+
+	dir := t.TempDir()
+	var (
+		saved string
+		err   error
+		opt   zentrox.UploadOptions
+	)
+	app := zentrox.NewApp()
+	app.POST("/upload", func(c *zentrox.Context) {
+		saved, err = c.SaveUploadedFile("avatar", dir, opt)
+		c.SendStatus(http.StatusNoContent)
+	})
+	upload := func(data []byte) {
+		app.ServeHTTP(httptest.NewRecorder(), multipartRequest(t, "avatar", uploadPart{"me.png", data}))
+	}
+
+	opt = zentrox.UploadOptions{Overwrite: true, Image: &zentrox.ImageOptions{
+		MaxWidth:   100,
+		Transforms: []zentrox.ImageTransform{zentrox.ResizeImage(10, 10)},
+	}}
+	upload(src.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := os.Open(saved)
+	cfg, format, derr := image.DecodeConfig(f)
+	f.Close()
+	if derr != nil || format != "png" || cfg.Width != 10 || cfg.Height != 5 {
+		t.Fatalf("saved %s %dx%d, %v", format, cfg.Width, cfg.Height, derr)
+	}
+
+	for name, o := range map[string]*zentrox.ImageOptions{
+		"too wide":   {MaxWidth: 20},
+		"wrong type": {Formats: []string{"jpeg"}},
+	} {
+		opt.Image = o
+		upload(src.Bytes())
+		if !errors.Is(err, zentrox.ErrUploadImage) {
+			t.Fatalf("%s: want ErrUploadImage, got %v", name, err)
+		}
+	}
+	opt.Image = &zentrox.ImageOptions{}
+	upload([]byte("not an image"))
+	if !errors.Is(err, zentrox.ErrUploadImage) {
+		t.Fatalf("want ErrUploadImage, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+)
+
+func TestUserAgentFilter(t *testing.T) {
+	cfg := middleware.DefaultUserAgentFilter()
+	cfg.Allow = []string{`Googlebot`}
+	cfg.Deny = []string{`BadScraper`}
+	cfg.Rules = []middleware.UserAgentRule{
+		{Pattern: `SuspiciousBrowser`, Action: middleware.UAChallenge},
+		{Pattern: `Monitor/`, Action: middleware.UATag, Tag: "uptime"},
+	}
+
+	app := zentrox.NewApp()
+	app.Plug(middleware.UserAgentFilter(cfg))
+	app.GET("/", func(c *zentrox.Context) {
+		tag, _ := c.Get("ua_tag")
+		c.String(http.StatusOK, "%v", tag)
+	})
+
+	for ua, want := range map[string]struct {
+		code int
+		body string
+	}{
+		"Mozilla/5.0 (X11; Linux x86_64) Firefox/120.0": {http.StatusOK, "<nil>"},
+		"Mozilla/5.0 (compatible; Googlebot/2.1)":       {http.StatusOK, "<nil>"},
+		"badscraper/1.0":        {http.StatusForbidden, ""},
+		"SuspiciousBrowser 1.0": {http.StatusForbidden, ""},
+		"Monitor/2.0":           {http.StatusOK, "uptime"},
+		"python-requests/2.31":  {http.StatusOK, "bot"},
+		"":                      {http.StatusOK, "empty"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", ua)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, req)
+		if w.Code != want.code || (want.body != "" && w.Body.String() != want.body) {
+			t.Fatalf("%q: want %d %q, got %d %q", ua, want.code, want.body, w.Code, w.Body.String())
+		}
+	}
+
+	if !middleware.IsBot("Mozilla/5.0 (compatible; bingbot/2.0)") || middleware.IsBot("Mozilla/5.0 Safari/605.1.15") {
+		t.Fatal("unexpected IsBot result")
+	}
+}
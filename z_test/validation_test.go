@@ -0,0 +1,129 @@
+package z_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/validation"
+)
+
+type signupAddress struct {
+	Zip string `json:"zip" validate:"required,len=5"`
+}
+
+type signupItem struct {
+	SKU string `json:"sku" binding:"required"`
+}
+
+type signupInput struct {
+	Email    string         `json:"email" binding:"required,email"`
+	Password string         `json:"password" binding:"required,min=6"`
+	Nick     string         `json:"nick" validate:"omitempty,min=3"`
+	Age      *int           `json:"age" validate:"omitempty,min=18"`
+	Address  *signupAddress `json:"address"`
+	Items    []signupItem   `json:"items"`
+}
+
+func TestValidateStruct_FieldErrors(t *testing.T) {
+	age := 12
+	in := signupInput{
+		Email:    "nope",
+		Password: "123",
+		Age:      &age,
+		Address:  &signupAddress{Zip: "12"},
+		Items:    []signupItem{{SKU: "a"}, {}},
+	}
+	err := validation.ValidateStruct(&in)
+	var verrs validation.Errors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected validation.Errors, got %T %v", err, err)
+	}
+	got := map[string]string{}
+	for _, fe := range verrs {
+		got[fe.Field] = fe.Rule
+	}
+	want := map[string]string{
+		"email":        "email",
+		"password":     "min",
+		"age":          "min",
+		"address.zip":  "len",
+		"items[1].sku": "required",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("field errors = %v, want %v", got, want)
+	}
+	if !strings.Contains(err.Error(), "password length must be >= 6") {
+		t.Fatalf("message = %q", err.Error())
+	}
+
+	ok := signupInput{Email: "a@b.co", Password: "secret1"}
+	if err := validation.ValidateStruct(&ok); err != nil {
+		t.Fatalf("omitempty fields should pass: %v", err)
+	}
+}
+
+func TestValidateStruct_CustomRule(t *testing.T) {
+	validation.Register("zt_even", func(v reflect.Value, _ string) error {
+		if v.Int()%2 != 0 {
+			return errors.New("must be even")
+		}
+		return nil
+	})
+	type in struct {
+		N int `json:"n" validate:"zt_even"`
+	}
+	err := validation.ValidateStruct(&in{N: 3})
+	var verrs validation.Errors
+	if !errors.As(err, &verrs) || verrs[0].Message != "n must be even" {
+		t.Fatalf("err = %v", err)
+	}
+	if err := validation.ValidateStruct(&in{N: 4}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBindJSONInto_ValidationAndCustomValidator(t *testing.T) {
+	app := zentrox.NewApp()
+	app.POST("/signup", func(c *zentrox.Context) {
+		var in signupInput
+		if err := c.BindJSONInto(&in); err != nil {
+			var verrs validation.Errors
+			if errors.As(err, &verrs) {
+				c.Fail(http.StatusUnprocessableEntity, "validation failed", verrs)
+				return
+			}
+			c.Fail(http.StatusBadRequest, "invalid input", err.Error())
+			return
+		}
+		c.SendStatus(http.StatusCreated)
+	})
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(`{"email":"x","password":"secret1"}`)))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("status = %d body=%s", w.Code, w.Body.String())
+	}
+	var body struct {
+		Detail []validation.FieldError `json:"detail"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Detail) != 1 || body.Detail[0].Field != "email" {
+		t.Fatalf("detail = %+v", body.Detail)
+	}
+
+	calls := 0
+	app.SetValidator(validation.ValidatorFunc(func(v any) error { calls++; return nil }))
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(`{"email":"x"}`)))
+	if w.Code != http.StatusCreated || calls != 1 {
+		t.Fatalf("custom validator not used: status=%d calls=%d", w.Code, calls)
+	}
+}
@@ -0,0 +1,110 @@
+package z_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/middleware"
+	"github.com/aminofox/zentrox/v2/webhook"
+)
+
+func TestWebhookDispatcher(t *testing.T) {
+	secret := []byte("hook-secret")
+	var attempts atomic.Int32
+	received := make(chan webhook.Event, 1)
+
+	receiver := zentrox.NewApp()
+	receiver.POST("/hooks", middleware.VerifySignature(middleware.DefaultSignature(secret)), func(c *zentrox.Context) {
+		if attempts.Add(1) == 1 {
+			c.SendStatus(http.StatusServiceUnavailable)
+			return
+		}
+		var ev webhook.Event
+		b, _ := io.ReadAll(c.Request.Body)
+		_ = json.Unmarshal(b, &ev)
+		if c.GetHeader(webhook.HeaderAttempt) != "2" || c.GetHeader(webhook.HeaderEvent) != ev.Event {
+			t.Errorf("headers: %v", c.Request.Header)
+		}
+		received <- ev
+		c.SendStatus(http.StatusNoContent)
+	})
+	rejecting := zentrox.NewApp()
+	rejecting.POST("/hooks", func(c *zentrox.Context) { c.SendStatus(http.StatusGone) })
+	srv := httptest.NewServer(receiver)
+	defer srv.Close()
+	gone := httptest.NewServer(rejecting)
+	defer gone.Close()
+
+	var (
+		mu   sync.Mutex
+		dead []string
+	)
+	hooks := webhook.New(webhook.Config{
+		InitialBackoff: 10 * time.Millisecond,
+		OnDeadLetter: func(d webhook.Delivery, err error) {
+			mu.Lock()
+			dead = append(dead, d.Endpoint.ID)
+			mu.Unlock()
+		},
+	})
+	if err := hooks.Register(webhook.Endpoint{ID: "crm", URL: srv.URL + "/hooks", Secret: secret, Events: []string{"order.*"}}); err != nil {
+		t.Fatal(err)
+	}
+	_ = hooks.Register(webhook.Endpoint{ID: "gone", URL: gone.URL + "/hooks", Events: []string{"order.paid"}})
+	_ = hooks.Register(webhook.Endpoint{ID: "users", URL: srv.URL + "/hooks", Events: []string{"user.created"}})
+
+	app := zentrox.NewApp()
+	app.GET("/none", func(c *zentrox.Context) {
+		if err := c.EmitWebhook("x", nil); !errors.Is(err, zentrox.ErrNoWebhooks) {
+			t.Errorf("without emitter: %v", err)
+		}
+	})
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/none", nil))
+	app.SetWebhooks(hooks)
+	app.POST("/orders/:id/pay", func(c *zentrox.Context) {
+		if err := c.EmitWebhook("order.paid", map[string]string{"id": c.Param("id")}); err != nil {
+			t.Errorf("emit: %v", err)
+		}
+		c.SendStatus(http.StatusAccepted)
+	})
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders/42/pay", nil))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("handler: %d", w.Code)
+	}
+
+	select {
+	case ev := <-received:
+		if ev.Event != "order.paid" || string(ev.Data) != `{"id":"42"}` || ev.ID == "" {
+			t.Fatalf("event: %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event not delivered")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := app.Shutdown(ctx, &http.Server{}); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if err := hooks.Emit(ctx, "order.paid", nil); !errors.Is(err, webhook.ErrClosed) {
+		t.Fatalf("emit after close: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(dead) != 1 || dead[0] != "gone" {
+		t.Fatalf("dead letters: %v", dead)
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Fatalf("attempts: %d", n)
+	}
+}
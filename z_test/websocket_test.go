@@ -0,0 +1,229 @@
+package z_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aminofox/zentrox/v2"
+	"github.com/aminofox/zentrox/v2/websocket"
+)
+
+type wsClient struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialWS(t *testing.T, srv *httptest.Server, path string, hdr map[string]string) (*wsClient, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(3 * time.Second))
+	req := "GET " + path + " HTTP/1.1\r\nHost: " + strings.TrimPrefix(srv.URL, "http://") +
+		"\r\nConnection: Upgrade\r\nUpgrade: websocket\r\nSec-WebSocket-Version: 13\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"
+	for k, v := range hdr {
+		req += k + ": " + v + "\r\n"
+	}
+	_, _ = io.WriteString(conn, req+"\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &wsClient{t: t, conn: conn, br: br}, resp
+}
+
+func (c *wsClient) send(fin bool, op byte, payload []byte) {
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	frame := []byte{b0, 0x80 | byte(len(payload))}
+	mask := []byte{1, 2, 3, 4}
+	frame = append(frame, mask...)
+	for i, p := range payload {
+		frame = append(frame, p^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *wsClient) recv() (byte, []byte) {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.br, hdr[:]); err != nil {
+		c.t.Fatal(err)
+	}
+	n := int(hdr[1] & 0x7f)
+	if n == 126 {
+		var ext [2]byte
+		_, _ = io.ReadFull(c.br, ext[:])
+		n = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		c.t.Fatal(err)
+	}
+	return hdr[0] & 0x0f, payload
+}
+
+func TestWebSocket_EchoPingClose(t *testing.T) {
+	closed := make(chan error, 1)
+	app := zentrox.NewApp()
+	app.GET("/ws", websocket.Handler(func(ws *websocket.Conn) {
+		for {
+			typ, msg, err := ws.ReadMessage()
+			if err != nil {
+				closed <- err
+				return
+			}
+			_ = ws.WriteMessage(typ, append([]byte("echo:"), msg...))
+		}
+	}))
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	c, resp := dialWS(t, srv, "/ws", nil)
+	if resp.StatusCode != http.StatusSwitchingProtocols ||
+		resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	c.send(true, 0x1, []byte("hi"))
+	if op, msg := c.recv(); op != 0x1 || string(msg) != "echo:hi" {
+		t.Fatalf("got op=%d %q", op, msg)
+	}
+
+	// Fragmented message with an interleaved ping.
+	c.send(false, 0x2, []byte("ab"))
+	c.send(true, 0x9, []byte("p"))
+	if op, msg := c.recv(); op != 0xA || string(msg) != "p" {
+		t.Fatalf("expected pong, got op=%d %q", op, msg)
+	}
+	c.send(true, 0x0, []byte("cd"))
+	if op, msg := c.recv(); op != 0x2 || string(msg) != "echo:abcd" {
+		t.Fatalf("got op=%d %q", op, msg)
+	}
+
+	c.send(true, 0x8, []byte{0x03, 0xE8}) // 1000
+	if op, msg := c.recv(); op != 0x8 || binary.BigEndian.Uint16(msg) != websocket.CloseNormal {
+		t.Fatalf("expected close reply, got op=%d %v", op, msg)
+	}
+	var ce *websocket.CloseError
+	if err := <-closed; !errors.As(err, &ce) || ce.Code != websocket.CloseNormal {
+		t.Fatalf("handler error = %v", err)
+	}
+}
+
+func TestWebSocket_ServerPingAndSubprotocol(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/ws", websocket.Handler(func(ws *websocket.Conn) {
+		_ = ws.WriteMessage(websocket.TextMessage, []byte(ws.Subprotocol()))
+		_, _, _ = ws.ReadMessage()
+	}, websocket.Config{PingInterval: 20 * time.Millisecond, Subprotocols: []string{"v2", "v1"}}))
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	c, resp := dialWS(t, srv, "/ws", map[string]string{"Sec-WebSocket-Protocol": "v1, v2"})
+	if resp.Header.Get("Sec-WebSocket-Protocol") != "v2" {
+		t.Fatalf("subprotocol = %q", resp.Header.Get("Sec-WebSocket-Protocol"))
+	}
+	if _, msg := c.recv(); string(msg) != "v2" {
+		t.Fatalf("got %q", msg)
+	}
+	if op, _ := c.recv(); op != 0x9 {
+		t.Fatalf("expected server ping, got op=%d", op)
+	}
+}
+
+func TestWebSocket_PingPayloadLimit(t *testing.T) {
+	errc := make(chan error, 1)
+	app := zentrox.NewApp()
+	app.GET("/ws", websocket.Handler(func(ws *websocket.Conn) {
+		errc <- ws.Ping(make([]byte, 126))
+		_ = ws.Ping([]byte("ok"))
+		_, _, _ = ws.ReadMessage()
+	}))
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	c, _ := dialWS(t, srv, "/ws", nil)
+	if err := <-errc; !errors.Is(err, websocket.ErrControlTooLong) {
+		t.Fatalf("oversized ping: %v", err)
+	}
+	if op, msg := c.recv(); op != 0x9 || string(msg) != "ok" {
+		t.Fatalf("expected ping, got op=%d %q", op, msg)
+	}
+}
+
+func TestWebSocket_CloseFrameValidation(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/ws", websocket.Handler(func(ws *websocket.Conn) {
+		_, _, _ = ws.ReadMessage()
+	}))
+	app.GET("/bye", websocket.Handler(func(ws *websocket.Conn) {
+		_ = ws.CloseWithReason(websocket.CloseNormal, strings.Repeat("é", 100))
+	}))
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	code := func(b []byte) int { return int(binary.BigEndian.Uint16(b)) }
+	for name, tc := range map[string]struct {
+		payload []byte
+		want    int
+	}{
+		"one byte":      {[]byte{0x03}, websocket.CloseProtocolError},
+		"below 1000":    {[]byte{0x03, 0xE7}, websocket.CloseProtocolError},
+		"1005":          {[]byte{0x03, 0xED}, websocket.CloseProtocolError},
+		"1015":          {[]byte{0x03, 0xF7}, websocket.CloseProtocolError},
+		"bad utf-8":     {[]byte{0x03, 0xE8, 0xff, 0xfe}, websocket.CloseInvalidPayload},
+		"app code 4000": {[]byte{0x0F, 0xA0, 'o', 'k'}, 4000},
+		"empty":         {nil, websocket.CloseNormal},
+	} {
+		c, _ := dialWS(t, srv, "/ws", nil)
+		c.send(true, 0x8, tc.payload)
+		if op, msg := c.recv(); op != 0x8 || len(msg) < 2 || code(msg) != tc.want {
+			t.Fatalf("%s: got op=%d %v", name, op, msg)
+		}
+	}
+
+	c, _ := dialWS(t, srv, "/bye", nil)
+	op, msg := c.recv()
+	if op != 0x8 || len(msg) > 125 || !utf8.Valid(msg[2:]) || len(msg[2:]) != 122 {
+		t.Fatalf("truncated reason: op=%d len=%d valid=%v", op, len(msg), utf8.Valid(msg[2:]))
+	}
+}
+
+func TestWebSocket_RejectedHandshakes(t *testing.T) {
+	app := zentrox.NewApp()
+	app.GET("/ws", websocket.Handler(func(ws *websocket.Conn) {}))
+	srv := httptest.NewServer(app)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("plain GET: status %d", resp.StatusCode)
+	}
+
+	_, resp = dialWS(t, srv, "/ws", map[string]string{"Origin": "https://evil.example"})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("cross-origin: status %d", resp.StatusCode)
+	}
+}
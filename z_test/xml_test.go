@@ -0,0 +1,74 @@
+package z_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aminofox/zentrox/v2"
+)
+
+type xmlOrder struct {
+	ID    string  `xml:"id,attr"`
+	Item  string  `xml:"item" validate:"required"`
+	Total float64 `xml:"total" validate:"min=1"`
+}
+
+func TestXML_BindAndRender(t *testing.T) {
+	app := zentrox.NewApp()
+	app.POST("/orders", func(c *zentrox.Context) {
+		var o xmlOrder
+		if err := c.BindXMLInto(&o); err != nil {
+			c.String(http.StatusBadRequest, "%v", err)
+			return
+		}
+		c.XML(http.StatusCreated, o)
+	})
+	app.POST("/auto", func(c *zentrox.Context) {
+		var o xmlOrder
+		if err := c.BindInto(&o); err != nil {
+			c.String(http.StatusBadRequest, "%v", err)
+			return
+		}
+		c.String(http.StatusOK, "%s", o.Item)
+	})
+	app.GET("/bad", func(c *zentrox.Context) {
+		c.XML(http.StatusOK, map[string]int{"a": 1})
+	})
+
+	body := `<xmlOrder id="o1"><item>book</item><total>12.5</total></xmlOrder>`
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("want 201, got %d %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get(zentrox.HeaderContentType); ct != zentrox.ContentTypeXMLUTF8 {
+		t.Fatalf("content type %q", ct)
+	}
+	if got := w.Body.String(); !strings.HasPrefix(got, "<?xml") || !strings.Contains(got, `<xmlOrder id="o1"><item>book</item>`) {
+		t.Fatalf("body %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`<xmlOrder><total>0</total></xmlOrder>`))
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("validation should fail, got %d", w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/auto", strings.NewReader(body))
+	req.Header.Set(zentrox.HeaderContentType, "text/xml; charset=utf-8")
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, req)
+	if w.Body.String() != "book" {
+		t.Fatalf("BindInto should detect XML, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/bad", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("unmarshalable value: want 500, got %d", w.Code)
+	}
+}
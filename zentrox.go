@@ -4,12 +4,13 @@ import (
 	"bufio"
 	"context"
 	"crypto/sha1"
+	"crypto/tls"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"log"
-	"mime"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/netip"
@@ -22,25 +23,45 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/aminofox/zentrox/v2/validation"
 )
 
 // Handler is the middleware/handler function type.
 type Handler func(*Context)
 
 type RouteInfo struct {
-	Method      string
+	Method string
+	// Host is the pattern of App.Host or Scope.Subdomain; empty for any host.
+	Host        string
 	Path        string
 	HandlerName string
 	Middlewares []string
 	File        string
 	Line        int
+	// Summary, Body and Response are set with Route.Summary, Route.Body and
+	// Route.Returns and used by ExportRoutes.
+	Summary  string
+	Body     any
+	Response any
+	// Group is the prefix of the Scope the route was registered on.
+	Group string
+	// Name is set with Route.Name.
+	Name string
+	// Doc is set with Route.Doc and used by GenerateOpenAPI.
+	Doc *RouteDoc
+	// Meta is set with Route.Meta.
+	Meta map[string]any
 }
 
 // App is the main entrypoint of the framework.
 type App struct {
-	rt   *router
-	plug []Handler // global middlewares
+	rt    *router
+	hosts []*hostRouter // see Host, in registration order
+	plug  []Handler     // global middlewares
+	pre   []Handler     // run before routing
 
 	// Optional lifecycle hooks.
 	// onRequest: called just after Context is initialized (before middleware chain).
@@ -56,16 +77,78 @@ type App struct {
 	// If nil, the default http.NotFound is used.
 	notFound Handler
 
+	// problemJSON renders framework errors as problem+json; see SetProblemJSON.
+	problemJSON bool
+
 	// Optional application version string; propagated to context as "app_version".
 	version string
 
 	// enable route printing when Run()
-	printRoutes bool
+	printRoutes  bool
+	routesFormat string
+	routesOut    io.Writer
+	// banner is called when a server is built; quiet suppresses it and
+	// route printing.
+	banner func(BannerInfo)
+	quiet  bool
 	// registry all registered routes
 	routeIndex map[string]RouteInfo
+	// route name -> pattern, see Route.Name and URLFor
+	routeNames map[string]string
 
 	trustedProxies []netip.Prefix
 	trustAllProxy  bool
+	// headers trusted proxies may use to report the client; nil means
+	// X-Forwarded-For then X-Real-IP.
+	clientIPHeaders []string
+
+	// mock mode serves examples instead of running route handlers.
+	mockMode bool
+	mocks    map[string]mockResponse
+
+	// key for SignURL.
+	urlSigningKey []byte
+	// codec for Context.SetSecureCookie and SecureCookie.
+	secureCookies *SecureCookies
+
+	// path fix-up options; fixPaths is set once any scope enables one.
+	routing  RouterOptions
+	fixPaths bool
+
+	// serverHooks adjust servers built by buildServer; see ConfigureServer.
+	serverHooks []func(*http.Server)
+
+	// JSON implementation, see SetJSONCodec; encoding/json when nil.
+	json *JSONCodec
+	// codecs set with SetCodec, consulted before the built-in ones.
+	codecs map[string]Codec
+
+	// hashed asset URLs by logical name, see StaticVersioned.
+	assets map[string]string
+
+	// validator used by the Bind*Into methods; validation.ValidateStruct when nil.
+	validator validation.Validator
+
+	// base logger for Context.Logger; slog.Default() when nil.
+	logger *slog.Logger
+
+	// emitter for Context.EmitWebhook, see SetWebhooks.
+	webhooks WebhookEmitter
+	// background task queue, see Tasks.
+	tasks   *TaskQueue
+	tasksMu sync.Mutex
+	// jobs registered with Schedule.
+	sched scheduler
+	// subscribers registered with On and OnAsync.
+	events eventBus
+	// dependencies registered with Provide.
+	deps container
+	// error-to-status mappings registered with MapError.
+	errMap errorMap
+
+	// connection counters and drain flag; see ConnStats and SetDraining.
+	conns    connTracker
+	draining atomic.Bool
 }
 
 // ServerConfig controls the underlying http.Server configuration.
@@ -90,6 +173,36 @@ type ServerConfig struct {
 
 	// BaseContext sets the base context for all connections (optional).
 	BaseContext func(net.Listener) context.Context
+
+	// ProxyProtocol requires a PROXY protocol v1/v2 header on every
+	// connection (HAProxy, AWS NLB in TCP mode) and uses the client address
+	// it carries. ProxyHeaderTimeout bounds reading it (default 5s).
+	ProxyProtocol      bool
+	ProxyHeaderTimeout time.Duration
+
+	// DisableKeepAlives closes each connection after one request.
+	DisableKeepAlives bool
+	// MaxConns caps concurrent connections; further connections wait in the
+	// accept backlog. MaxConnsPerHost caps connections per remote IP (the
+	// socket address, i.e. the proxy's when behind one); extra connections
+	// are closed. MaxIdleConns closes the longest-idle keep-alive
+	// connections beyond the limit. Zero means unlimited.
+	MaxConns        int
+	MaxConnsPerHost int
+	MaxIdleConns    int
+
+	// ConnState is called after the App's own connection tracking.
+	ConnState func(net.Conn, http.ConnState)
+
+	// H2C serves HTTP/2 over cleartext (prior knowledge, as gRPC and
+	// HTTP/2-only clients use) alongside HTTP/1.1, for deployments where TLS
+	// is terminated elsewhere or not used.
+	H2C bool
+
+	// TLSConfig is used by StartTLS, RunTLS and RunAutoTLS (default: TLS 1.2
+	// minimum). With GetCertificate or Certificates set, StartTLS may be
+	// called without certificate files.
+	TLSConfig *tls.Config
 }
 
 func NewApp() *App {
@@ -104,49 +217,91 @@ func (a *App) Plug(m ...Handler) {
 	a.plug = append(a.plug, m...)
 }
 
+// Pre registers middleware that runs before the router matches the request,
+// so it may rewrite c.Request (method, path) to change which route is chosen.
+// Pre handlers also run for requests that match no route. Aborting stops the
+// request before routing.
+func (a *App) Pre(m ...Handler) {
+	a.pre = append(a.pre, m...)
+}
+
 // On registers a route with a custom HTTP method.
-func (a *App) on(method, path string, hs ...Handler) {
+func (a *App) on(method, path string, hs ...Handler) *Route {
 	if len(hs) == 0 {
 		panic("zentrox: On requires at least one handler")
 	}
 	h := hs[len(hs)-1]    // main handler: last element
 	mws := hs[:len(hs)-1] // route middlewares
-	a.rt.add(method, path, append(a.plug, mws...), h)
-	a.trackRoute(method, path, h, append(a.plug, mws...))
+	entry := a.rt.add(method, path, append(a.plug, mws...), h)
+	a.trackRoute(method, path, "", "", h, append(a.plug, mws...))
 
-	// Auto-register OPTIONS handler if not already registered
+	// Auto-register an OPTIONS handler (with Allow) unless one is explicit.
 	if method != http.MethodOptions {
-		optHandler := func(c *Context) {
-			// Use SendStatus to ensure proper status code recording
-			c.SendStatus(http.StatusNoContent)
-		}
-		a.rt.add(http.MethodOptions, path, append(a.plug, mws...), optHandler)
+		a.rt.addAutoOptions(path, append(a.plug, mws...))
 	}
+	return &Route{app: a, entry: entry}
 }
 
 // GET registers a route for GET requests
-func (a *App) GET(path string, handlers ...Handler) {
-	a.on(http.MethodGet, path, handlers...)
+func (a *App) GET(path string, handlers ...Handler) *Route {
+	return a.on(http.MethodGet, path, handlers...)
 }
 
 // POST registers a route for POST requests
-func (a *App) POST(path string, handlers ...Handler) {
-	a.on(http.MethodPost, path, handlers...)
+func (a *App) POST(path string, handlers ...Handler) *Route {
+	return a.on(http.MethodPost, path, handlers...)
 }
 
 // PUT registers a route for PUT requests
-func (a *App) PUT(path string, handlers ...Handler) {
-	a.on(http.MethodPut, path, handlers...)
+func (a *App) PUT(path string, handlers ...Handler) *Route {
+	return a.on(http.MethodPut, path, handlers...)
 }
 
 // PATCH registers a route for PATCH requests
-func (a *App) PATCH(path string, handlers ...Handler) {
-	a.on(http.MethodPatch, path, handlers...)
+func (a *App) PATCH(path string, handlers ...Handler) *Route {
+	return a.on(http.MethodPatch, path, handlers...)
 }
 
 // DELETE registers a route for DELETE requests
-func (a *App) DELETE(path string, handlers ...Handler) {
-	a.on(http.MethodDelete, path, handlers...)
+func (a *App) DELETE(path string, handlers ...Handler) *Route {
+	return a.on(http.MethodDelete, path, handlers...)
+}
+
+// anyMethods are the methods ANY registers. HEAD is answered by the GET route
+// and OPTIONS by the automatic handler.
+var anyMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete,
+}
+
+// ANY registers a route for GET, POST, PUT, PATCH and DELETE requests.
+func (a *App) ANY(path string, handlers ...Handler) []*Route {
+	return a.Match(anyMethods, path, handlers...)
+}
+
+// Match registers the same route for each of methods:
+//
+//	app.Match([]string{"GET", "POST"}, "/webhooks/:provider", webhook)
+func (a *App) Match(methods []string, path string, handlers ...Handler) []*Route {
+	return matchMethods(methods, func(m string) *Route { return a.on(m, path, handlers...) })
+}
+
+// matchMethods registers methods in order, except an explicit OPTIONS route,
+// which goes last so the automatic OPTIONS handler doesn't replace it.
+func matchMethods(methods []string, on func(string) *Route) []*Route {
+	routes := make([]*Route, 0, len(methods))
+	opts := false
+	for _, m := range methods {
+		m = strings.ToUpper(m)
+		if m == http.MethodOptions {
+			opts = true
+			continue
+		}
+		routes = append(routes, on(m))
+	}
+	if opts {
+		routes = append(routes, on(http.MethodOptions))
+	}
+	return routes
 }
 
 // Scope creates a route group with a path prefix and optional middlewares.
@@ -159,15 +314,20 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Acquire a pooled Context instance.
 	ctx := acquireContext(w, r)
 	defer releaseContext(ctx)
-	ctx.realIP = a.clientIP
+	ctx.app = a
 
 	// Wrap writer to capture status/bytes for onResponse.
-	rr := &respRecorder{ResponseWriter: w}
+	rr := &ctx.rec
+	rr.ResponseWriter = w
 	ctx.Writer = rr
+	if a.draining.Load() {
+		w.Header().Set(HeaderConnection, "close")
+	}
 	// Lifecycle: onRequest
 	if a.onRequest != nil {
 		a.onRequest(ctx)
 	}
+	ctx.Emit(EventRequestStart, nil)
 
 	// Propagate app version to context for logs/metrics.
 	if a.version != "" {
@@ -184,6 +344,7 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			}
 			a.onResponse(ctx, st, time.Since(start))
 		}
+		a.emitFinish(ctx, rr.status, time.Since(start))
 	}()
 
 	// Panic hook: notify then rethrow so Recovery/ErrorHandler can handle it.
@@ -192,33 +353,64 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			if a.onPanic != nil {
 				a.onPanic(ctx, rec)
 			}
+			ctx.Emit(EventRequestPanic, rec)
 			panic(rec)
 		}
 	}()
 
+	if len(a.pre) > 0 {
+		ctx.stack = a.pre
+		ctx.Next()
+		if ctx.aborted {
+			return
+		}
+		ctx.stack = nil
+		ctx.index = -1
+		r = ctx.Request
+	}
+
+	rt := a.rt
+	if len(a.hosts) > 0 {
+		rt = a.routerFor(r.Host, r.URL.Path)
+	}
+
 	// Try exact method match first.
-	entry := a.rt.match(r.Method, r.URL.Path, ctx.params)
+	entry := rt.match(r.Method, r.URL.Path, &ctx.params)
+	if a.fixPaths {
+		var done bool
+		if entry, done = a.fixPath(ctx, rt, entry); done {
+			return
+		}
+	}
 
 	if entry == nil && r.Method == http.MethodHead {
-		if getEntry := a.rt.match(http.MethodGet, r.URL.Path, ctx.params); getEntry != nil {
-			hw := &headWriter{ResponseWriter: rr}
-			ctx.Writer = hw
+		ctx.params = ctx.params[:0]
+		if getEntry := rt.match(http.MethodGet, r.URL.Path, &ctx.params); getEntry != nil {
+			ctx.head = headWriter{ResponseWriter: rr}
+			ctx.Writer = &ctx.head
 			ctx.stack = getEntry.stack
+			ctx.entry = getEntry
+			if a.mockMode {
+				ctx.stack = a.mockStack(getEntry)
+			}
 			ctx.Next()
 			return
 		}
 	}
 
 	if entry == nil {
-		allow := a.rt.allowed(r.URL.Path)
-		if len(allow) > 0 {
-			rr.Header().Set(HeaderAllow, strings.Join(allow, ", "))
+		if allow := rt.allowed(r.URL.Path); allow != "" {
+			rr.Header().Set(HeaderAllow, allow)
 
 			if r.Method == http.MethodOptions {
 				rr.WriteHeader(http.StatusNoContent)
 				return
 			}
 
+			if a.problemJSON {
+				ctx.Problem(ProblemDetails{Status: http.StatusMethodNotAllowed, Instance: r.URL.Path})
+				return
+			}
 			http.Error(rr, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 			return
 		}
@@ -228,17 +420,26 @@ func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			ctx.Next()
 			return
 		}
+		if a.problemJSON {
+			ctx.Problem(ProblemDetails{Status: http.StatusNotFound, Instance: r.URL.Path})
+			return
+		}
 		http.NotFound(rr, r)
 		return
 	}
 
 	ctx.stack = entry.stack
+	ctx.entry = entry
+	if a.mockMode {
+		ctx.stack = a.mockStack(entry)
+	}
 	ctx.Next()
 }
 
 // Run keeps backward compatibility: starts a blocking server with
 // production-leaning defaults. Equivalent to ListenAndServe.
 func (a *App) Run(addr string) error {
+	a.exitIfExportingRoutes()
 	cfg := &ServerConfig{Addr: addr}
 	srv := a.buildServer(cfg)
 	return srv.ListenAndServe()
@@ -298,19 +499,50 @@ func (a *App) buildServer(cfg *ServerConfig) *http.Server {
 	if c.BaseContext != nil {
 		srv.BaseContext = c.BaseContext
 	}
-	if a.printRoutes {
-		a.PrintRoutes(os.Stdout)
+	if cfg != nil && cfg.TLSConfig != nil {
+		srv.TLSConfig = cfg.TLSConfig.Clone()
+	} else {
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if cfg != nil {
+		srv.ConnState = a.conns.connState(cfg.MaxIdleConns, cfg.ConnState)
+		srv.SetKeepAlivesEnabled(!cfg.DisableKeepAlives)
+	} else {
+		srv.ConnState = a.conns.connState(0, nil)
+	}
+	if cfg != nil && cfg.H2C {
+		p := new(http.Protocols)
+		p.SetHTTP1(true)
+		p.SetHTTP2(true)
+		p.SetUnencryptedHTTP2(true)
+		srv.Protocols = p
+	}
+	for _, fn := range a.serverHooks {
+		fn(srv)
 	}
+	a.startSchedule()
+	srv.RegisterOnShutdown(a.stopSchedule)
+	a.announce(srv.Addr)
 	return srv
 }
 
+// ConfigureServer registers fn to adjust every *http.Server built by Run,
+// Start, StartTLS, RunTLS and RunAutoTLS after the ServerConfig defaults are
+// applied, e.g. to change timeouts, MaxHeaderBytes or Protocols. Hooks run in
+// registration order.
+func (a *App) ConfigureServer(fn func(*http.Server)) *App {
+	a.serverHooks = append(a.serverHooks, fn)
+	return a
+}
+
 // Start starts the server in a new goroutine and returns *http.Server.
 // This is recommended in production to manage lifecycle explicitly.
 func (a *App) Start(cfg *ServerConfig) (*http.Server, error) {
+	a.exitIfExportingRoutes()
 	srv := a.buildServer(cfg)
 	go func() {
 		// ListenAndServe returns http.ErrServerClosed on Shutdown; do not treat as error.
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := a.serve(srv, cfg, false, "", ""); err != nil && err != http.ErrServerClosed {
 			srv.ErrorLog.Printf("listen error: %v", err)
 		}
 	}()
@@ -319,19 +551,58 @@ func (a *App) Start(cfg *ServerConfig) (*http.Server, error) {
 
 // StartTLS starts a TLS server in a new goroutine and returns *http.Server.
 func (a *App) StartTLS(cfg *ServerConfig, certFile, keyFile string) (*http.Server, error) {
+	a.exitIfExportingRoutes()
 	srv := a.buildServer(cfg)
 	go func() {
-		if err := srv.ListenAndServeTLS(certFile, keyFile); err != nil && err != http.ErrServerClosed {
+		if err := a.serve(srv, cfg, true, certFile, keyFile); err != nil && err != http.ErrServerClosed {
 			srv.ErrorLog.Printf("listen (tls) error: %v", err)
 		}
 	}()
 	return srv, nil
 }
 
+// serve listens on srv.Addr, wraps the listener as configured by cfg and
+// serves HTTP, or HTTPS when useTLS is set.
+func (a *App) serve(srv *http.Server, cfg *ServerConfig, useTLS bool, certFile, keyFile string) error {
+	if cfg == nil || (!cfg.ProxyProtocol && cfg.MaxConns <= 0 && cfg.MaxConnsPerHost <= 0) {
+		if useTLS {
+			return srv.ListenAndServeTLS(certFile, keyFile)
+		}
+		return srv.ListenAndServe()
+	}
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	if cfg.MaxConns > 0 || cfg.MaxConnsPerHost > 0 {
+		ln = newLimitListener(ln, cfg.MaxConns, cfg.MaxConnsPerHost, &a.conns)
+	}
+	if cfg.ProxyProtocol {
+		ln = NewProxyProtocolListener(ln, cfg.ProxyHeaderTimeout)
+	}
+	if useTLS {
+		return srv.ServeTLS(ln, certFile, keyFile)
+	}
+	return srv.Serve(ln)
+}
+
 // Shutdown requests a graceful stop. The server stops accepting new connections
-// and waits for in-flight requests until ctx is done.
+// and waits for in-flight requests until ctx is done. Scheduled jobs, queued
+// webhooks and background tasks are then drained within the same deadline,
+// see Schedule, SetWebhooks and Tasks.
 func (a *App) Shutdown(ctx context.Context, srv *http.Server) error {
-	return srv.Shutdown(ctx)
+	err := srv.Shutdown(ctx)
+	err = errors.Join(err, a.drainSchedule(ctx))
+	if cl, ok := a.webhooks.(interface{ Close(context.Context) error }); ok {
+		err = errors.Join(err, cl.Close(ctx))
+	}
+	a.tasksMu.Lock()
+	tasks := a.tasks
+	a.tasksMu.Unlock()
+	if tasks != nil {
+		err = errors.Join(err, tasks.Close(ctx))
+	}
+	return err
 }
 
 // Health mounts tiny health endpoints onto the current App.
@@ -371,6 +642,20 @@ func (a *App) SetNotFound(h Handler) *App {
 	return a
 }
 
+// SetProblemJSON makes the App send errors as application/problem+json
+// (RFC 9457): unmatched routes (404) and methods (405), Context.Fail,
+// typed handlers built with H, and the errors and panics handled by
+// middleware.ErrorHandler and Recovery.
+func (a *App) SetProblemJSON(on bool) *App {
+	a.problemJSON = on
+	return a
+}
+
+// ProblemJSON reports whether SetProblemJSON is on.
+func (a *App) ProblemJSON() bool {
+	return a.problemJSON
+}
+
 // SetOnPanic registers a hook called when a panic occurs.
 // The panic value is forwarded and will be re-panicked after the hook returns.
 func (a *App) SetOnPanic(fn func(*Context, any)) *App {
@@ -384,14 +669,42 @@ func (a *App) SetVersion(v string) *App {
 	return a
 }
 
+// SetValidator replaces the validation engine used by BindInto,
+// BindJSONInto, BindFormInto, BindQueryInto and BindJSONAPIInto.
+// The default is validation.ValidateStruct.
+func (a *App) SetValidator(v validation.Validator) *App {
+	a.validator = v
+	return a
+}
+
+// SetLogger sets the base logger used by Context.Logger.
+func (a *App) SetLogger(l *slog.Logger) *App {
+	a.logger = l
+	return a
+}
+
+// Logger returns the logger set with SetLogger, or slog.Default().
+func (a *App) Logger() *slog.Logger {
+	if a.logger == nil {
+		return slog.Default()
+	}
+	return a.logger
+}
+
 // Version returns the configured application version.
 func (a *App) Version() string {
 	return a.version
 }
 
-// Enable/disable route printing when server starts
-func (a *App) SetPrintRoutes(v bool) *App {
+// Enable/disable route printing when server starts. An optional
+// PrintRoutesOptions selects the format and destination (default: table on
+// stdout).
+func (a *App) SetPrintRoutes(v bool, opt ...PrintRoutesOptions) *App {
 	a.printRoutes = v
+	if len(opt) > 0 {
+		a.routesFormat = opt[0].Format
+		a.routesOut = opt[0].Output
+	}
 	return a
 }
 
@@ -436,6 +749,21 @@ func (a *App) SetTrustedProxies(values ...string) *App {
 	return a
 }
 
+// SetClientIPHeaders sets which headers, in order, a trusted proxy may use to
+// report the client address, e.g. "CF-Connecting-IP" behind Cloudflare or
+// "Forwarded" (RFC 7239). Comma-separated chains (X-Forwarded-For, Forwarded)
+// are walked from the right, skipping trusted proxies. Default:
+// X-Forwarded-For, then X-Real-IP.
+func (a *App) SetClientIPHeaders(headers ...string) *App {
+	a.clientIPHeaders = nil
+	for _, h := range headers {
+		a.clientIPHeaders = append(a.clientIPHeaders, http.CanonicalHeaderKey(strings.TrimSpace(h)))
+	}
+	return a
+}
+
+var defaultClientIPHeaders = []string{HeaderXForwardedFor, HeaderXRealIP}
+
 func (a *App) isTrustedProxy(ip netip.Addr) bool {
 	if !ip.IsValid() {
 		return false
@@ -491,9 +819,27 @@ func (a *App) clientIP(r *http.Request) string {
 		return remote.String()
 	}
 
-	xff := parseHeaderIPs(r.Header.Get(HeaderXForwardedFor))
-	if len(xff) > 0 {
-		chain := append(xff, remote)
+	headers := a.clientIPHeaders
+	if headers == nil {
+		headers = defaultClientIPHeaders
+	}
+	for _, h := range headers {
+		var chain []netip.Addr
+		switch h {
+		case HeaderXForwardedFor:
+			chain = parseHeaderIPs(strings.Join(r.Header.Values(h), ","))
+		case HeaderForwarded:
+			chain = parseForwarded(r.Header.Values(h))
+		default:
+			if ip, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get(h))); err == nil {
+				return ip.String()
+			}
+			continue
+		}
+		if len(chain) == 0 {
+			continue
+		}
+		chain = append(chain, remote)
 		for i := len(chain) - 1; i >= 0; i-- {
 			if !a.isTrustedProxy(chain[i]) {
 				return chain[i].String()
@@ -502,13 +848,30 @@ func (a *App) clientIP(r *http.Request) string {
 		return chain[0].String()
 	}
 
-	if xr := strings.TrimSpace(r.Header.Get(HeaderXRealIP)); xr != "" {
-		if ip, err := netip.ParseAddr(xr); err == nil {
-			return ip.String()
+	return remote.String()
+}
+
+// parseForwarded extracts the for= addresses of RFC 7239 Forwarded headers,
+// e.g. `for=192.0.2.60;proto=http, for="[2001:db8::1]:4711"`.
+func parseForwarded(values []string) []netip.Addr {
+	var out []netip.Addr
+	for _, v := range values {
+		for _, elem := range strings.Split(v, ",") {
+			for _, pair := range strings.Split(elem, ";") {
+				k, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+				if !ok || !strings.EqualFold(k, "for") {
+					continue
+				}
+				val = strings.Trim(val, `"`)
+				if ap, err := netip.ParseAddrPort(val); err == nil {
+					out = append(out, ap.Addr())
+				} else if ip, err := netip.ParseAddr(strings.Trim(val, "[]")); err == nil {
+					out = append(out, ip)
+				}
+			}
 		}
 	}
-
-	return remote.String()
+	return out
 }
 
 // Get route list (copy & sort for stability)
@@ -521,6 +884,9 @@ func (a *App) ListRoutes() []RouteInfo {
 		out = append(out, ri)
 	}
 	sort.Slice(out, func(i, j int) bool {
+		if out[i].Host != out[j].Host {
+			return out[i].Host < out[j].Host
+		}
 		if out[i].Path == out[j].Path {
 			return out[i].Method < out[j].Method
 		}
@@ -542,18 +908,34 @@ func (a *App) updateRouteName(method, fullPath, handlerName string) {
 	a.routeIndex[key] = ri
 }
 
+// hasPattern reports whether any method is registered for the exact pattern.
+func (a *App) hasPattern(pattern string) bool {
+	for _, ri := range a.routeIndex {
+		if ri.Path == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintRoutes writes the route table to w.
 func (a *App) PrintRoutes(w io.Writer) {
-	for _, r := range a.ListRoutes() {
+	writeRoutesTable(w, a.ListRoutes())
+}
+
+func writeRoutesTable(w io.Writer, routes []RouteInfo) {
+	for _, r := range routes {
 		mw := r.Middlewares
 		info := r.HandlerName
+		p := r.Host + r.Path
 		if r.File != "" && r.Line > 0 {
 			info = fmt.Sprintf("%s (%s:%d)", info, path.Base(r.File), r.Line)
 		}
 		if len(mw) == 0 {
-			fmt.Fprintf(w, " %-6s %-32s -> %s\n", "["+r.Method+"]", r.Path, info)
+			fmt.Fprintf(w, " %-6s %-32s -> %s\n", "["+r.Method+"]", p, info)
 		} else {
 			fmt.Fprintf(w, " %-6s %-32s -> %s  (mw: %s)\n",
-				"["+r.Method+"]", r.Path, info, strings.Join(mw, ", "))
+				"["+r.Method+"]", p, info, strings.Join(mw, ", "))
 		}
 	}
 }
@@ -562,7 +944,12 @@ func handlerName(h Handler) (string, string, int) {
 	if h == nil {
 		return "", "", 0
 	}
-	p := reflect.ValueOf(h).Pointer()
+	return funcName(h)
+}
+
+// funcName returns the short name and source position of the function f.
+func funcName(f any) (string, string, int) {
+	p := reflect.ValueOf(f).Pointer()
 	if p == 0 {
 		return "", "", 0
 	}
@@ -584,6 +971,41 @@ func handlerName(h Handler) (string, string, int) {
 	return name, file, line
 }
 
+// skipHandlers returns mws without the handlers whose name matches one of
+// names; see Route.SkipPlugs.
+func skipHandlers(mws []Handler, names []string) []Handler {
+	if len(names) == 0 {
+		return mws
+	}
+	out := make([]Handler, 0, len(mws))
+	for _, mw := range mws {
+		n, _, _ := handlerName(mw)
+		if i := strings.Index(n, "."); i >= 0 {
+			n = n[:i]
+		}
+		skip := false
+		for _, name := range names {
+			if strings.EqualFold(n, name) {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			out = append(out, mw)
+		}
+	}
+	return out
+}
+
+// routeKey identifies a route in routeIndex.
+func routeKey(method, host, fullPath string) string {
+	key := strings.ToUpper(method) + "\t" + fullPath
+	if host != "" {
+		key += "\t" + host
+	}
+	return key
+}
+
 func middlewareNames(mws []Handler) []string {
 	out := make([]string, 0, len(mws))
 	for _, mw := range mws {
@@ -594,30 +1016,37 @@ func middlewareNames(mws []Handler) []string {
 }
 
 // internal helper to track each registration
-func (a *App) trackRoute(method, fullPath string, h Handler, mws []Handler) {
+func (a *App) trackRoute(method, fullPath, group, host string, h Handler, mws []Handler) {
 	if a.routeIndex == nil {
 		a.routeIndex = make(map[string]RouteInfo)
 	}
-	key := strings.ToUpper(method) + "\t" + fullPath
+	key := routeKey(method, host, fullPath)
 	hn, file, line := handlerName(h)
-	a.routeIndex[key] = RouteInfo{
+	ri := RouteInfo{
 		Method:      strings.ToUpper(method),
+		Host:        host,
 		Path:        fullPath,
 		HandlerName: hn,
 		Middlewares: middlewareNames(mws),
 		File:        file,
 		Line:        line,
+		Group:       group,
 	}
+	a.routeIndex[key] = ri
 }
 
 // Scope (Route Group)
 type Scope struct {
-	app    *App
-	prefix string
-	plug   []Handler // group-level middlewares
+	app     *App
+	prefix  string
+	plug    []Handler // group-level middlewares
+	timeout time.Duration
+	skip    []string // middleware names dropped from routes, see SkipPlugs
+	routing *RouterOptions
+	host    *hostRouter // see App.Host
 }
 
-func (s *Scope) on(method, rel string, hs ...Handler) {
+func (s *Scope) on(method, rel string, hs ...Handler) *Route {
 	if len(hs) == 0 {
 		panic("zentrox: Scope.On requires at least one handler")
 	}
@@ -625,40 +1054,59 @@ func (s *Scope) on(method, rel string, hs ...Handler) {
 	h := hs[len(hs)-1]
 	mws := hs[:len(hs)-1]
 	stack := append(s.app.plug, append(s.plug, mws...)...)
-	s.app.rt.add(method, fullPath, stack, h)
-	s.app.trackRoute(method, fullPath, h, stack)
+	stack = skipHandlers(stack, s.skip)
+	rt, host := s.app.rt, ""
+	if s.host != nil {
+		rt, host = s.host.rt, s.host.pattern
+		if s.host.suffix {
+			host += ".*"
+		}
+	}
+	entry := rt.add(method, fullPath, stack, h)
+	entry.timeout = s.timeout
+	entry.routing = s.routing
+	entry.host = host
+	s.app.trackRoute(method, fullPath, s.prefix, host, h, stack)
 
 	if method != http.MethodOptions {
-		optHandler := func(c *Context) {
-			c.SendStatus(http.StatusNoContent)
-		}
-		s.app.rt.add(http.MethodOptions, fullPath, stack, optHandler)
+		rt.addAutoOptions(fullPath, stack)
 	}
+	return &Route{app: s.app, entry: entry}
 }
 
 // GET registers a route for GET requests
-func (s *Scope) GET(path string, handlers ...Handler) {
-	s.on(http.MethodGet, path, handlers...)
+func (s *Scope) GET(path string, handlers ...Handler) *Route {
+	return s.on(http.MethodGet, path, handlers...)
 }
 
 // POST registers a route for POST requests
-func (s *Scope) POST(path string, handlers ...Handler) {
-	s.on(http.MethodPost, path, handlers...)
+func (s *Scope) POST(path string, handlers ...Handler) *Route {
+	return s.on(http.MethodPost, path, handlers...)
 }
 
 // PUT registers a route for PUT requests
-func (s *Scope) PUT(path string, handlers ...Handler) {
-	s.on(http.MethodPut, path, handlers...)
+func (s *Scope) PUT(path string, handlers ...Handler) *Route {
+	return s.on(http.MethodPut, path, handlers...)
 }
 
 // PATCH registers a route for PATCH requests
-func (s *Scope) PATCH(path string, handlers ...Handler) {
-	s.on(http.MethodPatch, path, handlers...)
+func (s *Scope) PATCH(path string, handlers ...Handler) *Route {
+	return s.on(http.MethodPatch, path, handlers...)
 }
 
 // DELETE registers a route for DELETE requests
-func (s *Scope) DELETE(path string, handlers ...Handler) {
-	s.on(http.MethodDelete, path, handlers...)
+func (s *Scope) DELETE(path string, handlers ...Handler) *Route {
+	return s.on(http.MethodDelete, path, handlers...)
+}
+
+// ANY registers a route for GET, POST, PUT, PATCH and DELETE requests.
+func (s *Scope) ANY(path string, handlers ...Handler) []*Route {
+	return s.Match(anyMethods, path, handlers...)
+}
+
+// Match registers the same route for each of methods.
+func (s *Scope) Match(methods []string, path string, handlers ...Handler) []*Route {
+	return matchMethods(methods, func(m string) *Route { return s.on(m, path, handlers...) })
 }
 
 // Use adds middleware to this scope
@@ -671,18 +1119,39 @@ func (s *Scope) Scope(prefix string, mws ...Handler) *Scope {
 	combinedMws := append([]Handler{}, s.plug...)
 	combinedMws = append(combinedMws, mws...)
 	return &Scope{
-		app:    s.app,
-		prefix: s.prefix + prefix,
-		plug:   combinedMws,
+		app:     s.app,
+		prefix:  s.prefix + prefix,
+		plug:    combinedMws,
+		timeout: s.timeout,
+		skip:    append([]string(nil), s.skip...),
+		routing: s.routing,
+		host:    s.host,
 	}
 }
 
+// SkipPlugs drops the named middleware (see Route.SkipPlugs) from routes
+// registered on this scope (and nested scopes) afterwards:
+//
+//	ops := app.Scope("/ops").SkipPlugs("Logger")
+//	ops.GET("/healthz", healthz)
+//	ops.GET("/metrics", metrics)
+func (s *Scope) SkipPlugs(names ...string) *Scope {
+	s.skip = append(s.skip, names...)
+	return s
+}
+
+// Timeout overrides the Timeout middleware duration for routes registered
+// on this scope (and nested scopes) afterwards.
+func (s *Scope) Timeout(d time.Duration) *Scope {
+	s.timeout = d
+	return s
+}
+
 // Context pooling
 var ctxPool = sync.Pool{
 	New: func() any {
 		return &Context{
-			params: map[string]string{},
-			store:  map[string]any{},
+			params: make([]param, 0, 4),
 			index:  -1,
 		}
 	},
@@ -695,27 +1164,30 @@ func acquireContext(w http.ResponseWriter, r *http.Request) *Context {
 	c.index = -1
 	c.aborted = false
 	c.err = nil
-	c.realIP = nil
-	// params/store already exists; release will only delete the key
+	// params/store already exist; release only truncates/clears them
 	return c
 }
 
 func releaseContext(c *Context) {
-	// Clean maps without reallocations.
-	for k := range c.params {
-		delete(c.params, k)
-	}
-	for k := range c.store {
-		delete(c.store, k)
-	}
+	// Reuse backing storage without reallocations.
+	clear(c.params)
+	c.params = c.params[:0]
+	clear(c.store)
+	c.query = nil
+	c.rawQuery = ""
+	c.rec = respRecorder{}
+	c.head = headWriter{}
+	clear(c.logAttrs)
+	c.logAttrs = c.logAttrs[:0]
 	// Clear references to avoid retaining memory.
 	c.Writer = nil
 	c.Request = nil
+	c.app = nil
+	c.entry = nil
 	c.stack = nil
 	c.err = nil
 	c.aborted = false
 	c.index = -1
-	c.realIP = nil
 
 	ctxPool.Put(c)
 }
@@ -742,6 +1214,13 @@ func (w *headWriter) Write(b []byte) (int, error) {
 	return len(b), nil
 }
 
+func (w *headWriter) WriteString(s string) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return len(s), nil
+}
+
 // respRecorder captures status code and bytes without changing behavior.
 // It is used to feed onResponse hook with final status/latency.
 type respRecorder struct {
@@ -776,6 +1255,17 @@ func (w *respRecorder) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// WriteString avoids converting s to a []byte when the underlying writer
+// supports it, as net/http's does.
+func (w *respRecorder) WriteString(s string) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := io.WriteString(w.ResponseWriter, s)
+	w.bytes += n
+	return n, err
+}
+
 func (w *respRecorder) Flush() {
 	if f, ok := w.ResponseWriter.(http.Flusher); ok {
 		f.Flush()
@@ -806,6 +1296,14 @@ type StaticOptions struct {
 	Index string
 	// If true, do not auto-serve index when the request equals the prefix.
 	DisableIndex bool
+	// DirIndex overrides Index for specific directories, keyed by their path
+	// under Dir (e.g. "/docs": "README.html"); "" disables the index there.
+	DirIndex map[string]string
+	// Browse lists directories that have no index file. Dotfiles and files
+	// outside AllowedExt are left out.
+	Browse bool
+	// BrowseRender, if set, renders listings instead of the built-in HTML page.
+	BrowseRender func(c *Context, l DirListing)
 	// If non-zero, sets "Cache-Control: public, max-age=<seconds>" (otherwise no-cache).
 	MaxAge time.Duration
 	// If true, use strong ETag (SHA1 of content). Otherwise weak ETag (size-modtime).
@@ -815,7 +1313,9 @@ type StaticOptions struct {
 }
 
 // Static mounts a read-only file server under a prefix.
-// It sets ETag and Last-Modified, and handles If-None-Match / If-Modified-Since.
+// It sets ETag and Last-Modified, answers If-None-Match / If-Modified-Since
+// with 304, and serves Range / If-Range requests (206) with Accept-Ranges so
+// downloads and media players can resume and seek.
 // Security notes:
 // - Prevents path traversal ("..") by cleaning and validating joined path.
 // - Optional extension allow-list (if non-empty).
@@ -849,14 +1349,9 @@ func (a *App) Static(prefix string, opt StaticOptions) {
 	rootPath := prefix
 	h := func(c *Context) {
 		rel := c.Param("filepath")
-		// When requesting the prefix root ("/assets" == "/assets/"), serve index if allowed
-		if rel == "" || rel == "/" {
-			if !opt.DisableIndex && opt.Index != "" {
-				rel = "/" + opt.Index
-			} else {
-				c.String(http.StatusNotFound, MsgNotFound)
-				return
-			}
+		// The prefix root ("/assets" == "/assets/") is the root directory
+		if rel == "" {
+			rel = "/"
 		}
 
 		// Clean and join; prevent traversal outside root
@@ -871,15 +1366,6 @@ func (a *App) Static(prefix string, opt StaticOptions) {
 			return
 		}
 
-		// Extension allow-list check (if provided)
-		if len(allow) > 0 {
-			ext := strings.ToLower(filepath.Ext(target))
-			if _, ok := allow[ext]; !ok {
-				c.String(http.StatusForbidden, MsgForbidden)
-				return
-			}
-		}
-
 		// Stat file
 		fi, err := os.Stat(target)
 		if err != nil {
@@ -891,20 +1377,31 @@ func (a *App) Static(prefix string, opt StaticOptions) {
 			return
 		}
 		if fi.IsDir() {
-			// If directory is requested, optionally serve index
-			if !opt.DisableIndex && opt.Index != "" {
-				target = filepath.Join(target, opt.Index)
+			// If directory is requested, optionally serve its index, else list it
+			dir := target
+			if index := opt.indexFor(filepath.ToSlash(clean)); index != "" {
+				target = filepath.Join(dir, index)
 				fi, err = os.Stat(target)
-				if err != nil || fi.IsDir() {
-					c.String(http.StatusNotFound, MsgNotFound)
+			}
+			if target == dir || err != nil || fi.IsDir() {
+				if opt.Browse {
+					serveDirListing(c, opt, dir, prefix, filepath.ToSlash(clean), allow)
 					return
 				}
-			} else {
 				c.String(http.StatusNotFound, MsgNotFound)
 				return
 			}
 		}
 
+		// Extension allow-list check (if provided)
+		if len(allow) > 0 {
+			ext := strings.ToLower(filepath.Ext(target))
+			if _, ok := allow[ext]; !ok {
+				c.String(http.StatusForbidden, MsgForbidden)
+				return
+			}
+		}
+
 		// Compute ETag
 		etag, lastMod := "", fi.ModTime().UTC()
 		if opt.UseStrongETag {
@@ -928,35 +1425,8 @@ func (a *App) Static(prefix string, opt StaticOptions) {
 			c.SetHeader(HeaderCacheControl, CacheControlNoCache)
 		}
 
-		// Conditional requests
-		if inm := c.GetHeader(HeaderIfNoneMatch); inm != "" && etag != "" {
-			if etagMatch(inm, etag) {
-				c.Writer.WriteHeader(http.StatusNotModified)
-				return
-			}
-		}
-		if ims := c.GetHeader(HeaderIfModifiedSince); ims != "" {
-			if t, err := time.Parse(http.TimeFormat, ims); err == nil {
-				// If not modified since, return 304
-				if !lastMod.After(t) {
-					c.Writer.WriteHeader(http.StatusNotModified)
-					return
-				}
-			}
-		}
-
-		// Content-Type best effort
-		if ct := mime.TypeByExtension(filepath.Ext(target)); ct != "" {
-			c.SetHeader(HeaderContentType, ct)
-		}
-
-		// HEAD should not write body
-		if c.Request.Method == http.MethodHead {
-			c.Writer.WriteHeader(http.StatusOK)
-			return
-		}
-
-		// Stream the file to client
+		// Stream the file to client. ServeContent answers If-None-Match,
+		// If-Modified-Since, Range and If-Range, and handles HEAD.
 		f, err := os.Open(target)
 		if err != nil {
 			c.String(http.StatusInternalServerError, MsgOpenError)
@@ -964,8 +1434,7 @@ func (a *App) Static(prefix string, opt StaticOptions) {
 		}
 		defer f.Close()
 
-		c.Writer.WriteHeader(http.StatusOK)
-		_, _ = io.Copy(c.Writer, f)
+		http.ServeContent(c.Writer, c.Request, fi.Name(), lastMod, f)
 	}
 
 	a.GET(pat, h)